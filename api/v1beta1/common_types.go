@@ -27,8 +27,25 @@ import (
 const (
 	// UnhealthyAnnotation is the annotation that sets unhealthy status of BMH.
 	UnhealthyAnnotation = "capi.metal3.io/unhealthy"
+	// PermanentlyFailedAnnotation is the annotation marking a BMH as known to
+	// be bad. The host is neither chosen for a Metal3Machine nor remediated
+	// until the annotation is removed manually.
+	PermanentlyFailedAnnotation = "metal3.io/permanently-failed"
 
 	LiveISODiskFormat = "live-iso"
+
+	// HostCapabilityLabelPrefix prefixes the labels reporting the capabilities
+	// of a BareMetalHost. The label is set to "true" when the host has the
+	// capability, e.g. capability.metal3.io/tpm: "true". The labels are
+	// maintained by the operator of the hosts, neither the BareMetal Operator
+	// nor CAPM3 set them.
+	HostCapabilityLabelPrefix = "capability.metal3.io/"
+	// HostCapabilitySecureBoot is the capability of the hosts able to boot with
+	// UEFI secure boot. It is also reported by the UEFISecureBoot boot mode.
+	HostCapabilitySecureBoot = "secure-boot"
+	// HostTopologyLabelPrefix prefixes the labels reporting the CPU topology
+	// of a BareMetalHost, e.g. topology.metal3.io/numa-nodes: "2".
+	HostTopologyLabelPrefix = "topology.metal3.io/"
 )
 
 // APIEndpoint represents a reachable Kubernetes API endpoint.
@@ -51,6 +68,47 @@ type HostSelector struct {
 	// Label match expressions that must be true on a chosen BareMetalHost
 	// +optional
 	MatchExpressions []HostSelectorRequirement `json:"matchExpressions,omitempty"`
+
+	// RequiredCapabilities lists the capabilities a chosen BareMetalHost must
+	// have, e.g. secure-boot, as labelled on the BareMetalHost by its operator.
+	// See HostCapabilityLabelPrefix.
+	// +optional
+	RequiredCapabilities []string `json:"requiredCapabilities,omitempty"`
+
+	// MinimumHardware lists the minimum hardware, as reported by the
+	// inspection of the BareMetalHost, a chosen BareMetalHost must have. Among
+	// the matching BareMetalHosts, the one exceeding the minimums the least is
+	// chosen.
+	// +optional
+	MinimumHardware *HardwareRequirements `json:"minimumHardware,omitempty"`
+
+	// ReservedHosts is the number of available BareMetalHosts matching the
+	// selector kept in reserve, e.g. as warm spares for fast failover. A
+	// BareMetalHost is only chosen with the selector while more than
+	// ReservedHosts of them are available.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ReservedHosts int `json:"reservedHosts,omitempty"`
+}
+
+// HardwareRequirements describes the minimum hardware of a BareMetalHost.
+type HardwareRequirements struct {
+	// CPUCount is the minimum number of CPUs.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CPUCount int `json:"cpuCount,omitempty"`
+
+	// RAMMebibytes is the minimum amount of RAM in MiB.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RAMMebibytes int `json:"ramMebibytes,omitempty"`
+
+	// Topology lists the CPU topology, as labelled on the BareMetalHost during
+	// onboarding, a chosen BareMetalHost must have. Each key is matched
+	// against the value of its label with the HostTopologyLabelPrefix, e.g.
+	// numa-nodes: "2" requires the topology.metal3.io/numa-nodes: "2" label.
+	// +optional
+	Topology map[string]string `json:"topology,omitempty"`
 }
 
 type HostSelectorRequirement struct {
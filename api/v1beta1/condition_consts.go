@@ -30,6 +30,12 @@ const (
 	// occurred. The `Message` field of the Condition should be consluted for
 	// details on the failure.
 	InternalFailureReason = "InternalFailureOccured"
+	// HostsAvailableCondition reports whether there are enough BareMetalHosts
+	// to satisfy the replicas of the Cluster.
+	HostsAvailableCondition clusterv1.ConditionType = "HostsAvailable"
+	// InsufficientHostsReason is used when the Cluster requires more
+	// BareMetalHosts than are in use by the Cluster or free to be claimed.
+	InsufficientHostsReason = "InsufficientHosts"
 )
 
 // Metal3Machine Conditions and Reasons.
@@ -42,6 +48,8 @@ const (
 	WaitingForClusterInfrastructureReason = "WaitingForClusterInfrastructure"
 	// WaitingForBootstrapReadyReason used when waiting for bootstrap to be ready before proceeding.
 	WaitingForBootstrapReadyReason = "WaitingForBootstrapReady"
+	// WaitingForBootstrapDataReason used when waiting for the bootstrap data secret to exist before provisioning.
+	WaitingForBootstrapDataReason = "WaitingForBootstrapData"
 	// AssociateBMHFailedReason documents any errors while associating Metal3Machine with a BaremetalHost.
 	AssociateBMHFailedReason = "AssociateBMHFailed"
 	// WaitingForMetal3MachineOwnerRefReason is used when Metal3Machine is waiting for OwnerReference to be
@@ -51,10 +59,41 @@ const (
 	Metal3MachinePausedReason = "Metal3MachinePaused"
 	// WaitingforMetal3ClusterReason is used when Metal3Machine is waiting for Metal3Cluster.
 	WaitingforMetal3ClusterReason = "WaitingforMetal3Cluster"
-	// PauseAnnotationRemoveFailedReason is used when failed to remove/check pause annotation on associated bmh.
+	// PauseAnnotationRemoveFailedReason (Severity=Warning) is used when failed to remove/check pause annotation on associated bmh.
 	PauseAnnotationRemoveFailedReason = "PauseAnnotationRemoveFailed"
-	// PauseAnnotationSetFailedReason is used when failed to set pause annotation on associated bmh.
+	// PauseAnnotationSetFailedReason (Severity=Warning) is used when failed to set pause annotation on associated bmh.
 	PauseAnnotationSetFailedReason = "PauseAnnotationSetFailedReason"
+	// HostReservationExpiredReason is used when the associated BaremetalHost was
+	// released because it was not provisioned within the reservation TTL.
+	HostReservationExpiredReason = "HostReservationExpired"
+	// HostFencedReason is used when the associated BaremetalHost was released
+	// after being fenced by a remediation, to associate the Metal3Machine with
+	// another BaremetalHost.
+	HostFencedReason = "HostFenced"
+	// HostReferencedCondition reports whether the consumerRef of the
+	// associated BaremetalHost references the Metal3Machine. It turns false
+	// when the consumerRef was cleared manually and could not be set again.
+	HostReferencedCondition clusterv1.ConditionType = "HostReferenced"
+	// HostReclaimedReason is used in the event recorded when the consumerRef
+	// of the BaremetalHost was set again to reference the Metal3Machine.
+	HostReclaimedReason = "HostReclaimed"
+	// HostReferenceLostReason (Severity=Error) is used when the Metal3Machine
+	// was marked failed since the BaremetalHost could not be claimed again.
+	HostReferenceLostReason = "HostReferenceLost"
+
+	// HostSelectorCondition documents which host selector of the Metal3Machine
+	// the associated BaremetalHost was chosen with. It is false when a fallback
+	// host selector was used, or when no BaremetalHost could be chosen.
+	HostSelectorCondition clusterv1.ConditionType = "HostSelector"
+	// FallbackHostSelectorReason is used when the BaremetalHost was chosen with
+	// one of the fallback host selectors.
+	FallbackHostSelectorReason = "FallbackHostSelector"
+	// HostsReservedReason (Severity=Info) is used when no BaremetalHost was chosen because the
+	// available ones are kept in reserve by the host selectors.
+	HostsReservedReason = "HostsReserved"
+	// NoMatchingHostReason (Severity=Warning) is used when no available
+	// BaremetalHost matches the host selectors of the Metal3Machine.
+	NoMatchingHostReason = "NoMatchingHost"
 
 	// KubernetesNodeReadyCondition documents the transition of a Metal3Machine into a Kubernetes Node.
 	KubernetesNodeReadyCondition clusterv1.ConditionType = "KubernetesNodeReady"
@@ -62,6 +101,40 @@ const (
 	MissingBMHReason = "MissingBMH"
 	// Could not set the ProviderID on the target cluster's Node object.
 	SettingProviderIDOnNodeFailedReason = "SettingProviderIDOnNodeFailed"
+	// Could not set the node role label on the target cluster's Node object.
+	SettingNodeRoleFailedReason = "SettingNodeRoleFailed"
+	// HostPoweredOnCondition documents whether the associated BaremetalHost is powered on.
+	HostPoweredOnCondition clusterv1.ConditionType = "HostPoweredOn"
+	// WaitingForPowerOnReason is used when waiting for the associated BaremetalHost to be
+	// powered on for the power-on grace period before proceeding.
+	WaitingForPowerOnReason = "WaitingForPowerOn"
+	// HostHealthyCondition documents whether the associated BaremetalHost is
+	// free of errors. Its transition time records when the host entered the
+	// error state.
+	HostHealthyCondition clusterv1.ConditionType = "HostHealthy"
+	// HostErrorReason (Severity=Error) is used when the associated
+	// BaremetalHost reports an error.
+	HostErrorReason = "HostError"
+	// DeprovisionStuckCondition documents that the associated BaremetalHost has
+	// been deprovisioning for longer than the deprovision timeout.
+	DeprovisionStuckCondition clusterv1.ConditionType = "DeprovisionStuck"
+	// DeprovisionTimeoutReason is used when the associated BaremetalHost hit the
+	// deprovision timeout.
+	DeprovisionTimeoutReason = "DeprovisionTimeout"
+	// UserDataSizeCondition documents whether the userdata rendered for the
+	// Metal3Data is within the configured size threshold.
+	UserDataSizeCondition clusterv1.ConditionType = "UserDataSize"
+	// UserDataTooLargeReason (Severity=Warning) is used when the user data
+	// exceeds the size threshold, which some BMCs or firmware fail on.
+	UserDataTooLargeReason = "UserDataTooLarge"
+	// BootstrapDataUpToDateCondition documents whether the associated
+	// BaremetalHost was provisioned with the current bootstrap data of the
+	// Machine. It is only set when the BootstrapDataChangePolicy is not Ignore.
+	BootstrapDataUpToDateCondition clusterv1.ConditionType = "BootstrapDataUpToDate"
+	// BootstrapDataChangedReason is used when the bootstrap data secret
+	// changed after the host was provisioned, with the Warning severity when
+	// the change is only reported and Info when the host is reprovisioned.
+	BootstrapDataChangedReason = "BootstrapDataChanged"
 	// Metal3DataReadyCondition reports a summary of Metal3Data status.
 	Metal3DataReadyCondition clusterv1.ConditionType = "Metal3DataReady"
 	// WaitingForMetal3DataReason used when waiting for Metal3Data
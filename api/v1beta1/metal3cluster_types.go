@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	capierrors "sigs.k8s.io/cluster-api/errors"
@@ -50,6 +51,12 @@ type Metal3ClusterSpec struct {
 	// Default value is true, it is set in the webhook.
 	// +optional
 	CloudProviderEnabled *bool `json:"cloudProviderEnabled,omitempty"`
+	// DefaultDataTemplate is a reference to the Metal3DataTemplate used by the
+	// Metal3Machines of the cluster that do not reference one in their
+	// dataTemplate field. The namespace defaults to the one of the
+	// Metal3Machine.
+	// +optional
+	DefaultDataTemplate *corev1.ObjectReference `json:"defaultDataTemplate,omitempty"`
 }
 
 // IsValid returns an error if the object is not valid, otherwise nil. The
@@ -93,6 +100,11 @@ type Metal3ClusterStatus struct {
 	// metal3Cluster controller after creation.
 	// +optional
 	Ready bool `json:"ready"`
+	// ActiveRemediations is the number of Metal3Remediations of the Machines
+	// of the cluster that are neither finished nor being deleted. It is
+	// refreshed when the Metal3Cluster is reconciled.
+	// +optional
+	ActiveRemediations int `json:"activeRemediations,omitempty"`
 	// Conditions defines current service state of the Metal3Cluster.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
@@ -108,6 +120,7 @@ type Metal3ClusterStatus struct {
 // +kubebuilder:printcolumn:name="Error",type="string",JSONPath=".status.failureReason",description="Most recent error"
 // +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this BMCluster belongs"
 // +kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".spec.controlPlaneEndpoint",description="Control plane endpoint"
+// +kubebuilder:printcolumn:name="Remediations",type="integer",JSONPath=".status.activeRemediations",description="Number of active remediations",priority=1
 
 // Metal3Cluster is the Schema for the metal3clusters API.
 type Metal3Cluster struct {
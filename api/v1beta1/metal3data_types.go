@@ -19,12 +19,16 @@ package v1beta1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 const (
 	// DataFinalizer allows Metal3DataReconciler to clean up resources
 	// associated with Metal3Data before removing it from the apiserver.
 	DataFinalizer = "metal3data.infrastructure.cluster.x-k8s.io"
+	// DataSecretFinalizer is set on the secrets rendered by Metal3DataReconciler
+	// to prevent their deletion until the BareMetalHosts consumed them.
+	DataSecretFinalizer = "metal3datasecret.infrastructure.cluster.x-k8s.io"
 )
 
 // Metal3DataSpec defines the desired state of Metal3Data.
@@ -49,6 +53,11 @@ type Metal3DataSpec struct {
 	// +optional
 	NetworkData *corev1.SecretReference `json:"networkData,omitempty"`
 
+	// UserData points to the rendered UserData secret, merging the bootstrap
+	// data with the userData of the template.
+	// +optional
+	UserData *corev1.SecretReference `json:"userData,omitempty"`
+
 	// DataClaim points to the Metal3DataClaim the Metal3Data was created for.
 	Claim corev1.ObjectReference `json:"claim"`
 
@@ -65,6 +74,42 @@ type Metal3DataStatus struct {
 	// ErrorMessage contains the error message
 	// +optional
 	ErrorMessage *string `json:"errorMessage,omitempty"`
+
+	// Addresses lists the addresses allocated from the IP pools, as used to
+	// render the secrets.
+	// +optional
+	Addresses []Metal3DataAddress `json:"addresses,omitempty"`
+
+	// NICFingerprint is a hash of the NICs of the BareMetalHost the
+	// networkData was rendered for.
+	// +optional
+	NICFingerprint string `json:"nicFingerprint,omitempty"`
+
+	// MetaDataSourceFingerprint is a hash of the Secret and ConfigMap data
+	// the metaData was rendered from.
+	// +optional
+	MetaDataSourceFingerprint string `json:"metaDataSourceFingerprint,omitempty"`
+
+	// Conditions defines current service state of the Metal3Data.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// Metal3DataAddress is an address allocated from an IP pool for a Metal3Data.
+type Metal3DataAddress struct {
+	// Pool is the name of the IP pool the address was allocated from.
+	Pool string `json:"pool"`
+
+	// Address is the allocated IP address.
+	Address string `json:"address"`
+
+	// Prefix is the prefix length of the allocated address.
+	// +optional
+	Prefix int `json:"prefix,omitempty"`
+
+	// Gateway is the gateway of the allocated address.
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -85,6 +130,16 @@ type Metal3Data struct {
 	Status Metal3DataStatus `json:"status,omitempty"`
 }
 
+// GetConditions returns the list of conditions for a Metal3Data API object.
+func (c *Metal3Data) GetConditions() clusterv1.Conditions {
+	return c.Status.Conditions
+}
+
+// SetConditions will set the given conditions on a Metal3Data object.
+func (c *Metal3Data) SetConditions(conditions clusterv1.Conditions) {
+	c.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // Metal3DataList contains a list of Metal3Data.
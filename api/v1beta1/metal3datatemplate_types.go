@@ -26,6 +26,14 @@ const (
 	// DataTemplateFinalizer allows Metal3DataTemplateReconciler to clean up resources
 	// associated with Metal3DataTemplate before removing it from the apiserver.
 	DataTemplateFinalizer = "metal3datatemplate.infrastructure.cluster.x-k8s.io"
+
+	// HostnameFormatIndex, HostnameFormatCluster and HostnameFormatMachine are
+	// the placeholders of the hostnameFormat of the metaData, replaced by the
+	// index of the Metal3Data, the name of the Cluster and the name of the
+	// Machine respectively.
+	HostnameFormatIndex   = "{index}"
+	HostnameFormatCluster = "{cluster}"
+	HostnameFormatMachine = "{machine}"
 )
 
 // MetaDataIndex contains the information to render the index.
@@ -60,7 +68,7 @@ type MetaDataFromLabel struct {
 }
 
 // MetaDataFromAnnotation contains the information to fetch an annotation
-// content, if the label does not exist, it is rendered as empty string.
+// content, if the annotation does not exist, it is rendered as Default.
 type MetaDataFromAnnotation struct {
 	// Key will be used as the key to set in the metadata map for cloud-init
 	Key string `json:"key"`
@@ -69,6 +77,13 @@ type MetaDataFromAnnotation struct {
 	Object string `json:"object"`
 	// Annotation is the key of the Annotation to fetch
 	Annotation string `json:"annotation"`
+	// Default is rendered if the annotation does not exist.
+	// +optional
+	Default string `json:"default,omitempty"`
+	// Required makes the rendering fail while the annotation does not exist,
+	// it cannot be set with Default.
+	// +optional
+	Required bool `json:"required,omitempty"`
 }
 
 // MetaDataString contains the information to render the string.
@@ -85,6 +100,53 @@ type MetaDataNamespace struct {
 	Key string `json:"key"`
 }
 
+// MetaDataFromCluster contains the information to render a field of the
+// Cluster the Machine belongs to.
+type MetaDataFromCluster struct {
+	// Key will be used as the key to set in the metadata map for cloud-init
+	Key string `json:"key"`
+	// +kubebuilder:validation:Enum=name;namespace
+	// Field is the field of the Cluster to render
+	Field string `json:"field"`
+}
+
+// MetaDataFromSource contains the information to render the value of a key
+// of a Secret or ConfigMap in the namespace of the Metal3DataTemplate.
+type MetaDataFromSource struct {
+	// Key will be used as the key to set in the metadata map for cloud-init
+	Key string `json:"key"`
+	// Name is the name of the Secret or ConfigMap
+	Name string `json:"name"`
+	// DataKey is the key of the data of the Secret or ConfigMap to render
+	DataKey string `json:"dataKey"`
+}
+
+// MetaDataNTPServers contains the information to render a list of NTP
+// servers, given inline and read from a ConfigMap, as a comma-separated
+// list of hostnames or IP addresses.
+type MetaDataNTPServers struct {
+	// Key will be used as the key to set in the metadata map for cloud-init
+	Key string `json:"key"`
+	// Servers is the list of hostnames or IP addresses of the NTP servers
+	// +optional
+	Servers []string `json:"servers,omitempty"`
+	// FromConfigMap references the ConfigMap key holding NTP servers,
+	// separated by commas or whitespace, rendered after Servers
+	// +optional
+	FromConfigMap *NTPServersFromConfigMap `json:"fromConfigMap,omitempty"`
+}
+
+// NTPServersFromConfigMap references the key of a ConfigMap holding NTP
+// servers, in the namespace of the Metal3DataTemplate.
+type NTPServersFromConfigMap struct {
+	// Name is the name of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key is the key of the data of the ConfigMap holding the NTP servers
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
 // MetaDataObjectName contains the information to render the object name.
 type MetaDataObjectName struct {
 	// Key will be used as the key to set in the metadata map for cloud-init
@@ -168,6 +230,11 @@ type MetaData struct {
 	// +optional
 	PrefixesFromPool []FromPool `json:"prefixesFromIPPool,omitempty"`
 
+	// NetmasksFromPool is the list of metadata items to be rendered as network
+	// masks, in dotted notation for IPv4 and address notation for IPv6.
+	// +optional
+	NetmasksFromPool []FromPool `json:"netmasksFromIPPool,omitempty"`
+
 	// GatewaysFromPool is the list of metadata items to be rendered as gateway addresses.
 	// +optional
 	GatewaysFromPool []FromPool `json:"gatewaysFromIPPool,omitempty"`
@@ -189,6 +256,74 @@ type MetaData struct {
 	// Annotations
 	// +optional
 	FromAnnotations []MetaDataFromAnnotation `json:"fromAnnotations,omitempty"`
+
+	// FromCluster is the list of metadata items to be rendered from the
+	// Cluster the Machine belongs to
+	// +optional
+	FromCluster []MetaDataFromCluster `json:"fromCluster,omitempty"`
+
+	// FromSecrets is the list of metadata items to be rendered from the data
+	// of Secrets
+	// +optional
+	FromSecrets []MetaDataFromSource `json:"fromSecrets,omitempty"`
+
+	// FromConfigMaps is the list of metadata items to be rendered from the
+	// data of ConfigMaps
+	// +optional
+	FromConfigMaps []MetaDataFromSource `json:"fromConfigMaps,omitempty"`
+
+	// NTPServers is the list of metadata items to be rendered as lists of
+	// NTP servers
+	// +optional
+	NTPServers []MetaDataNTPServers `json:"ntpServers,omitempty"`
+
+	// HostnameFormat is the format of the hostname rendered in the
+	// local-hostname and local_hostname metadata items. It can contain the
+	// {index}, {cluster} and {machine} placeholders, e.g.
+	// worker-{index}.{cluster}.example.com. The rendered hostname must be a
+	// valid RFC 1123 subdomain.
+	// +optional
+	HostnameFormat string `json:"hostnameFormat,omitempty"`
+}
+
+// UserDataFileSource references the key of a Secret or ConfigMap in the
+// namespace of the Metal3DataTemplate.
+type UserDataFileSource struct {
+	// Name is the name of the Secret or ConfigMap
+	Name string `json:"name"`
+	// Key is the key of the data of the Secret or ConfigMap holding the
+	// content of the file
+	Key string `json:"key"`
+}
+
+// UserDataFile is a file written on the host by cloud-init. Its content is
+// either given inline or read from a ConfigMap or a Secret.
+type UserDataFile struct {
+	// Path is the absolute path of the file on the host
+	Path string `json:"path"`
+	// Content is the inline content of the file
+	// +optional
+	Content string `json:"content,omitempty"`
+	// FromConfigMap references the ConfigMap key holding the content of the
+	// file
+	// +optional
+	FromConfigMap *UserDataFileSource `json:"fromConfigMap,omitempty"`
+	// FromSecret references the Secret key holding the content of the file
+	// +optional
+	FromSecret *UserDataFileSource `json:"fromSecret,omitempty"`
+	// Permissions are the octal permissions of the file, e.g. "0644"
+	// +kubebuilder:validation:Pattern=`^0?[0-7]{3}$`
+	// +optional
+	Permissions string `json:"permissions,omitempty"`
+}
+
+// UserData contains the information needed to generate the userdata secret,
+// merging the bootstrap data of the Machine with cloud-init configuration.
+type UserData struct {
+	// Files is the list of files written on the host, rendered as cloud-init
+	// write_files
+	// +optional
+	Files []UserDataFile `json:"files,omitempty"`
 }
 
 // NetworkLinkEthernetMacFromAnnotation contains the information to fetch an annotation
@@ -237,6 +372,13 @@ type NetworkDataLinkEthernet struct {
 	// MACAddress is the MAC address of the interface, containing the object
 	// used to render it.
 	MACAddress *NetworkLinkEthernetMac `json:"macAddress"`
+
+	// Optional marks the link as optional. An optional link whose MAC address
+	// is fetched from a host interface that the BareMetalHost does not have
+	// is omitted, together with the vlans and networks on it, instead of
+	// failing the rendering. It is removed from the bonds it is part of.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
 }
 
 // NetworkDataLinkBond represents a bond link object.
@@ -318,6 +460,10 @@ type NetworkDataService struct {
 	// DNSFromIPPool is the name of the IPPool from which to get the DNS servers
 	// +optional
 	DNSFromIPPool *string `json:"dnsFromIPPool,omitempty"`
+
+	// SearchDomains is a list of DNS search domains
+	// +optional
+	SearchDomains []string `json:"searchDomains,omitempty"`
 }
 
 // NetworkDataServicev4 represents a service object.
@@ -404,6 +550,21 @@ type NetworkDataRoutev6 struct {
 	Services NetworkDataServicev6 `json:"services,omitempty"`
 }
 
+// RoutesFromConfigMap references the key of a ConfigMap, in the namespace of
+// the Metal3DataTemplate, holding routes of a network. The key holds a YAML
+// list of routes, each with a destination CIDR and a gateway address, e.g.
+// [{destination: 10.10.0.0/16, gateway: 192.168.0.1}].
+type RoutesFromConfigMap struct {
+	// Name is the name of the ConfigMap
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Key is the key of the data of the ConfigMap holding the routes. The key
+	// named after the BareMetalHost is used instead when the ConfigMap has one,
+	// to give routes to a single host.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
 // NetworkDataIPv4 represents an ipv4 static network object.
 type NetworkDataIPv4 struct {
 
@@ -422,6 +583,11 @@ type NetworkDataIPv4 struct {
 	// Routes contains a list of IPv4 routes
 	// +optional
 	Routes []NetworkDataRoutev4 `json:"routes,omitempty"`
+
+	// RoutesFromConfigMap references the ConfigMap key holding IPv4 routes
+	// rendered after Routes
+	// +optional
+	RoutesFromConfigMap *RoutesFromConfigMap `json:"routesFromConfigMap,omitempty"`
 }
 
 // NetworkDataIPv6 represents an ipv6 static network object.
@@ -442,6 +608,11 @@ type NetworkDataIPv6 struct {
 	// Routes contains a list of IPv6 routes
 	// +optional
 	Routes []NetworkDataRoutev6 `json:"routes,omitempty"`
+
+	// RoutesFromConfigMap references the ConfigMap key holding IPv6 routes
+	// rendered after Routes
+	// +optional
+	RoutesFromConfigMap *RoutesFromConfigMap `json:"routesFromConfigMap,omitempty"`
 }
 
 // NetworkDataIPv4DHCP represents an ipv4 DHCP network object.
@@ -456,6 +627,11 @@ type NetworkDataIPv4DHCP struct {
 	// Routes contains a list of IPv4 routes
 	// +optional
 	Routes []NetworkDataRoutev4 `json:"routes,omitempty"`
+
+	// RoutesFromConfigMap references the ConfigMap key holding IPv4 routes
+	// rendered after Routes
+	// +optional
+	RoutesFromConfigMap *RoutesFromConfigMap `json:"routesFromConfigMap,omitempty"`
 }
 
 // NetworkDataIPv6DHCP represents an ipv6 DHCP network object.
@@ -470,6 +646,11 @@ type NetworkDataIPv6DHCP struct {
 	// Routes contains a list of IPv6 routes
 	// +optional
 	Routes []NetworkDataRoutev6 `json:"routes,omitempty"`
+
+	// RoutesFromConfigMap references the ConfigMap key holding IPv6 routes
+	// rendered after Routes
+	// +optional
+	RoutesFromConfigMap *RoutesFromConfigMap `json:"routesFromConfigMap,omitempty"`
 }
 
 // NetworkDataNetwork represents a network object.
@@ -534,6 +715,11 @@ type Metal3DataTemplateSpec struct {
 	// secret
 	// +optional
 	NetworkData *NetworkData `json:"networkData,omitempty"`
+
+	// UserData contains the information needed to generate the userdata
+	// secret, merged with the bootstrap data of the Machine
+	// +optional
+	UserData *UserData `json:"userData,omitempty"`
 }
 
 // Metal3DataTemplateStatus defines the observed state of Metal3DataTemplate.
@@ -14,14 +14,21 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"fmt"
+	"net"
+	"path"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -32,6 +39,130 @@ func (c *Metal3DataTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
 		Complete()
 }
 
+// +kubebuilder:object:generate=false
+
+// Metal3DataTemplateWebhook validates the Metal3DataTemplates and warns about
+// the templates of the namespace they share index ranges and IPPools with.
+type Metal3DataTemplateWebhook struct {
+	// Client reads the Metal3DataTemplates of the namespace.
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &Metal3DataTemplateWebhook{}
+
+func (w *Metal3DataTemplateWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&Metal3DataTemplate{}).
+		WithValidator(w).
+		Complete()
+}
+
+// ValidateCreate implements webhook.CustomValidator.
+func (w *Metal3DataTemplateWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	c, ok := obj.(*Metal3DataTemplate)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a Metal3DataTemplate but got a %T", obj))
+	}
+	if _, err := c.ValidateCreate(); err != nil {
+		return nil, err
+	}
+	return w.warnings(ctx, c)
+}
+
+// ValidateUpdate implements webhook.CustomValidator.
+func (w *Metal3DataTemplateWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	c, ok := newObj.(*Metal3DataTemplate)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a Metal3DataTemplate but got a %T", newObj))
+	}
+	if _, err := c.ValidateUpdate(oldObj); err != nil {
+		return nil, err
+	}
+	return w.warnings(ctx, c)
+}
+
+// ValidateDelete implements webhook.CustomValidator.
+func (w *Metal3DataTemplateWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// warnings returns a warning for each other template of the namespace whose
+// indexes overlap with the ones of the template and that references an IPPool
+// the template references. The Metal3Data objects of both templates are named
+// after the same templateReference, or template name, and start at the same
+// index, so they share their IP claims and render duplicate addresses.
+func (w *Metal3DataTemplateWebhook) warnings(ctx context.Context, c *Metal3DataTemplate) (admission.Warnings, error) {
+	pools := c.ipPools()
+	if len(pools) == 0 {
+		return nil, nil
+	}
+	templates := &Metal3DataTemplateList{}
+	if err := w.Client.List(ctx, templates, client.InNamespace(c.Namespace)); err != nil {
+		return nil, apierrors.NewInternalError(errors.Wrap(err, "failed to list the Metal3DataTemplates"))
+	}
+	var warnings admission.Warnings
+	for _, other := range templates.Items {
+		if other.Name == c.Name || other.indexName() != c.indexName() {
+			continue
+		}
+		for pool := range other.ipPools() {
+			if _, ok := pools[pool]; ok {
+				warnings = append(warnings, fmt.Sprintf(
+					"Metal3DataTemplate %s allocates the same indexes from %s and references the IPPool %s: "+
+						"the addresses of their Metal3Data objects will be duplicated",
+					other.Name, c.indexName(), pool,
+				))
+				break
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// indexName returns the name the Metal3Data objects of the template are named
+// after, with their index.
+func (c *Metal3DataTemplate) indexName() string {
+	if c.Spec.TemplateReference != "" {
+		return c.Spec.TemplateReference
+	}
+	return c.Name
+}
+
+// ipPools returns the names of the IPPools the template renders addresses from.
+func (c *Metal3DataTemplate) ipPools() map[string]struct{} {
+	pools := map[string]struct{}{}
+	if c.Spec.MetaData != nil {
+		for _, fromPools := range [][]FromPool{
+			c.Spec.MetaData.IPAddressesFromPool,
+			c.Spec.MetaData.PrefixesFromPool,
+			c.Spec.MetaData.NetmasksFromPool,
+			c.Spec.MetaData.GatewaysFromPool,
+			c.Spec.MetaData.DNSServersFromPool,
+		} {
+			for _, pool := range fromPools {
+				pools[pool.Name] = struct{}{}
+			}
+		}
+	}
+	if c.Spec.NetworkData != nil {
+		for _, network := range c.Spec.NetworkData.Networks.IPv4 {
+			if network.FromPoolRef != nil && network.FromPoolRef.Name != "" {
+				pools[network.FromPoolRef.Name] = struct{}{}
+			} else if network.IPAddressFromIPPool != "" {
+				pools[network.IPAddressFromIPPool] = struct{}{}
+			}
+		}
+		for _, network := range c.Spec.NetworkData.Networks.IPv6 {
+			if network.FromPoolRef != nil && network.FromPoolRef.Name != "" {
+				pools[network.FromPoolRef.Name] = struct{}{}
+			} else if network.IPAddressFromIPPool != "" {
+				pools[network.IPAddressFromIPPool] = struct{}{}
+			}
+		}
+	}
+	return pools
+}
+
 // +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-metal3datatemplate,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=metal3datatemplates,versions=v1beta1,name=validation.metal3datatemplate.infrastructure.cluster.x-k8s.io,matchPolicy=Equivalent,sideEffects=None,admissionReviewVersions=v1;v1beta1,sideEffects=None
 // +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1beta1-metal3datatemplate,mutating=true,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=metal3datatemplates,versions=v1beta1,name=default.metal3datatemplate.infrastructure.cluster.x-k8s.io,matchPolicy=Equivalent,sideEffects=None,admissionReviewVersions=v1;v1beta1
 
@@ -73,6 +204,16 @@ func (c *Metal3DataTemplate) ValidateUpdate(old runtime.Object) (admission.Warni
 		)
 	}
 
+	if !reflect.DeepEqual(c.Spec.UserData, oldM3dt.Spec.UserData) {
+		allErrs = append(allErrs,
+			field.Invalid(
+				field.NewPath("spec", "UserData"),
+				c.Spec.UserData,
+				"cannot be modified",
+			),
+		)
+	}
+
 	if len(allErrs) == 0 {
 		return nil, nil
 	}
@@ -87,6 +228,49 @@ func (c *Metal3DataTemplate) ValidateDelete() (admission.Warnings, error) {
 func (c *Metal3DataTemplate) validate() error {
 	var allErrs field.ErrorList
 
+	if c.Spec.MetaData != nil && c.Spec.MetaData.HostnameFormat != "" {
+		// Render the format with sample values, the actual hostname is
+		// validated again when rendering the metadata.
+		hostname := strings.NewReplacer(
+			HostnameFormatIndex, "0",
+			HostnameFormatCluster, "cluster",
+			HostnameFormatMachine, "machine",
+		).Replace(c.Spec.MetaData.HostnameFormat)
+		for _, msg := range validation.IsDNS1123Subdomain(hostname) {
+			allErrs = append(allErrs, field.Invalid(
+				field.NewPath("spec", "metaData", "hostnameFormat"),
+				c.Spec.MetaData.HostnameFormat, msg,
+			))
+		}
+	}
+
+	if c.Spec.MetaData != nil {
+		for i, entry := range c.Spec.MetaData.FromAnnotations {
+			if entry.Required && entry.Default != "" {
+				allErrs = append(allErrs, field.Forbidden(
+					field.NewPath("spec", "metaData", "fromAnnotations", strconv.Itoa(i), "default"),
+					"cannot be set for a required annotation",
+				))
+			}
+		}
+		for i, entry := range c.Spec.MetaData.NTPServers {
+			fldPath := field.NewPath("spec", "metaData", "ntpServers", strconv.Itoa(i))
+			if len(entry.Servers) == 0 && entry.FromConfigMap == nil {
+				allErrs = append(allErrs, field.Required(fldPath,
+					"one of servers or fromConfigMap must be set",
+				))
+			}
+			for j, server := range entry.Servers {
+				for _, msg := range NTPServerErrors(server) {
+					allErrs = append(allErrs, field.Invalid(
+						fldPath.Child("servers", strconv.Itoa(j)), server,
+						"must be a hostname or an IP address: "+msg,
+					))
+				}
+			}
+		}
+	}
+
 	if c.Spec.NetworkData != nil {
 		for i, network := range c.Spec.NetworkData.Networks.IPv4 {
 			if (network.FromPoolRef == nil || network.FromPoolRef.Name == "") && network.IPAddressFromIPPool == "" {
@@ -104,6 +288,40 @@ func (c *Metal3DataTemplate) validate() error {
 				))
 			}
 		}
+		for i, domain := range c.Spec.NetworkData.Services.SearchDomains {
+			for _, msg := range validation.IsDNS1123Subdomain(domain) {
+				allErrs = append(allErrs, field.Invalid(
+					field.NewPath("spec", "networkData", "services", "searchDomains", strconv.Itoa(i)),
+					domain, msg,
+				))
+			}
+		}
+	}
+
+	if c.Spec.UserData != nil {
+		for i, file := range c.Spec.UserData.Files {
+			fldPath := field.NewPath("spec", "userData", "files", strconv.Itoa(i))
+			if !path.IsAbs(file.Path) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("path"),
+					file.Path, "must be an absolute path",
+				))
+			}
+			sources := 0
+			if file.Content != "" {
+				sources++
+			}
+			if file.FromConfigMap != nil {
+				sources++
+			}
+			if file.FromSecret != nil {
+				sources++
+			}
+			if sources > 1 {
+				allErrs = append(allErrs, field.Forbidden(fldPath,
+					"only one of content, fromConfigMap and fromSecret can be set",
+				))
+			}
+		}
 	}
 
 	if len(allErrs) == 0 {
@@ -111,3 +329,13 @@ func (c *Metal3DataTemplate) validate() error {
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("Metal3DataTemplate").GroupKind(), c.Name, allErrs)
 }
+
+// NTPServerErrors returns the reasons why server is not a valid NTP server,
+// which must be a hostname or an IP address. It validates the servers given in
+// the Metal3DataTemplate as well as those read from a ConfigMap.
+func NTPServerErrors(server string) []string {
+	if net.ParseIP(server) != nil {
+		return nil
+	}
+	return validation.IsDNS1123Subdomain(server)
+}
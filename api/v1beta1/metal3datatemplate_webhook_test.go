@@ -14,11 +14,14 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
 	"testing"
 
 	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestMetal3DataTemplateDefault(t *testing.T) {
@@ -52,6 +55,216 @@ func TestMetal3DataTemplateValidation(t *testing.T) {
 				Spec: Metal3DataTemplateSpec{},
 			},
 		},
+		{
+			name:      "should succeed with a valid hostnameFormat",
+			expectErr: false,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					MetaData: &MetaData{
+						HostnameFormat: "worker-{index}.{cluster}.example.com",
+					},
+				},
+			},
+		},
+		{
+			name:      "should fail with an invalid hostnameFormat",
+			expectErr: true,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					MetaData: &MetaData{
+						HostnameFormat: "Worker_{index}",
+					},
+				},
+			},
+		},
+		{
+			name:      "should fail with an unknown hostnameFormat placeholder",
+			expectErr: true,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					MetaData: &MetaData{
+						HostnameFormat: "{namespace}-{index}",
+					},
+				},
+			},
+		},
+		{
+			name:      "should succeed with a required annotation",
+			expectErr: false,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					MetaData: &MetaData{
+						FromAnnotations: []MetaDataFromAnnotation{
+							{Key: "region", Object: "machine", Annotation: "region", Required: true},
+							{Key: "zone", Object: "machine", Annotation: "zone", Default: "zone-a"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should fail with a default for a required annotation",
+			expectErr: true,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					MetaData: &MetaData{
+						FromAnnotations: []MetaDataFromAnnotation{
+							{Key: "region", Object: "machine", Annotation: "region", Required: true, Default: "eu"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should succeed with NTP servers",
+			expectErr: false,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					MetaData: &MetaData{
+						NTPServers: []MetaDataNTPServers{
+							{Key: "ntp", Servers: []string{"10.0.0.1", "2001:db8::1", "ntp.example.com"}},
+							{Key: "site-ntp", FromConfigMap: &NTPServersFromConfigMap{Name: "site", Key: "ntp"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should fail with an invalid NTP server",
+			expectErr: true,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					MetaData: &MetaData{
+						NTPServers: []MetaDataNTPServers{
+							{Key: "ntp", Servers: []string{"ntp.example.com", "NTP_Server"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should fail with NTP servers without source",
+			expectErr: true,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					MetaData: &MetaData{
+						NTPServers: []MetaDataNTPServers{
+							{Key: "ntp"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should succeed with userData files",
+			expectErr: false,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					UserData: &UserData{
+						Files: []UserDataFile{
+							{Path: "/etc/containers/registries.conf", Content: "mirror", Permissions: "0644"},
+							{Path: "/etc/pki/ca.pem", FromConfigMap: &UserDataFileSource{Name: "ca", Key: "ca.pem"}},
+							{Path: "/etc/token", FromSecret: &UserDataFileSource{Name: "token", Key: "token"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should fail with a relative userData file path",
+			expectErr: true,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					UserData: &UserData{
+						Files: []UserDataFile{
+							{Path: "etc/containers/registries.conf", Content: "mirror"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should fail with several userData file sources",
+			expectErr: true,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					UserData: &UserData{
+						Files: []UserDataFile{
+							{
+								Path:          "/etc/pki/ca.pem",
+								Content:       "ca",
+								FromConfigMap: &UserDataFileSource{Name: "ca", Key: "ca.pem"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should succeed with valid searchDomains",
+			expectErr: false,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					NetworkData: &NetworkData{
+						Services: NetworkDataService{
+							SearchDomains: []string{"example.com", "cluster.local"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:      "should fail with an invalid searchDomain",
+			expectErr: true,
+			c: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+				},
+				Spec: Metal3DataTemplateSpec{
+					NetworkData: &NetworkData{
+						Services: NetworkDataService{
+							SearchDomains: []string{"example.com", "Not_A.Domain"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -130,6 +343,20 @@ func TestMetal3DataTemplateUpdateValidation(t *testing.T) {
 			},
 		},
 
+		{
+			name:      "should fail when Userdata value changes",
+			expectErr: true,
+			new: &Metal3DataTemplateSpec{
+				UserData: &UserData{
+					Files: []UserDataFile{{Path: "/etc/abc", Content: "def"}},
+				},
+			},
+			old: &Metal3DataTemplateSpec{
+				UserData: &UserData{
+					Files: []UserDataFile{{Path: "/etc/abc", Content: "defg"}},
+				},
+			},
+		},
 		{
 			name:      "should fail when Networkdata value changes",
 			expectErr: true,
@@ -211,3 +438,93 @@ func TestMetal3DataTemplateUpdateValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestMetal3DataTemplateWebhookWarnings(t *testing.T) {
+	templateWithPool := func(name, templateReference, pool string) *Metal3DataTemplate {
+		return &Metal3DataTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "foo",
+			},
+			Spec: Metal3DataTemplateSpec{
+				TemplateReference: templateReference,
+				MetaData: &MetaData{
+					IPAddressesFromPool: []FromPool{
+						{Key: "address", Name: pool},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		existing      *Metal3DataTemplate
+		c             *Metal3DataTemplate
+		expectWarning bool
+	}{
+		{
+			name:          "should warn when a template shares the templateReference and the IPPool",
+			existing:      templateWithPool("abc", "ref", "pool"),
+			c:             templateWithPool("bcd", "ref", "pool"),
+			expectWarning: true,
+		},
+		{
+			name:          "should warn when a template is named after the templateReference and shares the IPPool",
+			existing:      templateWithPool("ref", "", "pool"),
+			c:             templateWithPool("bcd", "ref", "pool"),
+			expectWarning: true,
+		},
+		{
+			name:     "should not warn when a template shares the templateReference but not the IPPool",
+			existing: templateWithPool("abc", "ref", "other-pool"),
+			c:        templateWithPool("bcd", "ref", "pool"),
+		},
+		{
+			name:     "should not warn when a template shares the IPPool with other indexes",
+			existing: templateWithPool("abc", "", "pool"),
+			c:        templateWithPool("bcd", "", "pool"),
+		},
+		{
+			name: "should not warn when a template of another namespace shares the templateReference and the IPPool",
+			existing: &Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: "bar"},
+				Spec:       templateWithPool("abc", "ref", "pool").Spec,
+			},
+			c: templateWithPool("bcd", "ref", "pool"),
+		},
+		{
+			name:     "should not warn about the template itself",
+			existing: templateWithPool("bcd", "ref", "pool"),
+			c:        templateWithPool("bcd", "ref", "pool"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(AddToScheme(scheme)).To(Succeed())
+			w := &Metal3DataTemplateWebhook{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tt.existing).Build(),
+			}
+
+			warnings, err := w.ValidateCreate(context.TODO(), tt.c)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tt.expectWarning {
+				g.Expect(warnings).To(HaveLen(1))
+			} else {
+				g.Expect(warnings).To(BeEmpty())
+			}
+
+			warnings, err = w.ValidateUpdate(context.TODO(), tt.c.DeepCopy(), tt.c)
+			g.Expect(err).NotTo(HaveOccurred())
+			if tt.expectWarning {
+				g.Expect(warnings).To(HaveLen(1))
+			} else {
+				g.Expect(warnings).To(BeEmpty())
+			}
+		})
+	}
+}
@@ -31,6 +31,22 @@ const (
 	CleaningModeMetadata = "metadata"
 	ClonedFromGroupKind  = "Metal3MachineTemplate.infrastructure.cluster.x-k8s.io"
 	LiveIsoDiskFormat    = "live-iso"
+	// NodeRoleLabelPrefix is the prefix of the label applied on the Node for
+	// the NodeRole of the Metal3Machine.
+	NodeRoleLabelPrefix = "node-role.kubernetes.io/"
+	// PowerOn and PowerOff are the values of PostDeprovisionPower.
+	PowerOn  = "on"
+	PowerOff = "off"
+	// HostPlacementSpread and HostPlacementPack are the policies of
+	// HostPlacement.
+	HostPlacementSpread = "Spread"
+	HostPlacementPack   = "Pack"
+	// BootstrapDataChangeIgnore, BootstrapDataChangeWarn and
+	// BootstrapDataChangeReprovision are the values of
+	// BootstrapDataChangePolicy.
+	BootstrapDataChangeIgnore      = "Ignore"
+	BootstrapDataChangeWarn        = "Warn"
+	BootstrapDataChangeReprovision = "Reprovision"
 )
 
 // Metal3MachineSpec defines the desired state of Metal3Machine.
@@ -44,6 +60,15 @@ type Metal3MachineSpec struct {
 	// +optional
 	Image Image `json:"image,omitempty"`
 
+	// ImageRef references a ConfigMap, in the namespace of the Metal3Machine,
+	// holding the image to be provisioned in its url, checksum, checksumType
+	// and format keys. It allows to roll out a new image to all the
+	// Metal3Machines referencing it by updating the ConfigMap. The image is
+	// resolved when a host is provisioned, already provisioned hosts are not
+	// affected. It is mutually exclusive with Image.
+	// +optional
+	ImageRef *corev1.LocalObjectReference `json:"imageRef,omitempty"`
+
 	// A custom deploy procedure.
 	// +optional
 	CustomDeploy *CustomDeploy `json:"customDeploy,omitempty"`
@@ -60,6 +85,17 @@ type Metal3MachineSpec struct {
 	// +optional
 	HostSelector HostSelector `json:"hostSelector,omitempty"`
 
+	// FallbackHostSelectors is an ordered list of host selectors tried in turn
+	// when no BareMetalHost matching HostSelector is available. The host is
+	// chosen among the first non-empty set of matching BareMetalHosts.
+	// +optional
+	FallbackHostSelectors []HostSelector `json:"fallbackHostSelectors,omitempty"`
+
+	// HostPlacement spreads or packs the Metal3Machines of a MachineDeployment
+	// across the values of a BareMetalHost label, e.g. the rack of the hosts.
+	// +optional
+	HostPlacement *HostPlacement `json:"hostPlacement,omitempty"`
+
 	// MetadataTemplate is a reference to a Metal3DataTemplate object containing
 	// a template of metadata to be rendered. Metadata keys defined in the
 	// metadataTemplate take precedence over keys defined in metadata field.
@@ -81,6 +117,88 @@ type Metal3MachineSpec struct {
 	// +kubebuilder:validation:Enum:=metadata;disabled
 	// +optional
 	AutomatedCleaningMode *string `json:"automatedCleaningMode,omitempty"`
+
+	// PostDeprovisionPower is the power state the host is left in once
+	// deprovisioned, on for a faster re-provisioning or off to save energy.
+	// It overrides --post-deprovision-power. When neither is set, the host
+	// is powered off unless fast track is enabled and cleaning is not
+	// disabled.
+	// +kubebuilder:validation:Enum:=on;off
+	// +optional
+	PostDeprovisionPower *string `json:"postDeprovisionPower,omitempty"`
+
+	// NodeRole is the role applied on the Node as the
+	// node-role.kubernetes.io/<role> label once it registered, e.g. worker.
+	// It must consist of lower case alphanumeric characters or '-', start
+	// and end with an alphanumeric character and be at most 63 characters.
+	// +optional
+	NodeRole string `json:"nodeRole,omitempty"`
+
+	// Timeouts overrides the timeouts configured on the controller for this
+	// Metal3Machine, e.g. for hosts with a slow BMC.
+	// +optional
+	Timeouts *Metal3MachineTimeouts `json:"timeouts,omitempty"`
+
+	// BootstrapDataChangePolicy is the behavior when the bootstrap data secret
+	// of the Machine changes after the host was provisioned. Ignore, the
+	// default, keeps the host as is, Warn sets the BootstrapDataUpToDate
+	// condition to false and Reprovision deprovisions the host and provisions
+	// it again with the new bootstrap data.
+	// +kubebuilder:validation:Enum=Ignore;Warn;Reprovision
+	// +optional
+	BootstrapDataChangePolicy string `json:"bootstrapDataChangePolicy,omitempty"`
+
+	// ProvisioningInterface is the NIC of the host to provision from, given
+	// by name or MAC address, for multi-NIC hosts. Its MAC address is resolved
+	// from the hardware details of the host and set as the boot MAC address
+	// of the host on association. It cannot differ from a boot MAC address
+	// already set on the host.
+	// +optional
+	ProvisioningInterface string `json:"provisioningInterface,omitempty"`
+}
+
+// Metal3MachineTimeouts holds the timeouts of a Metal3Machine. Each of them
+// overrides the default configured on the controller when set.
+type Metal3MachineTimeouts struct {
+	// Provisioning is the duration after which a BareMetalHost associated with
+	// the Metal3Machine, whose provisioning has not started, is released. It
+	// overrides --host-reservation-ttl, zero disables the release.
+	// +optional
+	Provisioning *metav1.Duration `json:"provisioning,omitempty"`
+
+	// Deprovision is the duration after which a BareMetalHost still
+	// deprovisioning is reported as stuck. It overrides --deprovision-timeout,
+	// zero disables the timeout.
+	// +optional
+	Deprovision *metav1.Duration `json:"deprovision,omitempty"`
+
+	// PowerOn is the duration a provisioned BareMetalHost must have been
+	// reported as powered on before the machine is considered running. It
+	// overrides --power-on-grace-period.
+	// +optional
+	PowerOn *metav1.Duration `json:"powerOn,omitempty"`
+
+	// HostError is the duration the associated BareMetalHost must have been
+	// in an error state continuously before the Metal3Machine is marked
+	// failed. It overrides --host-error-grace-period, zero disables marking
+	// the Metal3Machine failed.
+	// +optional
+	HostError *metav1.Duration `json:"hostError,omitempty"`
+}
+
+// HostPlacement places a Metal3Machine relative to the BareMetalHosts consumed
+// by the other Machines of its MachineDeployment.
+type HostPlacement struct {
+	// TopologyKey is the label of the BareMetalHosts whose values are the
+	// placement domains, e.g. the rack of the hosts.
+	// +kubebuilder:validation:MinLength=1
+	TopologyKey string `json:"topologyKey"`
+
+	// Policy is Spread to choose a host in the domain with the fewest
+	// Machines of the MachineDeployment, or Pack to choose a host in the
+	// domain with the most.
+	// +kubebuilder:validation:Enum=Spread;Pack
+	Policy string `json:"policy"`
 }
 
 // Metal3MachineStatus defines the observed state of Metal3Machine.
@@ -138,6 +256,57 @@ type Metal3MachineStatus struct {
 	// +optional
 	Phase string `json:"phase,omitempty"`
 
+	// BMCProtocol is the protocol used to reach the BMC of the associated
+	// BareMetalHost, as parsed from its BMC address, e.g. ipmi or redfish.
+	// +optional
+	BMCProtocol string `json:"bmcProtocol,omitempty"`
+
+	// BMCAddress is the BMC address of the associated BareMetalHost, without
+	// the credentials it may embed, e.g. redfish://192.168.111.1/redfish/v1/Systems/1.
+	// +optional
+	BMCAddress string `json:"bmcAddress,omitempty"`
+
+	// FirmwareVersions are the current versions of the firmware components of
+	// the associated BareMetalHost, e.g. bios or bmc, as reported by its
+	// HostFirmwareComponents. It is empty if they are not available.
+	// +optional
+	FirmwareVersions map[string]string `json:"firmwareVersions,omitempty"`
+
+	// LastInspected is the time at which the last successful inspection of
+	// the associated BareMetalHost completed. Hosts inspected long ago may
+	// have stale hardware details and need to be inspected again.
+	// +optional
+	LastInspected *metav1.Time `json:"lastInspected,omitempty"`
+
+	// AssociatedAt is the time at which a BareMetalHost was chosen for the
+	// Metal3Machine.
+	// +optional
+	AssociatedAt *metav1.Time `json:"associatedAt,omitempty"`
+
+	// ProvisioningDuration is the time it took from the association with a
+	// BareMetalHost until the host was provisioned. It is set once, when
+	// provisioning completes.
+	// +optional
+	ProvisioningDuration *metav1.Duration `json:"provisioningDuration,omitempty"`
+
+	// ProvisioningAttempts is the number of times the associated BareMetalHost
+	// started provisioning for the Metal3Machine. It is above one when
+	// provisioning restarted after a failure, the BareMetal Operator cleaning
+	// the host and provisioning it again.
+	// +optional
+	ProvisioningAttempts int `json:"provisioningAttempts,omitempty"`
+
+	// LastProvisioningStart is the time at which the last provisioning
+	// attempt counted in ProvisioningAttempts started.
+	// +optional
+	LastProvisioningStart *metav1.Time `json:"lastProvisioningStart,omitempty"`
+
+	// BootstrapDataFingerprint is a hash of the bootstrap data secret the
+	// host was provisioned with. It is only set when the
+	// BootstrapDataChangePolicy is not Ignore.
+	// +optional
+	BootstrapDataFingerprint string `json:"bootstrapDataFingerprint,omitempty"`
+
 	// Ready is the state of the metal3.
 	// TODO : Document the variable :
 	// mhrivnak: " it would be good to document what this means, how to interpret
@@ -180,6 +349,7 @@ type Metal3MachineStatus struct {
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="metal3machine is Ready"
 // +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this M3Machine belongs"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="metal3machine current phase"
+// +kubebuilder:printcolumn:name="Inspected",type="date",JSONPath=".status.lastInspected",description="Time duration since the last inspection of the BareMetalHost",priority=1
 
 // Metal3Machine is the Schema for the metal3machines API.
 type Metal3Machine struct {
@@ -14,7 +14,10 @@ limitations under the License.
 package v1beta1
 
 import (
+	"regexp"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -55,12 +58,75 @@ func (c *Metal3Machine) ValidateDelete() (admission.Warnings, error) {
 func (c *Metal3Machine) validate() error {
 	var allErrs field.ErrorList
 
-	if c.Spec.CustomDeploy == nil || c.Spec.CustomDeploy.Method == "" {
-		allErrs = append(allErrs, c.Spec.Image.Validate(*field.NewPath("Spec", "Image"))...)
-	}
+	allErrs = append(allErrs, validateMachineImage(&c.Spec, field.NewPath("Spec"))...)
+	allErrs = append(allErrs, validateMachineTimeouts(c.Spec.Timeouts, field.NewPath("Spec", "Timeouts"))...)
+
+	allErrs = append(allErrs, validateNodeRole(c.Spec.NodeRole, field.NewPath("Spec", "NodeRole"))...)
 
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("Metal3Machine").GroupKind(), c.Name, allErrs)
 }
+
+// validateMachineImage checks the image of a Metal3Machine spec, which is
+// either set inline, referenced through ImageRef or replaced by a custom
+// deploy. The content of a referenced image is validated on resolution.
+func validateMachineImage(spec *Metal3MachineSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.ImageRef != nil {
+		if spec.ImageRef.Name == "" {
+			allErrs = append(allErrs, field.Required(fldPath.Child("ImageRef", "Name"), "cannot be empty"))
+		}
+		if spec.Image.URL != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("Image"), "image and imageRef are mutually exclusive"))
+		}
+		return allErrs
+	}
+	if spec.CustomDeploy == nil || spec.CustomDeploy.Method == "" {
+		allErrs = append(allErrs, spec.Image.Validate(*fldPath.Child("Image"))...)
+	}
+	return allErrs
+}
+
+// validateMachineTimeouts checks that the timeouts of a Metal3Machine spec
+// are not negative.
+func validateMachineTimeouts(timeouts *Metal3MachineTimeouts, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if timeouts == nil {
+		return allErrs
+	}
+	for _, timeout := range []struct {
+		name     string
+		duration *metav1.Duration
+	}{
+		{"Provisioning", timeouts.Provisioning},
+		{"Deprovision", timeouts.Deprovision},
+		{"PowerOn", timeouts.PowerOn},
+		{"HostError", timeouts.HostError},
+	} {
+		if timeout.duration != nil && timeout.duration.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(timeout.name),
+				timeout.duration.Duration.String(), "must not be negative"))
+		}
+	}
+	return allErrs
+}
+
+// nodeRoleRegexp matches the roles which can be applied as node-role label.
+var nodeRoleRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// validateNodeRole checks that the node role can be used as the name part of
+// the node-role.kubernetes.io/<role> label.
+func validateNodeRole(role string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if role == "" {
+		return allErrs
+	}
+	if len(role) > 63 || !nodeRoleRegexp.MatchString(role) {
+		allErrs = append(allErrs, field.Invalid(fldPath, role,
+			"must consist of at most 63 lower case alphanumeric characters or '-', and must start and end with an alphanumeric character",
+		))
+	}
+	return allErrs
+}
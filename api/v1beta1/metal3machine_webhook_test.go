@@ -15,8 +15,10 @@ package v1beta1
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 )
@@ -54,6 +56,39 @@ func TestMetal3MachineValidation(t *testing.T) {
 	validIso.Spec.Image.Checksum = ""
 	validIso.Spec.Image.DiskFormat = ptr.To(LiveISODiskFormat)
 
+	validImageRef := valid.DeepCopy()
+	validImageRef.Spec.Image = Image{}
+	validImageRef.Spec.ImageRef = &corev1.LocalObjectReference{Name: "golden-image"}
+
+	invalidImageRefName := validImageRef.DeepCopy()
+	invalidImageRefName.Spec.ImageRef.Name = ""
+
+	invalidImageAndImageRef := valid.DeepCopy()
+	invalidImageAndImageRef.Spec.ImageRef = &corev1.LocalObjectReference{Name: "golden-image"}
+
+	validTimeouts := valid.DeepCopy()
+	validTimeouts.Spec.Timeouts = &Metal3MachineTimeouts{
+		Provisioning: &metav1.Duration{Duration: time.Hour},
+		PowerOn:      &metav1.Duration{},
+		HostError:    &metav1.Duration{Duration: 10 * time.Minute},
+	}
+
+	invalidHostErrorTimeout := valid.DeepCopy()
+	invalidHostErrorTimeout.Spec.Timeouts = &Metal3MachineTimeouts{
+		HostError: &metav1.Duration{Duration: -time.Minute},
+	}
+
+	invalidTimeouts := valid.DeepCopy()
+	invalidTimeouts.Spec.Timeouts = &Metal3MachineTimeouts{
+		Deprovision: &metav1.Duration{Duration: -time.Minute},
+	}
+
+	validNodeRole := valid.DeepCopy()
+	validNodeRole.Spec.NodeRole = "gpu-worker"
+
+	invalidNodeRole := valid.DeepCopy()
+	invalidNodeRole.Spec.NodeRole = "GPU_Worker"
+
 	validCustomDeploy := &Metal3Machine{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "foo",
@@ -95,6 +130,46 @@ func TestMetal3MachineValidation(t *testing.T) {
 			expectErr: false,
 			c:         validCustomDeploy,
 		},
+		{
+			name:      "should succeed with imageRef",
+			expectErr: false,
+			c:         validImageRef,
+		},
+		{
+			name:      "should return error when imageRef name empty",
+			expectErr: true,
+			c:         invalidImageRefName,
+		},
+		{
+			name:      "should return error when both image and imageRef are set",
+			expectErr: true,
+			c:         invalidImageAndImageRef,
+		},
+		{
+			name:      "should succeed when timeouts correct",
+			expectErr: false,
+			c:         validTimeouts,
+		},
+		{
+			name:      "should return error when a timeout is negative",
+			expectErr: true,
+			c:         invalidTimeouts,
+		},
+		{
+			name:      "should return error when the host error timeout is negative",
+			expectErr: true,
+			c:         invalidHostErrorTimeout,
+		},
+		{
+			name:      "should succeed when node role correct",
+			expectErr: false,
+			c:         validNodeRole,
+		},
+		{
+			name:      "should return error when node role invalid",
+			expectErr: true,
+			c:         invalidNodeRole,
+		},
 	}
 
 	for _, tt := range tests {
@@ -55,9 +55,11 @@ func (c *Metal3MachineTemplate) ValidateDelete() (admission.Warnings, error) {
 func (c *Metal3MachineTemplate) validate() error {
 	var allErrs field.ErrorList
 
-	if c.Spec.Template.Spec.CustomDeploy == nil || c.Spec.Template.Spec.CustomDeploy.Method == "" {
-		allErrs = append(allErrs, c.Spec.Template.Spec.Image.Validate(*field.NewPath("Spec", "Template", "Spec", "Image"))...)
-	}
+	allErrs = append(allErrs, validateMachineImage(&c.Spec.Template.Spec, field.NewPath("Spec", "Template", "Spec"))...)
+	allErrs = append(allErrs, validateMachineTimeouts(c.Spec.Template.Spec.Timeouts,
+		field.NewPath("Spec", "Template", "Spec", "Timeouts"))...)
+
+	allErrs = append(allErrs, validateNodeRole(c.Spec.Template.Spec.NodeRole, field.NewPath("Spec", "Template", "Spec", "NodeRole"))...)
 
 	if len(allErrs) == 0 {
 		return nil
@@ -58,6 +58,12 @@ func TestMetal3MachineTemplateValidation(t *testing.T) {
 	validIso.Spec.Template.Spec.Image.Checksum = ""
 	validIso.Spec.Template.Spec.Image.DiskFormat = ptr.To(LiveISODiskFormat)
 
+	validNodeRole := valid.DeepCopy()
+	validNodeRole.Spec.Template.Spec.NodeRole = "worker"
+
+	invalidNodeRole := valid.DeepCopy()
+	invalidNodeRole.Spec.Template.Spec.NodeRole = "-worker"
+
 	validCustomDeploy := &Metal3MachineTemplate{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "foo",
@@ -103,6 +109,16 @@ func TestMetal3MachineTemplateValidation(t *testing.T) {
 			expectErr: false,
 			c:         validCustomDeploy,
 		},
+		{
+			name:      "should succeed when node role correct",
+			expectErr: false,
+			c:         validNodeRole,
+		},
+		{
+			name:      "should return error when node role invalid",
+			expectErr: true,
+			c:         invalidNodeRole,
+		},
 	}
 
 	for _, tt := range tests {
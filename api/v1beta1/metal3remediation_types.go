@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,6 +30,20 @@ const (
 
 	// RebootRemediationStrategy sets RemediationType to Reboot.
 	RebootRemediationStrategy RemediationType = "Reboot"
+
+	// QuarantineRemediationStrategy sets RemediationType to Quarantine. The node
+	// is cordoned and the host marked unhealthy, without any power action.
+	QuarantineRemediationStrategy RemediationType = "Quarantine"
+
+	// ReassociateRemediationStrategy sets RemediationType to Reassociate. The
+	// host is fenced, i.e. powered off and marked unhealthy, and the
+	// Metal3Machine is associated with another host, preserving the Machine.
+	ReassociateRemediationStrategy RemediationType = "Reassociate"
+
+	// RemediationDryRunAnnotation makes the remediation go through its phases
+	// without acting on the host, the node or the Machine. The actions it
+	// would have taken are only logged.
+	RemediationDryRunAnnotation = "metal3.io/remediation-dry-run"
 )
 
 const (
@@ -44,6 +59,21 @@ const (
 	// PhaseFailed represents the state where host will not be remediated.
 	// Remediation Controller will set the state to PhaseFailed when a user has set bmh.Spec.Online to false.
 	PhaseFailed = "Failed"
+
+	// PhaseSucceeded represents the state where a host without a Machine has been rebooted and is powered on again.
+	PhaseSucceeded = "Succeeded"
+
+	// PhaseHostGone represents the state where the unhealthy host has been missing for longer than the
+	// configured timeout and will not be remediated.
+	PhaseHostGone = "HostGone"
+
+	// PhaseQuarantined represents the terminal state where the node has been cordoned
+	// by the Quarantine remediation strategy, which is kept for investigation.
+	PhaseQuarantined = "Quarantined"
+
+	// PhaseReassociating represents the state where the host has been fenced by the
+	// Reassociate remediation strategy and the Metal3Machine is associated with another host.
+	PhaseReassociating = "Reassociating"
 )
 
 // Metal3RemediationSpec defines the desired state of Metal3Remediation.
@@ -51,6 +81,18 @@ type Metal3RemediationSpec struct {
 	// Strategy field defines remediation strategy.
 	// +optional
 	Strategy *RemediationStrategy `json:"strategy,omitempty"`
+
+	// HostRef references the BareMetalHost to remediate when the Metal3Remediation
+	// is not owned by a Machine, e.g. for a host which failed during provisioning.
+	// +optional
+	HostRef *corev1.ObjectReference `json:"hostRef,omitempty"`
+
+	// MachineSelector selects the Machine to remediate, in the namespace of
+	// the Metal3Remediation, when the Metal3Remediation is not owned by a
+	// Machine. It must match exactly one Machine, which is then set as owner
+	// of the Metal3Remediation. It cannot be set together with HostRef.
+	// +optional
+	MachineSelector *metav1.LabelSelector `json:"machineSelector,omitempty"`
 }
 
 // RemediationStrategy describes how to remediate machines.
@@ -66,6 +108,40 @@ type RemediationStrategy struct {
 	// Sets the timeout between remediation retries.
 	// +optional
 	Timeout *metav1.Duration `json:"timeout"`
+
+	// ReadinessCheck adds a criterion to the node being Ready before the node
+	// is considered recovered after a reboot.
+	// +optional
+	ReadinessCheck *NodeReadinessCheck `json:"readinessCheck,omitempty"`
+
+	// DrainSkipPodSelector selects the pods of the node the drain does not
+	// wait for, like DaemonSet pods, e.g. the pods of a storage system.
+	// +optional
+	DrainSkipPodSelector *metav1.LabelSelector `json:"drainSkipPodSelector,omitempty"`
+
+	// RebootsBeforeEscalation sets the number of reboots of the Reboot
+	// strategy after which a host that is still unhealthy is remediated with
+	// the EscalationType strategy instead, even if RetryLimit is not reached.
+	// Zero disables the escalation.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RebootsBeforeEscalation int `json:"rebootsBeforeEscalation,omitempty"`
+
+	// EscalationType is the remediation strategy the Reboot strategy escalates
+	// to once RebootsBeforeEscalation is reached.
+	// +kubebuilder:validation:Enum=Quarantine;Reassociate
+	// +optional
+	EscalationType RemediationType `json:"escalationType,omitempty"`
+}
+
+// NodeReadinessCheck describes a pod which must be Running on the node, for
+// example one of a DaemonSet, for the node to be considered recovered.
+type NodeReadinessCheck struct {
+	// Namespace of the pod in the workload cluster.
+	Namespace string `json:"namespace"`
+
+	// PodSelector selects the pod. It must not be empty.
+	PodSelector metav1.LabelSelector `json:"podSelector"`
 }
 
 // Metal3RemediationStatus defines the observed state of Metal3Remediation.
@@ -83,6 +159,21 @@ type Metal3RemediationStatus struct {
 	// LastRemediated identifies when the host was last remediated
 	// +optional
 	LastRemediated *metav1.Time `json:"lastRemediated,omitempty"`
+
+	// HostNotFoundSince identifies when the unhealthy host was first found to be missing.
+	// It is cleared again once the host is found.
+	// +optional
+	HostNotFoundSince *metav1.Time `json:"hostNotFoundSince,omitempty"`
+
+	// RebootCount is the number of times the host has been rebooted by the
+	// remediation.
+	// +optional
+	RebootCount int `json:"rebootCount,omitempty"`
+
+	// EscalatedType is the remediation strategy the remediation escalated
+	// to after RebootsBeforeEscalation reboots, if any.
+	// +optional
+	EscalatedType RemediationType `json:"escalatedType,omitempty"`
 }
 
 // +kubebuilder:object:root=true
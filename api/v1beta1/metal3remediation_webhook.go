@@ -17,7 +17,12 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -29,12 +34,47 @@ import (
 // log is for logging in this package.
 var metal3remediationlog = logf.Log.WithName("metal3remediation-resource")
 
-func (r *Metal3Remediation) SetupWebhookWithManager(mgr ctrl.Manager) error {
+// +kubebuilder:object:generate=false
+
+// Metal3RemediationWebhook validates the Metal3Remediations against the
+// minimum remediation timeout it is configured with.
+type Metal3RemediationWebhook struct {
+	// MinRemediationTimeout is the minimum time between remediation retries.
+	MinRemediationTimeout time.Duration
+}
+
+var _ webhook.CustomValidator = &Metal3RemediationWebhook{}
+
+func (w *Metal3RemediationWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
-		For(r).
+		For(&Metal3Remediation{}).
+		WithValidator(w).
 		Complete()
 }
 
+// ValidateCreate implements webhook.CustomValidator.
+func (w *Metal3RemediationWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*Metal3Remediation)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a Metal3Remediation but got a %T", obj))
+	}
+	return nil, r.validate(w.MinRemediationTimeout)
+}
+
+// ValidateUpdate implements webhook.CustomValidator.
+func (w *Metal3RemediationWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	r, ok := newObj.(*Metal3Remediation)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a Metal3Remediation but got a %T", newObj))
+	}
+	return nil, r.validate(w.MinRemediationTimeout)
+}
+
+// ValidateDelete implements webhook.CustomValidator.
+func (w *Metal3RemediationWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
 // +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-metal3remediation,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=metal3remediations,versions=v1beta1,name=validation.metal3remediation.infrastructure.cluster.x-k8s.io,matchPolicy=Equivalent,sideEffects=None,admissionReviewVersions=v1;v1beta1
 // +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1beta1-metal3remediation,mutating=true,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=metal3remediations,versions=v1beta1,name=default.metal3remediation.infrastructure.cluster.x-k8s.io,matchPolicy=Equivalent,sideEffects=None,admissionReviewVersions=v1;v1beta1
 
@@ -45,14 +85,16 @@ var _ webhook.Validator = &Metal3Remediation{}
 func (r *Metal3Remediation) Default() {
 }
 
-// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+// ValidateCreate implements webhook.Validator with the DefaultMinRemediationTimeout.
+// The webhook validates with the Metal3RemediationWebhook instead.
 func (r *Metal3Remediation) ValidateCreate() (admission.Warnings, error) {
-	return nil, r.validate()
+	return nil, r.validate(DefaultMinRemediationTimeout)
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// ValidateUpdate implements webhook.Validator with the DefaultMinRemediationTimeout.
+// The webhook validates with the Metal3RemediationWebhook instead.
 func (r *Metal3Remediation) ValidateUpdate(_ runtime.Object) (admission.Warnings, error) {
-	return nil, r.validate()
+	return nil, r.validate(DefaultMinRemediationTimeout)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
@@ -61,26 +103,20 @@ func (r *Metal3Remediation) ValidateDelete() (admission.Warnings, error) {
 	return nil, nil
 }
 
-func (r *Metal3Remediation) validate() error {
+func (r *Metal3Remediation) validate(minTimeout time.Duration) error {
 	var allErrs field.ErrorList
-	if r.Spec.Strategy.Timeout != nil && r.Spec.Strategy.Timeout.Seconds() < minTimeout.Seconds() {
-		allErrs = append(
-			allErrs,
-			field.Invalid(
-				field.NewPath("spec", "strategy", "timeout"),
-				r.Spec.Strategy.Timeout,
-				"min duration is minTimeout.Seconds()",
-			),
-		)
-	}
+	allErrs = append(allErrs, validateRemediationTimeout(r.Spec.Strategy.Timeout, minTimeout,
+		field.NewPath("spec", "strategy", "timeout"))...,
+	)
 
-	if r.Spec.Strategy.Type != RebootRemediationStrategy {
+	if r.Spec.Strategy.Type != RebootRemediationStrategy && r.Spec.Strategy.Type != QuarantineRemediationStrategy &&
+		r.Spec.Strategy.Type != ReassociateRemediationStrategy {
 		allErrs = append(
 			allErrs,
 			field.Invalid(
 				field.NewPath("spec", "strategy", "type"),
 				r.Spec.Strategy.Type,
-				"is only supported remediation strategy",
+				"supported remediation strategies are Reboot, Quarantine and Reassociate",
 			),
 		)
 	}
@@ -96,8 +132,116 @@ func (r *Metal3Remediation) validate() error {
 		)
 	}
 
+	allErrs = append(allErrs, validateReadinessCheck(r.Spec.Strategy.ReadinessCheck,
+		field.NewPath("spec", "strategy", "readinessCheck"))...,
+	)
+	allErrs = append(allErrs, validateDrainSkipPodSelector(r.Spec.Strategy.DrainSkipPodSelector,
+		field.NewPath("spec", "strategy", "drainSkipPodSelector"))...,
+	)
+	allErrs = append(allErrs, validateEscalation(r.Spec.Strategy, field.NewPath("spec", "strategy"))...)
+
+	if r.Spec.HostRef != nil && r.Spec.HostRef.Name == "" {
+		allErrs = append(
+			allErrs,
+			field.Required(
+				field.NewPath("spec", "hostRef", "name"),
+				"hostRef needs to contain the name of a BareMetalHost",
+			),
+		)
+	}
+
+	allErrs = append(allErrs, validateMachineSelector(r.Spec.MachineSelector,
+		field.NewPath("spec", "machineSelector"))...,
+	)
+	if r.Spec.HostRef != nil && r.Spec.MachineSelector != nil {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(
+				field.NewPath("spec", "machineSelector"),
+				"machineSelector cannot be set together with hostRef",
+			),
+		)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("Metal3Remediation").GroupKind(), r.Name, allErrs)
 }
+
+// validateMachineSelector validates the Machine selector of a remediation, if
+// any. An empty selector would match all the Machines of the namespace.
+func validateMachineSelector(machineSelector *metav1.LabelSelector, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if machineSelector == nil {
+		return allErrs
+	}
+	selector, err := metav1.LabelSelectorAsSelector(machineSelector)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, machineSelector, err.Error()))
+	} else if selector.Empty() {
+		allErrs = append(allErrs, field.Required(fldPath, "machineSelector must not be empty"))
+	}
+	return allErrs
+}
+
+// validateReadinessCheck validates the readiness check of a remediation
+// strategy, if any.
+func validateReadinessCheck(check *NodeReadinessCheck, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if check == nil {
+		return allErrs
+	}
+	if check.Namespace == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("namespace"), "namespace of the pod is required"))
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&check.PodSelector)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("podSelector"), check.PodSelector, err.Error()))
+	} else if selector.Empty() {
+		allErrs = append(allErrs, field.Required(fldPath.Child("podSelector"), "podSelector must not be empty"))
+	}
+	return allErrs
+}
+
+// validateDrainSkipPodSelector validates the drain skip pod selector of a
+// remediation strategy, if any. An empty selector would skip all the pods.
+func validateDrainSkipPodSelector(podSelector *metav1.LabelSelector, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if podSelector == nil {
+		return allErrs
+	}
+	selector, err := metav1.LabelSelectorAsSelector(podSelector)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, podSelector, err.Error()))
+	} else if selector.Empty() {
+		allErrs = append(allErrs, field.Required(fldPath, "drainSkipPodSelector must not be empty"))
+	}
+	return allErrs
+}
+
+// validateEscalation validates the escalation of a remediation strategy, which
+// only applies to the Reboot strategy.
+func validateEscalation(strategy *RemediationStrategy, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if strategy.RebootsBeforeEscalation < 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("rebootsBeforeEscalation"),
+			strategy.RebootsBeforeEscalation, "must not be negative"))
+	}
+	if strategy.RebootsBeforeEscalation == 0 && strategy.EscalationType == "" {
+		return allErrs
+	}
+	if strategy.Type != RebootRemediationStrategy {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("type"), strategy.Type,
+			"escalation is only supported by the Reboot remediation strategy"))
+	}
+	if strategy.RebootsBeforeEscalation == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("rebootsBeforeEscalation"),
+			"rebootsBeforeEscalation is required with an escalationType"))
+	}
+	if strategy.EscalationType != QuarantineRemediationStrategy && strategy.EscalationType != ReassociateRemediationStrategy {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("escalationType"), strategy.EscalationType,
+			"supported escalation strategies are Quarantine and Reassociate"))
+	}
+	return allErrs
+}
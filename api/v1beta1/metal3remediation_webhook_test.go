@@ -14,10 +14,12 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -34,6 +36,12 @@ func TestMetal3RemediationValidation(t *testing.T) {
 		timeout   *metav1.Duration
 		limit     int
 		strategy  RemediationType
+		hostRef   *corev1.ObjectReference
+		selector  *metav1.LabelSelector
+		readiness *NodeReadinessCheck
+		drainSkip *metav1.LabelSelector
+		reboots   int
+		escalate  RemediationType
 		expectErr bool
 	}{
 		{
@@ -79,7 +87,21 @@ func TestMetal3RemediationValidation(t *testing.T) {
 			expectErr: false,
 		},
 		{
-			name:      "when the Remediation Type is not Reboot",
+			name:      "when the Remediation Type is Quarantine",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  QuarantineRemediationStrategy,
+			expectErr: false,
+		},
+		{
+			name:      "when the Remediation Type is Reassociate",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  ReassociateRemediationStrategy,
+			expectErr: false,
+		},
+		{
+			name:      "when the Remediation Type is not supported",
 			timeout:   &threeMinutes,
 			limit:     1,
 			strategy:  WrongRemediationStrategy,
@@ -106,6 +128,166 @@ func TestMetal3RemediationValidation(t *testing.T) {
 			strategy:  RebootRemediationStrategy,
 			expectErr: false,
 		},
+		{
+			name:      "when the HostRef is given",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  RebootRemediationStrategy,
+			hostRef:   &corev1.ObjectReference{Name: "host-0"},
+			expectErr: false,
+		},
+		{
+			name:      "when the HostRef has no name",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  RebootRemediationStrategy,
+			hostRef:   &corev1.ObjectReference{Namespace: "default"},
+			expectErr: true,
+		},
+		{
+			name:      "when the MachineSelector is given",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  RebootRemediationStrategy,
+			selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "1"}},
+			expectErr: false,
+		},
+		{
+			name:      "when the MachineSelector is empty",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  RebootRemediationStrategy,
+			selector:  &metav1.LabelSelector{},
+			expectErr: true,
+		},
+		{
+			name:     "when the MachineSelector is invalid",
+			timeout:  &threeMinutes,
+			limit:    1,
+			strategy: RebootRemediationStrategy,
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "rack", Operator: "Foo"},
+			}},
+			expectErr: true,
+		},
+		{
+			name:      "when the MachineSelector is given with the HostRef",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  RebootRemediationStrategy,
+			hostRef:   &corev1.ObjectReference{Name: "host-0"},
+			selector:  &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "1"}},
+			expectErr: true,
+		},
+		{
+			name:     "when the ReadinessCheck is given",
+			timeout:  &threeMinutes,
+			limit:    1,
+			strategy: RebootRemediationStrategy,
+			readiness: &NodeReadinessCheck{
+				Namespace:   "monitoring",
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}},
+			},
+			expectErr: false,
+		},
+		{
+			name:     "when the ReadinessCheck has no namespace",
+			timeout:  &threeMinutes,
+			limit:    1,
+			strategy: RebootRemediationStrategy,
+			readiness: &NodeReadinessCheck{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "agent"}},
+			},
+			expectErr: true,
+		},
+		{
+			name:     "when the ReadinessCheck has an empty pod selector",
+			timeout:  &threeMinutes,
+			limit:    1,
+			strategy: RebootRemediationStrategy,
+			readiness: &NodeReadinessCheck{
+				Namespace: "monitoring",
+			},
+			expectErr: true,
+		},
+		{
+			name:      "when the DrainSkipPodSelector is given",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  RebootRemediationStrategy,
+			drainSkip: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "storage"}},
+			expectErr: false,
+		},
+		{
+			name:      "when the DrainSkipPodSelector is empty",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  RebootRemediationStrategy,
+			drainSkip: &metav1.LabelSelector{},
+			expectErr: true,
+		},
+		{
+			name:     "when the DrainSkipPodSelector is invalid",
+			timeout:  &threeMinutes,
+			limit:    1,
+			strategy: RebootRemediationStrategy,
+			drainSkip: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "app", Operator: "Foo"},
+			}},
+			expectErr: true,
+		},
+		{
+			name:      "when the escalation is given",
+			timeout:   &threeMinutes,
+			limit:     3,
+			strategy:  RebootRemediationStrategy,
+			reboots:   2,
+			escalate:  ReassociateRemediationStrategy,
+			expectErr: false,
+		},
+		{
+			name:      "when the escalation has no escalationType",
+			timeout:   &threeMinutes,
+			limit:     3,
+			strategy:  RebootRemediationStrategy,
+			reboots:   2,
+			expectErr: true,
+		},
+		{
+			name:      "when the escalation has no rebootsBeforeEscalation",
+			timeout:   &threeMinutes,
+			limit:     3,
+			strategy:  RebootRemediationStrategy,
+			escalate:  QuarantineRemediationStrategy,
+			expectErr: true,
+		},
+		{
+			name:      "when the escalation is to the Reboot strategy",
+			timeout:   &threeMinutes,
+			limit:     3,
+			strategy:  RebootRemediationStrategy,
+			reboots:   2,
+			escalate:  RebootRemediationStrategy,
+			expectErr: true,
+		},
+		{
+			name:      "when the escalation is from the Quarantine strategy",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  QuarantineRemediationStrategy,
+			reboots:   2,
+			escalate:  ReassociateRemediationStrategy,
+			expectErr: true,
+		},
+		{
+			name:      "when the rebootsBeforeEscalation is negative",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  RebootRemediationStrategy,
+			reboots:   -1,
+			escalate:  ReassociateRemediationStrategy,
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -114,10 +296,16 @@ func TestMetal3RemediationValidation(t *testing.T) {
 		m3r := &Metal3Remediation{
 			Spec: Metal3RemediationSpec{
 				Strategy: &RemediationStrategy{
-					Timeout:    tt.timeout,
-					RetryLimit: tt.limit,
-					Type:       tt.strategy,
+					Timeout:                 tt.timeout,
+					RetryLimit:              tt.limit,
+					Type:                    tt.strategy,
+					ReadinessCheck:          tt.readiness,
+					DrainSkipPodSelector:    tt.drainSkip,
+					RebootsBeforeEscalation: tt.reboots,
+					EscalationType:          tt.escalate,
 				},
+				HostRef:         tt.hostRef,
+				MachineSelector: tt.selector,
 			},
 		}
 
@@ -134,3 +322,53 @@ func TestMetal3RemediationValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestMetal3RemediationMinRemediationTimeout(t *testing.T) {
+	w := &Metal3RemediationWebhook{MinRemediationTimeout: time.Minute}
+
+	tests := []struct {
+		name      string
+		timeout   time.Duration
+		expectErr bool
+	}{
+		{
+			name:      "when the Timeout is the configured minimum",
+			timeout:   time.Minute,
+			expectErr: false,
+		},
+		{
+			name:      "when the Timeout is less than the default but above the configured minimum",
+			timeout:   90 * time.Second,
+			expectErr: false,
+		},
+		{
+			name:      "when the Timeout is less than the configured minimum",
+			timeout:   59 * time.Second,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			m3r := &Metal3Remediation{
+				Spec: Metal3RemediationSpec{
+					Strategy: &RemediationStrategy{
+						Timeout:    &metav1.Duration{Duration: tt.timeout},
+						RetryLimit: 1,
+						Type:       RebootRemediationStrategy,
+					},
+				},
+			}
+
+			_, err := w.ValidateCreate(context.TODO(), m3r)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.strategy.timeout"))
+				g.Expect(err.Error()).To(ContainSubstring("must be at least 1m0s"))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PhaseCompleted represents the state where all the Machines selected by a
+	// Metal3RemediationSet have been remediated, successfully or not.
+	PhaseCompleted = "Completed"
+)
+
+// Metal3RemediationSetSpec defines the desired state of Metal3RemediationSet.
+type Metal3RemediationSetSpec struct {
+	// Selector selects the Machines to remediate, in the namespace of the
+	// Metal3RemediationSet. It must not be empty.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// MaxConcurrency is the maximum number of Machines remediated at the same
+	// time. The Machines are remediated in the order of their names.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
+
+	// Strategy is the remediation strategy of the Metal3Remediations created for
+	// the selected Machines. Its timeout must be set.
+	// +kubebuilder:validation:XValidation:rule="has(self.timeout)",message="timeout must be set"
+	Strategy *RemediationStrategy `json:"strategy"`
+}
+
+// Metal3RemediationSetStatus defines the observed state of Metal3RemediationSet.
+type Metal3RemediationSetStatus struct {
+	// Phase represents the current phase of the remediation of the set,
+	// either Running or Completed.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// InProgress lists the names of the Machines being remediated.
+	// +optional
+	InProgress []string `json:"inProgress,omitempty"`
+
+	// Remediated lists the names of the Machines successfully remediated.
+	// +optional
+	Remediated []string `json:"remediated,omitempty"`
+
+	// Failed lists the names of the Machines whose remediation failed.
+	// +optional
+	Failed []string `json:"failed,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:path=metal3remediationsets,scope=Namespaced,categories=cluster-api,shortName=m3rs;m3remediationset
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Max concurrency",type=string,JSONPath=".spec.maxConcurrency",description="How many Machines are remediated at the same time"
+// +kubebuilder:printcolumn:name="Strategy",type=string,JSONPath=".spec.strategy.type",description="Type of the remediation strategy"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=".status.phase",description="Phase of the remediation of the set"
+
+// Metal3RemediationSet is the Schema for the metal3remediationsets API. It
+// remediates a group of Machines with a shared concurrency and ordering, by
+// creating a Metal3Remediation for each of them.
+type Metal3RemediationSet struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec Metal3RemediationSetSpec `json:"spec,omitempty"`
+	// +optional
+	Status Metal3RemediationSetStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// Metal3RemediationSetList contains a list of Metal3RemediationSet.
+type Metal3RemediationSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Metal3RemediationSet `json:"items"`
+}
+
+func init() {
+	objectTypes = append(objectTypes, &Metal3RemediationSet{}, &Metal3RemediationSetList{})
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
+	"fmt"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -29,11 +31,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
+// DefaultMinRemediationTimeout is the default minimum time between remediation
+// retries. A shorter timeout reboots the host again before its node can
+// recover.
+const DefaultMinRemediationTimeout = 100 * time.Second
+
 var (
 	// Default retry timeout is 600 seconds.
 	defaultTimeout = metav1.Duration{Duration: 600 * time.Second}
-	// Minimum time between remediation retries.
-	minTimeout = metav1.Duration{Duration: 100 * time.Second}
 	// Mininum remediation retry limit is 1.
 	// Controller will try to remediate unhealhy node at least once.
 	minRetryLimit = 1
@@ -42,12 +47,50 @@ var (
 // log is for logging in this package.
 var metal3remediationtemplatelog = logf.Log.WithName("metal3remediationtemplate-resource")
 
-func (r *Metal3RemediationTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+// +kubebuilder:object:generate=false
+
+// Metal3RemediationTemplateWebhook validates the Metal3RemediationTemplates
+// against the minimum remediation timeout it is configured with. The defaults
+// are set by the Metal3RemediationTemplate itself.
+type Metal3RemediationTemplateWebhook struct {
+	// MinRemediationTimeout is the minimum time between remediation retries.
+	MinRemediationTimeout time.Duration
+}
+
+var _ webhook.CustomValidator = &Metal3RemediationTemplateWebhook{}
+
+func (w *Metal3RemediationTemplateWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewWebhookManagedBy(mgr).
-		For(r).
+		For(&Metal3RemediationTemplate{}).
+		WithValidator(w).
 		Complete()
 }
 
+// ValidateCreate implements webhook.CustomValidator.
+func (w *Metal3RemediationTemplateWebhook) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*Metal3RemediationTemplate)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a Metal3RemediationTemplate but got a %T", obj))
+	}
+	metal3remediationtemplatelog.Info("validate create", "name", r.Name)
+	return nil, r.validate(w.MinRemediationTimeout)
+}
+
+// ValidateUpdate implements webhook.CustomValidator.
+func (w *Metal3RemediationTemplateWebhook) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	r, ok := newObj.(*Metal3RemediationTemplate)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("expected a Metal3RemediationTemplate but got a %T", newObj))
+	}
+	metal3remediationtemplatelog.Info("validate update", "name", r.Name)
+	return nil, r.validate(w.MinRemediationTimeout)
+}
+
+// ValidateDelete implements webhook.CustomValidator.
+func (w *Metal3RemediationTemplateWebhook) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
 // +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-metal3remediationtemplate,mutating=false,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=metal3remediationtemplates,versions=v1beta1,name=validation.metal3remediationtemplate.infrastructure.cluster.x-k8s.io,matchPolicy=Equivalent,sideEffects=None,admissionReviewVersions=v1;v1beta1
 // +kubebuilder:webhook:verbs=create;update,path=/mutate-infrastructure-cluster-x-k8s-io-v1beta1-metal3remediationtemplate,mutating=true,failurePolicy=fail,groups=infrastructure.cluster.x-k8s.io,resources=metal3remediationtemplates,versions=v1beta1,name=default.metal3remediationtemplate.infrastructure.cluster.x-k8s.io,matchPolicy=Equivalent,sideEffects=None,admissionReviewVersions=v1;v1beta1
 
@@ -69,16 +112,18 @@ func (r *Metal3RemediationTemplate) Default() {
 	}
 }
 
-// ValidateCreate implements webhook.Validator so a webhook will be registered for the type.
+// ValidateCreate implements webhook.Validator with the DefaultMinRemediationTimeout.
+// The webhook validates with the Metal3RemediationTemplateWebhook instead.
 func (r *Metal3RemediationTemplate) ValidateCreate() (admission.Warnings, error) {
 	metal3remediationtemplatelog.Info("validate create", "name", r.Name)
-	return nil, r.validate()
+	return nil, r.validate(DefaultMinRemediationTimeout)
 }
 
-// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
+// ValidateUpdate implements webhook.Validator with the DefaultMinRemediationTimeout.
+// The webhook validates with the Metal3RemediationTemplateWebhook instead.
 func (r *Metal3RemediationTemplate) ValidateUpdate(_ runtime.Object) (admission.Warnings, error) {
 	metal3remediationtemplatelog.Info("validate update", "name", r.Name)
-	return nil, r.validate()
+	return nil, r.validate(DefaultMinRemediationTimeout)
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
@@ -87,26 +132,21 @@ func (r *Metal3RemediationTemplate) ValidateDelete() (admission.Warnings, error)
 	return nil, nil
 }
 
-func (r *Metal3RemediationTemplate) validate() error {
+func (r *Metal3RemediationTemplate) validate(minTimeout time.Duration) error {
 	var allErrs field.ErrorList
-	if r.Spec.Template.Spec.Strategy.Timeout != nil && r.Spec.Template.Spec.Strategy.Timeout.Seconds() < minTimeout.Seconds() {
-		allErrs = append(
-			allErrs,
-			field.Invalid(
-				field.NewPath("spec", "template", "spec", "strategy", "timeout"),
-				r.Spec.Template.Spec.Strategy.Timeout,
-				"min duration is 100s",
-			),
-		)
-	}
+	allErrs = append(allErrs, validateRemediationTimeout(r.Spec.Template.Spec.Strategy.Timeout, minTimeout,
+		field.NewPath("spec", "template", "spec", "strategy", "timeout"))...,
+	)
 
-	if r.Spec.Template.Spec.Strategy.Type != RebootRemediationStrategy {
+	if r.Spec.Template.Spec.Strategy.Type != RebootRemediationStrategy &&
+		r.Spec.Template.Spec.Strategy.Type != QuarantineRemediationStrategy &&
+		r.Spec.Template.Spec.Strategy.Type != ReassociateRemediationStrategy {
 		allErrs = append(
 			allErrs,
 			field.Invalid(
 				field.NewPath("spec", "template", "spec", "strategy", "type"),
 				r.Spec.Template.Spec.Strategy.Type,
-				"only supported remediation strategy is reboot",
+				"supported remediation strategies are reboot, quarantine and reassociate",
 			),
 		)
 	}
@@ -122,8 +162,31 @@ func (r *Metal3RemediationTemplate) validate() error {
 		)
 	}
 
+	allErrs = append(allErrs, validateReadinessCheck(r.Spec.Template.Spec.Strategy.ReadinessCheck,
+		field.NewPath("spec", "template", "spec", "strategy", "readinessCheck"))...,
+	)
+	allErrs = append(allErrs, validateDrainSkipPodSelector(r.Spec.Template.Spec.Strategy.DrainSkipPodSelector,
+		field.NewPath("spec", "template", "spec", "strategy", "drainSkipPodSelector"))...,
+	)
+	allErrs = append(allErrs, validateEscalation(r.Spec.Template.Spec.Strategy,
+		field.NewPath("spec", "template", "spec", "strategy"))...,
+	)
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("Metal3Remediation").GroupKind(), r.Name, allErrs)
 }
+
+// validateRemediationTimeout validates the timeout of a remediation strategy,
+// if any, against minTimeout.
+func validateRemediationTimeout(timeout *metav1.Duration, minTimeout time.Duration, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if timeout != nil && timeout.Duration < minTimeout {
+		allErrs = append(allErrs, field.Invalid(fldPath, timeout.Duration.String(),
+			fmt.Sprintf("must be at least %s, a shorter timeout reboots the host again before its node can recover",
+				minTimeout),
+		))
+	}
+	return allErrs
+}
@@ -14,6 +14,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -127,7 +128,21 @@ func TestMetal3RemediationTemplateValidation(t *testing.T) {
 			expectErr: false,
 		},
 		{
-			name:      "when the Remediation Type is not Reboot",
+			name:      "when the Remediation Type is Quarantine",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  QuarantineRemediationStrategy,
+			expectErr: false,
+		},
+		{
+			name:      "when the Remediation Type is Reassociate",
+			timeout:   &threeMinutes,
+			limit:     1,
+			strategy:  ReassociateRemediationStrategy,
+			expectErr: false,
+		},
+		{
+			name:      "when the Remediation Type is not supported",
 			timeout:   &threeMinutes,
 			limit:     1,
 			strategy:  WrongRemediationStrategy,
@@ -186,3 +201,57 @@ func TestMetal3RemediationTemplateValidation(t *testing.T) {
 		}
 	}
 }
+
+func TestMetal3RemediationTemplateMinRemediationTimeout(t *testing.T) {
+	w := &Metal3RemediationTemplateWebhook{MinRemediationTimeout: time.Minute}
+
+	tests := []struct {
+		name      string
+		timeout   time.Duration
+		expectErr bool
+	}{
+		{
+			name:      "when the Timeout is the configured minimum",
+			timeout:   time.Minute,
+			expectErr: false,
+		},
+		{
+			name:      "when the Timeout is less than the default but above the configured minimum",
+			timeout:   90 * time.Second,
+			expectErr: false,
+		},
+		{
+			name:      "when the Timeout is less than the configured minimum",
+			timeout:   59 * time.Second,
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			m3rt := &Metal3RemediationTemplate{
+				Spec: Metal3RemediationTemplateSpec{
+					Template: Metal3RemediationTemplateResource{
+						Spec: Metal3RemediationSpec{
+							Strategy: &RemediationStrategy{
+								Timeout:    &metav1.Duration{Duration: tt.timeout},
+								RetryLimit: 1,
+								Type:       RebootRemediationStrategy,
+							},
+						},
+					},
+				},
+			}
+
+			_, err := w.ValidateCreate(context.TODO(), m3rt)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("spec.template.spec.strategy.timeout"))
+				g.Expect(err.Error()).To(ContainSubstring("must be at least 1m0s"))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
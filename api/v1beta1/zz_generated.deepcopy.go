@@ -74,6 +74,43 @@ func (in *FromPool) DeepCopy() *FromPool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareRequirements) DeepCopyInto(out *HardwareRequirements) {
+	*out = *in
+	if in.Topology != nil {
+		in, out := &in.Topology, &out.Topology
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareRequirements.
+func (in *HardwareRequirements) DeepCopy() *HardwareRequirements {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareRequirements)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostPlacement) DeepCopyInto(out *HostPlacement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostPlacement.
+func (in *HostPlacement) DeepCopy() *HostPlacement {
+	if in == nil {
+		return nil
+	}
+	out := new(HostPlacement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostSelector) DeepCopyInto(out *HostSelector) {
 	*out = *in
@@ -91,6 +128,16 @@ func (in *HostSelector) DeepCopyInto(out *HostSelector) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RequiredCapabilities != nil {
+		in, out := &in.RequiredCapabilities, &out.RequiredCapabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinimumHardware != nil {
+		in, out := &in.MinimumHardware, &out.MinimumHardware
+		*out = new(HardwareRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostSelector.
@@ -181,6 +228,11 @@ func (in *MetaData) DeepCopyInto(out *MetaData) {
 		*out = make([]FromPool, len(*in))
 		copy(*out, *in)
 	}
+	if in.NetmasksFromPool != nil {
+		in, out := &in.NetmasksFromPool, &out.NetmasksFromPool
+		*out = make([]FromPool, len(*in))
+		copy(*out, *in)
+	}
 	if in.GatewaysFromPool != nil {
 		in, out := &in.GatewaysFromPool, &out.GatewaysFromPool
 		*out = make([]FromPool, len(*in))
@@ -206,6 +258,28 @@ func (in *MetaData) DeepCopyInto(out *MetaData) {
 		*out = make([]MetaDataFromAnnotation, len(*in))
 		copy(*out, *in)
 	}
+	if in.FromCluster != nil {
+		in, out := &in.FromCluster, &out.FromCluster
+		*out = make([]MetaDataFromCluster, len(*in))
+		copy(*out, *in)
+	}
+	if in.FromSecrets != nil {
+		in, out := &in.FromSecrets, &out.FromSecrets
+		*out = make([]MetaDataFromSource, len(*in))
+		copy(*out, *in)
+	}
+	if in.FromConfigMaps != nil {
+		in, out := &in.FromConfigMaps, &out.FromConfigMaps
+		*out = make([]MetaDataFromSource, len(*in))
+		copy(*out, *in)
+	}
+	if in.NTPServers != nil {
+		in, out := &in.NTPServers, &out.NTPServers
+		*out = make([]MetaDataNTPServers, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetaData.
@@ -233,6 +307,21 @@ func (in *MetaDataFromAnnotation) DeepCopy() *MetaDataFromAnnotation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetaDataFromCluster) DeepCopyInto(out *MetaDataFromCluster) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetaDataFromCluster.
+func (in *MetaDataFromCluster) DeepCopy() *MetaDataFromCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(MetaDataFromCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetaDataFromLabel) DeepCopyInto(out *MetaDataFromLabel) {
 	*out = *in
@@ -248,6 +337,21 @@ func (in *MetaDataFromLabel) DeepCopy() *MetaDataFromLabel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetaDataFromSource) DeepCopyInto(out *MetaDataFromSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetaDataFromSource.
+func (in *MetaDataFromSource) DeepCopy() *MetaDataFromSource {
+	if in == nil {
+		return nil
+	}
+	out := new(MetaDataFromSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetaDataHostInterface) DeepCopyInto(out *MetaDataHostInterface) {
 	*out = *in
@@ -308,6 +412,31 @@ func (in *MetaDataIndex) DeepCopy() *MetaDataIndex {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetaDataNTPServers) DeepCopyInto(out *MetaDataNTPServers) {
+	*out = *in
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FromConfigMap != nil {
+		in, out := &in.FromConfigMap, &out.FromConfigMap
+		*out = new(NTPServersFromConfigMap)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetaDataNTPServers.
+func (in *MetaDataNTPServers) DeepCopy() *MetaDataNTPServers {
+	if in == nil {
+		return nil
+	}
+	out := new(MetaDataNTPServers)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetaDataNamespace) DeepCopyInto(out *MetaDataNamespace) {
 	*out = *in
@@ -426,6 +555,11 @@ func (in *Metal3ClusterSpec) DeepCopyInto(out *Metal3ClusterSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.DefaultDataTemplate != nil {
+		in, out := &in.DefaultDataTemplate, &out.DefaultDataTemplate
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3ClusterSpec.
@@ -591,6 +725,21 @@ func (in *Metal3Data) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3DataAddress) DeepCopyInto(out *Metal3DataAddress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3DataAddress.
+func (in *Metal3DataAddress) DeepCopy() *Metal3DataAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3DataAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metal3DataClaim) DeepCopyInto(out *Metal3DataClaim) {
 	*out = *in
@@ -736,6 +885,11 @@ func (in *Metal3DataSpec) DeepCopyInto(out *Metal3DataSpec) {
 		*out = new(v1.SecretReference)
 		**out = **in
 	}
+	if in.UserData != nil {
+		in, out := &in.UserData, &out.UserData
+		*out = new(v1.SecretReference)
+		**out = **in
+	}
 	out.Claim = in.Claim
 	out.Template = in.Template
 }
@@ -758,6 +912,18 @@ func (in *Metal3DataStatus) DeepCopyInto(out *Metal3DataStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]Metal3DataAddress, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(apiv1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3DataStatus.
@@ -842,6 +1008,11 @@ func (in *Metal3DataTemplateSpec) DeepCopyInto(out *Metal3DataTemplateSpec) {
 		*out = new(NetworkData)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.UserData != nil {
+		in, out := &in.UserData, &out.UserData
+		*out = new(UserData)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3DataTemplateSpec.
@@ -948,6 +1119,11 @@ func (in *Metal3MachineSpec) DeepCopyInto(out *Metal3MachineSpec) {
 		**out = **in
 	}
 	in.Image.DeepCopyInto(&out.Image)
+	if in.ImageRef != nil {
+		in, out := &in.ImageRef, &out.ImageRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	if in.CustomDeploy != nil {
 		in, out := &in.CustomDeploy, &out.CustomDeploy
 		*out = new(CustomDeploy)
@@ -959,6 +1135,18 @@ func (in *Metal3MachineSpec) DeepCopyInto(out *Metal3MachineSpec) {
 		**out = **in
 	}
 	in.HostSelector.DeepCopyInto(&out.HostSelector)
+	if in.FallbackHostSelectors != nil {
+		in, out := &in.FallbackHostSelectors, &out.FallbackHostSelectors
+		*out = make([]HostSelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HostPlacement != nil {
+		in, out := &in.HostPlacement, &out.HostPlacement
+		*out = new(HostPlacement)
+		**out = **in
+	}
 	if in.DataTemplate != nil {
 		in, out := &in.DataTemplate, &out.DataTemplate
 		*out = new(v1.ObjectReference)
@@ -979,6 +1167,16 @@ func (in *Metal3MachineSpec) DeepCopyInto(out *Metal3MachineSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.PostDeprovisionPower != nil {
+		in, out := &in.PostDeprovisionPower, &out.PostDeprovisionPower
+		*out = new(string)
+		**out = **in
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(Metal3MachineTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3MachineSpec.
@@ -1013,6 +1211,30 @@ func (in *Metal3MachineStatus) DeepCopyInto(out *Metal3MachineStatus) {
 		*out = make(apiv1beta1.MachineAddresses, len(*in))
 		copy(*out, *in)
 	}
+	if in.FirmwareVersions != nil {
+		in, out := &in.FirmwareVersions, &out.FirmwareVersions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastInspected != nil {
+		in, out := &in.LastInspected, &out.LastInspected
+		*out = (*in).DeepCopy()
+	}
+	if in.AssociatedAt != nil {
+		in, out := &in.AssociatedAt, &out.AssociatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.ProvisioningDuration != nil {
+		in, out := &in.ProvisioningDuration, &out.ProvisioningDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.LastProvisioningStart != nil {
+		in, out := &in.LastProvisioningStart, &out.LastProvisioningStart
+		*out = (*in).DeepCopy()
+	}
 	if in.UserData != nil {
 		in, out := &in.UserData, &out.UserData
 		*out = new(v1.SecretReference)
@@ -1142,6 +1364,41 @@ func (in *Metal3MachineTemplateSpec) DeepCopy() *Metal3MachineTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3MachineTimeouts) DeepCopyInto(out *Metal3MachineTimeouts) {
+	*out = *in
+	if in.Provisioning != nil {
+		in, out := &in.Provisioning, &out.Provisioning
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Deprovision != nil {
+		in, out := &in.Deprovision, &out.Deprovision
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PowerOn != nil {
+		in, out := &in.PowerOn, &out.PowerOn
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.HostError != nil {
+		in, out := &in.HostError, &out.HostError
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3MachineTimeouts.
+func (in *Metal3MachineTimeouts) DeepCopy() *Metal3MachineTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3MachineTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metal3Remediation) DeepCopyInto(out *Metal3Remediation) {
 	*out = *in
@@ -1201,6 +1458,116 @@ func (in *Metal3RemediationList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationSet) DeepCopyInto(out *Metal3RemediationSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationSet.
+func (in *Metal3RemediationSet) DeepCopy() *Metal3RemediationSet {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Metal3RemediationSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationSetList) DeepCopyInto(out *Metal3RemediationSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Metal3RemediationSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationSetList.
+func (in *Metal3RemediationSetList) DeepCopy() *Metal3RemediationSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Metal3RemediationSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationSetSpec) DeepCopyInto(out *Metal3RemediationSetSpec) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(RemediationStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationSetSpec.
+func (in *Metal3RemediationSetSpec) DeepCopy() *Metal3RemediationSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metal3RemediationSetStatus) DeepCopyInto(out *Metal3RemediationSetStatus) {
+	*out = *in
+	if in.InProgress != nil {
+		in, out := &in.InProgress, &out.InProgress
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Remediated != nil {
+		in, out := &in.Remediated, &out.Remediated
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Failed != nil {
+		in, out := &in.Failed, &out.Failed
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationSetStatus.
+func (in *Metal3RemediationSetStatus) DeepCopy() *Metal3RemediationSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(Metal3RemediationSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metal3RemediationSpec) DeepCopyInto(out *Metal3RemediationSpec) {
 	*out = *in
@@ -1209,6 +1576,16 @@ func (in *Metal3RemediationSpec) DeepCopyInto(out *Metal3RemediationSpec) {
 		*out = new(RemediationStrategy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HostRef != nil {
+		in, out := &in.HostRef, &out.HostRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
+	if in.MachineSelector != nil {
+		in, out := &in.MachineSelector, &out.MachineSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationSpec.
@@ -1228,6 +1605,10 @@ func (in *Metal3RemediationStatus) DeepCopyInto(out *Metal3RemediationStatus) {
 		in, out := &in.LastRemediated, &out.LastRemediated
 		*out = (*in).DeepCopy()
 	}
+	if in.HostNotFoundSince != nil {
+		in, out := &in.HostNotFoundSince, &out.HostNotFoundSince
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metal3RemediationStatus.
@@ -1347,6 +1728,21 @@ func (in *Metal3RemediationTemplateStatus) DeepCopy() *Metal3RemediationTemplate
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NTPServersFromConfigMap) DeepCopyInto(out *NTPServersFromConfigMap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NTPServersFromConfigMap.
+func (in *NTPServersFromConfigMap) DeepCopy() *NTPServersFromConfigMap {
+	if in == nil {
+		return nil
+	}
+	out := new(NTPServersFromConfigMap)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkData) DeepCopyInto(out *NetworkData) {
 	*out = *in
@@ -1380,6 +1776,11 @@ func (in *NetworkDataIPv4) DeepCopyInto(out *NetworkDataIPv4) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RoutesFromConfigMap != nil {
+		in, out := &in.RoutesFromConfigMap, &out.RoutesFromConfigMap
+		*out = new(RoutesFromConfigMap)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkDataIPv4.
@@ -1402,6 +1803,11 @@ func (in *NetworkDataIPv4DHCP) DeepCopyInto(out *NetworkDataIPv4DHCP) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RoutesFromConfigMap != nil {
+		in, out := &in.RoutesFromConfigMap, &out.RoutesFromConfigMap
+		*out = new(RoutesFromConfigMap)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkDataIPv4DHCP.
@@ -1429,6 +1835,11 @@ func (in *NetworkDataIPv6) DeepCopyInto(out *NetworkDataIPv6) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RoutesFromConfigMap != nil {
+		in, out := &in.RoutesFromConfigMap, &out.RoutesFromConfigMap
+		*out = new(RoutesFromConfigMap)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkDataIPv6.
@@ -1451,6 +1862,11 @@ func (in *NetworkDataIPv6DHCP) DeepCopyInto(out *NetworkDataIPv6DHCP) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.RoutesFromConfigMap != nil {
+		in, out := &in.RoutesFromConfigMap, &out.RoutesFromConfigMap
+		*out = new(RoutesFromConfigMap)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkDataIPv6DHCP.
@@ -1661,6 +2077,11 @@ func (in *NetworkDataService) DeepCopyInto(out *NetworkDataService) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.SearchDomains != nil {
+		in, out := &in.SearchDomains, &out.SearchDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkDataService.
@@ -1818,6 +2239,22 @@ func (in *NetworkLinkEthernetMacFromAnnotation) DeepCopy() *NetworkLinkEthernetM
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeReadinessCheck) DeepCopyInto(out *NodeReadinessCheck) {
+	*out = *in
+	in.PodSelector.DeepCopyInto(&out.PodSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeReadinessCheck.
+func (in *NodeReadinessCheck) DeepCopy() *NodeReadinessCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeReadinessCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemediationStrategy) DeepCopyInto(out *RemediationStrategy) {
 	*out = *in
@@ -1826,6 +2263,16 @@ func (in *RemediationStrategy) DeepCopyInto(out *RemediationStrategy) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.ReadinessCheck != nil {
+		in, out := &in.ReadinessCheck, &out.ReadinessCheck
+		*out = new(NodeReadinessCheck)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DrainSkipPodSelector != nil {
+		in, out := &in.DrainSkipPodSelector, &out.DrainSkipPodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationStrategy.
@@ -1837,3 +2284,80 @@ func (in *RemediationStrategy) DeepCopy() *RemediationStrategy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutesFromConfigMap) DeepCopyInto(out *RoutesFromConfigMap) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutesFromConfigMap.
+func (in *RoutesFromConfigMap) DeepCopy() *RoutesFromConfigMap {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutesFromConfigMap)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserData) DeepCopyInto(out *UserData) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]UserDataFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserData.
+func (in *UserData) DeepCopy() *UserData {
+	if in == nil {
+		return nil
+	}
+	out := new(UserData)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserDataFile) DeepCopyInto(out *UserDataFile) {
+	*out = *in
+	if in.FromConfigMap != nil {
+		in, out := &in.FromConfigMap, &out.FromConfigMap
+		*out = new(UserDataFileSource)
+		**out = **in
+	}
+	if in.FromSecret != nil {
+		in, out := &in.FromSecret, &out.FromSecret
+		*out = new(UserDataFileSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserDataFile.
+func (in *UserDataFile) DeepCopy() *UserDataFile {
+	if in == nil {
+		return nil
+	}
+	out := new(UserDataFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserDataFileSource) DeepCopyInto(out *UserDataFileSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserDataFileSource.
+func (in *UserDataFileSource) DeepCopy() *UserDataFileSource {
+	if in == nil {
+		return nil
+	}
+	out := new(UserDataFileSource)
+	in.DeepCopyInto(out)
+	return out
+}
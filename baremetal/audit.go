@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"sort"
+
+	"github.com/go-logr/logr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+const (
+	// machineAuditActor and remediationAuditActor are the actors of the audit
+	// log lines of the machine and remediation managers.
+	machineAuditActor     = "Metal3Machine-controller"
+	remediationAuditActor = "Metal3Remediation-controller"
+)
+
+// AuditLogLevel is the verbosity of the audit log lines recording the changes
+// made to the BareMetalHosts. A negative level, the default, disables the audit
+// log.
+var AuditLogLevel = -1
+
+// hostChange is a field of a BareMetalHost changed by a mutation.
+type hostChange struct {
+	field    string
+	oldValue interface{}
+	newValue interface{}
+}
+
+// auditHostPatch logs an audit line for each field of the host changed from
+// before, right before the host is patched. before is a copy of the host taken
+// when its patch helper was created, i.e. the object the helper diffs against.
+// Nothing is logged without it.
+func auditHostPatch(log logr.Logger, actor string, before, host *bmov1alpha1.BareMetalHost) {
+	if AuditLogLevel < 0 || before == nil {
+		return
+	}
+	auditLog := log.V(AuditLogLevel)
+	for _, change := range hostChanges(before, host) {
+		auditLog.Info("Audit: patching BareMetalHost", "actor", actor, "kind", "BareMetalHost",
+			"namespace", host.Namespace, "name", host.Name, "field", change.field,
+			"oldValue", change.oldValue, "newValue", change.newValue,
+		)
+	}
+}
+
+// hostChanges returns the changes of the spec fields, annotations and labels
+// set by CAPM3 from previous to host, in a stable order.
+func hostChanges(previous, host *bmov1alpha1.BareMetalHost) []hostChange {
+	changes := []hostChange{}
+	addChange := func(field string, oldValue, newValue interface{}) {
+		if !equality.Semantic.DeepEqual(oldValue, newValue) {
+			changes = append(changes, hostChange{field: field, oldValue: oldValue, newValue: newValue})
+		}
+	}
+	addChange("spec.consumerRef", previous.Spec.ConsumerRef, host.Spec.ConsumerRef)
+	addChange("spec.image", previous.Spec.Image, host.Spec.Image)
+	addChange("spec.customDeploy", previous.Spec.CustomDeploy, host.Spec.CustomDeploy)
+	addChange("spec.online", previous.Spec.Online, host.Spec.Online)
+	addChange("spec.userData", previous.Spec.UserData, host.Spec.UserData)
+	addChange("spec.metaData", previous.Spec.MetaData, host.Spec.MetaData)
+	addChange("spec.networkData", previous.Spec.NetworkData, host.Spec.NetworkData)
+	addChange("spec.automatedCleaningMode", previous.Spec.AutomatedCleaningMode, host.Spec.AutomatedCleaningMode)
+	addMapChanges := func(field string, oldMap, newMap map[string]string) {
+		keys := []string{}
+		for key := range oldMap {
+			keys = append(keys, key)
+		}
+		for key := range newMap {
+			if _, ok := oldMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			var oldValue, newValue interface{}
+			if value, ok := oldMap[key]; ok {
+				oldValue = value
+			}
+			if value, ok := newMap[key]; ok {
+				newValue = value
+			}
+			addChange(field+"["+key+"]", oldValue, newValue)
+		}
+	}
+	addMapChanges("metadata.annotations", previous.Annotations, host.Annotations)
+	addMapChanges("metadata.labels", previous.Labels, host.Labels)
+	return changes
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Audit log", func() {
+	// auditLogger returns a logger of the given verbosity recording the audit
+	// lines in lines.
+	auditLogger := func(verbosity int, lines *[]string) logr.Logger {
+		return funcr.New(func(_, args string) {
+			*lines = append(*lines, args)
+		}, funcr.Options{Verbosity: verbosity})
+	}
+
+	newAuditedHost := func(annotations map[string]string) *bmov1alpha1.BareMetalHost {
+		return &bmov1alpha1.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        baremetalhostName,
+				Namespace:   namespaceName,
+				Annotations: annotations,
+			},
+		}
+	}
+
+	newAuditedMetal3Machine := func() *infrav1.Metal3Machine {
+		return newMetal3Machine(metal3machineName, nil, nil, &metav1.ObjectMeta{
+			Name:      metal3machineName,
+			Namespace: namespaceName,
+			Annotations: map[string]string{
+				HostAnnotation: namespaceName + "/" + baremetalhostName,
+			},
+		})
+	}
+
+	type testCaseHostChanges struct {
+		Mutate          func(*bmov1alpha1.BareMetalHost)
+		ExpectedChanges []hostChange
+	}
+
+	DescribeTable("Test hostChanges",
+		func(tc testCaseHostChanges) {
+			previous := newAuditedHost(map[string]string{"foo": "bar"})
+			previous.Spec.Online = true
+			host := previous.DeepCopy()
+			tc.Mutate(host)
+			Expect(hostChanges(previous, host)).To(Equal(tc.ExpectedChanges))
+		},
+		Entry("No change", testCaseHostChanges{
+			Mutate:          func(*bmov1alpha1.BareMetalHost) {},
+			ExpectedChanges: []hostChange{},
+		}),
+		Entry("Image set", testCaseHostChanges{
+			Mutate: func(host *bmov1alpha1.BareMetalHost) {
+				host.Spec.Image = &bmov1alpha1.Image{URL: "http://image"}
+			},
+			ExpectedChanges: []hostChange{
+				{
+					field:    "spec.image",
+					oldValue: (*bmov1alpha1.Image)(nil),
+					newValue: &bmov1alpha1.Image{URL: "http://image"},
+				},
+			},
+		}),
+		Entry("Host powered off", testCaseHostChanges{
+			Mutate: func(host *bmov1alpha1.BareMetalHost) {
+				host.Spec.Online = false
+			},
+			ExpectedChanges: []hostChange{{field: "spec.online", oldValue: true, newValue: false}},
+		}),
+		Entry("ConsumerRef set", testCaseHostChanges{
+			Mutate: func(host *bmov1alpha1.BareMetalHost) {
+				host.Spec.ConsumerRef = &corev1.ObjectReference{Name: metal3machineName}
+			},
+			ExpectedChanges: []hostChange{
+				{
+					field:    "spec.consumerRef",
+					oldValue: (*corev1.ObjectReference)(nil),
+					newValue: &corev1.ObjectReference{Name: metal3machineName},
+				},
+			},
+		}),
+		Entry("Annotations added and removed", testCaseHostChanges{
+			Mutate: func(host *bmov1alpha1.BareMetalHost) {
+				host.Annotations = map[string]string{bmov1alpha1.PausedAnnotation: PausedAnnotationKey}
+			},
+			ExpectedChanges: []hostChange{
+				{
+					field:    "metadata.annotations[" + bmov1alpha1.PausedAnnotation + "]",
+					oldValue: nil,
+					newValue: PausedAnnotationKey,
+				},
+				{field: "metadata.annotations[foo]", oldValue: "bar", newValue: nil},
+			},
+		}),
+		Entry("Label set", testCaseHostChanges{
+			Mutate: func(host *bmov1alpha1.BareMetalHost) {
+				host.Labels = map[string]string{"foo": "bar"}
+			},
+			ExpectedChanges: []hostChange{{field: "metadata.labels[foo]", oldValue: nil, newValue: "bar"}},
+		}),
+	)
+
+	type testCaseAuditHostPatch struct {
+		AuditLogLevel     int
+		LoggerVerbosity   int
+		ExpectAuditLogged bool
+	}
+
+	DescribeTable("Test audit log of the Metal3Machine manager",
+		func(tc testCaseAuditHostPatch) {
+			defer func(level int) { AuditLogLevel = level }(AuditLogLevel)
+			AuditLogLevel = tc.AuditLogLevel
+
+			m3m := newAuditedMetal3Machine()
+			fakeClient := fake.NewClientBuilder().WithScheme(setupSchemeMm()).WithObjects(
+				newAuditedHost(nil), m3m,
+			).Build()
+			lines := []string{}
+			machineMgr, err := NewMachineManager(fakeClient, nil, nil, nil, m3m,
+				auditLogger(tc.LoggerVerbosity, &lines),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(machineMgr.SetPauseAnnotation(context.TODO())).To(Succeed())
+
+			auditLines := []string{}
+			for _, line := range lines {
+				if strings.Contains(line, `"msg"="Audit: patching BareMetalHost"`) {
+					auditLines = append(auditLines, line)
+				}
+			}
+			if !tc.ExpectAuditLogged {
+				Expect(auditLines).To(BeEmpty())
+				return
+			}
+			Expect(auditLines).To(HaveLen(2))
+			for _, line := range auditLines {
+				Expect(line).To(ContainSubstring(`"actor"="Metal3Machine-controller"`))
+				Expect(line).To(ContainSubstring(`"kind"="BareMetalHost"`))
+				Expect(line).To(ContainSubstring(`"namespace"="` + namespaceName + `"`))
+				Expect(line).To(ContainSubstring(`"name"="` + baremetalhostName + `"`))
+			}
+			Expect(auditLines[0]).To(ContainSubstring(
+				`"field"="metadata.annotations[` + bmov1alpha1.PausedAnnotation + `]" "oldValue"=null "newValue"="` +
+					PausedAnnotationKey + `"`,
+			))
+			Expect(auditLines[1]).To(ContainSubstring(
+				`"field"="metadata.annotations[` + bmov1alpha1.StatusAnnotation + `]"`,
+			))
+		},
+		Entry("Audit log at verbosity zero", testCaseAuditHostPatch{
+			ExpectAuditLogged: true,
+		}),
+		Entry("Audit log at a verbosity enabled in the logger", testCaseAuditHostPatch{
+			AuditLogLevel:     2,
+			LoggerVerbosity:   2,
+			ExpectAuditLogged: true,
+		}),
+		Entry("Audit log at a verbosity disabled in the logger", testCaseAuditHostPatch{
+			AuditLogLevel:   2,
+			LoggerVerbosity: 1,
+		}),
+		Entry("Audit log disabled", testCaseAuditHostPatch{
+			AuditLogLevel:   -1,
+			LoggerVerbosity: 2,
+		}),
+	)
+
+	It("Audits the unhealthy annotation set by the Metal3Remediation manager", func() {
+		defer func(level int) { AuditLogLevel = level }(AuditLogLevel)
+		AuditLogLevel = 0
+
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(newAuditedHost(nil)).Build()
+		lines := []string{}
+		remediationMgr, err := NewRemediationManager(fakeClient, nil, &infrav1.Metal3Remediation{},
+			newAuditedMetal3Machine(), nil, auditLogger(0, &lines),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(remediationMgr.SetUnhealthyAnnotation(context.TODO())).To(Succeed())
+		Expect(lines).To(ContainElement(And(
+			ContainSubstring(`"msg"="Audit: patching BareMetalHost"`),
+			ContainSubstring(`"actor"="Metal3Remediation-controller"`),
+			ContainSubstring(`"field"="metadata.annotations[`+infrav1.UnhealthyAnnotation+`]"`),
+			ContainSubstring(`"newValue"="capm3/UnhealthyNode"`),
+		)))
+	})
+})
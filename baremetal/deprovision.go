@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MaxConcurrentDeprovisions is the number of BareMetalHosts of a cluster the
+// Metal3Machine controller deprovisions at once, so that tearing down a large
+// cluster does not overload the provisioning network. The deprovisioning of
+// the other hosts is delayed until one of them is deprovisioned. Zero disables
+// the limit.
+var MaxConcurrentDeprovisions int
+
+// countDeprovisioningHosts returns the number of BareMetalHosts labelled for
+// the cluster that are being deprovisioned. The count is derived from the
+// hosts rather than tracked by the controller, so that it is neither lost on
+// a restart nor left behind by a Metal3Machine that is gone.
+func countDeprovisioningHosts(ctx context.Context, cl client.Client,
+	namespace, clusterName string,
+) (int, error) {
+	hosts := bmov1alpha1.BareMetalHostList{}
+	if err := cl.List(ctx, &hosts,
+		client.InNamespace(namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName},
+	); err != nil {
+		return 0, errors.Wrapf(err, "failed to list BareMetalHosts for cluster %s/%s",
+			namespace, clusterName,
+		)
+	}
+	count := 0
+	for i := range hosts.Items {
+		if isDeprovisioning(&hosts.Items[i]) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// isDeprovisioning returns true if the host is deprovisioning, or if its
+// image was removed and the BareMetal Operator did not start deprovisioning
+// it yet.
+func isDeprovisioning(host *bmov1alpha1.BareMetalHost) bool {
+	switch host.Status.Provisioning.State {
+	case bmov1alpha1.StateDeprovisioning:
+		return true
+	case bmov1alpha1.StateProvisioned:
+		return host.Spec.ConsumerRef != nil &&
+			host.Spec.Image == nil && host.Spec.CustomDeploy == nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Deprovision limit", func() {
+	consumerRef := &corev1.ObjectReference{
+		Name:       "machine",
+		Namespace:  namespaceName,
+		Kind:       "M3Machine",
+		APIVersion: infrav1.GroupVersion.String(),
+	}
+
+	type testCaseIsDeprovisioning struct {
+		Spec     *bmov1alpha1.BareMetalHostSpec
+		State    bmov1alpha1.ProvisioningState
+		Expected bool
+	}
+
+	DescribeTable("Test isDeprovisioning",
+		func(tc testCaseIsDeprovisioning) {
+			host := newBareMetalHost("host", tc.Spec, tc.State, bmhStatus(), true, "metadata", true, "")
+			Expect(isDeprovisioning(host)).To(Equal(tc.Expected))
+		},
+		Entry("Deprovisioning", testCaseIsDeprovisioning{
+			Spec:     &bmov1alpha1.BareMetalHostSpec{},
+			State:    bmov1alpha1.StateDeprovisioning,
+			Expected: true,
+		}),
+		Entry("Provisioned, image removed", testCaseIsDeprovisioning{
+			Spec:     &bmov1alpha1.BareMetalHostSpec{ConsumerRef: consumerRef},
+			State:    bmov1alpha1.StateProvisioned,
+			Expected: true,
+		}),
+		Entry("Provisioned, with an image", testCaseIsDeprovisioning{
+			Spec: &bmov1alpha1.BareMetalHostSpec{
+				ConsumerRef: consumerRef,
+				Image:       &bmov1alpha1.Image{URL: "myimage"},
+			},
+			State:    bmov1alpha1.StateProvisioned,
+			Expected: false,
+		}),
+		Entry("Provisioned, with a custom deploy", testCaseIsDeprovisioning{
+			Spec: &bmov1alpha1.BareMetalHostSpec{
+				ConsumerRef:  consumerRef,
+				CustomDeploy: &bmov1alpha1.CustomDeploy{Method: "install_great_stuff"},
+			},
+			State:    bmov1alpha1.StateProvisioned,
+			Expected: false,
+		}),
+		Entry("Provisioned, not consumed", testCaseIsDeprovisioning{
+			Spec:     &bmov1alpha1.BareMetalHostSpec{},
+			State:    bmov1alpha1.StateProvisioned,
+			Expected: false,
+		}),
+		Entry("Available", testCaseIsDeprovisioning{
+			Spec:     &bmov1alpha1.BareMetalHostSpec{},
+			State:    bmov1alpha1.StateAvailable,
+			Expected: false,
+		}),
+	)
+
+	It("Counts the deprovisioning hosts of the cluster only", func() {
+		objects := []client.Object{
+			newBareMetalHost("host-0", &bmov1alpha1.BareMetalHostSpec{},
+				bmov1alpha1.StateDeprovisioning, bmhStatus(), true, "metadata", true, ""),
+			newBareMetalHost("host-1", &bmov1alpha1.BareMetalHostSpec{ConsumerRef: consumerRef},
+				bmov1alpha1.StateProvisioned, bmhStatus(), true, "metadata", true, ""),
+			newBareMetalHost("host-2", &bmov1alpha1.BareMetalHostSpec{},
+				bmov1alpha1.StateAvailable, bmhStatus(), true, "metadata", true, ""),
+			// Not labelled for the cluster.
+			newBareMetalHost("host-3", &bmov1alpha1.BareMetalHostSpec{},
+				bmov1alpha1.StateDeprovisioning, bmhStatus(), true, "metadata", false, ""),
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(setupSchemeMm()).WithObjects(objects...).Build()
+
+		count, err := countDeprovisioningHosts(context.TODO(), fakeClient, namespaceName, clusterName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+	})
+
+	It("Deprovisions the hosts of the Metal3Machines in parallel up to the limit", func() {
+		defer func(limit int) {
+			MaxConcurrentDeprovisions = limit
+		}(MaxConcurrentDeprovisions)
+		MaxConcurrentDeprovisions = 2
+
+		objects := []client.Object{}
+		m3ms := []*infrav1.Metal3Machine{}
+		for i := range 3 {
+			name := fmt.Sprintf("machine-%d", i)
+			hostName := fmt.Sprintf("host-%d", i)
+			m3m := newMetal3Machine(name, nil, nil, &metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+				Annotations: map[string]string{
+					HostAnnotation: namespaceName + "/" + hostName,
+				},
+			})
+			host := newBareMetalHost(hostName, &bmov1alpha1.BareMetalHostSpec{
+				ConsumerRef: &corev1.ObjectReference{
+					Name:       name,
+					Namespace:  namespaceName,
+					Kind:       "M3Machine",
+					APIVersion: infrav1.GroupVersion.String(),
+				},
+				Image:  &bmov1alpha1.Image{URL: "myimage"},
+				Online: true,
+			}, bmov1alpha1.StateProvisioned, bmhStatus(), true, "metadata", true, "")
+			objects = append(objects, m3m, host)
+			m3ms = append(m3ms, m3m)
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(setupSchemeMm()).WithObjects(objects...).Build()
+
+		deleteMachine := func(i int) error {
+			machineMgr, err := NewMachineManager(fakeClient, nil, nil, newMachine(machineName, nil),
+				m3ms[i], logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+			return machineMgr.Delete(context.TODO())
+		}
+		getHost := func(i int) *bmov1alpha1.BareMetalHost {
+			host := &bmov1alpha1.BareMetalHost{}
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKey{
+				Name: fmt.Sprintf("host-%d", i), Namespace: namespaceName,
+			}, host)).To(Succeed())
+			return host
+		}
+		expectRequeue := func(err error) {
+			var reconcileError ReconcileError
+			Expect(errors.As(err, &reconcileError)).To(BeTrue())
+			Expect(reconcileError.IsTransient()).To(BeTrue())
+		}
+
+		// The first two hosts are deprovisioned at once, the third waits.
+		for i := range 3 {
+			expectRequeue(deleteMachine(i))
+		}
+		Expect(getHost(0).Spec.Image).To(BeNil())
+		Expect(getHost(1).Spec.Image).To(BeNil())
+		Expect(getHost(2).Spec.Image).NotTo(BeNil())
+
+		// Once the first host is deprovisioned, the third one starts.
+		host := getHost(0)
+		host.Status.Provisioning.State = bmov1alpha1.StateAvailable
+		Expect(fakeClient.Update(context.TODO(), host)).To(Succeed())
+		Expect(deleteMachine(0)).To(Succeed())
+
+		expectRequeue(deleteMachine(2))
+		Expect(getHost(2).Spec.Image).To(BeNil())
+	})
+})
@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// HostMappingPath is the path of the debug endpoint serving the host mappings.
+const HostMappingPath = "/debug/host-mapping"
+
+// HostMapping maps a BareMetalHost to the Metal3Machine consuming it and to
+// the node of that machine. The Metal3Machine and the node are empty when not
+// known.
+type HostMapping struct {
+	Namespace     string `json:"namespace"`
+	Host          string `json:"host"`
+	Metal3Machine string `json:"metal3Machine,omitempty"`
+	Machine       string `json:"machine,omitempty"`
+	Node          string `json:"node,omitempty"`
+}
+
+// BuildHostMappings returns the mapping of each BareMetalHost to its
+// Metal3Machine and node, sorted by namespace and host name. The Metal3Machine
+// is the consumer of the host or, when the host has no Metal3Machine consumer,
+// the one whose host annotation points to the host. The node is the one
+// referenced by the Machine owning the Metal3Machine.
+func BuildHostMappings(hosts []bmov1alpha1.BareMetalHost,
+	m3ms []infrav1.Metal3Machine, machines []clusterv1.Machine,
+) []HostMapping {
+	m3mByName := map[string]*infrav1.Metal3Machine{}
+	m3mByHost := map[string]*infrav1.Metal3Machine{}
+	for i := range m3ms {
+		m3m := &m3ms[i]
+		m3mByName[m3m.Namespace+"/"+m3m.Name] = m3m
+		if hostKey, ok := m3m.Annotations[HostAnnotation]; ok {
+			m3mByHost[hostKey] = m3m
+		}
+	}
+	machineByName := map[string]*clusterv1.Machine{}
+	for i := range machines {
+		machineByName[machines[i].Namespace+"/"+machines[i].Name] = &machines[i]
+	}
+
+	mappings := make([]HostMapping, 0, len(hosts))
+	for _, host := range hosts {
+		mapping := HostMapping{
+			Namespace: host.Namespace,
+			Host:      host.Name,
+		}
+
+		var m3m *infrav1.Metal3Machine
+		if consumer := host.Spec.ConsumerRef; consumer != nil && consumer.Kind == "Metal3Machine" {
+			mapping.Metal3Machine = consumer.Name
+			m3m = m3mByName[consumer.Namespace+"/"+consumer.Name]
+		} else if m3m = m3mByHost[host.Namespace+"/"+host.Name]; m3m != nil {
+			mapping.Metal3Machine = m3m.Name
+		}
+
+		if m3m != nil {
+			for _, ref := range m3m.OwnerReferences {
+				if ref.Kind != "Machine" {
+					continue
+				}
+				mapping.Machine = ref.Name
+				machine := machineByName[m3m.Namespace+"/"+ref.Name]
+				if machine != nil && machine.Status.NodeRef != nil {
+					mapping.Node = machine.Status.NodeRef.Name
+				}
+				break
+			}
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+		if mappings[i].Namespace != mappings[j].Namespace {
+			return mappings[i].Namespace < mappings[j].Namespace
+		}
+		return mappings[i].Host < mappings[j].Host
+	})
+	return mappings
+}
+
+// ListHostMappings lists the BareMetalHosts, Metal3Machines and Machines and
+// returns the current host mappings.
+func ListHostMappings(ctx context.Context, cl client.Reader, opts ...client.ListOption) ([]HostMapping, error) {
+	hosts := bmov1alpha1.BareMetalHostList{}
+	if err := cl.List(ctx, &hosts, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to list BareMetalHosts")
+	}
+	m3ms := infrav1.Metal3MachineList{}
+	if err := cl.List(ctx, &m3ms, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to list Metal3Machines")
+	}
+	machines := clusterv1.MachineList{}
+	if err := cl.List(ctx, &machines, opts...); err != nil {
+		return nil, errors.Wrap(err, "failed to list Machines")
+	}
+	return BuildHostMappings(hosts.Items, m3ms.Items, machines.Items), nil
+}
+
+// HostMappingHandler returns a read-only HTTP handler serving the current host
+// mappings as JSON.
+func HostMappingHandler(cl client.Reader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		mappings, err := ListHostMappings(r.Context(), cl)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(mappings); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
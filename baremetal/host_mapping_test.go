@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Host mapping", func() {
+	newHost := func(name string, consumer *corev1.ObjectReference) bmov1alpha1.BareMetalHost {
+		return bmov1alpha1.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+			},
+			Spec: bmov1alpha1.BareMetalHostSpec{
+				ConsumerRef: consumer,
+			},
+		}
+	}
+
+	newM3M := func(name, hostName, machineName string) infrav1.Metal3Machine {
+		m3m := infrav1.Metal3Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+			},
+		}
+		if hostName != "" {
+			m3m.Annotations = map[string]string{HostAnnotation: namespaceName + "/" + hostName}
+		}
+		if machineName != "" {
+			m3m.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       machineName,
+				},
+			}
+		}
+		return m3m
+	}
+
+	newMachine := func(name, nodeName string) clusterv1.Machine {
+		machine := clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+			},
+		}
+		if nodeName != "" {
+			machine.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: nodeName}
+		}
+		return machine
+	}
+
+	m3mRef := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{
+			APIVersion: infrav1.GroupVersion.String(),
+			Kind:       "Metal3Machine",
+			Name:       name,
+			Namespace:  namespaceName,
+		}
+	}
+
+	type testCaseBuildHostMappings struct {
+		Hosts            []bmov1alpha1.BareMetalHost
+		M3Ms             []infrav1.Metal3Machine
+		Machines         []clusterv1.Machine
+		ExpectedMappings []HostMapping
+	}
+
+	DescribeTable("Test BuildHostMappings",
+		func(tc testCaseBuildHostMappings) {
+			Expect(BuildHostMappings(tc.Hosts, tc.M3Ms, tc.Machines)).To(Equal(tc.ExpectedMappings))
+		},
+		Entry("No hosts", testCaseBuildHostMappings{
+			ExpectedMappings: []HostMapping{},
+		}),
+		Entry("Hosts mapped through the consumer ref, sorted by name", testCaseBuildHostMappings{
+			Hosts: []bmov1alpha1.BareMetalHost{
+				newHost("host-1", m3mRef("m3m-1")),
+				newHost("host-0", m3mRef("m3m-0")),
+				newHost("host-2", nil),
+			},
+			M3Ms: []infrav1.Metal3Machine{
+				newM3M("m3m-0", "host-0", "machine-0"),
+				newM3M("m3m-1", "host-1", "machine-1"),
+			},
+			Machines: []clusterv1.Machine{
+				newMachine("machine-0", "node-0"),
+				newMachine("machine-1", ""),
+			},
+			ExpectedMappings: []HostMapping{
+				{Namespace: namespaceName, Host: "host-0", Metal3Machine: "m3m-0", Machine: "machine-0", Node: "node-0"},
+				{Namespace: namespaceName, Host: "host-1", Metal3Machine: "m3m-1", Machine: "machine-1"},
+				{Namespace: namespaceName, Host: "host-2"},
+			},
+		}),
+		Entry("Host mapped through the host annotation", testCaseBuildHostMappings{
+			Hosts: []bmov1alpha1.BareMetalHost{
+				newHost("host-0", nil),
+			},
+			M3Ms: []infrav1.Metal3Machine{
+				newM3M("m3m-0", "host-0", "machine-0"),
+			},
+			Machines: []clusterv1.Machine{
+				newMachine("machine-0", "node-0"),
+			},
+			ExpectedMappings: []HostMapping{
+				{Namespace: namespaceName, Host: "host-0", Metal3Machine: "m3m-0", Machine: "machine-0", Node: "node-0"},
+			},
+		}),
+		Entry("Host consumed by something else than a Metal3Machine", testCaseBuildHostMappings{
+			Hosts: []bmov1alpha1.BareMetalHost{
+				newHost("host-0", &corev1.ObjectReference{Kind: "Other", Name: "other", Namespace: namespaceName}),
+			},
+			ExpectedMappings: []HostMapping{
+				{Namespace: namespaceName, Host: "host-0"},
+			},
+		}),
+		Entry("Consumer Metal3Machine not found", testCaseBuildHostMappings{
+			Hosts: []bmov1alpha1.BareMetalHost{
+				newHost("host-0", m3mRef("m3m-0")),
+			},
+			ExpectedMappings: []HostMapping{
+				{Namespace: namespaceName, Host: "host-0", Metal3Machine: "m3m-0"},
+			},
+		}),
+	)
+
+	It("Serves the host mappings as JSON", func() {
+		host := newHost("host-0", m3mRef("m3m-0"))
+		m3m := newM3M("m3m-0", "host-0", "machine-0")
+		machine := newMachine("machine-0", "node-0")
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).
+			WithObjects([]client.Object{&host, &m3m, &machine}...).Build()
+
+		request := httptest.NewRequest(http.MethodGet, HostMappingPath, nil).WithContext(context.TODO())
+		recorder := httptest.NewRecorder()
+		HostMappingHandler(fakeClient).ServeHTTP(recorder, request)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		mappings := []HostMapping{}
+		Expect(json.Unmarshal(recorder.Body.Bytes(), &mappings)).To(Succeed())
+		Expect(mappings).To(Equal([]HostMapping{
+			{Namespace: namespaceName, Host: "host-0", Metal3Machine: "m3m-0", Machine: "machine-0", Node: "node-0"},
+		}))
+
+		request = httptest.NewRequest(http.MethodPost, HostMappingPath, nil)
+		recorder = httptest.NewRecorder()
+		HostMappingHandler(fakeClient).ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -46,6 +46,9 @@ type ManagerFactoryInterface interface {
 	NewRemediationManager(*infrav1.Metal3Remediation, *infrav1.Metal3Machine, *clusterv1.Machine, logr.Logger) (
 		RemediationManagerInterface, error,
 	)
+	NewRemediationSetManager(*infrav1.Metal3RemediationSet, logr.Logger) (
+		RemediationSetManagerInterface, error,
+	)
 }
 
 // ManagerFactory only contains a client.
@@ -95,3 +98,9 @@ func (f ManagerFactory) NewRemediationManager(remediation *infrav1.Metal3Remedia
 	remediationLog logr.Logger) (RemediationManagerInterface, error) {
 	return NewRemediationManager(f.client, capm3remote.NewClusterClient, remediation, metal3machine, machine, remediationLog)
 }
+
+// NewRemediationSetManager creates a new RemediationSetManager.
+func (f ManagerFactory) NewRemediationSetManager(remediationSet *infrav1.Metal3RemediationSet,
+	remediationSetLog logr.Logger) (RemediationSetManagerInterface, error) {
+	return NewRemediationSetManager(f.client, remediationSet, remediationSetLog)
+}
@@ -88,4 +88,9 @@ var _ = Describe("Manager factory testing", func() {
 		_, err := managerFactory.NewRemediationManager(&infrav1.Metal3Remediation{}, &infrav1.Metal3Machine{}, &clusterv1.Machine{}, clusterLog)
 		Expect(err).NotTo(HaveOccurred())
 	})
+
+	It("returns a RemediationSet manager", func() {
+		_, err := managerFactory.NewRemediationSetManager(&infrav1.Metal3RemediationSet{}, clusterLog)
+		Expect(err).NotTo(HaveOccurred())
+	})
 })
@@ -21,10 +21,14 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -37,6 +41,8 @@ type ClusterManagerInterface interface {
 	Create(context.Context) error
 	Delete() error
 	UpdateClusterStatus() error
+	UpdateHostsAvailability(context.Context) error
+	UpdateActiveRemediations(context.Context) error
 	SetFinalizer()
 	UnsetFinalizer()
 	CountDescendants(context.Context) (int, error)
@@ -142,6 +148,147 @@ func (s *ClusterManager) UpdateClusterStatus() error {
 	return nil
 }
 
+// UpdateHostsAvailability sets the HostsAvailable condition by comparing the
+// number of hosts the cluster requires, i.e. the replicas of its
+// MachineDeployments and of its KubeadmControlPlane, to the number of hosts
+// in use by the cluster or free to be claimed. Host selectors are not taken
+// into account.
+func (s *ClusterManager) UpdateHostsAvailability(ctx context.Context) error {
+	required, err := s.countRequiredHosts(ctx)
+	if err != nil {
+		return err
+	}
+	available, err := s.countAvailableHosts(ctx)
+	if err != nil {
+		return err
+	}
+
+	if required > available {
+		s.Log.Info("Not enough BareMetalHosts for the cluster", "required", required, "available", available)
+		conditions.MarkFalse(s.Metal3Cluster, infrav1.HostsAvailableCondition, infrav1.InsufficientHostsReason,
+			clusterv1.ConditionSeverityWarning, "%d BareMetalHosts required, %d available, %d missing",
+			required, available, required-available,
+		)
+		return nil
+	}
+	conditions.MarkTrue(s.Metal3Cluster, infrav1.HostsAvailableCondition)
+	return nil
+}
+
+// UpdateActiveRemediations sets the ActiveRemediations status field to the
+// number of active Metal3Remediations owned by the Machines of the cluster.
+func (s *ClusterManager) UpdateActiveRemediations(ctx context.Context) error {
+	machines := clusterv1.MachineList{}
+	if err := s.client.List(ctx, &machines,
+		client.InNamespace(s.Cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: s.Cluster.Name},
+	); err != nil {
+		return errors.Wrapf(err, "failed to list Machines for cluster %s/%s",
+			s.Cluster.Namespace, s.Cluster.Name,
+		)
+	}
+	machineNames := make(map[string]struct{}, len(machines.Items))
+	for _, machine := range machines.Items {
+		machineNames[machine.Name] = struct{}{}
+	}
+
+	remediations := infrav1.Metal3RemediationList{}
+	if err := s.client.List(ctx, &remediations, client.InNamespace(s.Cluster.Namespace)); err != nil {
+		return errors.Wrapf(err, "failed to list Metal3Remediations in namespace %s", s.Cluster.Namespace)
+	}
+	active := 0
+	for i := range remediations.Items {
+		remediation := &remediations.Items[i]
+		if !IsActiveRemediation(remediation) {
+			continue
+		}
+		for _, ref := range remediation.OwnerReferences {
+			if _, ok := machineNames[ref.Name]; ok && ref.Kind == "Machine" {
+				active++
+				break
+			}
+		}
+	}
+	s.Metal3Cluster.Status.ActiveRemediations = active
+	return nil
+}
+
+// countRequiredHosts returns the sum of the replicas of the MachineDeployments
+// and of the KubeadmControlPlane of the cluster. Other control plane providers
+// are not taken into account.
+func (s *ClusterManager) countRequiredHosts(ctx context.Context) (int, error) {
+	listOptions := []client.ListOption{
+		client.InNamespace(s.Cluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: s.Cluster.Name},
+	}
+
+	machineDeployments := clusterv1.MachineDeploymentList{}
+	if err := s.client.List(ctx, &machineDeployments, listOptions...); err != nil {
+		return 0, errors.Wrapf(err, "failed to list MachineDeployments for cluster %s/%s",
+			s.Cluster.Namespace, s.Cluster.Name,
+		)
+	}
+	required := 0
+	for _, md := range machineDeployments.Items {
+		required += int(ptr.Deref(md.Spec.Replicas, 0))
+	}
+
+	ref := s.Cluster.Spec.ControlPlaneRef
+	if ref == nil || ref.Kind != "KubeadmControlPlane" ||
+		ref.GroupVersionKind().Group != controlplanev1.GroupVersion.Group {
+		return required, nil
+	}
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = s.Cluster.Namespace
+	}
+	controlPlane := &controlplanev1.KubeadmControlPlane{}
+	if err := s.client.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: namespace}, controlPlane); err != nil {
+		if apierrors.IsNotFound(err) {
+			return required, nil
+		}
+		return 0, errors.Wrapf(err, "failed to get KubeadmControlPlane %s/%s", namespace, ref.Name)
+	}
+	return required + int(ptr.Deref(controlPlane.Spec.Replicas, 0)), nil
+}
+
+// countAvailableHosts returns the number of hosts in the cluster namespace
+// that are either consumed by the cluster or free to be claimed. The
+// permanently failed hosts are not counted, even when consumed.
+func (s *ClusterManager) countAvailableHosts(ctx context.Context) (int, error) {
+	hosts := bmov1alpha1.BareMetalHostList{}
+	if err := s.client.List(ctx, &hosts, client.InNamespace(s.Cluster.Namespace)); err != nil {
+		return 0, errors.Wrapf(err, "failed to list BareMetalHosts in namespace %s", s.Cluster.Namespace)
+	}
+
+	available := 0
+	for _, host := range hosts.Items {
+		if _, ok := host.Annotations[infrav1.PermanentlyFailedAnnotation]; ok {
+			continue
+		}
+		if host.Spec.ConsumerRef != nil {
+			if host.Labels[clusterv1.ClusterNameLabel] == s.Cluster.Name {
+				available++
+			}
+			continue
+		}
+		if host.GetDeletionTimestamp() != nil || host.Status.ErrorMessage != "" {
+			continue
+		}
+		if _, ok := host.Annotations[bmov1alpha1.PausedAnnotation]; ok {
+			continue
+		}
+		if _, ok := host.Annotations[infrav1.UnhealthyAnnotation]; ok {
+			continue
+		}
+		switch host.Status.Provisioning.State {
+		case bmov1alpha1.StateReady, bmov1alpha1.StateAvailable:
+			available++
+		}
+	}
+	return available, nil
+}
+
 // setError sets the FailureMessage and FailureReason fields on the metal3Cluster and logs
 // the message. It assumes the reason is invalid configuration, since that is
 // currently the only relevant Metal3ClusterStatusError choice.
@@ -18,16 +18,21 @@ package baremetal
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-logr/logr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	_ "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -363,8 +368,217 @@ var _ = Describe("Metal3Cluster manager", func() {
 		},
 		descendantsTestCases,
 	)
+
+	type testCaseHostsAvailability struct {
+		MachineDeploymentReplicas []int32
+		ControlPlaneReplicas      *int32
+		Hosts                     []*bmov1alpha1.BareMetalHost
+		ExpectedStatus            corev1.ConditionStatus
+		ExpectedMessage           string
+	}
+
+	DescribeTable("Test UpdateHostsAvailability",
+		func(tc testCaseHostsAvailability) {
+			objects := []client.Object{}
+			for i, replicas := range tc.MachineDeploymentReplicas {
+				objects = append(objects, &clusterv1.MachineDeployment{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("md-%d", i),
+						Namespace: namespaceName,
+						Labels:    map[string]string{clusterv1.ClusterNameLabel: clusterName},
+					},
+					Spec: clusterv1.MachineDeploymentSpec{
+						ClusterName: clusterName,
+						Replicas:    ptr.To(replicas),
+					},
+				})
+			}
+			cluster := newCluster(clusterName)
+			if tc.ControlPlaneReplicas != nil {
+				objects = append(objects, &controlplanev1.KubeadmControlPlane{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "cp",
+						Namespace: namespaceName,
+					},
+					Spec: controlplanev1.KubeadmControlPlaneSpec{
+						Replicas: tc.ControlPlaneReplicas,
+					},
+				})
+				cluster.Spec.ControlPlaneRef = &corev1.ObjectReference{
+					Name:       "cp",
+					Kind:       "KubeadmControlPlane",
+					APIVersion: controlplanev1.GroupVersion.String(),
+				}
+			}
+			for _, host := range tc.Hosts {
+				objects = append(objects, host)
+			}
+			bmCluster := newMetal3Cluster(metal3ClusterName, nil, nil, nil)
+			clusterMgr := &ClusterManager{
+				client:        fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(objects...).Build(),
+				Metal3Cluster: bmCluster,
+				Cluster:       cluster,
+				Log:           logr.Discard(),
+			}
+
+			err := clusterMgr.UpdateHostsAvailability(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+
+			condition := conditions.Get(bmCluster, infrav1.HostsAvailableCondition)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(tc.ExpectedStatus))
+			Expect(condition.Message).To(Equal(tc.ExpectedMessage))
+			if tc.ExpectedStatus == corev1.ConditionFalse {
+				Expect(condition.Reason).To(Equal(infrav1.InsufficientHostsReason))
+			}
+		},
+		Entry("Insufficient hosts", testCaseHostsAvailability{
+			MachineDeploymentReplicas: []int32{2, 1},
+			ControlPlaneReplicas:      ptr.To[int32](1),
+			Hosts: []*bmov1alpha1.BareMetalHost{
+				newAvailabilityHost("host-0", bmov1alpha1.StateAvailable, nil),
+				newAvailabilityHost("host-1", bmov1alpha1.StateProvisioned,
+					&corev1.ObjectReference{Name: "consumer"},
+				),
+				newAvailabilityHost("host-2", bmov1alpha1.StateInspecting, nil),
+			},
+			ExpectedStatus:  corev1.ConditionFalse,
+			ExpectedMessage: "4 BareMetalHosts required, 2 available, 2 missing",
+		}),
+		Entry("Enough hosts", testCaseHostsAvailability{
+			MachineDeploymentReplicas: []int32{1},
+			ControlPlaneReplicas:      ptr.To[int32](1),
+			Hosts: []*bmov1alpha1.BareMetalHost{
+				newAvailabilityHost("host-0", bmov1alpha1.StateAvailable, nil),
+				newAvailabilityHost("host-1", bmov1alpha1.StateReady, nil),
+				newAvailabilityHost("host-2", bmov1alpha1.StateProvisioned,
+					&corev1.ObjectReference{Name: "consumer"},
+				),
+			},
+			ExpectedStatus: corev1.ConditionTrue,
+		}),
+		Entry("Permanently failed hosts are not available", testCaseHostsAvailability{
+			MachineDeploymentReplicas: []int32{1},
+			ControlPlaneReplicas:      ptr.To[int32](1),
+			Hosts: []*bmov1alpha1.BareMetalHost{
+				newAvailabilityHost("host-0", bmov1alpha1.StateAvailable, nil),
+				permanentlyFailed(newAvailabilityHost("host-1", bmov1alpha1.StateReady, nil)),
+				permanentlyFailed(newAvailabilityHost("host-2", bmov1alpha1.StateProvisioned,
+					&corev1.ObjectReference{Name: "consumer"},
+				)),
+			},
+			ExpectedStatus:  corev1.ConditionFalse,
+			ExpectedMessage: "2 BareMetalHosts required, 1 available, 1 missing",
+		}),
+		Entry("Insufficient hosts for the control plane", testCaseHostsAvailability{
+			ControlPlaneReplicas: ptr.To[int32](3),
+			Hosts: []*bmov1alpha1.BareMetalHost{
+				newAvailabilityHost("host-0", bmov1alpha1.StateAvailable, nil),
+			},
+			ExpectedStatus:  corev1.ConditionFalse,
+			ExpectedMessage: "3 BareMetalHosts required, 1 available, 2 missing",
+		}),
+		Entry("No hosts required", testCaseHostsAvailability{
+			ExpectedStatus: corev1.ConditionTrue,
+		}),
+	)
+
+	newClusterMachine := func(name, cluster string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster},
+			},
+			Spec: clusterv1.MachineSpec{ClusterName: cluster},
+		}
+	}
+
+	newMachineRemediation := func(machineName, phase string) *infrav1.Metal3Remediation {
+		return &infrav1.Metal3Remediation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      machineName,
+				Namespace: namespaceName,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: clusterv1.GroupVersion.String(),
+						Kind:       "Machine",
+						Name:       machineName,
+					},
+				},
+			},
+			Status: infrav1.Metal3RemediationStatus{Phase: phase},
+		}
+	}
+
+	DescribeTable("Test UpdateActiveRemediations",
+		func(remediations []*infrav1.Metal3Remediation, expectedCount int) {
+			objects := []client.Object{
+				newClusterMachine("machine-0", clusterName),
+				newClusterMachine("machine-1", clusterName),
+				newClusterMachine("machine-2", clusterName),
+				newClusterMachine("other-machine", "other-cluster"),
+			}
+			for _, remediation := range remediations {
+				objects = append(objects, remediation)
+			}
+			bmCluster := newMetal3Cluster(metal3ClusterName, nil, nil, nil)
+			bmCluster.Status.ActiveRemediations = 5
+			clusterMgr := &ClusterManager{
+				client:        fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(objects...).Build(),
+				Metal3Cluster: bmCluster,
+				Cluster:       newCluster(clusterName),
+				Log:           logr.Discard(),
+			}
+
+			Expect(clusterMgr.UpdateActiveRemediations(context.TODO())).To(Succeed())
+			Expect(bmCluster.Status.ActiveRemediations).To(Equal(expectedCount))
+		},
+		Entry("No remediations", nil, 0),
+		Entry("Remediations across the machines of the cluster", []*infrav1.Metal3Remediation{
+			newMachineRemediation("machine-0", infrav1.PhaseRunning),
+			newMachineRemediation("machine-1", infrav1.PhaseWaiting),
+			newMachineRemediation("machine-2", infrav1.PhaseQuarantined),
+		}, 3),
+		Entry("Finished remediations are not counted", []*infrav1.Metal3Remediation{
+			newMachineRemediation("machine-0", infrav1.PhaseRunning),
+			newMachineRemediation("machine-1", infrav1.PhaseSucceeded),
+			newMachineRemediation("machine-2", infrav1.PhaseFailed),
+		}, 1),
+		Entry("Remediations of other clusters are not counted", []*infrav1.Metal3Remediation{
+			newMachineRemediation("machine-0", infrav1.PhaseRunning),
+			newMachineRemediation("other-machine", infrav1.PhaseRunning),
+		}, 1),
+	)
 })
 
+// newAvailabilityHost returns a host of the test cluster in the given state.
+// Hosts with a consumerRef are labelled as belonging to the test cluster.
+func newAvailabilityHost(name string, state bmov1alpha1.ProvisioningState,
+	consumerRef *corev1.ObjectReference) *bmov1alpha1.BareMetalHost {
+	host := &bmov1alpha1.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespaceName,
+		},
+		Spec: bmov1alpha1.BareMetalHostSpec{
+			ConsumerRef: consumerRef,
+		},
+		Status: bmov1alpha1.BareMetalHostStatus{
+			Provisioning: bmov1alpha1.ProvisionStatus{State: state},
+		},
+	}
+	if consumerRef != nil {
+		host.Labels = map[string]string{clusterv1.ClusterNameLabel: clusterName}
+	}
+	return host
+}
+
+func permanentlyFailed(host *bmov1alpha1.BareMetalHost) *bmov1alpha1.BareMetalHost {
+	host.Annotations = map[string]string{infrav1.PermanentlyFailedAnnotation: ""}
+	return host
+}
+
 func newBMClusterSetup(tc testCaseBMClusterManager) *ClusterManager {
 	objects := []client.Object{}
 
@@ -17,44 +17,117 @@ limitations under the License.
 package baremetal
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net"
+	"net/textproto"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/go-logr/logr"
 	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
 	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	caipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1alpha1"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/yaml"
 )
 
 const (
-	m3machine         = "metal3machine"
-	host              = "baremetalhost"
-	capimachine       = "machine"
-	DataLabelName     = "infrastructure.cluster.x-k8s.io/data-name"
-	PoolLabelName     = "infrastructure.cluster.x-k8s.io/pool-name"
-	networkDataSuffix = "-networdata"
-	metaDataSuffix    = "-metadata"
+	m3machine   = "metal3machine"
+	host        = "baremetalhost"
+	capimachine = "machine"
+	// clusterFieldName and clusterFieldNamespace are the Cluster fields that
+	// can be rendered in the metadata.
+	clusterFieldName      = "name"
+	clusterFieldNamespace = "namespace"
+	DataLabelName         = "infrastructure.cluster.x-k8s.io/data-name"
+	PoolLabelName         = "infrastructure.cluster.x-k8s.io/pool-name"
+	networkDataSuffix     = "-networdata"
+	metaDataSuffix        = "-metadata"
+	userDataSuffix        = "-userdata"
+	// userDataBoundary is the boundary of the MIME multipart user data.
+	userDataBoundary = "MIMEBOUNDARY"
+	// UserDataFormatCloudConfig is the cloud-init user data format.
+	UserDataFormatCloudConfig = "cloud-config"
+	// UserDataFormatIgnition is the ignition user data format.
+	UserDataFormatIgnition = "ignition"
+	// userDataFormatKey is the key holding the format in a bootstrap data secret.
+	userDataFormatKey = "format"
+	// MetaDataSourceSecret and MetaDataSourceConfigMap are the kinds of the
+	// objects the metadata can be rendered from.
+	MetaDataSourceSecret    = "Secret"
+	MetaDataSourceConfigMap = "ConfigMap"
+	// InterfaceNamesAnnotation is the BareMetalHost annotation overriding the
+	// names of its NICs, as a comma-separated list of mac=name pairs. The
+	// names are used in place of the introspected ones in the templates and
+	// rendered in the networkData links.
+	InterfaceNamesAnnotation = "metal3.io/interface-names"
+	// ReferenceHostAnnotation is the Metal3DataTemplate annotation naming a
+	// BareMetalHost of its namespace whose NICs the host interfaces of the
+	// networkData links are checked against at admission.
+	ReferenceHostAnnotation = "metal3.io/reference-host"
+	// RerenderAnnotation is the Metal3Data annotation requesting the
+	// re-rendering of some of its secrets, as a comma-separated list of
+	// metaData, networkData and userData. The userData secret is only
+	// re-rendered when listed explicitly. The annotation is removed once the
+	// secrets are rendered.
+	RerenderAnnotation = "metal3.io/rerender"
+	// RerenderMetaData, RerenderNetworkData and RerenderUserData are the
+	// values of the RerenderAnnotation.
+	RerenderMetaData    = "metaData"
+	RerenderNetworkData = "networkData"
+	RerenderUserData    = "userData"
 )
 
 var (
 	EnableBMHNameBasedPreallocation bool
+	// RerenderNetworkDataOnNICChange enables the re-rendering of the
+	// networkData secret when the NICs of the BareMetalHost change.
+	RerenderNetworkDataOnNICChange bool
+	// RerenderMetaDataOnSourceChange enables the re-rendering of the metaData
+	// secret when the Secrets or ConfigMaps it is rendered from change.
+	RerenderMetaDataOnSourceChange bool
+	// IPClaimCreationRate is the number of IP claims per second created from a
+	// single pool, so that a burst of Metal3Data does not overwhelm the
+	// reconciler of the pool. Zero disables the limit.
+	IPClaimCreationRate float64
+	// IPClaimCreationBurst is the number of IP claims created from a single pool
+	// at once before IPClaimCreationRate applies.
+	IPClaimCreationBurst = 1
+	// UserDataSizeThreshold is the size, in bytes, above which the rendered
+	// userdata sets the UserDataSize condition of the Metal3Data to false.
+	// Zero disables the check.
+	UserDataSizeThreshold int
+
+	// ipClaimLimiters are the rate limiters of the IP claim creation, by pool.
+	ipClaimLimiters   = map[types.NamespacedName]*rate.Limiter{}
+	ipClaimLimitersMu sync.Mutex
 )
 
 // DataManagerInterface is an interface for a DataManager.
@@ -63,6 +136,7 @@ type DataManagerInterface interface {
 	UnsetFinalizer()
 	Reconcile(ctx context.Context) error
 	ReleaseLeases(ctx context.Context) error
+	ReleaseSecrets(ctx context.Context) error
 }
 
 // DataManager is responsible for performing machine reconciliation.
@@ -76,7 +150,7 @@ type DataManager struct {
 func NewDataManager(client client.Client,
 	data *infrav1.Metal3Data, dataLog logr.Logger) (*DataManager, error) {
 	return &DataManager{
-		client: client,
+		client: managerClient(client, dataLog),
 		Data:   data,
 		Log:    dataLog,
 	}, nil
@@ -119,12 +193,109 @@ func (m *DataManager) Reconcile(ctx context.Context) error {
 		return err
 	}
 
+	if _, err := m.releaseSecrets(ctx, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseSecrets removes the finalizer of the rendered secrets, once no
+// BareMetalHost waits for them anymore.
+func (m *DataManager) ReleaseSecrets(ctx context.Context) error {
+	pending, err := m.releaseSecrets(ctx, true)
+	if err != nil {
+		return err
+	}
+	if pending {
+		errMessage := "Waiting for the BareMetalHost to consume the secrets"
+		m.Log.Info(errMessage)
+		return WithTransientError(errors.New(errMessage), requeueAfter)
+	}
 	return nil
 }
 
+// releaseSecrets removes the DataSecretFinalizer from the rendered secrets
+// once the hosts referencing them are provisioned. When the Metal3Data is
+// deleted, it is also removed from the secrets no host references. It returns
+// true if some secrets are still waited for by a host.
+func (m *DataManager) releaseSecrets(ctx context.Context, deleting bool) (bool, error) {
+	names := []string{}
+	for _, ref := range []*corev1.SecretReference{m.Data.Spec.MetaData, m.Data.Spec.NetworkData, m.Data.Spec.UserData} {
+		if ref != nil && ref.Name != "" {
+			names = append(names, ref.Name)
+		}
+	}
+	if len(names) == 0 {
+		return false, nil
+	}
+
+	hosts := bmov1alpha1.BareMetalHostList{}
+	if err := m.client.List(ctx, &hosts, client.InNamespace(m.Data.Namespace)); err != nil {
+		return false, errors.Wrap(err, "failed to list the BareMetalHosts")
+	}
+
+	pendingSecrets := false
+	for _, name := range names {
+		secret, err := checkSecretExists(ctx, m.client, name, m.Data.Namespace)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		if !controllerutil.ContainsFinalizer(&secret, infrav1.DataSecretFinalizer) {
+			continue
+		}
+		provisioned, pending := secretConsumers(hosts.Items, name)
+		if pending {
+			pendingSecrets = true
+			continue
+		}
+		if !provisioned && !deleting {
+			continue
+		}
+		m.Log.Info("Removing the finalizer of the secret", "secret", name)
+		controllerutil.RemoveFinalizer(&secret, infrav1.DataSecretFinalizer)
+		if err := updateObject(ctx, m.client, &secret); err != nil {
+			return false, err
+		}
+	}
+	return pendingSecrets, nil
+}
+
+// secretConsumers returns whether one of the hosts referencing the secret
+// is provisioned and whether one of them is not provisioned yet.
+func secretConsumers(hosts []bmov1alpha1.BareMetalHost, name string) (bool, bool) {
+	var provisioned, pending bool
+	for i := range hosts {
+		host := &hosts[i]
+		if !hostReferencesSecret(host, name) {
+			continue
+		}
+		switch host.Status.Provisioning.State {
+		case bmov1alpha1.StateProvisioned, bmov1alpha1.StateExternallyProvisioned:
+			provisioned = true
+		default:
+			pending = true
+		}
+	}
+	return provisioned, pending
+}
+
+// hostReferencesSecret returns true if the userData, metaData or networkData
+// of the host is the secret.
+func hostReferencesSecret(host *bmov1alpha1.BareMetalHost, name string) bool {
+	for _, ref := range []*corev1.SecretReference{host.Spec.UserData, host.Spec.MetaData, host.Spec.NetworkData} {
+		if ref != nil && ref.Name == name && (ref.Namespace == "" || ref.Namespace == host.Namespace) {
+			return true
+		}
+	}
+	return false
+}
+
 // CreateSecrets creates the secret if they do not exist.
 func (m *DataManager) createSecrets(ctx context.Context) error {
-	var metaDataErr, networkDataErr error
+	var metaDataErr, networkDataErr, userDataErr error
 
 	if m.Data.Spec.Template.Name == "" {
 		return nil
@@ -154,8 +325,24 @@ func (m *DataManager) createSecrets(ctx context.Context) error {
 	}
 	m.Log.V(4).Info("Fetched Metal3Machine")
 
+	// The secrets explicitly referenced by the Metal3Machine are used as is,
+	// skip their rendering.
+	metaDataFromTemplate := m3dt.Spec.MetaData != nil && m3m.Spec.MetaData == nil
+	networkDataFromTemplate := m3dt.Spec.NetworkData != nil && m3m.Spec.NetworkData == nil
+	if m3dt.Spec.MetaData != nil && !metaDataFromTemplate {
+		m.Log.Info("MetaData secret provided by the Metal3Machine, skipping rendering")
+	}
+	if m3dt.Spec.NetworkData != nil && !networkDataFromTemplate {
+		m.Log.Info("NetworkData secret provided by the Metal3Machine, skipping rendering")
+	}
+	userDataFromTemplate := m3dt.Spec.UserData != nil && len(m3dt.Spec.UserData.Files) > 0 &&
+		m3m.Spec.UserData == nil
+	if m3dt.Spec.UserData != nil && m3m.Spec.UserData != nil {
+		m.Log.Info("UserData secret provided by the Metal3Machine, skipping rendering")
+	}
+
 	// If the MetaData is given as part of Metal3DataTemplate
-	if m3dt.Spec.MetaData != nil {
+	if metaDataFromTemplate {
 		m.Log.Info("Metadata is part of Metal3DataTemplate")
 		// If the secret name is unset, set it
 		if m.Data.Spec.MetaData == nil || m.Data.Spec.MetaData.Name == "" {
@@ -181,7 +368,7 @@ func (m *DataManager) createSecrets(ctx context.Context) error {
 	}
 
 	// If the NetworkData is given as part of Metal3DataTemplate
-	if m3dt.Spec.NetworkData != nil {
+	if networkDataFromTemplate {
 		m.Log.Info("NetworkData is part of Metal3DataTemplate")
 		// If the secret name is unset, set it
 		if m.Data.Spec.NetworkData == nil || m.Data.Spec.NetworkData.Name == "" {
@@ -205,8 +392,80 @@ func (m *DataManager) createSecrets(ctx context.Context) error {
 		}
 	}
 
+	// If the UserData is given as part of Metal3DataTemplate
+	if userDataFromTemplate {
+		m.Log.Info("UserData is part of Metal3DataTemplate")
+		// If the secret name is unset, set it
+		if m.Data.Spec.UserData == nil || m.Data.Spec.UserData.Name == "" {
+			m.Data.Spec.UserData = &corev1.SecretReference{
+				Name:      m3m.Name + userDataSuffix,
+				Namespace: m.Data.Namespace,
+			}
+		}
+
+		// Try to fetch the secret. If it exists, we do not modify it, to be able
+		// to reprovision a node in the exact same state.
+		m.Log.Info("Checking if secret exists", "secret", m.Data.Spec.UserData.Name)
+		_, userDataErr = checkSecretExists(ctx, m.client, m.Data.Spec.UserData.Name,
+			m.Data.Namespace,
+		)
+		if userDataErr != nil && !apierrors.IsNotFound(userDataErr) {
+			return userDataErr
+		}
+		if apierrors.IsNotFound(userDataErr) {
+			m.Log.Info("UserData secret creation needed", "secret", m.Data.Spec.UserData.Name)
+		}
+	}
+
+	// Fetch the Secrets and ConfigMaps the MetaData is rendered from, and
+	// re-render the MetaData secret if their data changed since it was rendered.
+	createMetaData := apierrors.IsNotFound(metaDataErr)
+	var sources map[string]map[string]string
+	if metaDataFromTemplate {
+		sources, err = m.getMetaDataSources(ctx, m3dt)
+		if err != nil {
+			return err
+		}
+		if RerenderMetaDataOnSourceChange && !createMetaData {
+			createMetaData = m.metaDataSourcesChanged(m3dt, sources)
+		}
+	}
+
+	// Re-render the NetworkData secret if the NICs of the host changed since
+	// it was rendered.
+	createNetworkData := apierrors.IsNotFound(networkDataErr)
+	if RerenderNetworkDataOnNICChange && networkDataFromTemplate && !createNetworkData {
+		createNetworkData, err = m.nicFingerprintChanged(ctx, m3m)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Re-render the secrets requested by the RerenderAnnotation. The UserData
+	// secret is left untouched unless listed.
+	createUserData := apierrors.IsNotFound(userDataErr)
+	rerenderRequested := m.Data.Annotations[RerenderAnnotation] != ""
+	if rerenderRequested {
+		rerenderMeta, rerenderNetwork, rerenderUser := m.requestedRerenders()
+		if rerenderMeta && metaDataFromTemplate && !createMetaData {
+			m.Log.Info("MetaData secret re-rendering requested", "secret", m.Data.Spec.MetaData.Name)
+			createMetaData = true
+		}
+		if rerenderNetwork && networkDataFromTemplate && !createNetworkData {
+			m.Log.Info("NetworkData secret re-rendering requested", "secret", m.Data.Spec.NetworkData.Name)
+			createNetworkData = true
+		}
+		if rerenderUser && userDataFromTemplate && !createUserData {
+			m.Log.Info("UserData secret re-rendering requested", "secret", m.Data.Spec.UserData.Name)
+			createUserData = true
+		}
+	}
+
 	// No secret needs creation
-	if metaDataErr == nil && networkDataErr == nil {
+	if !createMetaData && !createNetworkData && !createUserData {
+		if rerenderRequested {
+			delete(m.Data.Annotations, RerenderAnnotation)
+		}
 		m.Log.Info("Metal3Data Reconciled")
 		m.Data.Status.Ready = true
 		return nil
@@ -237,12 +496,25 @@ func (m *DataManager) createSecrets(ctx context.Context) error {
 	}
 	m.Log.V(4).Info("Fetched BMH")
 
+	// Fetch the Cluster only if some metadata or the hostname is rendered
+	// from it
+	var cluster *clusterv1.Cluster
+	if m3dt.Spec.MetaData != nil && (len(m3dt.Spec.MetaData.FromCluster) > 0 ||
+		strings.Contains(m3dt.Spec.MetaData.HostnameFormat, infrav1.HostnameFormatCluster)) {
+		cluster, err = m.getCluster(ctx, capiMachine)
+		if err != nil {
+			return err
+		}
+		m.Log.V(4).Info("Fetched Cluster")
+	}
+
 	// Fetch all the Metal3IPPools and create Metal3IPClaims as needed. Check if the
 	// IP address has been allocated, if so, fetch the address, gateway and prefix.
 	poolAddresses, err := m.getAddressesFromPool(ctx, *m3dt)
 	if err != nil {
 		return err
 	}
+	m.setAddressesStatus(poolAddresses)
 
 	// Create the owner Ref for the secret
 	ownerRefs := []metav1.OwnerReference{
@@ -255,10 +527,10 @@ func (m *DataManager) createSecrets(ctx context.Context) error {
 		},
 	}
 
-	// The MetaData secret must be created
-	if apierrors.IsNotFound(metaDataErr) {
+	// The MetaData secret must be created or re-rendered
+	if createMetaData {
 		m.Log.Info("Creating Metadata secret")
-		metadata, err := renderMetaData(m.Data, m3dt, m3m, capiMachine, bmh, poolAddresses)
+		metadata, err := renderMetaData(m.Data, m3dt, m3m, capiMachine, bmh, cluster, poolAddresses, sources)
 		if err != nil {
 			return err
 		}
@@ -268,12 +540,19 @@ func (m *DataManager) createSecrets(ctx context.Context) error {
 		); err != nil {
 			return err
 		}
+		if RerenderMetaDataOnSourceChange {
+			m.Data.Status.MetaDataSourceFingerprint = metaDataSourceFingerprint(m3dt, sources)
+		}
 	}
 
-	// The NetworkData secret must be created
-	if apierrors.IsNotFound(networkDataErr) {
+	// The NetworkData secret must be created or re-rendered
+	if createNetworkData {
 		m.Log.Info("Creating Networkdata secret")
-		networkData, err := renderNetworkData(m3dt, m3m, capiMachine, bmh, poolAddresses)
+		configMapRoutes, err := m.getConfigMapRoutes(ctx, m3dt, bmh)
+		if err != nil {
+			return err
+		}
+		networkData, err := renderNetworkData(m3dt, m3m, capiMachine, bmh, poolAddresses, configMapRoutes)
 		if err != nil {
 			return err
 		}
@@ -283,13 +562,541 @@ func (m *DataManager) createSecrets(ctx context.Context) error {
 		); err != nil {
 			return err
 		}
+		if RerenderNetworkDataOnNICChange {
+			m.Data.Status.NICFingerprint = nicFingerprint(bmh)
+		}
+	}
+
+	// The UserData secret must be created or re-rendered
+	if createUserData {
+		m.Log.Info("Creating Userdata secret")
+		userData, format, err := m.renderUserData(ctx, m3dt, capiMachine)
+		if err != nil {
+			return err
+		}
+		m.checkUserDataSize(userData)
+		if err := createSecret(ctx, m.client, m.Data.Spec.UserData.Name,
+			m.Data.Namespace, m3dt.Labels[clusterv1.ClusterNameLabel],
+			ownerRefs, map[string][]byte{
+				"value":           userData,
+				userDataFormatKey: []byte(format),
+			},
+		); err != nil {
+			return err
+		}
 	}
 
+	if rerenderRequested {
+		delete(m.Data.Annotations, RerenderAnnotation)
+	}
 	m.Log.Info("Metal3Data reconciled")
 	m.Data.Status.Ready = true
 	return nil
 }
 
+// requestedRerenders returns whether the RerenderAnnotation of the Metal3Data
+// requests the re-rendering of the MetaData, NetworkData and UserData secrets.
+func (m *DataManager) requestedRerenders() (bool, bool, bool) {
+	var metaData, networkData, userData bool
+	for _, value := range strings.Split(m.Data.Annotations[RerenderAnnotation], ",") {
+		switch strings.TrimSpace(value) {
+		case RerenderMetaData:
+			metaData = true
+		case RerenderNetworkData:
+			networkData = true
+		case RerenderUserData:
+			userData = true
+		case "":
+		default:
+			m.Log.Info("Ignoring unknown secret in the re-render annotation",
+				"annotation", RerenderAnnotation, "value", value,
+			)
+		}
+	}
+	return metaData, networkData, userData
+}
+
+// renderUserData renders the bootstrap data of the Machine and the files of
+// the template into the user data, and returns it with its format, read from
+// the format key of the bootstrap data secret and cloud-config if unset.
+// Cloud-init bootstrap data is rendered into a MIME multipart user data with
+// the files as cloud-init write_files. The lists of the cloud-config parts are
+// appended, so that the files of the bootstrap data are kept. The files are
+// appended to the storage files of ignition bootstrap data.
+func (m *DataManager) renderUserData(ctx context.Context, m3dt *infrav1.Metal3DataTemplate,
+	machine *clusterv1.Machine,
+) ([]byte, string, error) {
+	if machine.Spec.Bootstrap.DataSecretName == nil {
+		errMessage := "Waiting for the bootstrap data to render the userdata"
+		m.Log.Info(errMessage)
+		return nil, "", WithTransientError(errors.New(errMessage), requeueAfter)
+	}
+	bootstrap, err := checkSecretExists(ctx, m.client, *machine.Spec.Bootstrap.DataSecretName,
+		machine.Namespace,
+	)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to get the bootstrap data secret")
+	}
+	format := UserDataFormatCloudConfig
+	if string(bootstrap.Data[userDataFormatKey]) == UserDataFormatIgnition {
+		format = UserDataFormatIgnition
+	}
+
+	contents := make([][]byte, 0, len(m3dt.Spec.UserData.Files))
+	for _, file := range m3dt.Spec.UserData.Files {
+		content, err := m.getUserDataFileContent(ctx, m3dt.Namespace, file)
+		if err != nil {
+			return nil, "", err
+		}
+		contents = append(contents, content)
+	}
+
+	if format == UserDataFormatIgnition {
+		userData, err := renderIgnitionUserData(bootstrap.Data["value"], m3dt.Spec.UserData.Files, contents)
+		if err != nil {
+			return nil, "", err
+		}
+		return userData, format, nil
+	}
+
+	files := make([]interface{}, 0, len(m3dt.Spec.UserData.Files))
+	for i, file := range m3dt.Spec.UserData.Files {
+		entry := map[string]interface{}{
+			"path":     file.Path,
+			"encoding": "b64",
+			"content":  base64.StdEncoding.EncodeToString(contents[i]),
+		}
+		if file.Permissions != "" {
+			entry["permissions"] = file.Permissions
+		}
+		files = append(files, entry)
+	}
+	cloudConfig, err := yaml.Marshal(map[string]interface{}{
+		"write_files": files,
+		"merge_how": []interface{}{
+			map[string]interface{}{"name": "list", "settings": []string{"append"}},
+			map[string]interface{}{"name": "dict", "settings": []string{"no_replace", "recurse_list"}},
+		},
+	})
+	if err != nil {
+		return nil, "", errors.Wrap(err, "failed to marshal the userdata files")
+	}
+
+	var userData bytes.Buffer
+	writer := multipart.NewWriter(&userData)
+	if err := writer.SetBoundary(userDataBoundary); err != nil {
+		return nil, "", err
+	}
+	fmt.Fprintf(&userData, "Content-Type: multipart/mixed; boundary=%q\nMIME-Version: 1.0\n\n", userDataBoundary)
+	parts := [][]byte{bootstrap.Data["value"], append([]byte("#cloud-config\n"), cloudConfig...)}
+	for _, part := range parts {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type": {userDataPartContentType(part)},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := partWriter.Write(part); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return userData.Bytes(), format, nil
+}
+
+// renderIgnitionUserData appends the files, with their contents as data URLs,
+// to the storage files of the ignition config. Ignition spec 2 configs need
+// the filesystem of the files.
+func renderIgnitionUserData(config []byte, files []infrav1.UserDataFile, contents [][]byte) ([]byte, error) {
+	ignition := map[string]interface{}{}
+	if err := json.Unmarshal(config, &ignition); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the ignition bootstrap data")
+	}
+	version := ""
+	if ignitionVersion, ok := ignition["ignition"].(map[string]interface{}); ok {
+		version, _ = ignitionVersion["version"].(string)
+	}
+	storage, ok := ignition["storage"].(map[string]interface{})
+	if !ok {
+		storage = map[string]interface{}{}
+	}
+	storageFiles, _ := storage["files"].([]interface{})
+	for i, file := range files {
+		entry := map[string]interface{}{
+			"path": file.Path,
+			"contents": map[string]interface{}{
+				"source": "data:;base64," + base64.StdEncoding.EncodeToString(contents[i]),
+			},
+		}
+		if strings.HasPrefix(version, "2.") {
+			entry["filesystem"] = "root"
+		}
+		if file.Permissions != "" {
+			mode, err := strconv.ParseUint(file.Permissions, 8, 32)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid permissions of the userdata file %s", file.Path)
+			}
+			entry["mode"] = mode
+		}
+		storageFiles = append(storageFiles, entry)
+	}
+	storage["files"] = storageFiles
+	ignition["storage"] = storage
+	return json.Marshal(ignition)
+}
+
+// checkUserDataSize sets the UserDataSize condition of the Metal3Data to false
+// when the rendered userdata exceeds UserDataSizeThreshold, as some BMCs or
+// firmware silently fail on large userdata. The userdata is rendered anyway.
+func (m *DataManager) checkUserDataSize(userData []byte) {
+	if UserDataSizeThreshold <= 0 {
+		return
+	}
+	if len(userData) > UserDataSizeThreshold {
+		m.Log.Info("Userdata exceeds the size threshold, the host may fail to boot",
+			"size", len(userData), "threshold", UserDataSizeThreshold)
+		conditions.MarkFalse(m.Data, infrav1.UserDataSizeCondition, infrav1.UserDataTooLargeReason,
+			clusterv1.ConditionSeverityWarning, "userdata is %d bytes, exceeding the threshold of %d bytes",
+			len(userData), UserDataSizeThreshold,
+		)
+		return
+	}
+	conditions.MarkTrue(m.Data, infrav1.UserDataSizeCondition)
+}
+
+// userDataPartContentType returns the MIME type cloud-init handles the part
+// of the user data with, cloud-config unless it is a script.
+func userDataPartContentType(part []byte) string {
+	if bytes.HasPrefix(part, []byte("#!")) {
+		return `text/x-shellscript; charset="utf-8"`
+	}
+	return `text/cloud-config; charset="utf-8"`
+}
+
+// getUserDataFileContent returns the content of a userdata file, given inline
+// or read from a ConfigMap or a Secret in the namespace.
+func (m *DataManager) getUserDataFileContent(ctx context.Context, namespace string,
+	file infrav1.UserDataFile,
+) ([]byte, error) {
+	switch {
+	case file.FromConfigMap != nil:
+		configMap := &corev1.ConfigMap{}
+		err := m.client.Get(ctx, client.ObjectKey{Name: file.FromConfigMap.Name, Namespace: namespace}, configMap)
+		if apierrors.IsNotFound(err) {
+			errMessage := "Waiting for ConfigMap " + file.FromConfigMap.Name + " to render the userdata"
+			m.Log.Info(errMessage)
+			return nil, WithTransientError(errors.New(errMessage), requeueAfter)
+		} else if err != nil {
+			return nil, err
+		}
+		if content, ok := configMap.Data[file.FromConfigMap.Key]; ok {
+			return []byte(content), nil
+		}
+		if content, ok := configMap.BinaryData[file.FromConfigMap.Key]; ok {
+			return content, nil
+		}
+		return nil, errors.Errorf("key %s not found in ConfigMap %s", file.FromConfigMap.Key, file.FromConfigMap.Name)
+	case file.FromSecret != nil:
+		secret := &corev1.Secret{}
+		err := m.client.Get(ctx, client.ObjectKey{Name: file.FromSecret.Name, Namespace: namespace}, secret)
+		if apierrors.IsNotFound(err) {
+			errMessage := "Waiting for Secret " + file.FromSecret.Name + " to render the userdata"
+			m.Log.Info(errMessage)
+			return nil, WithTransientError(errors.New(errMessage), requeueAfter)
+		} else if err != nil {
+			return nil, err
+		}
+		content, ok := secret.Data[file.FromSecret.Key]
+		if !ok {
+			return nil, errors.Errorf("key %s not found in Secret %s", file.FromSecret.Key, file.FromSecret.Name)
+		}
+		return content, nil
+	default:
+		return []byte(file.Content), nil
+	}
+}
+
+// nicFingerprintChanged returns true if the NICs of the BareMetalHost changed
+// since the NetworkData secret was rendered. The fingerprint is only recorded
+// if it is missing, for secrets rendered before it was tracked.
+func (m *DataManager) nicFingerprintChanged(ctx context.Context,
+	m3m *infrav1.Metal3Machine,
+) (bool, error) {
+	bmh, err := getHost(ctx, m3m, m.client, m.Log)
+	if err != nil {
+		return false, err
+	}
+	fingerprint := nicFingerprint(bmh)
+	// The NICs are not known, for example during an inspection
+	if fingerprint == "" {
+		return false, nil
+	}
+	if m.Data.Status.NICFingerprint == "" {
+		m.Data.Status.NICFingerprint = fingerprint
+		return false, nil
+	}
+	if m.Data.Status.NICFingerprint == fingerprint {
+		return false, nil
+	}
+	m.Log.Info("NICs of the BareMetalHost changed, NetworkData secret re-rendering needed",
+		"secret", m.Data.Spec.NetworkData.Name,
+	)
+	return true, nil
+}
+
+// nicFingerprint returns a hash of the names and MAC addresses of the NICs of
+// the BareMetalHost, or an empty string if they are not known.
+func nicFingerprint(bmh *bmov1alpha1.BareMetalHost) string {
+	if bmh == nil || bmh.Status.HardwareDetails == nil || len(bmh.Status.HardwareDetails.NIC) == 0 {
+		return ""
+	}
+	nics := make([]string, 0, len(bmh.Status.HardwareDetails.NIC))
+	for _, nic := range bmh.Status.HardwareDetails.NIC {
+		nics = append(nics, nic.Name+"="+strings.ToLower(nic.MAC))
+	}
+	sort.Strings(nics)
+	hash := sha256.Sum256([]byte(strings.Join(nics, ",")))
+	return hex.EncodeToString(hash[:])
+}
+
+// getMetaDataSources fetches the Secrets and ConfigMaps referenced by the
+// metadata of the Metal3DataTemplate and returns their data, indexed by
+// MetaDataSourceKey.
+func (m *DataManager) getMetaDataSources(ctx context.Context,
+	m3dt *infrav1.Metal3DataTemplate,
+) (map[string]map[string]string, error) {
+	sources := make(map[string]map[string]string)
+	for _, entry := range m3dt.Spec.MetaData.FromSecrets {
+		key := MetaDataSourceKey(MetaDataSourceSecret, entry.Name)
+		if _, ok := sources[key]; ok {
+			continue
+		}
+		secret := &corev1.Secret{}
+		err := m.client.Get(ctx, client.ObjectKey{Name: entry.Name, Namespace: m3dt.Namespace}, secret)
+		if apierrors.IsNotFound(err) {
+			errMessage := "Waiting for Secret " + entry.Name + " to render the metadata"
+			m.Log.Info(errMessage)
+			return nil, WithTransientError(errors.New(errMessage), requeueAfter)
+		} else if err != nil {
+			return nil, err
+		}
+		data := make(map[string]string, len(secret.Data))
+		for dataKey, value := range secret.Data {
+			data[dataKey] = string(value)
+		}
+		sources[key] = data
+	}
+	for _, entry := range slices.Concat(m3dt.Spec.MetaData.FromConfigMaps,
+		ntpServersFromConfigMaps(m3dt.Spec.MetaData.NTPServers),
+	) {
+		key := MetaDataSourceKey(MetaDataSourceConfigMap, entry.Name)
+		if _, ok := sources[key]; ok {
+			continue
+		}
+		configMap := &corev1.ConfigMap{}
+		err := m.client.Get(ctx, client.ObjectKey{Name: entry.Name, Namespace: m3dt.Namespace}, configMap)
+		if apierrors.IsNotFound(err) {
+			errMessage := "Waiting for ConfigMap " + entry.Name + " to render the metadata"
+			m.Log.Info(errMessage)
+			return nil, WithTransientError(errors.New(errMessage), requeueAfter)
+		} else if err != nil {
+			return nil, err
+		}
+		sources[key] = configMap.Data
+	}
+	return sources, nil
+}
+
+// configMapRoute is a route read from the ConfigMap referenced by the
+// RoutesFromConfigMap of a network.
+type configMapRoute struct {
+	Destination string `json:"destination"`
+	Gateway     string `json:"gateway"`
+}
+
+// getConfigMapRoutes fetches the ConfigMaps referenced by the networks of the
+// Metal3DataTemplate and returns their routes for the host, indexed by
+// reference.
+func (m *DataManager) getConfigMapRoutes(ctx context.Context,
+	m3dt *infrav1.Metal3DataTemplate, bmh *bmov1alpha1.BareMetalHost,
+) (map[infrav1.RoutesFromConfigMap][]configMapRoute, error) {
+	routes := make(map[infrav1.RoutesFromConfigMap][]configMapRoute)
+	if m3dt.Spec.NetworkData == nil {
+		return routes, nil
+	}
+	for _, ref := range networkRoutesFromConfigMaps(m3dt.Spec.NetworkData.Networks) {
+		if _, ok := routes[*ref]; ok {
+			continue
+		}
+		configMap := &corev1.ConfigMap{}
+		err := m.client.Get(ctx, client.ObjectKey{Name: ref.Name, Namespace: m3dt.Namespace}, configMap)
+		if apierrors.IsNotFound(err) {
+			errMessage := "Waiting for ConfigMap " + ref.Name + " to render the networkdata"
+			m.Log.Info(errMessage)
+			return nil, WithTransientError(errors.New(errMessage), requeueAfter)
+		} else if err != nil {
+			return nil, err
+		}
+		// The key named after the host overrides the routes of the key.
+		key := ref.Key
+		if _, ok := configMap.Data[bmh.Name]; ok {
+			key = bmh.Name
+		}
+		content, ok := configMap.Data[key]
+		if !ok {
+			return nil, errors.Errorf("key %s not found in ConfigMap %s", key, ref.Name)
+		}
+		hostRoutes, err := parseConfigMapRoutes(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid routes in key %s of ConfigMap %s", key, ref.Name)
+		}
+		routes[*ref] = hostRoutes
+	}
+	return routes, nil
+}
+
+// networkRoutesFromConfigMaps returns the ConfigMap references of the routes
+// of the networks.
+func networkRoutesFromConfigMaps(networks infrav1.NetworkDataNetwork) []*infrav1.RoutesFromConfigMap {
+	refs := []*infrav1.RoutesFromConfigMap{}
+	for _, network := range networks.IPv4 {
+		refs = append(refs, network.RoutesFromConfigMap)
+	}
+	for _, network := range networks.IPv6 {
+		refs = append(refs, network.RoutesFromConfigMap)
+	}
+	for _, network := range networks.IPv4DHCP {
+		refs = append(refs, network.RoutesFromConfigMap)
+	}
+	for _, network := range networks.IPv6DHCP {
+		refs = append(refs, network.RoutesFromConfigMap)
+	}
+	for _, network := range networks.IPv6SLAAC {
+		refs = append(refs, network.RoutesFromConfigMap)
+	}
+	return slices.DeleteFunc(refs, func(ref *infrav1.RoutesFromConfigMap) bool {
+		return ref == nil
+	})
+}
+
+// parseConfigMapRoutes parses the routes held by a ConfigMap key and checks
+// that each has a destination CIDR and a gateway address of the same family.
+func parseConfigMapRoutes(content string) ([]configMapRoute, error) {
+	routes := []configMapRoute{}
+	if err := yaml.UnmarshalStrict([]byte(content), &routes); err != nil {
+		return nil, err
+	}
+	for i, route := range routes {
+		_, destination, err := net.ParseCIDR(route.Destination)
+		if err != nil {
+			return nil, errors.Errorf("route %d: invalid destination CIDR %q", i, route.Destination)
+		}
+		gateway := net.ParseIP(route.Gateway)
+		if gateway == nil {
+			return nil, errors.Errorf("route %d: invalid gateway address %q", i, route.Gateway)
+		}
+		if (destination.IP.To4() == nil) != (gateway.To4() == nil) {
+			return nil, errors.Errorf("route %d: gateway %s and destination %s are not of the same family",
+				i, route.Gateway, route.Destination)
+		}
+	}
+	return routes, nil
+}
+
+// metaDataSourcesChanged returns true if the data the metadata is rendered
+// from changed since the MetaData secret was rendered. The fingerprint is
+// only recorded if it is missing, for secrets rendered before it was tracked.
+func (m *DataManager) metaDataSourcesChanged(m3dt *infrav1.Metal3DataTemplate,
+	sources map[string]map[string]string,
+) bool {
+	fingerprint := metaDataSourceFingerprint(m3dt, sources)
+	// The metadata is not rendered from any Secret or ConfigMap
+	if fingerprint == "" {
+		return false
+	}
+	if m.Data.Status.MetaDataSourceFingerprint == "" {
+		m.Data.Status.MetaDataSourceFingerprint = fingerprint
+		return false
+	}
+	if m.Data.Status.MetaDataSourceFingerprint == fingerprint {
+		return false
+	}
+	m.Log.Info("Sources of the metadata changed, MetaData secret re-rendering needed",
+		"secret", m.Data.Spec.MetaData.Name,
+	)
+	return true
+}
+
+// metaDataSourceFingerprint returns a hash of the Secret and ConfigMap values
+// rendered in the metadata, or an empty string if there are none.
+func metaDataSourceFingerprint(m3dt *infrav1.Metal3DataTemplate,
+	sources map[string]map[string]string,
+) string {
+	if m3dt.Spec.MetaData == nil {
+		return ""
+	}
+	values := []string{}
+	addValues := func(kind string, entries []infrav1.MetaDataFromSource) {
+		for _, entry := range entries {
+			key := MetaDataSourceKey(kind, entry.Name)
+			values = append(values, key+"/"+entry.DataKey+"="+sources[key][entry.DataKey])
+		}
+	}
+	addValues(MetaDataSourceSecret, m3dt.Spec.MetaData.FromSecrets)
+	addValues(MetaDataSourceConfigMap, m3dt.Spec.MetaData.FromConfigMaps)
+	addValues(MetaDataSourceConfigMap, ntpServersFromConfigMaps(m3dt.Spec.MetaData.NTPServers))
+	if len(values) == 0 {
+		return ""
+	}
+	sort.Strings(values)
+	hash := sha256.Sum256([]byte(strings.Join(values, "\n")))
+	return hex.EncodeToString(hash[:])
+}
+
+// ntpServersFromConfigMaps returns the ConfigMap keys the NTP servers of the
+// metadata are read from, as metadata sources.
+func ntpServersFromConfigMaps(entries []infrav1.MetaDataNTPServers) []infrav1.MetaDataFromSource {
+	sources := []infrav1.MetaDataFromSource{}
+	for _, entry := range entries {
+		if entry.FromConfigMap == nil {
+			continue
+		}
+		sources = append(sources, infrav1.MetaDataFromSource{
+			Key:     entry.Key,
+			Name:    entry.FromConfigMap.Name,
+			DataKey: entry.FromConfigMap.Key,
+		})
+	}
+	return sources
+}
+
+// MetaDataSourceKey returns the key identifying a Secret or ConfigMap the
+// metadata is rendered from, within the namespace of the Metal3DataTemplate.
+func MetaDataSourceKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// setAddressesStatus exposes the addresses allocated from the pools in the
+// Metal3Data status. The addresses are replaced whenever the secrets are
+// rendered, so that they always match the content of the secrets.
+func (m *DataManager) setAddressesStatus(poolAddresses map[string]addressFromPool) {
+	addresses := make([]infrav1.Metal3DataAddress, 0, len(poolAddresses))
+	for pool, address := range poolAddresses {
+		addresses = append(addresses, infrav1.Metal3DataAddress{
+			Pool:    pool,
+			Address: string(address.Address),
+			Prefix:  address.Prefix,
+			Gateway: string(address.Gateway),
+		})
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].Pool < addresses[j].Pool
+	})
+	m.Data.Status.Addresses = addresses
+}
+
 // ReleaseLeases releases addresses from pool.
 func (m *DataManager) ReleaseLeases(ctx context.Context) error {
 	if m.Data.Spec.Template.Name == "" {
@@ -355,6 +1162,9 @@ func (m *DataManager) getAddressesFromPool(ctx context.Context,
 			return addresses, err
 		}
 		claims[pool] = rc
+		if isMetal3IPPoolRef(ref) {
+			m.updateIPPoolUtilization(ctx, ref)
+		}
 	}
 
 	requeue := false
@@ -401,6 +1211,9 @@ func isMetal3IPPoolRef(ref corev1.TypedLocalObjectReference) bool {
 }
 
 // releaseAddressesFromPool releases all addresses allocated by a [Metal3DataTemplate] by deleting the IP claims.
+// The claims are released synchronously: if a claim or its IP address is still present after the deletion,
+// for example while the IPAM controller frees the address, it returns a Transient type ReconcileError so that
+// the Metal3Data finalizer is kept until the addresses are gone.
 func (m *DataManager) releaseAddressesFromPool(ctx context.Context, m3dt infrav1.Metal3DataTemplate) error {
 	poolRefs, err := getReferencedPools(m3dt)
 	if err != nil {
@@ -417,10 +1230,51 @@ func (m *DataManager) releaseAddressesFromPool(ctx context.Context, m3dt infrav1
 		if err != nil {
 			return err
 		}
+		if isMetal3IPPoolRef(ref) {
+			m.updateIPPoolUtilization(ctx, ref)
+		}
+	}
+	for pool, ref := range poolRefs {
+		var released bool
+		if isMetal3IPPoolRef(ref) {
+			released, err = m.m3PoolAddressReleased(ctx, ref)
+		} else {
+			released, err = m.poolAddressReleased(ctx, ref)
+		}
+		if err != nil {
+			return err
+		}
+		if !released {
+			m.Log.Info("Waiting for address to be released from IPPool", "pool name", pool)
+			return WithTransientError(errors.New("waiting for IP claims to be released"), requeueAfter)
+		}
 	}
 	return nil
 }
 
+// updateIPPoolUtilization updates the utilization metrics of a Metal3 IPPool
+// from the IPClaims on it. The metrics are best effort, errors are only logged.
+func (m *DataManager) updateIPPoolUtilization(ctx context.Context, poolRef corev1.TypedLocalObjectReference) {
+	pool := &ipamv1.IPPool{}
+	key := types.NamespacedName{Namespace: m.Data.Namespace, Name: poolRef.Name}
+	if err := m.client.Get(ctx, key, pool); err != nil {
+		m.Log.V(4).Info("Unable to get the IPPool to report its utilization", "pool name", poolRef.Name, "error", err.Error())
+		return
+	}
+	ipClaims := ipamv1.IPClaimList{}
+	if err := m.client.List(ctx, &ipClaims, client.InNamespace(m.Data.Namespace)); err != nil {
+		m.Log.V(4).Info("Unable to list the IPClaims to report the IPPool utilization", "pool name", poolRef.Name, "error", err.Error())
+		return
+	}
+	allocated := 0
+	for _, ipClaim := range ipClaims.Items {
+		if ipClaim.Spec.Pool.Name == pool.Name {
+			allocated++
+		}
+	}
+	recordIPPoolUtilization(pool, allocated)
+}
+
 // poolRefs is used to consolidate the various references of a Metal3DataTemplate into a single map of references.
 // The names of the referenced pools need to be unique.
 type poolRefs map[string]corev1.TypedLocalObjectReference
@@ -476,6 +1330,11 @@ func getReferencedPools(m3dt infrav1.Metal3DataTemplate) (map[string]corev1.Type
 				return pools, err
 			}
 		}
+		for _, pool := range m3dt.Spec.MetaData.NetmasksFromPool {
+			if err := pools.addFromPool(pool); err != nil {
+				return pools, err
+			}
+		}
 		for _, pool := range m3dt.Spec.MetaData.GatewaysFromPool {
 			if err := pools.addFromPool(pool); err != nil {
 				return pools, err
@@ -670,6 +1529,10 @@ func (m *DataManager) ensureM3IPClaim(ctx context.Context, poolRef corev1.TypedL
 		return reconciledClaim{m3Claim: ipClaim}, err
 	}
 
+	if err := m.throttleIPClaimCreation(poolRef); err != nil {
+		return reconciledClaim{}, err
+	}
+
 	m.Log.Info("Creating Metal3IPClaim")
 	var ObjMeta *metav1.ObjectMeta
 	if EnableBMHNameBasedPreallocation {
@@ -740,6 +1603,7 @@ func (m *DataManager) addressFromM3Claim(ctx context.Context, poolRef corev1.Typ
 		m.setError(ctx, fmt.Sprintf(
 			"IP Allocation for %v failed : %v", poolRef.Name, *ipClaim.Status.ErrorMessage,
 		))
+		m.recordIPAllocationFailure(ctx, poolRef, *ipClaim.Status.ErrorMessage)
 		return addressFromPool{}, false, errors.New(*m.Data.Status.ErrorMessage)
 	}
 
@@ -777,6 +1641,46 @@ func (m *DataManager) addressFromM3Claim(ctx context.Context, poolRef corev1.Typ
 	}, false, nil
 }
 
+// recordIPAllocationFailure records a warning event on the Metal3Data, and on
+// its Machine if found, when an address cannot be allocated from the pool. The
+// PoolExhausted reason is used when the pool has no address left.
+func (m *DataManager) recordIPAllocationFailure(ctx context.Context, poolRef corev1.TypedLocalObjectReference, message string) {
+	if EventRecorder == nil {
+		return
+	}
+	reason := "IPAllocationFailed"
+	if m.ipPoolExhausted(ctx, poolRef) {
+		reason = "PoolExhausted"
+	}
+	EventRecorder.Eventf(m.Data, corev1.EventTypeWarning, reason,
+		"IP allocation from IPPool %s failed: %s", poolRef.Name, message)
+
+	m3m, err := m.getM3Machine(ctx, nil)
+	if err != nil || m3m == nil {
+		m.Log.V(4).Info("Unable to get the Metal3Machine to record the IP allocation failure", "error", err)
+		return
+	}
+	capiMachine, err := util.GetOwnerMachine(ctx, m.client, m3m.ObjectMeta)
+	if err != nil || capiMachine == nil {
+		m.Log.V(4).Info("Unable to get the Machine to record the IP allocation failure", "error", err)
+		return
+	}
+	EventRecorder.Eventf(capiMachine, corev1.EventTypeWarning, reason,
+		"IP allocation from IPPool %s failed for Metal3Data %s: %s", poolRef.Name, m.Data.Name, message)
+}
+
+// ipPoolExhausted returns true if all the addresses of the pool are
+// allocated. It returns false if the pool cannot be fetched.
+func (m *DataManager) ipPoolExhausted(ctx context.Context, poolRef corev1.TypedLocalObjectReference) bool {
+	pool := &ipamv1.IPPool{}
+	key := types.NamespacedName{Namespace: m.Data.Namespace, Name: poolRef.Name}
+	if err := m.client.Get(ctx, key, pool); err != nil {
+		return false
+	}
+	size := ipPoolSize(pool)
+	return size > 0 && float64(len(pool.Status.Allocations)) >= size
+}
+
 // releaseAddressFromM3Pool deletes the Metal3IPClaim for a referenced pool.
 func (m *DataManager) releaseAddressFromM3Pool(ctx context.Context, poolRef corev1.TypedLocalObjectReference) error {
 	var ipClaim *ipamv1.IPClaim
@@ -815,6 +1719,38 @@ func (m *DataManager) releaseAddressFromM3Pool(ctx context.Context, poolRef core
 	return deleteObject(ctx, m.client, ipClaim)
 }
 
+// m3PoolAddressReleased returns true once the Metal3IPClaims for a referenced pool and the
+// IPAddress allocated for the claim are gone.
+func (m *DataManager) m3PoolAddressReleased(ctx context.Context, poolRef corev1.TypedLocalObjectReference) (bool, error) {
+	ipClaimsList, err := m.fetchIPClaimsWithLabels(ctx, poolRef.Name)
+	if err != nil {
+		return false, err
+	}
+	if len(ipClaimsList) > 0 {
+		return false, nil
+	}
+	claimName := m.Data.Name + "-" + poolRef.Name
+	ipClaim := &ipamv1.IPClaim{}
+	err = m.client.Get(ctx, types.NamespacedName{Namespace: m.Data.Namespace, Name: claimName}, ipClaim)
+	if err == nil {
+		return false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	addresses := ipamv1.IPAddressList{}
+	if err := m.client.List(ctx, &addresses, client.InNamespace(m.Data.Namespace)); err != nil {
+		return false, err
+	}
+	for _, address := range addresses.Items {
+		if address.Spec.Claim.Name == claimName {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // ensureIPClaim creates a CAPI IPAddressClaim for a pool if it does not exist yet.
 func (m *DataManager) ensureIPClaim(ctx context.Context, poolRef corev1.TypedLocalObjectReference) (reconciledClaim, error) {
 	claim := &caipamv1.IPAddressClaim{}
@@ -831,6 +1767,10 @@ func (m *DataManager) ensureIPClaim(ctx context.Context, poolRef corev1.TypedLoc
 		return reconciledClaim{claim: claim}, nil
 	}
 
+	if err := m.throttleIPClaimCreation(poolRef); err != nil {
+		return reconciledClaim{}, err
+	}
+
 	// No claim exists, we create a new one
 	claim = &caipamv1.IPAddressClaim{
 		ObjectMeta: metav1.ObjectMeta{
@@ -863,6 +1803,40 @@ func (m *DataManager) ensureIPClaim(ctx context.Context, poolRef corev1.TypedLoc
 	return reconciledClaim{claim: claim}, err
 }
 
+// throttleIPClaimCreation returns a transient error if a claim cannot be
+// created from the pool yet without exceeding IPClaimCreationRate.
+func (m *DataManager) throttleIPClaimCreation(poolRef corev1.TypedLocalObjectReference) error {
+	delay := ipClaimCreationDelay(types.NamespacedName{Namespace: m.Data.Namespace, Name: poolRef.Name})
+	if delay == 0 {
+		return nil
+	}
+	m.Log.Info("Throttling the IP claim creation", "pool", poolRef.Name, "delay", delay)
+	return WithTransientError(errors.Errorf("IP claim creation from pool %s throttled", poolRef.Name), delay)
+}
+
+// ipClaimCreationDelay takes a token from the rate limiter of the pool. It
+// returns zero if a claim can be created now, or the time to wait otherwise.
+func ipClaimCreationDelay(pool types.NamespacedName) time.Duration {
+	if IPClaimCreationRate <= 0 {
+		return 0
+	}
+	ipClaimLimitersMu.Lock()
+	defer ipClaimLimitersMu.Unlock()
+
+	limiter, ok := ipClaimLimiters[pool]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(IPClaimCreationRate), max(IPClaimCreationBurst, 1))
+		ipClaimLimiters[pool] = limiter
+	}
+	reservation := limiter.Reserve()
+	delay := reservation.Delay()
+	if delay > 0 {
+		// Do not consume the token, the claim is not created.
+		reservation.Cancel()
+	}
+	return delay
+}
+
 // addressFromClaim retrieves the IPAddress for a CAPI IPAddressClaim.
 func (m *DataManager) addressFromClaim(ctx context.Context, _ corev1.TypedLocalObjectReference, claim *caipamv1.IPAddressClaim) (addressFromPool, bool, error) {
 	if claim == nil {
@@ -923,25 +1897,57 @@ func (m *DataManager) releaseAddressFromPool(ctx context.Context, poolRef corev1
 	return deleteObject(ctx, m.client, claim)
 }
 
+// poolAddressReleased returns true once the CAPI IP claim for a pool and the IPAddress
+// allocated for it are gone.
+func (m *DataManager) poolAddressReleased(ctx context.Context, poolRef corev1.TypedLocalObjectReference) (bool, error) {
+	claimName := m.Data.Name + "-" + poolRef.Name
+	claim := &caipamv1.IPAddressClaim{}
+	err := m.client.Get(ctx, types.NamespacedName{Namespace: m.Data.Namespace, Name: claimName}, claim)
+	if err == nil {
+		return false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	addresses := caipamv1.IPAddressList{}
+	if err := m.client.List(ctx, &addresses, client.InNamespace(m.Data.Namespace)); err != nil {
+		return false, err
+	}
+	for _, address := range addresses.Items {
+		if address.Spec.ClaimRef.Name == claimName {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 // renderNetworkData renders the networkData into an object that will be
 // marshalled into the secret.
 func renderNetworkData(m3dt *infrav1.Metal3DataTemplate,
 	m3m *infrav1.Metal3Machine, machine *clusterv1.Machine, bmh *bmov1alpha1.BareMetalHost,
-	poolAddresses map[string]addressFromPool,
+	poolAddresses map[string]addressFromPool, configMapRoutes map[infrav1.RoutesFromConfigMap][]configMapRoute,
 ) ([]byte, error) {
 	if m3dt.Spec.NetworkData == nil {
 		return nil, nil
 	}
+	if _, err := hostInterfaceNames(bmh); err != nil {
+		return nil, err
+	}
 	var err error
 
 	networkData := map[string][]interface{}{}
 
-	networkData["links"], err = renderNetworkLinks(m3dt.Spec.NetworkData.Links, m3m, machine, bmh)
+	links, networks := omitMissingOptionalLinks(m3dt.Spec.NetworkData.Links,
+		m3dt.Spec.NetworkData.Networks, bmh,
+	)
+
+	networkData["links"], err = renderNetworkLinks(links, m3m, machine, bmh)
 	if err != nil {
 		return nil, err
 	}
 
-	networkData["networks"], err = renderNetworkNetworks(m3dt.Spec.NetworkData.Networks, poolAddresses)
+	networkData["networks"], err = renderNetworkNetworks(networks, poolAddresses, configMapRoutes)
 	if err != nil {
 		return nil, err
 	}
@@ -954,6 +1960,187 @@ func renderNetworkData(m3dt *infrav1.Metal3DataTemplate,
 	return yaml.Marshal(networkData)
 }
 
+// omitMissingOptionalLinks returns the links and networks to render for the
+// host. The optional ethernet links whose host interface is not present on
+// the host are left out, as well as the vlans and networks on top of them,
+// and they are removed from the bonds.
+func omitMissingOptionalLinks(links infrav1.NetworkDataLink,
+	networks infrav1.NetworkDataNetwork, bmh *bmov1alpha1.BareMetalHost,
+) (infrav1.NetworkDataLink, infrav1.NetworkDataNetwork) {
+	missing := map[string]bool{}
+	for _, link := range links.Ethernets {
+		if link.Optional && link.MACAddress != nil && link.MACAddress.FromHostInterface != nil &&
+			!hostHasNIC(*link.MACAddress.FromHostInterface, bmh) {
+			missing[link.Id] = true
+		}
+	}
+	if len(missing) == 0 {
+		return links, networks
+	}
+
+	filtered := infrav1.NetworkDataLink{}
+	for _, link := range links.Ethernets {
+		if !missing[link.Id] {
+			filtered.Ethernets = append(filtered.Ethernets, link)
+		}
+	}
+	for _, link := range links.Vlans {
+		if missing[link.VlanLink] {
+			missing[link.Id] = true
+			continue
+		}
+		filtered.Vlans = append(filtered.Vlans, link)
+	}
+	for _, link := range links.Bonds {
+		bondLinks := []string{}
+		for _, bondLink := range link.BondLinks {
+			if !missing[bondLink] {
+				bondLinks = append(bondLinks, bondLink)
+			}
+		}
+		link.BondLinks = bondLinks
+		filtered.Bonds = append(filtered.Bonds, link)
+	}
+
+	filteredNetworks := infrav1.NetworkDataNetwork{}
+	for _, network := range networks.IPv4 {
+		if !missing[network.Link] {
+			filteredNetworks.IPv4 = append(filteredNetworks.IPv4, network)
+		}
+	}
+	for _, network := range networks.IPv6 {
+		if !missing[network.Link] {
+			filteredNetworks.IPv6 = append(filteredNetworks.IPv6, network)
+		}
+	}
+	for _, network := range networks.IPv4DHCP {
+		if !missing[network.Link] {
+			filteredNetworks.IPv4DHCP = append(filteredNetworks.IPv4DHCP, network)
+		}
+	}
+	for _, network := range networks.IPv6DHCP {
+		if !missing[network.Link] {
+			filteredNetworks.IPv6DHCP = append(filteredNetworks.IPv6DHCP, network)
+		}
+	}
+	for _, network := range networks.IPv6SLAAC {
+		if !missing[network.Link] {
+			filteredNetworks.IPv6SLAAC = append(filteredNetworks.IPv6SLAAC, network)
+		}
+	}
+	return filtered, filteredNetworks
+}
+
+// hostHasNIC returns whether the inspected host has a NIC with the given name.
+// A host not inspected yet is considered to have it, so that the rendering
+// fails until the NICs are known.
+func hostHasNIC(name string, bmh *bmov1alpha1.BareMetalHost) bool {
+	if bmh == nil || bmh.Status.HardwareDetails == nil || bmh.Status.HardwareDetails.NIC == nil {
+		return true
+	}
+	interfaceNames, err := hostInterfaceNames(bmh)
+	if err != nil {
+		// Keep the link, rendering it reports the error.
+		return true
+	}
+	if _, ok := interfaceNames[name]; ok {
+		return true
+	}
+	for _, nic := range bmh.Status.HardwareDetails.NIC {
+		if nic.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingHostInterfaces returns the host interfaces the networkData links
+// fetch their MAC address from that the inspected host does not have. The
+// optional ethernet links are not checked, they are omitted on such hosts.
+func MissingHostInterfaces(networkData *infrav1.NetworkData, bmh *bmov1alpha1.BareMetalHost) []string {
+	if networkData == nil {
+		return nil
+	}
+	macs := []*infrav1.NetworkLinkEthernetMac{}
+	for _, link := range networkData.Links.Ethernets {
+		if !link.Optional {
+			macs = append(macs, link.MACAddress)
+		}
+	}
+	for _, link := range networkData.Links.Bonds {
+		macs = append(macs, link.MACAddress)
+	}
+	for _, link := range networkData.Links.Vlans {
+		macs = append(macs, link.MACAddress)
+	}
+
+	missing := []string{}
+	for _, mac := range macs {
+		if mac == nil || mac.FromHostInterface == nil {
+			continue
+		}
+		name := *mac.FromHostInterface
+		if !hostHasNIC(name, bmh) && !slices.Contains(missing, name) {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// hostInterfaceNames returns the interface names set in the
+// InterfaceNamesAnnotation of the host, mapped to the MAC addresses of the
+// matching NICs. The MAC addresses must belong to NICs of the host.
+func hostInterfaceNames(bmh *bmov1alpha1.BareMetalHost) (map[string]string, error) {
+	if bmh == nil || bmh.Annotations[InterfaceNamesAnnotation] == "" {
+		return nil, nil
+	}
+	if bmh.Status.HardwareDetails == nil || bmh.Status.HardwareDetails.NIC == nil {
+		return nil, errors.New("NICs list not populated")
+	}
+	interfaceNames := map[string]string{}
+	for _, entry := range strings.Split(bmh.Annotations[InterfaceNamesAnnotation], ",") {
+		mac, name, found := strings.Cut(strings.TrimSpace(entry), "=")
+		mac, name = strings.TrimSpace(mac), strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, errors.Errorf("invalid %s annotation entry %q, expected mac=name",
+				InterfaceNamesAnnotation, entry,
+			)
+		}
+		if _, ok := interfaceNames[name]; ok {
+			return nil, errors.Errorf("interface name %s is set more than once in the %s annotation",
+				name, InterfaceNamesAnnotation,
+			)
+		}
+		hostMAC := ""
+		for _, nic := range bmh.Status.HardwareDetails.NIC {
+			if strings.EqualFold(nic.MAC, mac) {
+				hostMAC = nic.MAC
+				break
+			}
+		}
+		if hostMAC == "" {
+			return nil, errors.Errorf("MAC address %s of interface %s not found on the host", mac, name)
+		}
+		interfaceNames[name] = hostMAC
+	}
+	return interfaceNames, nil
+}
+
+// hostInterfaceName returns the interface name set in the
+// InterfaceNamesAnnotation of the host for the MAC address, if any.
+func hostInterfaceName(mac string, bmh *bmov1alpha1.BareMetalHost) string {
+	interfaceNames, err := hostInterfaceNames(bmh)
+	if err != nil {
+		return ""
+	}
+	for name, hostMAC := range interfaceNames {
+		if strings.EqualFold(hostMAC, mac) {
+			return name
+		}
+	}
+	return ""
+}
+
 // renderNetworkServices renders the services.
 func renderNetworkServices(services infrav1.NetworkDataService, poolAddresses map[string]addressFromPool) ([]interface{}, error) {
 	data := []interface{}{}
@@ -978,6 +2165,13 @@ func renderNetworkServices(services infrav1.NetworkDataService, poolAddresses ma
 		}
 	}
 
+	for _, domain := range services.SearchDomains {
+		data = append(data, map[string]interface{}{
+			"type":   "dns-search",
+			"domain": domain,
+		})
+	}
+
 	return data, nil
 }
 
@@ -1009,12 +2203,16 @@ func renderNetworkLinks(networkLinks infrav1.NetworkDataLink,
 		if err != nil {
 			return nil, err
 		}
-		data = append(data, map[string]interface{}{
+		ethernet := map[string]interface{}{
 			"type":                 link.Type,
 			"id":                   link.Id,
 			"mtu":                  link.MTU,
 			"ethernet_mac_address": macAddress,
-		})
+		}
+		if name := hostInterfaceName(macAddress, bmh); name != "" {
+			ethernet["name"] = name
+		}
+		data = append(data, ethernet)
 	}
 
 	// Vlan links
@@ -1038,7 +2236,7 @@ func renderNetworkLinks(networkLinks infrav1.NetworkDataLink,
 
 // renderNetworkNetworks renders the different types of network.
 func renderNetworkNetworks(networks infrav1.NetworkDataNetwork,
-	poolAddresses map[string]addressFromPool,
+	poolAddresses map[string]addressFromPool, configMapRoutes map[infrav1.RoutesFromConfigMap][]configMapRoute,
 ) ([]interface{}, error) {
 	data := []interface{}{}
 
@@ -1054,6 +2252,10 @@ func renderNetworkNetworks(networks infrav1.NetworkDataNetwork,
 		if err != nil {
 			return nil, err
 		}
+		routes, err = appendConfigMapRoutes(routes, configMapRoutes, network.RoutesFromConfigMap, true)
+		if err != nil {
+			return nil, err
+		}
 		data = append(data, map[string]interface{}{
 			"type":       "ipv4",
 			"id":         network.ID,
@@ -1076,6 +2278,10 @@ func renderNetworkNetworks(networks infrav1.NetworkDataNetwork,
 		if err != nil {
 			return nil, err
 		}
+		routes, err = appendConfigMapRoutes(routes, configMapRoutes, network.RoutesFromConfigMap, false)
+		if err != nil {
+			return nil, err
+		}
 		data = append(data, map[string]interface{}{
 			"type":       "ipv6",
 			"id":         network.ID,
@@ -1092,6 +2298,10 @@ func renderNetworkNetworks(networks infrav1.NetworkDataNetwork,
 		if err != nil {
 			return nil, err
 		}
+		routes, err = appendConfigMapRoutes(routes, configMapRoutes, network.RoutesFromConfigMap, true)
+		if err != nil {
+			return nil, err
+		}
 		data = append(data, map[string]interface{}{
 			"type":   "ipv4_dhcp",
 			"id":     network.ID,
@@ -1106,6 +2316,10 @@ func renderNetworkNetworks(networks infrav1.NetworkDataNetwork,
 		if err != nil {
 			return nil, err
 		}
+		routes, err = appendConfigMapRoutes(routes, configMapRoutes, network.RoutesFromConfigMap, false)
+		if err != nil {
+			return nil, err
+		}
 		data = append(data, map[string]interface{}{
 			"type":   "ipv6_dhcp",
 			"id":     network.ID,
@@ -1120,6 +2334,10 @@ func renderNetworkNetworks(networks infrav1.NetworkDataNetwork,
 		if err != nil {
 			return nil, err
 		}
+		routes, err = appendConfigMapRoutes(routes, configMapRoutes, network.RoutesFromConfigMap, false)
+		if err != nil {
+			return nil, err
+		}
 		data = append(data, map[string]interface{}{
 			"type":   "ipv6_slaac",
 			"id":     network.ID,
@@ -1131,6 +2349,45 @@ func renderNetworkNetworks(networks infrav1.NetworkDataNetwork,
 	return data, nil
 }
 
+// appendConfigMapRoutes appends the routes read from the ConfigMap referenced
+// by ref, which must be of the family of the network, to the routes.
+func appendConfigMapRoutes(routes []interface{},
+	configMapRoutes map[infrav1.RoutesFromConfigMap][]configMapRoute,
+	ref *infrav1.RoutesFromConfigMap, ipv4 bool,
+) ([]interface{}, error) {
+	if ref == nil {
+		return routes, nil
+	}
+	hostRoutes, ok := configMapRoutes[*ref]
+	if !ok {
+		return nil, errors.Errorf("routes of ConfigMap %s not found in cache", ref.Name)
+	}
+	for _, route := range hostRoutes {
+		// The routes were validated when read from the ConfigMap.
+		_, destination, _ := net.ParseCIDR(route.Destination)
+		if (destination.IP.To4() != nil) != ipv4 {
+			return nil, errors.Errorf("route to %s of ConfigMap %s does not match the family of the network",
+				route.Destination, ref.Name)
+		}
+		prefix, _ := destination.Mask.Size()
+		var network, gateway interface{}
+		if ipv4 {
+			network = ipamv1.IPAddressv4Str(destination.IP.String())
+			gateway = ipamv1.IPAddressv4Str(route.Gateway)
+		} else {
+			network = ipamv1.IPAddressv6Str(destination.IP.String())
+			gateway = ipamv1.IPAddressv6Str(route.Gateway)
+		}
+		routes = append(routes, map[string]interface{}{
+			"network":  network,
+			"netmask":  translateMask(prefix, ipv4),
+			"gateway":  gateway,
+			"services": []interface{}{},
+		})
+	}
+	return routes, nil
+}
+
 // getRoutesv4 returns the IPv4 routes.
 func getRoutesv4(netRoutes []infrav1.NetworkDataRoutev4,
 	poolAddresses map[string]addressFromPool,
@@ -1237,6 +2494,16 @@ func translateMask(maskInt int, ipv4 bool) interface{} {
 	return ipamv1.IPAddressv6Str(address)
 }
 
+// poolAddressNetmask returns the mask of the prefix of an address from a pool,
+// in dotted notation for an IPv4 address and address notation for IPv6.
+func poolAddressNetmask(poolAddress addressFromPool) (string, error) {
+	ip := net.ParseIP(string(poolAddress.Address))
+	if ip == nil {
+		return "", errors.Errorf("invalid IP address %s", poolAddress.Address)
+	}
+	return fmt.Sprint(translateMask(poolAddress.Prefix, ip.To4() != nil)), nil
+}
+
 // getLinkMacAddress returns the mac address.
 func getLinkMacAddress(mac *infrav1.NetworkLinkEthernetMac,
 	m3m *infrav1.Metal3Machine, machine *clusterv1.Machine, bmh *bmov1alpha1.BareMetalHost) (
@@ -1252,7 +2519,7 @@ func getLinkMacAddress(mac *infrav1.NetworkLinkEthernetMac,
 		macaddress, err = getBMHMacByName(*mac.FromHostInterface, bmh)
 	} else if mac.FromAnnotation != nil {
 		// if an annotation reference is given
-		macaddress, err = getValueFromAnnotation(mac.FromAnnotation.Object,
+		macaddress, _, err = getValueFromAnnotation(mac.FromAnnotation.Object,
 			mac.FromAnnotation.Annotation, m3m, machine, bmh)
 	}
 
@@ -1274,13 +2541,26 @@ func getLinkMacAddress(mac *infrav1.NetworkLinkEthernetMac,
 // renderMetaData renders the MetaData items.
 func renderMetaData(m3d *infrav1.Metal3Data, m3dt *infrav1.Metal3DataTemplate,
 	m3m *infrav1.Metal3Machine, machine *clusterv1.Machine, bmh *bmov1alpha1.BareMetalHost,
-	poolAddresses map[string]addressFromPool,
+	cluster *clusterv1.Cluster, poolAddresses map[string]addressFromPool,
+	sources map[string]map[string]string,
 ) ([]byte, error) {
 	if m3dt.Spec.MetaData == nil {
 		return nil, nil
 	}
 	metadata := make(map[string]string)
 
+	// Hostname, rendered first so that explicit metadata items override it
+	if m3dt.Spec.MetaData.HostnameFormat != "" {
+		hostname, err := renderHostname(m3dt.Spec.MetaData.HostnameFormat,
+			m3d.Spec.Index, cluster, machine,
+		)
+		if err != nil {
+			return nil, err
+		}
+		metadata["local-hostname"] = hostname
+		metadata["local_hostname"] = hostname
+	}
+
 	// Mac addresses
 	for _, entry := range m3dt.Spec.MetaData.FromHostInterfaces {
 		value, err := getBMHMacByName(entry.Interface, bmh)
@@ -1308,6 +2588,19 @@ func renderMetaData(m3d *infrav1.Metal3Data, m3dt *infrav1.Metal3DataTemplate,
 		metadata[entry.Key] = strconv.Itoa(poolAddress.Prefix)
 	}
 
+	// Netmasks
+	for _, entry := range m3dt.Spec.MetaData.NetmasksFromPool {
+		poolAddress, ok := poolAddresses[entry.Name]
+		if !ok {
+			return nil, errors.New("Pool not found in cache")
+		}
+		netmask, err := poolAddressNetmask(poolAddress)
+		if err != nil {
+			return nil, err
+		}
+		metadata[entry.Key] = netmask
+	}
+
 	// Gateways
 	for _, entry := range m3dt.Spec.MetaData.GatewaysFromPool {
 		poolAddress, ok := poolAddresses[entry.Name]
@@ -1360,8 +2653,49 @@ func renderMetaData(m3d *infrav1.Metal3Data, m3dt *infrav1.Metal3DataTemplate,
 
 	// Annotations
 	for _, entry := range m3dt.Spec.MetaData.FromAnnotations {
-		value, err := getValueFromAnnotation(entry.Object,
-			entry.Annotation, m3m, machine, bmh)
+		value, err := getMetaDataFromAnnotation(entry, m3m, machine, bmh)
+		if err != nil {
+			return nil, err
+		}
+		metadata[entry.Key] = value
+	}
+
+	// Cluster fields
+	for _, entry := range m3dt.Spec.MetaData.FromCluster {
+		if cluster == nil {
+			return nil, errors.New("Cluster not found")
+		}
+		switch strings.ToLower(entry.Field) {
+		case clusterFieldName:
+			metadata[entry.Key] = cluster.Name
+		case clusterFieldNamespace:
+			metadata[entry.Key] = cluster.Namespace
+		default:
+			return nil, errors.New("Unknown cluster field")
+		}
+	}
+
+	// Secrets
+	for _, entry := range m3dt.Spec.MetaData.FromSecrets {
+		value, err := getValueFromSource(MetaDataSourceSecret, entry, sources)
+		if err != nil {
+			return nil, err
+		}
+		metadata[entry.Key] = value
+	}
+
+	// ConfigMaps
+	for _, entry := range m3dt.Spec.MetaData.FromConfigMaps {
+		value, err := getValueFromSource(MetaDataSourceConfigMap, entry, sources)
+		if err != nil {
+			return nil, err
+		}
+		metadata[entry.Key] = value
+	}
+
+	// NTP servers
+	for _, entry := range m3dt.Spec.MetaData.NTPServers {
+		value, err := renderNTPServers(entry, sources)
 		if err != nil {
 			return nil, err
 		}
@@ -1377,11 +2711,84 @@ func renderMetaData(m3d *infrav1.Metal3Data, m3dt *infrav1.Metal3DataTemplate,
 	return yaml.Marshal(metadata)
 }
 
+// renderHostname renders the hostname format with the index of the
+// Metal3Data, the name of the Cluster and the name of the Machine, and checks
+// that the result is a valid RFC 1123 subdomain.
+func renderHostname(format string, index int, cluster *clusterv1.Cluster,
+	machine *clusterv1.Machine,
+) (string, error) {
+	clusterName := ""
+	if strings.Contains(format, infrav1.HostnameFormatCluster) {
+		if cluster == nil {
+			return "", errors.New("Cluster not found")
+		}
+		clusterName = cluster.Name
+	}
+	hostname := strings.NewReplacer(
+		infrav1.HostnameFormatIndex, strconv.Itoa(index),
+		infrav1.HostnameFormatCluster, clusterName,
+		infrav1.HostnameFormatMachine, machine.Name,
+	).Replace(format)
+	if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+		return "", fmt.Errorf("invalid hostname %s: %s", hostname, strings.Join(errs, ", "))
+	}
+	return hostname, nil
+}
+
+// renderNTPServers renders the NTP servers given inline and read from a
+// fetched ConfigMap as a comma-separated list, checking that the servers of
+// the ConfigMap are hostnames or IP addresses.
+func renderNTPServers(entry infrav1.MetaDataNTPServers, sources map[string]map[string]string) (string, error) {
+	servers := slices.Clone(entry.Servers)
+	if entry.FromConfigMap != nil {
+		value, err := getValueFromSource(MetaDataSourceConfigMap, infrav1.MetaDataFromSource{
+			Key:     entry.Key,
+			Name:    entry.FromConfigMap.Name,
+			DataKey: entry.FromConfigMap.Key,
+		}, sources)
+		if err != nil {
+			return "", err
+		}
+		for _, server := range strings.FieldsFunc(value, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		}) {
+			if errs := infrav1.NTPServerErrors(server); len(errs) > 0 {
+				return "", errors.Errorf("invalid NTP server %s in key %s of ConfigMap %s: %s",
+					server, entry.FromConfigMap.Key, entry.FromConfigMap.Name, strings.Join(errs, ", "))
+			}
+			servers = append(servers, server)
+		}
+	}
+	return strings.Join(servers, ","), nil
+}
+
+// getValueFromSource returns the value of a key of a fetched Secret or ConfigMap.
+func getValueFromSource(kind string, entry infrav1.MetaDataFromSource,
+	sources map[string]map[string]string,
+) (string, error) {
+	data, ok := sources[MetaDataSourceKey(kind, entry.Name)]
+	if !ok {
+		return "", errors.New(kind + " not found in cache")
+	}
+	value, ok := data[entry.DataKey]
+	if !ok {
+		return "", errors.Errorf("key %s not found in %s %s", entry.DataKey, kind, entry.Name)
+	}
+	return value, nil
+}
+
 // getBMHMacByName returns the mac address of the interface matching the name.
 func getBMHMacByName(name string, bmh *bmov1alpha1.BareMetalHost) (string, error) {
 	if bmh == nil || bmh.Status.HardwareDetails == nil || bmh.Status.HardwareDetails.NIC == nil {
 		return "", errors.New("NICs list not populated")
 	}
+	interfaceNames, err := hostInterfaceNames(bmh)
+	if err != nil {
+		return "", err
+	}
+	if mac, ok := interfaceNames[name]; ok {
+		return mac, nil
+	}
 	for _, nics := range bmh.Status.HardwareDetails.NIC {
 		if nics.Name == name {
 			return nics.MAC, nil
@@ -1390,19 +2797,53 @@ func getBMHMacByName(name string, bmh *bmov1alpha1.BareMetalHost) (string, error
 	return "", fmt.Errorf("NIC name not found %v", name)
 }
 
-// getValueFromAnnotation returns an annotation from an object representing a machine.
+// getValueFromAnnotation returns an annotation from an object representing a machine,
+// and whether the annotation is set.
 func getValueFromAnnotation(object string, annotation string,
-	m3m *infrav1.Metal3Machine, machine *clusterv1.Machine, bmh *bmov1alpha1.BareMetalHost) (string, error) {
+	m3m *infrav1.Metal3Machine, machine *clusterv1.Machine, bmh *bmov1alpha1.BareMetalHost) (string, bool, error) {
+	var annotations map[string]string
 	switch strings.ToLower(object) {
 	case m3machine:
-		return m3m.Annotations[annotation], nil
+		annotations = m3m.Annotations
 	case capimachine:
-		return machine.Annotations[annotation], nil
+		annotations = machine.Annotations
 	case host:
-		return bmh.Annotations[annotation], nil
+		annotations = bmh.Annotations
 	default:
-		return "", errors.New("Unknown object type")
+		return "", false, errors.New("Unknown object type")
 	}
+	value, ok := annotations[annotation]
+	return value, ok, nil
+}
+
+// getMetaDataFromAnnotation returns the value of the annotation of a
+// metadata item. An absent annotation is rendered as the default of the item,
+// or is an error if the item is required.
+func getMetaDataFromAnnotation(entry infrav1.MetaDataFromAnnotation,
+	m3m *infrav1.Metal3Machine, machine *clusterv1.Machine, bmh *bmov1alpha1.BareMetalHost,
+) (string, error) {
+	value, ok, err := getValueFromAnnotation(entry.Object, entry.Annotation, m3m, machine, bmh)
+	if err != nil || ok {
+		return value, err
+	}
+	if entry.Required {
+		return "", errors.Errorf("annotation %s not found on the %s", entry.Annotation, entry.Object)
+	}
+	return entry.Default, nil
+}
+
+// getCluster returns the Cluster the Machine belongs to, based on its cluster label.
+func (m *DataManager) getCluster(ctx context.Context, machine *clusterv1.Machine) (*clusterv1.Cluster, error) {
+	if _, ok := machine.Labels[clusterv1.ClusterNameLabel]; !ok {
+		return nil, errors.Errorf("Machine %s has no %s label, cannot render metadata from its Cluster",
+			machine.Name, clusterv1.ClusterNameLabel,
+		)
+	}
+	cluster, err := util.GetClusterFromMetadata(ctx, m.client, machine.ObjectMeta)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the Cluster of Machine %s", machine.Name)
+	}
+	return cluster, nil
 }
 
 func (m *DataManager) getM3Machine(ctx context.Context, m3dt *infrav1.Metal3DataTemplate) (*infrav1.Metal3Machine, error) {
@@ -1441,11 +2882,26 @@ func (m *DataManager) getM3Machine(ctx context.Context, m3dt *infrav1.Metal3Data
 		return nil, errors.New("Metal3Machine not found in owner references")
 	}
 
+	// The Metal3DataClaim references the data template resolved by the
+	// Metal3Machine, which may be the default one of its cluster and then is
+	// not referenced by the Metal3Machine itself.
+	if m3dt != nil && claimReferencesTemplate(capm3DataClaim, m3dt) {
+		m3dt = nil
+	}
+
 	return getM3Machine(ctx, m.client,
 		m.Log, metal3MachineName, m.Data.Namespace, m3dt, true,
 	)
 }
 
+// claimReferencesTemplate returns true if the Metal3DataClaim references the
+// Metal3DataTemplate.
+func claimReferencesTemplate(claim *infrav1.Metal3DataClaim, m3dt *infrav1.Metal3DataTemplate) bool {
+	template := claim.Spec.Template
+	return template.Name == m3dt.Name &&
+		(template.Namespace == "" || template.Namespace == m3dt.Namespace)
+}
+
 // fetchM3IPClaim returns an IPClaim.
 func fetchM3IPClaim(ctx context.Context, cl client.Client, mLog logr.Logger,
 	name, namespace string,
@@ -17,8 +17,13 @@ limitations under the License.
 package baremetal
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -27,14 +32,19 @@ import (
 	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/time/rate"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	caipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1alpha1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -148,19 +158,223 @@ var _ = Describe("Metal3Data manager", func() {
 	)
 
 	type testCaseCreateSecrets struct {
-		m3d                 *infrav1.Metal3Data
-		m3dt                *infrav1.Metal3DataTemplate
-		m3m                 *infrav1.Metal3Machine
-		dataClaim           *infrav1.Metal3DataClaim
-		machine             *clusterv1.Machine
-		bmh                 *bmov1alpha1.BareMetalHost
-		metadataSecret      *corev1.Secret
-		networkdataSecret   *corev1.Secret
-		expectError         bool
-		expectRequeue       bool
-		expectReady         bool
-		expectedMetadata    *string
-		expectedNetworkData *string
+		m3d                       *infrav1.Metal3Data
+		m3dt                      *infrav1.Metal3DataTemplate
+		m3m                       *infrav1.Metal3Machine
+		dataClaim                 *infrav1.Metal3DataClaim
+		machine                   *clusterv1.Machine
+		bmh                       *bmov1alpha1.BareMetalHost
+		metadataSecret            *corev1.Secret
+		networkdataSecret         *corev1.Secret
+		expectError               bool
+		expectRequeue             bool
+		expectReady               bool
+		expectedMetadata          *string
+		expectedNetworkData       *string
+		rerenderOnNICChange       bool
+		expectedFingerprint       *string
+		expectNoNetworkData       bool
+		sources                   []client.Object
+		rerenderOnSourceChange    bool
+		expectedSourceFingerprint *string
+		userdataSecret            *corev1.Secret
+		expectedUserData          *string
+		expectUserDataRerendered  bool
+	}
+
+	nicHost := func(mac string) *bmov1alpha1.BareMetalHost {
+		return &bmov1alpha1.BareMetalHost{
+			ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, bmhuid),
+			Status: bmov1alpha1.BareMetalHostStatus{
+				HardwareDetails: &bmov1alpha1.HardwareDetails{
+					NIC: []bmov1alpha1.NIC{
+						{
+							Name: "eth0",
+							MAC:  mac,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// nicChangeTestCase returns a test case where both secrets exist and the
+	// host has a NIC with the given MAC address, rendered in the networkData.
+	nicChangeTestCase := func(mac string, fingerprint string, rerender bool) testCaseCreateSecrets {
+		return testCaseCreateSecrets{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMetaWithOR(metal3DataName, metal3machineName),
+				Spec: infrav1.Metal3DataSpec{
+					Template: *testObjectReference(metal3DataTemplateName),
+					Claim:    *testObjectReference(metal3DataClaimName),
+				},
+				Status: infrav1.Metal3DataStatus{
+					NICFingerprint: fingerprint,
+				},
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName, namespaceName, m3dtuid),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{},
+					NetworkData: &infrav1.NetworkData{
+						Links: infrav1.NetworkDataLink{
+							Ethernets: []infrav1.NetworkDataLinkEthernet{
+								{
+									Type: "phy",
+									Id:   "eth0",
+									MTU:  1500,
+									MACAddress: &infrav1.NetworkLinkEthernetMac{
+										FromHostInterface: ptr.To("eth0"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      metal3machineName,
+					Namespace: namespaceName,
+					UID:       m3muid,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							Name:       machineName,
+							Kind:       "Machine",
+							APIVersion: clusterv1.GroupVersion.String(),
+						},
+					},
+					Annotations: map[string]string{
+						"metal3.io/BareMetalHost": namespaceName + "/" + baremetalhostName,
+					},
+				},
+				Spec: infrav1.Metal3MachineSpec{
+					DataTemplate: testObjectReference(metal3DataTemplateName),
+				},
+			},
+			dataClaim: &infrav1.Metal3DataClaim{
+				ObjectMeta: testObjectMetaWithOR(metal3DataClaimName, metal3machineName),
+				Spec:       infrav1.Metal3DataClaimSpec{},
+			},
+			machine: &clusterv1.Machine{
+				ObjectMeta: testObjectMeta(machineName, namespaceName, muid),
+			},
+			bmh: nicHost(mac),
+			metadataSecret: &corev1.Secret{
+				ObjectMeta: testObjectMeta(metal3machineName+metaDataSuffix, namespaceName, ""),
+				Data: map[string][]byte{
+					"metaData": []byte("Hello"),
+				},
+			},
+			networkdataSecret: &corev1.Secret{
+				ObjectMeta: testObjectMeta(metal3machineName+networkDataSuffix, namespaceName, ""),
+				Data: map[string][]byte{
+					"networkData": []byte("Bye"),
+				},
+			},
+			rerenderOnNICChange: rerender,
+			expectReady:         true,
+			expectedMetadata:    ptr.To("Hello"),
+		}
+	}
+
+	oldNICFingerprint := nicFingerprint(nicHost("12:34:56:78:9A:BC"))
+	newNICFingerprint := nicFingerprint(nicHost("DE:F0:12:34:56:78"))
+
+	sourceTemplate := &infrav1.Metal3DataTemplate{
+		Spec: infrav1.Metal3DataTemplateSpec{
+			MetaData: &infrav1.MetaData{
+				FromSecrets: []infrav1.MetaDataFromSource{
+					{
+						Key:     "token",
+						Name:    "credentials",
+						DataKey: "join-token",
+					},
+				},
+			},
+		},
+	}
+	sourceFingerprint := func(token string) string {
+		return metaDataSourceFingerprint(sourceTemplate, map[string]map[string]string{
+			"Secret/credentials": {"join-token": token},
+		})
+	}
+
+	// sourceChangeTestCase returns a test case where both secrets exist and the
+	// metadata is rendered from a Secret containing the given token.
+	sourceChangeTestCase := func(token string, fingerprint string, rerender bool) testCaseCreateSecrets {
+		tc := nicChangeTestCase("12:34:56:78:9A:BC", oldNICFingerprint, false)
+		tc.m3dt.Spec.MetaData = sourceTemplate.Spec.MetaData.DeepCopy()
+		tc.m3d.Status.MetaDataSourceFingerprint = fingerprint
+		tc.sources = []client.Object{
+			&corev1.Secret{
+				ObjectMeta: testObjectMeta("credentials", namespaceName, ""),
+				Data: map[string][]byte{
+					"join-token": []byte(token),
+				},
+			},
+		}
+		tc.rerenderOnSourceChange = rerender
+		tc.expectedNetworkData = ptr.To("Bye")
+		return tc
+	}
+
+	// rerenderTestCase returns a test case where the three secrets exist and
+	// the Metal3Data requests the re-rendering of the given secrets. The
+	// networkData rendered from the host differs from the existing secret.
+	rerenderTestCase := func(secrets string) testCaseCreateSecrets {
+		tc := nicChangeTestCase("12:34:56:78:9A:BC", oldNICFingerprint, false)
+		tc.m3d.Annotations = map[string]string{RerenderAnnotation: secrets}
+		tc.m3dt.Spec.UserData = &infrav1.UserData{
+			Files: []infrav1.UserDataFile{{Path: "/etc/motd", Content: "Hi"}},
+		}
+		tc.userdataSecret = &corev1.Secret{
+			ObjectMeta: testObjectMeta(metal3machineName+userDataSuffix, namespaceName, ""),
+			Data: map[string][]byte{
+				"value": []byte("Original"),
+			},
+		}
+		tc.expectedUserData = ptr.To("Original")
+		tc.expectedNetworkData = ptr.To("Bye")
+		return tc
+	}
+	renderedNetworkData := "links:\n- ethernet_mac_address: 12:34:56:78:9A:BC\n  id: eth0\n  mtu: 1500\n  type: phy\nnetworks: []\nservices: []\n"
+
+	ntpTemplate := &infrav1.Metal3DataTemplate{
+		Spec: infrav1.Metal3DataTemplateSpec{
+			MetaData: &infrav1.MetaData{
+				NTPServers: []infrav1.MetaDataNTPServers{
+					{
+						Key:     "ntp",
+						Servers: []string{"pool.ntp.org"},
+						FromConfigMap: &infrav1.NTPServersFromConfigMap{
+							Name: "site",
+							Key:  "ntp",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// ntpChangeTestCase returns a test case where both secrets exist and the
+	// metadata renders the NTP servers of a ConfigMap, previously rendered with
+	// the old servers.
+	ntpChangeTestCase := func(oldServers string, servers string) testCaseCreateSecrets {
+		tc := nicChangeTestCase("12:34:56:78:9A:BC", oldNICFingerprint, false)
+		tc.m3dt.Spec.MetaData = ntpTemplate.Spec.MetaData.DeepCopy()
+		tc.m3d.Status.MetaDataSourceFingerprint = metaDataSourceFingerprint(ntpTemplate,
+			map[string]map[string]string{"ConfigMap/site": {"ntp": oldServers}},
+		)
+		tc.sources = []client.Object{
+			&corev1.ConfigMap{
+				ObjectMeta: testObjectMeta("site", namespaceName, ""),
+				Data:       map[string]string{"ntp": servers},
+			},
+		}
+		tc.rerenderOnSourceChange = true
+		tc.expectedNetworkData = ptr.To("Bye")
+		return tc
 	}
 
 	DescribeTable("Test createSecrets",
@@ -187,11 +401,19 @@ var _ = Describe("Metal3Data manager", func() {
 			if tc.networkdataSecret != nil {
 				objects = append(objects, tc.networkdataSecret)
 			}
+			if tc.userdataSecret != nil {
+				objects = append(objects, tc.userdataSecret)
+			}
+			objects = append(objects, tc.sources...)
 			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(objects...).Build()
 			dataMgr, err := NewDataManager(fakeClient, tc.m3d,
 				logr.Discard(),
 			)
 			Expect(err).NotTo(HaveOccurred())
+			RerenderNetworkDataOnNICChange = tc.rerenderOnNICChange
+			defer func() { RerenderNetworkDataOnNICChange = false }()
+			RerenderMetaDataOnSourceChange = tc.rerenderOnSourceChange
+			defer func() { RerenderMetaDataOnSourceChange = false }()
 			err = dataMgr.createSecrets(context.TODO())
 			if tc.expectError || tc.expectRequeue {
 				Expect(err).To(HaveOccurred())
@@ -232,6 +454,41 @@ var _ = Describe("Metal3Data manager", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(string(tmpSecret.Data["networkData"])).To(Equal(*tc.expectedNetworkData))
 			}
+			if tc.expectedUserData != nil {
+				tmpSecret := corev1.Secret{}
+				err = fakeClient.Get(context.TODO(),
+					client.ObjectKey{
+						Name:      metal3machineName + userDataSuffix,
+						Namespace: namespaceName,
+					},
+					&tmpSecret,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				if tc.expectUserDataRerendered {
+					Expect(string(tmpSecret.Data["value"])).To(ContainSubstring(*tc.expectedUserData))
+				} else {
+					Expect(string(tmpSecret.Data["value"])).To(Equal(*tc.expectedUserData))
+					Expect(tmpSecret.ResourceVersion).To(Equal(tc.userdataSecret.ResourceVersion))
+				}
+			}
+			Expect(tc.m3d.Annotations).NotTo(HaveKey(RerenderAnnotation))
+			if tc.expectedFingerprint != nil {
+				Expect(tc.m3d.Status.NICFingerprint).To(Equal(*tc.expectedFingerprint))
+			}
+			if tc.expectedSourceFingerprint != nil {
+				Expect(tc.m3d.Status.MetaDataSourceFingerprint).To(Equal(*tc.expectedSourceFingerprint))
+			}
+			if tc.expectNoNetworkData {
+				Expect(tc.m3d.Spec.NetworkData).To(BeNil())
+				err = fakeClient.Get(context.TODO(),
+					client.ObjectKey{
+						Name:      metal3machineName + networkDataSuffix,
+						Namespace: namespaceName,
+					},
+					&corev1.Secret{},
+				)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
 		},
 		Entry("Empty", testCaseCreateSecrets{
 			m3d: &infrav1.Metal3Data{
@@ -561,6 +818,124 @@ var _ = Describe("Metal3Data manager", func() {
 			},
 			expectRequeue: true,
 		}),
+		Entry("NIC changed, re-render enabled", func() testCaseCreateSecrets {
+			tc := nicChangeTestCase("DE:F0:12:34:56:78", oldNICFingerprint, true)
+			tc.expectedNetworkData = ptr.To("links:\n- ethernet_mac_address: DE:F0:12:34:56:78\n  id: eth0\n  mtu: 1500\n  type: phy\nnetworks: []\nservices: []\n")
+			tc.expectedFingerprint = ptr.To(newNICFingerprint)
+			return tc
+		}()),
+		Entry("Hostname rendered from the Cluster", func() testCaseCreateSecrets {
+			tc := nicChangeTestCase("12:34:56:78:9A:BC", oldNICFingerprint, false)
+			tc.m3d.Spec.Index = 3
+			tc.m3dt.Spec.MetaData = &infrav1.MetaData{HostnameFormat: "{cluster}-{index}"}
+			tc.machine.Labels = map[string]string{clusterv1.ClusterNameLabel: clusterName}
+			tc.sources = []client.Object{newCluster(clusterName)}
+			tc.metadataSecret = nil
+			tc.expectedMetadata = ptr.To("local-hostname: " + clusterName + "-3\nlocal_hostname: " + clusterName + "-3\n" +
+				"providerid: " + namespaceName + "/" + baremetalhostName + "/" + metal3machineName + "\n")
+			tc.expectedNetworkData = ptr.To("Bye")
+			return tc
+		}()),
+		Entry("Network-only re-render requested", func() testCaseCreateSecrets {
+			tc := rerenderTestCase("networkData")
+			tc.expectedNetworkData = ptr.To(renderedNetworkData)
+			return tc
+		}()),
+		Entry("MetaData and networkData re-render requested", func() testCaseCreateSecrets {
+			tc := rerenderTestCase("metaData, networkData")
+			tc.expectedMetadata = ptr.To("providerid: " + namespaceName + "/" + baremetalhostName + "/" + metal3machineName + "\n")
+			tc.expectedNetworkData = ptr.To(renderedNetworkData)
+			return tc
+		}()),
+		Entry("Unknown re-render requested", rerenderTestCase("bootstrapData")),
+		Entry("UserData re-render requested", func() testCaseCreateSecrets {
+			tc := rerenderTestCase("userData")
+			tc.machine.Spec.Bootstrap.DataSecretName = ptr.To("bootstrap")
+			tc.sources = []client.Object{
+				&corev1.Secret{
+					ObjectMeta: testObjectMeta("bootstrap", namespaceName, ""),
+					Data: map[string][]byte{
+						"value": []byte("#cloud-config\nruncmd: [rotated]\n"),
+					},
+				},
+			}
+			tc.expectedUserData = ptr.To("runcmd: [rotated]")
+			tc.expectUserDataRerendered = true
+			return tc
+		}()),
+		Entry("No re-render requested", func() testCaseCreateSecrets {
+			tc := rerenderTestCase("")
+			tc.m3d.Annotations = nil
+			return tc
+		}()),
+		Entry("NIC changed, re-render disabled", func() testCaseCreateSecrets {
+			tc := nicChangeTestCase("DE:F0:12:34:56:78", oldNICFingerprint, false)
+			tc.expectedNetworkData = ptr.To("Bye")
+			tc.expectedFingerprint = ptr.To(oldNICFingerprint)
+			return tc
+		}()),
+		Entry("NIC unchanged, re-render enabled", func() testCaseCreateSecrets {
+			tc := nicChangeTestCase("12:34:56:78:9A:BC", oldNICFingerprint, true)
+			tc.expectedNetworkData = ptr.To("Bye")
+			tc.expectedFingerprint = ptr.To(oldNICFingerprint)
+			return tc
+		}()),
+		Entry("NIC fingerprint missing, re-render enabled", func() testCaseCreateSecrets {
+			tc := nicChangeTestCase("DE:F0:12:34:56:78", "", true)
+			tc.expectedNetworkData = ptr.To("Bye")
+			tc.expectedFingerprint = ptr.To(newNICFingerprint)
+			return tc
+		}()),
+		Entry("Secret changed, re-render enabled", func() testCaseCreateSecrets {
+			tc := sourceChangeTestCase("new-token", sourceFingerprint("old-token"), true)
+			tc.expectedMetadata = ptr.To("providerid: " + namespaceName + "/" + baremetalhostName + "/" + metal3machineName + "\ntoken: new-token\n")
+			tc.expectedSourceFingerprint = ptr.To(sourceFingerprint("new-token"))
+			return tc
+		}()),
+		Entry("Secret changed, re-render disabled", func() testCaseCreateSecrets {
+			tc := sourceChangeTestCase("new-token", sourceFingerprint("old-token"), false)
+			tc.expectedSourceFingerprint = ptr.To(sourceFingerprint("old-token"))
+			return tc
+		}()),
+		Entry("Secret unchanged, re-render enabled", func() testCaseCreateSecrets {
+			tc := sourceChangeTestCase("old-token", sourceFingerprint("old-token"), true)
+			tc.expectedSourceFingerprint = ptr.To(sourceFingerprint("old-token"))
+			return tc
+		}()),
+		Entry("Secret fingerprint missing, re-render enabled", func() testCaseCreateSecrets {
+			tc := sourceChangeTestCase("new-token", "", true)
+			tc.expectedSourceFingerprint = ptr.To(sourceFingerprint("new-token"))
+			return tc
+		}()),
+		Entry("Secret missing", func() testCaseCreateSecrets {
+			tc := sourceChangeTestCase("new-token", "", true)
+			tc.sources = nil
+			tc.expectRequeue = true
+			return tc
+		}()),
+		Entry("NTP servers ConfigMap changed, re-render enabled", func() testCaseCreateSecrets {
+			tc := ntpChangeTestCase("10.0.0.1", "10.0.0.2\nntp.example.com")
+			tc.expectedMetadata = ptr.To("ntp: pool.ntp.org,10.0.0.2,ntp.example.com\nproviderid: " +
+				namespaceName + "/" + baremetalhostName + "/" + metal3machineName + "\n")
+			return tc
+		}()),
+		Entry("NTP servers ConfigMap unchanged, re-render enabled", func() testCaseCreateSecrets {
+			tc := ntpChangeTestCase("10.0.0.1", "10.0.0.1")
+			tc.expectedMetadata = ptr.To("Hello")
+			return tc
+		}()),
+		Entry("NTP servers ConfigMap with an invalid server", func() testCaseCreateSecrets {
+			tc := ntpChangeTestCase("10.0.0.1", "10.0.0.2, ntp_server")
+			tc.expectError = true
+			return tc
+		}()),
+		Entry("NetworkData secret provided by the Metal3Machine", func() testCaseCreateSecrets {
+			tc := nicChangeTestCase("DE:F0:12:34:56:78", "", true)
+			tc.m3m.Spec.NetworkData = &corev1.SecretReference{Name: "provided-network-data"}
+			tc.networkdataSecret = nil
+			tc.expectNoNetworkData = true
+			return tc
+		}()),
 	)
 
 	type testCaseReleaseLeases struct {
@@ -622,6 +997,95 @@ var _ = Describe("Metal3Data manager", func() {
 		}),
 	)
 
+	type testCaseReleaseSecrets struct {
+		hostState       bmov1alpha1.ProvisioningState
+		hostReferences  bool
+		deleting        bool
+		expectRequeue   bool
+		expectFinalizer bool
+	}
+
+	DescribeTable("Test ReleaseSecrets",
+		func(tc testCaseReleaseSecrets) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       metal3machineName + networkDataSuffix,
+					Namespace:  namespaceName,
+					Finalizers: []string{infrav1.DataSecretFinalizer},
+				},
+			}
+			host := &bmov1alpha1.BareMetalHost{
+				ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, bmhuid),
+				Status: bmov1alpha1.BareMetalHostStatus{
+					Provisioning: bmov1alpha1.ProvisionStatus{State: tc.hostState},
+				},
+			}
+			if tc.hostReferences {
+				host.Spec.NetworkData = &corev1.SecretReference{Name: secret.Name}
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(secret, host).Build()
+			m3d := &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta(metal3DataName, namespaceName, m3duid),
+				Spec: infrav1.Metal3DataSpec{
+					NetworkData: &corev1.SecretReference{Name: secret.Name},
+				},
+			}
+			dataMgr, err := NewDataManager(fakeClient, m3d, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Deleting the secret, blocked by the finalizer")
+			Expect(fakeClient.Delete(context.TODO(), secret)).To(Succeed())
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+			Expect(secret.DeletionTimestamp.IsZero()).To(BeFalse())
+
+			if tc.deleting {
+				err = dataMgr.ReleaseSecrets(context.TODO())
+			} else {
+				_, err = dataMgr.releaseSecrets(context.TODO(), false)
+			}
+			if tc.expectRequeue {
+				Expect(err).To(BeAssignableToTypeOf(ReconcileError{}))
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			err = fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(secret), &corev1.Secret{})
+			if tc.expectFinalizer {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			}
+		},
+		Entry("Host provisioning", testCaseReleaseSecrets{
+			hostState:       bmov1alpha1.StateProvisioning,
+			hostReferences:  true,
+			expectFinalizer: true,
+		}),
+		Entry("Host provisioned", testCaseReleaseSecrets{
+			hostState:      bmov1alpha1.StateProvisioned,
+			hostReferences: true,
+		}),
+		Entry("Host externally provisioned", testCaseReleaseSecrets{
+			hostState:      bmov1alpha1.StateExternallyProvisioned,
+			hostReferences: true,
+		}),
+		Entry("Secret not referenced yet", testCaseReleaseSecrets{
+			hostState:       bmov1alpha1.StateAvailable,
+			expectFinalizer: true,
+		}),
+		Entry("Deleting, host provisioning", testCaseReleaseSecrets{
+			hostState:       bmov1alpha1.StateProvisioning,
+			hostReferences:  true,
+			deleting:        true,
+			expectRequeue:   true,
+			expectFinalizer: true,
+		}),
+		Entry("Deleting, secret not referenced anymore", testCaseReleaseSecrets{
+			hostState: bmov1alpha1.StateDeprovisioning,
+			deleting:  true,
+		}),
+	)
+
 	type testCaseGetAddressesFromPool struct {
 		m3dtSpec      infrav1.Metal3DataTemplateSpec
 		m3IPClaims    []string
@@ -826,6 +1290,23 @@ var _ = Describe("Metal3Data manager", func() {
 			},
 			expectRequeue: true,
 		}),
+		Entry("NetmasksFromPool", testCaseGetAddressesFromPool{
+			m3dtSpec: infrav1.Metal3DataTemplateSpec{
+				MetaData: &infrav1.MetaData{
+					NetmasksFromPool: []infrav1.FromPool{
+						{
+							Key:  "Netmask-1",
+							Name: "abcd",
+						},
+					},
+				},
+				NetworkData: &infrav1.NetworkData{},
+			},
+			m3IPClaims: []string{
+				"abcd",
+			},
+			expectRequeue: true,
+		}),
 		Entry("GatewaysFromPool", testCaseGetAddressesFromPool{
 			m3dtSpec: infrav1.Metal3DataTemplateSpec{
 				MetaData: &infrav1.MetaData{
@@ -1001,31 +1482,147 @@ var _ = Describe("Metal3Data manager", func() {
 		}),
 	)
 
+	type testCaseSetAddressesStatus struct {
+		poolAddresses     map[string]addressFromPool
+		existingAddresses []infrav1.Metal3DataAddress
+		expectedAddresses []infrav1.Metal3DataAddress
+	}
+
+	DescribeTable("Test setAddressesStatus",
+		func(tc testCaseSetAddressesStatus) {
+			m3d := &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta(metal3DataName, namespaceName, ""),
+				Status: infrav1.Metal3DataStatus{
+					Addresses: tc.existingAddresses,
+				},
+			}
+			dataMgr, err := NewDataManager(nil, m3d, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+			dataMgr.setAddressesStatus(tc.poolAddresses)
+			Expect(m3d.Status.Addresses).To(Equal(tc.expectedAddresses))
+		},
+		Entry("No pools", testCaseSetAddressesStatus{
+			poolAddresses:     map[string]addressFromPool{},
+			expectedAddresses: []infrav1.Metal3DataAddress{},
+		}),
+		Entry("Addresses sorted by pool", testCaseSetAddressesStatus{
+			poolAddresses: map[string]addressFromPool{
+				"pool-b": {
+					Address: ipamv1.IPAddressStr("192.168.1.12"),
+					Prefix:  24,
+					Gateway: ipamv1.IPAddressStr("192.168.1.1"),
+				},
+				"pool-a": {
+					Address: ipamv1.IPAddressStr("2001::12"),
+					Prefix:  64,
+				},
+			},
+			expectedAddresses: []infrav1.Metal3DataAddress{
+				{
+					Pool:    "pool-a",
+					Address: "2001::12",
+					Prefix:  64,
+				},
+				{
+					Pool:    "pool-b",
+					Address: "192.168.1.12",
+					Prefix:  24,
+					Gateway: "192.168.1.1",
+				},
+			},
+		}),
+		Entry("Stale addresses replaced", testCaseSetAddressesStatus{
+			poolAddresses: map[string]addressFromPool{
+				"pool-a": {
+					Address: ipamv1.IPAddressStr("192.168.0.10"),
+					Prefix:  24,
+				},
+			},
+			existingAddresses: []infrav1.Metal3DataAddress{
+				{
+					Pool:    "pool-a",
+					Address: "192.168.0.9",
+					Prefix:  24,
+				},
+				{
+					Pool:    "pool-old",
+					Address: "192.168.2.9",
+					Prefix:  24,
+				},
+			},
+			expectedAddresses: []infrav1.Metal3DataAddress{
+				{
+					Pool:    "pool-a",
+					Address: "192.168.0.10",
+					Prefix:  24,
+				},
+			},
+		}),
+	)
+
+	// releasePoolsM3dtSpec returns a template referencing the metal3 pool
+	// abcd-1, or the given pool if set, for its IPv4 address.
+	releasePoolsM3dtSpec := func(poolRef *corev1.TypedLocalObjectReference) infrav1.Metal3DataTemplateSpec {
+		ipv4 := infrav1.NetworkDataIPv4{FromPoolRef: poolRef}
+		if poolRef == nil {
+			ipv4.IPAddressFromIPPool = "abcd-1"
+		}
+		return infrav1.Metal3DataTemplateSpec{
+			NetworkData: &infrav1.NetworkData{
+				Networks: infrav1.NetworkDataNetwork{
+					IPv4: []infrav1.NetworkDataIPv4{ipv4},
+				},
+			},
+		}
+	}
+
 	type testCaseReleaseAddressesFromPool struct {
-		m3dtSpec      infrav1.Metal3DataTemplateSpec
-		m3IPClaims    []string
-		ipClaims      []string
-		expectError   bool
-		expectRequeue bool
+		m3dtSpec        infrav1.Metal3DataTemplateSpec
+		m3IPClaims      []string
+		ipClaims        []string
+		claimFinalizers []string
+		m3IPAddresses   []string
+		ipAddresses     []string
+		expectError     bool
+		expectRequeue   bool
 	}
 
 	DescribeTable("Test releaseAddressesFromPool",
 		func(tc testCaseReleaseAddressesFromPool) {
 			objects := []client.Object{}
 			for _, poolName := range tc.m3IPClaims {
-				objects = append(objects, &ipamv1.IPClaim{
+				claim := &ipamv1.IPClaim{
 					ObjectMeta: testObjectMeta(metal3DataName+"-"+poolName, namespaceName, ""),
 					Spec: ipamv1.IPClaimSpec{
 						Pool: *testObjectReference("abc"),
 					},
+				}
+				claim.Finalizers = tc.claimFinalizers
+				objects = append(objects, claim)
+			}
+			for _, poolName := range tc.m3IPAddresses {
+				objects = append(objects, &ipamv1.IPAddress{
+					ObjectMeta: testObjectMeta(poolName+"-address", namespaceName, ""),
+					Spec: ipamv1.IPAddressSpec{
+						Claim: corev1.ObjectReference{Name: metal3DataName + "-" + poolName},
+						Pool:  *testObjectReference(poolName),
+					},
 				})
 			}
-			for _, poolName := range tc.ipClaims {
+			for _, poolName := range tc.ipAddresses {
+				objects = append(objects, &caipamv1.IPAddress{
+					ObjectMeta: testObjectMeta(poolName+"-address", namespaceName, ""),
+					Spec: caipamv1.IPAddressSpec{
+						ClaimRef: corev1.LocalObjectReference{Name: metal3DataName + "-" + poolName},
+					},
+				})
+			}
+			for _, poolName := range tc.ipClaims {
 				objects = append(objects, &caipamv1.IPAddressClaim{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:       metal3DataName + "-" + poolName,
 						Namespace:  namespaceName,
-						Finalizers: []string{infrav1.DataFinalizer},
+						Finalizers: append([]string{infrav1.DataFinalizer}, tc.claimFinalizers...),
 					},
 					Spec: caipamv1.IPAddressClaimSpec{
 						PoolRef: corev1.TypedLocalObjectReference{
@@ -1064,6 +1661,11 @@ var _ = Describe("Metal3Data manager", func() {
 			} else {
 				Expect(err).NotTo(HaveOccurred())
 			}
+			if tc.expectRequeue {
+				// The Metal3Data finalizer must be kept while a claim or an
+				// address is still present.
+				return
+			}
 			for _, poolName := range tc.m3IPClaims {
 				capm3IPClaim := &ipamv1.IPClaim{}
 				claimNamespacedName := types.NamespacedName{
@@ -1207,6 +1809,39 @@ var _ = Describe("Metal3Data manager", func() {
 				"v6",
 			},
 		}),
+		Entry("Metal3 IP claim still held by the IPAM controller", testCaseReleaseAddressesFromPool{
+			m3dtSpec:        releasePoolsM3dtSpec(nil),
+			m3IPClaims:      []string{"abcd-1"},
+			claimFinalizers: []string{"ipam.metal3.io"},
+			expectRequeue:   true,
+		}),
+		Entry("Metal3 IP address not freed yet", testCaseReleaseAddressesFromPool{
+			m3dtSpec:      releasePoolsM3dtSpec(nil),
+			m3IPClaims:    []string{"abcd-1"},
+			m3IPAddresses: []string{"abcd-1"},
+			expectRequeue: true,
+		}),
+		Entry("Metal3 IP address of another claim", testCaseReleaseAddressesFromPool{
+			m3dtSpec:      releasePoolsM3dtSpec(nil),
+			m3IPClaims:    []string{"abcd-1"},
+			m3IPAddresses: []string{"abcd-2"},
+		}),
+		Entry("CAPI IP claim still held by the IPAM provider", testCaseReleaseAddressesFromPool{
+			m3dtSpec: releasePoolsM3dtSpec(&corev1.TypedLocalObjectReference{
+				APIGroup: ptr.To("ipam.cluster.x-k8s.io"), Kind: "TestPool", Name: "v4",
+			}),
+			ipClaims:        []string{"v4"},
+			claimFinalizers: []string{"ipam.cluster.x-k8s.io"},
+			expectRequeue:   true,
+		}),
+		Entry("CAPI IP address not freed yet", testCaseReleaseAddressesFromPool{
+			m3dtSpec: releasePoolsM3dtSpec(&corev1.TypedLocalObjectReference{
+				APIGroup: ptr.To("ipam.cluster.x-k8s.io"), Kind: "TestPool", Name: "v4",
+			}),
+			ipClaims:      []string{"v4"},
+			ipAddresses:   []string{"v4"},
+			expectRequeue: true,
+		}),
 	)
 
 	type testCaseAddressFromM3Claim struct {
@@ -1514,6 +2149,121 @@ var _ = Describe("Metal3Data manager", func() {
 		}),
 	)
 
+	type testCaseIPAllocationFailureEvents struct {
+		Allocations    map[string]ipamv1.IPAddressStr
+		WithMachine    bool
+		ExpectedReason string
+	}
+
+	DescribeTable("Test IP allocation failure events",
+		func(tc testCaseIPAllocationFailureEvents) {
+			defer func(recorder record.EventRecorder) {
+				EventRecorder = recorder
+			}(EventRecorder)
+			recorder := record.NewFakeRecorder(10)
+			EventRecorder = recorder
+
+			m3d := &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta(metal3DataName, namespaceName, "123-456-789"),
+				Spec: infrav1.Metal3DataSpec{
+					Claim: *testObjectReference(metal3DataClaimName),
+				},
+			}
+			ipClaim := &ipamv1.IPClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      metal3DataName + "-" + testPoolName,
+					Namespace: namespaceName,
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							Kind: "Metal3Data",
+							Name: metal3DataName,
+							UID:  "123-456-789",
+						},
+					},
+				},
+				Status: ipamv1.IPClaimStatus{
+					ErrorMessage: ptr.To("Failed to create associated IPAddress object"),
+				},
+			}
+			pool := &ipamv1.IPPool{
+				ObjectMeta: testObjectMeta(testPoolName, namespaceName, ""),
+				Spec: ipamv1.IPPoolSpec{
+					Pools: []ipamv1.Pool{{
+						Start: (*ipamv1.IPAddressStr)(ptr.To("192.168.0.10")),
+						End:   (*ipamv1.IPAddressStr)(ptr.To("192.168.0.11")),
+					}},
+				},
+				Status: ipamv1.IPPoolStatus{Allocations: tc.Allocations},
+			}
+			objects := []client.Object{ipClaim, pool}
+			if tc.WithMachine {
+				objects = append(objects,
+					&infrav1.Metal3DataClaim{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      metal3DataClaimName,
+							Namespace: namespaceName,
+							OwnerReferences: []metav1.OwnerReference{{
+								APIVersion: infrav1.GroupVersion.String(),
+								Kind:       "Metal3Machine",
+								Name:       metal3machineName,
+							}},
+						},
+					},
+					&infrav1.Metal3Machine{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      metal3machineName,
+							Namespace: namespaceName,
+							OwnerReferences: []metav1.OwnerReference{{
+								APIVersion: clusterv1.GroupVersion.String(),
+								Kind:       "Machine",
+								Name:       machineName,
+							}},
+						},
+					},
+					&clusterv1.Machine{
+						ObjectMeta: testObjectMeta(machineName, namespaceName, ""),
+					},
+				)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(objects...).Build()
+			dataMgr, err := NewDataManager(fakeClient, m3d, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = dataMgr.addressFromM3Claim(context.TODO(),
+				corev1.TypedLocalObjectReference{Name: testPoolName}, ipClaim,
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(recorder.Events).To(Receive(And(
+				ContainSubstring(tc.ExpectedReason), ContainSubstring(testPoolName),
+			)))
+			if tc.WithMachine {
+				Expect(recorder.Events).To(Receive(And(
+					ContainSubstring(tc.ExpectedReason), ContainSubstring(metal3DataName),
+				)))
+			}
+			Expect(recorder.Events).NotTo(Receive())
+		},
+		Entry("Allocation failure from a pool with free addresses", testCaseIPAllocationFailureEvents{
+			Allocations:    map[string]ipamv1.IPAddressStr{"other": "192.168.0.10"},
+			ExpectedReason: "IPAllocationFailed",
+		}),
+		Entry("Exhausted pool", testCaseIPAllocationFailureEvents{
+			Allocations: map[string]ipamv1.IPAddressStr{
+				"other":   "192.168.0.10",
+				"another": "192.168.0.11",
+			},
+			ExpectedReason: "PoolExhausted",
+		}),
+		Entry("Exhausted pool, surfaced on the Machine", testCaseIPAllocationFailureEvents{
+			Allocations: map[string]ipamv1.IPAddressStr{
+				"other":   "192.168.0.10",
+				"another": "192.168.0.11",
+			},
+			WithMachine:    true,
+			ExpectedReason: "PoolExhausted",
+		}),
+	)
+
 	type testCaseReleaseAddressFromM3Pool struct {
 		m3d             *infrav1.Metal3Data
 		poolRef         corev1.TypedLocalObjectReference
@@ -1958,6 +2708,165 @@ var _ = Describe("Metal3Data manager", func() {
 		}),
 	)
 
+	Context("IP claim creation rate", func() {
+		BeforeEach(func() {
+			DeferCleanup(func(creationRate float64, creationBurst int) {
+				IPClaimCreationRate = creationRate
+				IPClaimCreationBurst = creationBurst
+				ipClaimLimiters = map[types.NamespacedName]*rate.Limiter{}
+			}, IPClaimCreationRate, IPClaimCreationBurst)
+			ipClaimLimiters = map[types.NamespacedName]*rate.Limiter{}
+		})
+
+		pool := types.NamespacedName{Namespace: namespaceName, Name: testPoolName}
+
+		It("Does not limit the creation by default", func() {
+			IPClaimCreationRate = 0
+			for range 10 {
+				Expect(ipClaimCreationDelay(pool)).To(BeZero())
+			}
+		})
+
+		It("Limits the creation per pool", func() {
+			IPClaimCreationRate = 0.01
+			IPClaimCreationBurst = 2
+			Expect(ipClaimCreationDelay(pool)).To(BeZero())
+			Expect(ipClaimCreationDelay(pool)).To(BeZero())
+			Expect(ipClaimCreationDelay(pool)).To(BeNumerically(">", 90*time.Second))
+			// The throttled attempt does not consume a token.
+			Expect(ipClaimCreationDelay(pool)).To(BeNumerically("<=", 100*time.Second))
+
+			otherPool := types.NamespacedName{Namespace: namespaceName, Name: "other-pool"}
+			Expect(ipClaimCreationDelay(otherPool)).To(BeZero())
+		})
+
+		It("Does not create a claim when throttled", func() {
+			IPClaimCreationRate = 0.01
+			IPClaimCreationBurst = 1
+			fc := fakeClient()
+			poolRef := corev1.TypedLocalObjectReference{Name: testPoolName}
+
+			for i, expectThrottled := range []bool{false, true} {
+				m3d := &infrav1.Metal3Data{
+					ObjectMeta: testObjectMeta(fmt.Sprintf("%s-%d", metal3DataName, i), namespaceName, ""),
+				}
+				dataMgr, err := NewDataManager(fc, m3d, logr.Discard())
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = dataMgr.ensureIPClaim(context.Background(), poolRef)
+				claim := &caipamv1.IPAddressClaim{}
+				getErr := fc.Get(context.Background(), types.NamespacedName{
+					Name:      m3d.Name + "-" + testPoolName,
+					Namespace: namespaceName,
+				}, claim)
+				if !expectThrottled {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(getErr).NotTo(HaveOccurred())
+					continue
+				}
+				var reconcileError ReconcileError
+				Expect(errors.As(err, &reconcileError)).To(BeTrue())
+				Expect(reconcileError.IsTransient()).To(BeTrue())
+				Expect(reconcileError.GetRequeueAfter()).To(BeNumerically(">", 0))
+				Expect(apierrors.IsNotFound(getErr)).To(BeTrue())
+			}
+		})
+	})
+
+	Context("IP pool utilization", func() {
+		newIPClaim := func(name string) *ipamv1.IPClaim {
+			return &ipamv1.IPClaim{
+				ObjectMeta: testObjectMeta(name+"-"+testPoolName, namespaceName, ""),
+				Spec: ipamv1.IPClaimSpec{
+					Pool: *testObjectReference(testPoolName),
+				},
+			}
+		}
+
+		It("Updates the gauges as claims are created and released", func() {
+			pool := &ipamv1.IPPool{
+				ObjectMeta: testObjectMeta(testPoolName, namespaceName, ""),
+				Spec: ipamv1.IPPoolSpec{
+					Pools: []ipamv1.Pool{
+						{Subnet: ptr.To(ipamv1.IPSubnetStr("192.168.0.0/24"))},
+						{
+							Start: ptr.To(ipamv1.IPAddressStr("10.0.0.10")),
+							End:   ptr.To(ipamv1.IPAddressStr("10.0.0.19")),
+						},
+					},
+				},
+			}
+			m3dt := infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName, namespaceName, ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						IPAddressesFromPool: []infrav1.FromPool{{Key: "address", Name: testPoolName}},
+					},
+				},
+			}
+			fc := fakeClient(pool, newIPClaim(metal3DataName), newIPClaim("other-data"))
+			m3d := &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta(metal3DataName, namespaceName, ""),
+			}
+			dataMgr, err := NewDataManager(fc, m3d, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+			allocated := ipPoolAllocated.WithLabelValues(namespaceName, testPoolName)
+			capacity := ipPoolCapacity.WithLabelValues(namespaceName, testPoolName)
+
+			// The claims are not fulfilled, the addresses are still pending.
+			_, err = dataMgr.getAddressesFromPool(context.TODO(), m3dt)
+			Expect(err).To(BeAssignableToTypeOf(ReconcileError{}))
+			Expect(testutil.ToFloat64(allocated)).To(Equal(2.0))
+			Expect(testutil.ToFloat64(capacity)).To(Equal(264.0))
+
+			Expect(fc.Create(context.TODO(), newIPClaim("new-data"))).To(Succeed())
+			_, err = dataMgr.getAddressesFromPool(context.TODO(), m3dt)
+			Expect(err).To(BeAssignableToTypeOf(ReconcileError{}))
+			Expect(testutil.ToFloat64(allocated)).To(Equal(3.0))
+
+			Expect(dataMgr.releaseAddressesFromPool(context.TODO(), m3dt)).To(Succeed())
+			Expect(testutil.ToFloat64(allocated)).To(Equal(2.0))
+		})
+
+		DescribeTable("Test ipPoolSize",
+			func(pools []ipamv1.Pool, expectedSize float64) {
+				pool := &ipamv1.IPPool{Spec: ipamv1.IPPoolSpec{Pools: pools}}
+				Expect(ipPoolSize(pool)).To(Equal(expectedSize))
+			},
+			Entry("No range", nil, 0.0),
+			Entry("IPv4 subnet", []ipamv1.Pool{
+				{Subnet: ptr.To(ipamv1.IPSubnetStr("192.168.1.17/28"))},
+			}, 14.0),
+			Entry("Start in a subnet", []ipamv1.Pool{
+				{
+					Start:  ptr.To(ipamv1.IPAddressStr("192.168.1.100")),
+					Subnet: ptr.To(ipamv1.IPSubnetStr("192.168.1.0/24")),
+				},
+			}, 155.0),
+			Entry("IPv6 range", []ipamv1.Pool{
+				{
+					Start: ptr.To(ipamv1.IPAddressStr("2001:db8::1:0")),
+					End:   ptr.To(ipamv1.IPAddressStr("2001:db8::1:ffff")),
+				},
+			}, 65536.0),
+			Entry("IPv6 subnet", []ipamv1.Pool{
+				{Subnet: ptr.To(ipamv1.IPSubnetStr("2001:db8::/64"))},
+			}, float64(1<<64-2)),
+			Entry("Unbounded and invalid ranges", []ipamv1.Pool{
+				{Start: ptr.To(ipamv1.IPAddressStr("192.168.1.1"))},
+				{
+					Start: ptr.To(ipamv1.IPAddressStr("192.168.1.20")),
+					End:   ptr.To(ipamv1.IPAddressStr("192.168.1.10")),
+				},
+				{
+					Start: ptr.To(ipamv1.IPAddressStr("192.168.1.1")),
+					End:   ptr.To(ipamv1.IPAddressStr("2001:db8::1")),
+				},
+				{Subnet: ptr.To(ipamv1.IPSubnetStr("not-a-subnet"))},
+			}, 0.0),
+		)
+	})
+
 	type testCaseAddressFromClaim struct {
 		m3d             *infrav1.Metal3Data
 		poolName        string
@@ -2166,18 +3075,19 @@ var _ = Describe("Metal3Data manager", func() {
 	)
 
 	type testCaseRenderNetworkData struct {
-		m3dt           *infrav1.Metal3DataTemplate
-		m3m            *infrav1.Metal3Machine
-		machine        *clusterv1.Machine
-		bmh            *bmov1alpha1.BareMetalHost
-		poolAddresses  map[string]addressFromPool
-		expectError    bool
-		expectedOutput map[string][]interface{}
+		m3dt            *infrav1.Metal3DataTemplate
+		m3m             *infrav1.Metal3Machine
+		machine         *clusterv1.Machine
+		bmh             *bmov1alpha1.BareMetalHost
+		poolAddresses   map[string]addressFromPool
+		configMapRoutes map[infrav1.RoutesFromConfigMap][]configMapRoute
+		expectError     bool
+		expectedOutput  map[string][]interface{}
 	}
 
 	DescribeTable("Test renderNetworkData",
 		func(tc testCaseRenderNetworkData) {
-			result, err := renderNetworkData(tc.m3dt, tc.m3m, tc.machine, tc.bmh, tc.poolAddresses)
+			result, err := renderNetworkData(tc.m3dt, tc.m3m, tc.machine, tc.bmh, tc.poolAddresses, tc.configMapRoutes)
 			if tc.expectError {
 				Expect(err).To(HaveOccurred())
 				return
@@ -2306,105 +3216,371 @@ var _ = Describe("Metal3Data manager", func() {
 			},
 			expectError: true,
 		}),
-		Entry("Address error", testCaseRenderNetworkData{
+		Entry("Optional interface missing on the host", testCaseRenderNetworkData{
 			m3dt: &infrav1.Metal3DataTemplate{
 				Spec: infrav1.Metal3DataTemplateSpec{
 					NetworkData: &infrav1.NetworkData{
+						Links: infrav1.NetworkDataLink{
+							Ethernets: []infrav1.NetworkDataLinkEthernet{
+								{
+									Type: "phy",
+									Id:   "eth0",
+									MTU:  1500,
+									MACAddress: &infrav1.NetworkLinkEthernetMac{
+										FromHostInterface: ptr.To("eth0"),
+									},
+								},
+								{
+									Type: "phy",
+									Id:   "eth1",
+									MTU:  1500,
+									MACAddress: &infrav1.NetworkLinkEthernetMac{
+										FromHostInterface: ptr.To("eth1"),
+									},
+									Optional: true,
+								},
+							},
+							Bonds: []infrav1.NetworkDataLinkBond{
+								{
+									BondMode: "802.3ad",
+									Id:       "bond0",
+									MTU:      1500,
+									MACAddress: &infrav1.NetworkLinkEthernetMac{
+										FromHostInterface: ptr.To("eth0"),
+									},
+									BondLinks: []string{"eth0", "eth1"},
+								},
+							},
+							Vlans: []infrav1.NetworkDataLinkVlan{
+								{
+									VlanID:   2,
+									Id:       "vlan2",
+									MTU:      1500,
+									VlanLink: "eth1",
+									MACAddress: &infrav1.NetworkLinkEthernetMac{
+										FromHostInterface: ptr.To("eth1"),
+									},
+								},
+							},
+						},
 						Networks: infrav1.NetworkDataNetwork{
-							IPv4: []infrav1.NetworkDataIPv4{
+							IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{
 								{
-									ID:                  "abc",
-									Link:                "def",
-									IPAddressFromIPPool: "abc",
+									ID:   "bond0-dhcp",
+									Link: "bond0",
+								},
+								{
+									ID:   "vlan2-dhcp",
+									Link: "vlan2",
 								},
 							},
 						},
 					},
 				},
 			},
-			expectError: true,
-		}),
-		Entry("Empty", testCaseRenderNetworkData{
-			m3dt: &infrav1.Metal3DataTemplate{
-				Spec: infrav1.Metal3DataTemplateSpec{
-					NetworkData: nil,
-				},
-			},
-			expectedOutput: map[string][]interface{}{},
-		}),
-	)
-
-	type testRenderNetworkServices struct {
-		services       infrav1.NetworkDataService
-		poolAddresses  map[string]addressFromPool
-		expectedOutput []interface{}
-		expectError    bool
-	}
-
-	DescribeTable("Test renderNetworkServices",
-		func(tc testRenderNetworkServices) {
-			result, err := renderNetworkServices(tc.services, tc.poolAddresses)
-			if tc.expectError {
-				Expect(err).To(HaveOccurred())
-				return
-			}
-			Expect(err).NotTo(HaveOccurred())
-			Expect(result).To(Equal(tc.expectedOutput))
-		},
-		Entry("Services and poolAddresses have the same pool", testRenderNetworkServices{
-			services: infrav1.NetworkDataService{
-				DNS: []ipamv1.IPAddressStr{
-					(ipamv1.IPAddressStr)("8.8.8.8"),
-					(ipamv1.IPAddressStr)("2001::8888"),
-				},
-				DNSFromIPPool: ptr.To("pool1"),
-			},
-			poolAddresses: map[string]addressFromPool{
-				"pool1": {
-					dnsServers: []ipamv1.IPAddressStr{
-						ipamv1.IPAddressStr("8.8.4.4"),
+			bmh: &bmov1alpha1.BareMetalHost{
+				Status: bmov1alpha1.BareMetalHostStatus{
+					HardwareDetails: &bmov1alpha1.HardwareDetails{
+						NIC: []bmov1alpha1.NIC{
+							{
+								Name: "eth0",
+								MAC:  "12:34:56:78:9A:BC",
+							},
+						},
 					},
 				},
 			},
-			expectedOutput: []interface{}{
-				map[string]interface{}{
-					"type":    "dns",
-					"address": ipamv1.IPAddressStr("8.8.8.8"),
-				},
-				map[string]interface{}{
-					"type":    "dns",
-					"address": ipamv1.IPAddressStr("2001::8888"),
-				},
-				map[string]interface{}{
-					"type":    "dns",
-					"address": ipamv1.IPAddressStr("8.8.4.4"),
-				},
-			},
-			expectError: false,
-		}),
-		Entry("Services and poolAddresses have different pools", testRenderNetworkServices{
-			services: infrav1.NetworkDataService{
-				DNS: []ipamv1.IPAddressStr{
-					(ipamv1.IPAddressStr)("8.8.8.8"),
-					(ipamv1.IPAddressStr)("2001::8888"),
+			expectedOutput: map[string][]interface{}{
+				"services": {},
+				"links": {
+					map[interface{}]interface{}{
+						"type":                  "bond",
+						"id":                    "bond0",
+						"mtu":                   1500,
+						"ethernet_mac_address":  "12:34:56:78:9A:BC",
+						"bond_mode":             "802.3ad",
+						"bond_xmit_hash_policy": "",
+						"bond_links":            []interface{}{"eth0"},
+					},
+					map[interface{}]interface{}{
+						"type":                 "phy",
+						"id":                   "eth0",
+						"mtu":                  1500,
+						"ethernet_mac_address": "12:34:56:78:9A:BC",
+					},
 				},
-				DNSFromIPPool: ptr.To("pool1"),
-			},
-			poolAddresses: map[string]addressFromPool{
-				"pool2": {
-					dnsServers: []ipamv1.IPAddressStr{
-						ipamv1.IPAddressStr("8.8.4.4"),
+				"networks": {
+					map[interface{}]interface{}{
+						"type":   "ipv4_dhcp",
+						"id":     "bond0-dhcp",
+						"link":   "bond0",
+						"routes": []interface{}{},
 					},
 				},
 			},
-			expectError: true,
 		}),
-	)
-	type testCaseRenderNetworkLinks struct {
-		links          infrav1.NetworkDataLink
-		m3m            *infrav1.Metal3Machine
-		machine        *clusterv1.Machine
-		bmh            *bmov1alpha1.BareMetalHost
+		Entry("Optional interface present on the host", testCaseRenderNetworkData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				Spec: infrav1.Metal3DataTemplateSpec{
+					NetworkData: &infrav1.NetworkData{
+						Links: infrav1.NetworkDataLink{
+							Ethernets: []infrav1.NetworkDataLinkEthernet{
+								{
+									Type: "phy",
+									Id:   "eth0",
+									MTU:  1500,
+									MACAddress: &infrav1.NetworkLinkEthernetMac{
+										FromHostInterface: ptr.To("eth0"),
+									},
+								},
+								{
+									Type: "phy",
+									Id:   "eth1",
+									MTU:  1500,
+									MACAddress: &infrav1.NetworkLinkEthernetMac{
+										FromHostInterface: ptr.To("eth1"),
+									},
+									Optional: true,
+								},
+							},
+							Bonds: []infrav1.NetworkDataLinkBond{
+								{
+									BondMode: "802.3ad",
+									Id:       "bond0",
+									MTU:      1500,
+									MACAddress: &infrav1.NetworkLinkEthernetMac{
+										FromHostInterface: ptr.To("eth0"),
+									},
+									BondLinks: []string{"eth0", "eth1"},
+								},
+							},
+							Vlans: []infrav1.NetworkDataLinkVlan{
+								{
+									VlanID:   2,
+									Id:       "vlan2",
+									MTU:      1500,
+									VlanLink: "eth1",
+									MACAddress: &infrav1.NetworkLinkEthernetMac{
+										FromHostInterface: ptr.To("eth1"),
+									},
+								},
+							},
+						},
+						Networks: infrav1.NetworkDataNetwork{
+							IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{
+								{
+									ID:   "bond0-dhcp",
+									Link: "bond0",
+								},
+								{
+									ID:   "vlan2-dhcp",
+									Link: "vlan2",
+								},
+							},
+						},
+					},
+				},
+			},
+			bmh: &bmov1alpha1.BareMetalHost{
+				Status: bmov1alpha1.BareMetalHostStatus{
+					HardwareDetails: &bmov1alpha1.HardwareDetails{
+						NIC: []bmov1alpha1.NIC{
+							{
+								Name: "eth0",
+								MAC:  "12:34:56:78:9A:BC",
+							},
+							{
+								Name: "eth1",
+								MAC:  "12:34:56:78:9A:BD",
+							},
+						},
+					},
+				},
+			},
+			expectedOutput: map[string][]interface{}{
+				"services": {},
+				"links": {
+					map[interface{}]interface{}{
+						"type":                  "bond",
+						"id":                    "bond0",
+						"mtu":                   1500,
+						"ethernet_mac_address":  "12:34:56:78:9A:BC",
+						"bond_mode":             "802.3ad",
+						"bond_xmit_hash_policy": "",
+						"bond_links":            []interface{}{"eth0", "eth1"},
+					},
+					map[interface{}]interface{}{
+						"type":                 "phy",
+						"id":                   "eth0",
+						"mtu":                  1500,
+						"ethernet_mac_address": "12:34:56:78:9A:BC",
+					},
+					map[interface{}]interface{}{
+						"type":                 "phy",
+						"id":                   "eth1",
+						"mtu":                  1500,
+						"ethernet_mac_address": "12:34:56:78:9A:BD",
+					},
+					map[interface{}]interface{}{
+						"type":             "vlan",
+						"id":               "vlan2",
+						"mtu":              1500,
+						"vlan_mac_address": "12:34:56:78:9A:BD",
+						"vlan_id":          2,
+						"vlan_link":        "eth1",
+					},
+				},
+				"networks": {
+					map[interface{}]interface{}{
+						"type":   "ipv4_dhcp",
+						"id":     "bond0-dhcp",
+						"link":   "bond0",
+						"routes": []interface{}{},
+					},
+					map[interface{}]interface{}{
+						"type":   "ipv4_dhcp",
+						"id":     "vlan2-dhcp",
+						"link":   "vlan2",
+						"routes": []interface{}{},
+					},
+				},
+			},
+		}),
+		Entry("Address error", testCaseRenderNetworkData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				Spec: infrav1.Metal3DataTemplateSpec{
+					NetworkData: &infrav1.NetworkData{
+						Networks: infrav1.NetworkDataNetwork{
+							IPv4: []infrav1.NetworkDataIPv4{
+								{
+									ID:                  "abc",
+									Link:                "def",
+									IPAddressFromIPPool: "abc",
+								},
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Empty", testCaseRenderNetworkData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				Spec: infrav1.Metal3DataTemplateSpec{
+					NetworkData: nil,
+				},
+			},
+			expectedOutput: map[string][]interface{}{},
+		}),
+	)
+
+	type testRenderNetworkServices struct {
+		services       infrav1.NetworkDataService
+		poolAddresses  map[string]addressFromPool
+		expectedOutput []interface{}
+		expectError    bool
+	}
+
+	DescribeTable("Test renderNetworkServices",
+		func(tc testRenderNetworkServices) {
+			result, err := renderNetworkServices(tc.services, tc.poolAddresses)
+			if tc.expectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(tc.expectedOutput))
+		},
+		Entry("Services and poolAddresses have the same pool", testRenderNetworkServices{
+			services: infrav1.NetworkDataService{
+				DNS: []ipamv1.IPAddressStr{
+					(ipamv1.IPAddressStr)("8.8.8.8"),
+					(ipamv1.IPAddressStr)("2001::8888"),
+				},
+				DNSFromIPPool: ptr.To("pool1"),
+			},
+			poolAddresses: map[string]addressFromPool{
+				"pool1": {
+					dnsServers: []ipamv1.IPAddressStr{
+						ipamv1.IPAddressStr("8.8.4.4"),
+					},
+				},
+			},
+			expectedOutput: []interface{}{
+				map[string]interface{}{
+					"type":    "dns",
+					"address": ipamv1.IPAddressStr("8.8.8.8"),
+				},
+				map[string]interface{}{
+					"type":    "dns",
+					"address": ipamv1.IPAddressStr("2001::8888"),
+				},
+				map[string]interface{}{
+					"type":    "dns",
+					"address": ipamv1.IPAddressStr("8.8.4.4"),
+				},
+			},
+			expectError: false,
+		}),
+		Entry("Services and poolAddresses have different pools", testRenderNetworkServices{
+			services: infrav1.NetworkDataService{
+				DNS: []ipamv1.IPAddressStr{
+					(ipamv1.IPAddressStr)("8.8.8.8"),
+					(ipamv1.IPAddressStr)("2001::8888"),
+				},
+				DNSFromIPPool: ptr.To("pool1"),
+			},
+			poolAddresses: map[string]addressFromPool{
+				"pool2": {
+					dnsServers: []ipamv1.IPAddressStr{
+						ipamv1.IPAddressStr("8.8.4.4"),
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Static search domains", testRenderNetworkServices{
+			services: infrav1.NetworkDataService{
+				DNS: []ipamv1.IPAddressStr{
+					(ipamv1.IPAddressStr)("8.8.8.8"),
+				},
+				SearchDomains: []string{"example.com", "cluster.local"},
+			},
+			expectedOutput: []interface{}{
+				map[string]interface{}{
+					"type":    "dns",
+					"address": ipamv1.IPAddressStr("8.8.8.8"),
+				},
+				map[string]interface{}{
+					"type":   "dns-search",
+					"domain": "example.com",
+				},
+				map[string]interface{}{
+					"type":   "dns-search",
+					"domain": "cluster.local",
+				},
+			},
+		}),
+		Entry("Empty search domains are omitted", testRenderNetworkServices{
+			services: infrav1.NetworkDataService{
+				DNS: []ipamv1.IPAddressStr{
+					(ipamv1.IPAddressStr)("8.8.8.8"),
+				},
+				SearchDomains: []string{},
+			},
+			expectedOutput: []interface{}{
+				map[string]interface{}{
+					"type":    "dns",
+					"address": ipamv1.IPAddressStr("8.8.8.8"),
+				},
+			},
+		}),
+	)
+	type testCaseRenderNetworkLinks struct {
+		links          infrav1.NetworkDataLink
+		m3m            *infrav1.Metal3Machine
+		machine        *clusterv1.Machine
+		bmh            *bmov1alpha1.BareMetalHost
 		expectError    bool
 		expectedOutput []interface{}
 	}
@@ -2460,52 +3636,114 @@ var _ = Describe("Metal3Data manager", func() {
 			},
 			expectError: true,
 		}),
-		Entry("Bond, MAC from string", testCaseRenderNetworkLinks{
+		Entry("Ethernet, interface name overridden", testCaseRenderNetworkLinks{
 			links: infrav1.NetworkDataLink{
-				Bonds: []infrav1.NetworkDataLinkBond{
+				Ethernets: []infrav1.NetworkDataLinkEthernet{
 					{
-						BondMode:           "802.3ad",
-						BondXmitHashPolicy: "layer3+4",
-						Id:                 "bond0",
-						MTU:                1500,
+						Type: "phy",
+						Id:   "data0",
+						MTU:  1500,
 						MACAddress: &infrav1.NetworkLinkEthernetMac{
-							String: ptr.To("12:34:56:78:9A:BC"),
+							FromHostInterface: ptr.To("data0"),
+						},
+					},
+					{
+						Type: "phy",
+						Id:   "eth1",
+						MTU:  1500,
+						MACAddress: &infrav1.NetworkLinkEthernetMac{
+							FromHostInterface: ptr.To("eth1"),
 						},
-						BondLinks: []string{"eth0"},
 					},
 				},
 			},
+			bmh: newInterfaceNamesHost("12:34:56:78:9a:bc=data0"),
 			expectedOutput: []interface{}{
 				map[string]interface{}{
-					"type":                  "bond",
-					"id":                    "bond0",
-					"mtu":                   1500,
-					"ethernet_mac_address":  "12:34:56:78:9A:BC",
-					"bond_mode":             "802.3ad",
-					"bond_xmit_hash_policy": "layer3+4",
-					"bond_links":            []string{"eth0"},
+					"type":                 "phy",
+					"id":                   "data0",
+					"mtu":                  1500,
+					"ethernet_mac_address": "12:34:56:78:9A:BC",
+					"name":                 "data0",
+				},
+				map[string]interface{}{
+					"type":                 "phy",
+					"id":                   "eth1",
+					"mtu":                  1500,
+					"ethernet_mac_address": "12:34:56:78:9A:BD",
 				},
 			},
 		}),
-		Entry("Bond, MAC error", testCaseRenderNetworkLinks{
+		Entry("Ethernet, kernel name of an overridden interface", testCaseRenderNetworkLinks{
 			links: infrav1.NetworkDataLink{
-				Bonds: []infrav1.NetworkDataLinkBond{
+				Ethernets: []infrav1.NetworkDataLinkEthernet{
 					{
-						BondMode: "802.3ad",
-						Id:       "bond0",
-						MTU:      1500,
+						Type: "phy",
+						Id:   "eth0",
+						MTU:  1500,
 						MACAddress: &infrav1.NetworkLinkEthernetMac{
-							FromHostInterface: ptr.To("eth2"),
+							FromHostInterface: ptr.To("eth0"),
 						},
-						BondLinks: []string{"eth0"},
 					},
 				},
 			},
-			bmh: &bmov1alpha1.BareMetalHost{
-				ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, ""),
-				Status:     bmov1alpha1.BareMetalHostStatus{},
-			},
-			expectError: true,
+			bmh: newInterfaceNamesHost("12:34:56:78:9A:BC=data0"),
+			expectedOutput: []interface{}{
+				map[string]interface{}{
+					"type":                 "phy",
+					"id":                   "eth0",
+					"mtu":                  1500,
+					"ethernet_mac_address": "12:34:56:78:9A:BC",
+					"name":                 "data0",
+				},
+			},
+		}),
+		Entry("Bond, MAC from string", testCaseRenderNetworkLinks{
+			links: infrav1.NetworkDataLink{
+				Bonds: []infrav1.NetworkDataLinkBond{
+					{
+						BondMode:           "802.3ad",
+						BondXmitHashPolicy: "layer3+4",
+						Id:                 "bond0",
+						MTU:                1500,
+						MACAddress: &infrav1.NetworkLinkEthernetMac{
+							String: ptr.To("12:34:56:78:9A:BC"),
+						},
+						BondLinks: []string{"eth0"},
+					},
+				},
+			},
+			expectedOutput: []interface{}{
+				map[string]interface{}{
+					"type":                  "bond",
+					"id":                    "bond0",
+					"mtu":                   1500,
+					"ethernet_mac_address":  "12:34:56:78:9A:BC",
+					"bond_mode":             "802.3ad",
+					"bond_xmit_hash_policy": "layer3+4",
+					"bond_links":            []string{"eth0"},
+				},
+			},
+		}),
+		Entry("Bond, MAC error", testCaseRenderNetworkLinks{
+			links: infrav1.NetworkDataLink{
+				Bonds: []infrav1.NetworkDataLinkBond{
+					{
+						BondMode: "802.3ad",
+						Id:       "bond0",
+						MTU:      1500,
+						MACAddress: &infrav1.NetworkLinkEthernetMac{
+							FromHostInterface: ptr.To("eth2"),
+						},
+						BondLinks: []string{"eth0"},
+					},
+				},
+			},
+			bmh: &bmov1alpha1.BareMetalHost{
+				ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, ""),
+				Status:     bmov1alpha1.BareMetalHostStatus{},
+			},
+			expectError: true,
 		}),
 		Entry("Vlan, MAC from string", testCaseRenderNetworkLinks{
 			links: infrav1.NetworkDataLink{
@@ -2555,16 +3793,17 @@ var _ = Describe("Metal3Data manager", func() {
 	)
 
 	type testCaseRenderNetworkNetworks struct {
-		networks       infrav1.NetworkDataNetwork
-		m3d            *infrav1.Metal3Data
-		poolAddresses  map[string]addressFromPool
-		expectError    bool
-		expectedOutput []interface{}
+		networks        infrav1.NetworkDataNetwork
+		m3d             *infrav1.Metal3Data
+		poolAddresses   map[string]addressFromPool
+		configMapRoutes map[infrav1.RoutesFromConfigMap][]configMapRoute
+		expectError     bool
+		expectedOutput  []interface{}
 	}
 
 	DescribeTable("Test renderNetworkNetworks",
 		func(tc testCaseRenderNetworkNetworks) {
-			result, err := renderNetworkNetworks(tc.networks, tc.poolAddresses)
+			result, err := renderNetworkNetworks(tc.networks, tc.poolAddresses, tc.configMapRoutes)
 			if tc.expectError {
 				Expect(err).To(HaveOccurred())
 				return
@@ -2720,6 +3959,112 @@ var _ = Describe("Metal3Data manager", func() {
 			},
 			expectError: true,
 		}),
+		Entry("IPv4 DHCP, routes from a ConfigMap", testCaseRenderNetworkNetworks{
+			networks: infrav1.NetworkDataNetwork{
+				IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{
+					{
+						ID:   "abc",
+						Link: "def",
+						Routes: []infrav1.NetworkDataRoutev4{
+							{
+								Network: "10.0.0.0",
+								Prefix:  16,
+								Gateway: infrav1.NetworkGatewayv4{
+									String: (*ipamv1.IPAddressv4Str)(ptr.To("192.168.1.1")),
+								},
+							},
+						},
+						RoutesFromConfigMap: &infrav1.RoutesFromConfigMap{Name: "routes", Key: "default"},
+					},
+				},
+			},
+			configMapRoutes: map[infrav1.RoutesFromConfigMap][]configMapRoute{
+				{Name: "routes", Key: "default"}: {
+					{Destination: "10.10.0.0/16", Gateway: "192.168.0.1"},
+				},
+			},
+			expectedOutput: []interface{}{
+				map[string]interface{}{
+					"routes": []interface{}{
+						map[string]interface{}{
+							"network":  ipamv1.IPAddressv4Str("10.0.0.0"),
+							"netmask":  ipamv1.IPAddressv4Str("255.255.0.0"),
+							"gateway":  ipamv1.IPAddressv4Str("192.168.1.1"),
+							"services": []interface{}{},
+						},
+						map[string]interface{}{
+							"network":  ipamv1.IPAddressv4Str("10.10.0.0"),
+							"netmask":  ipamv1.IPAddressv4Str("255.255.0.0"),
+							"gateway":  ipamv1.IPAddressv4Str("192.168.0.1"),
+							"services": []interface{}{},
+						},
+					},
+					"type": "ipv4_dhcp",
+					"id":   "abc",
+					"link": "def",
+				},
+			},
+		}),
+		Entry("IPv6 DHCP, routes from a ConfigMap", testCaseRenderNetworkNetworks{
+			networks: infrav1.NetworkDataNetwork{
+				IPv6DHCP: []infrav1.NetworkDataIPv6DHCP{
+					{
+						ID:                  "abc",
+						Link:                "def",
+						RoutesFromConfigMap: &infrav1.RoutesFromConfigMap{Name: "routes", Key: "ipv6"},
+					},
+				},
+			},
+			configMapRoutes: map[infrav1.RoutesFromConfigMap][]configMapRoute{
+				{Name: "routes", Key: "ipv6"}: {
+					{Destination: "2001:db8::/32", Gateway: "fd00::1"},
+				},
+			},
+			expectedOutput: []interface{}{
+				map[string]interface{}{
+					"routes": []interface{}{
+						map[string]interface{}{
+							"network":  ipamv1.IPAddressv6Str("2001:db8::"),
+							"netmask":  ipamv1.IPAddressv6Str("ffff:ffff::"),
+							"gateway":  ipamv1.IPAddressv6Str("fd00::1"),
+							"services": []interface{}{},
+						},
+					},
+					"type": "ipv6_dhcp",
+					"id":   "abc",
+					"link": "def",
+				},
+			},
+		}),
+		Entry("IPv4 DHCP, routes from a ConfigMap of another family", testCaseRenderNetworkNetworks{
+			networks: infrav1.NetworkDataNetwork{
+				IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{
+					{
+						ID:                  "abc",
+						Link:                "def",
+						RoutesFromConfigMap: &infrav1.RoutesFromConfigMap{Name: "routes", Key: "ipv6"},
+					},
+				},
+			},
+			configMapRoutes: map[infrav1.RoutesFromConfigMap][]configMapRoute{
+				{Name: "routes", Key: "ipv6"}: {
+					{Destination: "2001:db8::/32", Gateway: "fd00::1"},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("IPv4 DHCP, routes of a ConfigMap not fetched", testCaseRenderNetworkNetworks{
+			networks: infrav1.NetworkDataNetwork{
+				IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{
+					{
+						ID:                  "abc",
+						Link:                "def",
+						RoutesFromConfigMap: &infrav1.RoutesFromConfigMap{Name: "routes", Key: "default"},
+					},
+				},
+			},
+			expectError: true,
+		}),
 		Entry("IPv4 DHCP", testCaseRenderNetworkNetworks{
 			networks: infrav1.NetworkDataNetwork{
 				IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{
@@ -2867,6 +4212,57 @@ var _ = Describe("Metal3Data manager", func() {
 				},
 			},
 		}),
+		Entry("IPv6 static, DHCP and SLAAC networks, only the static one from a pool", testCaseRenderNetworkNetworks{
+			poolAddresses: map[string]addressFromPool{
+				"abc": {
+					Address: ipamv1.IPAddressStr("fe80::2001:38"),
+					Prefix:  96,
+				},
+			},
+			networks: infrav1.NetworkDataNetwork{
+				IPv6: []infrav1.NetworkDataIPv6{
+					{
+						ID:                  "static",
+						Link:                "eth0",
+						IPAddressFromIPPool: "abc",
+					},
+				},
+				IPv6DHCP: []infrav1.NetworkDataIPv6DHCP{
+					{
+						ID:   "dhcp",
+						Link: "eth1",
+					},
+				},
+				IPv6SLAAC: []infrav1.NetworkDataIPv6DHCP{
+					{
+						ID:   "slaac",
+						Link: "eth2",
+					},
+				},
+			},
+			expectedOutput: []interface{}{
+				map[string]interface{}{
+					"ip_address": ipamv1.IPAddressv6Str("fe80::2001:38"),
+					"routes":     []interface{}{},
+					"type":       "ipv6",
+					"id":         "static",
+					"link":       "eth0",
+					"netmask":    ipamv1.IPAddressv6Str("ffff:ffff:ffff:ffff:ffff:ffff::"),
+				},
+				map[string]interface{}{
+					"routes": []interface{}{},
+					"type":   "ipv6_dhcp",
+					"id":     "dhcp",
+					"link":   "eth1",
+				},
+				map[string]interface{}{
+					"routes": []interface{}{},
+					"type":   "ipv6_slaac",
+					"id":     "slaac",
+					"link":   "eth2",
+				},
+			},
+		}),
 	)
 
 	It("Test getRoutesv4", func() {
@@ -3212,7 +4608,9 @@ var _ = Describe("Metal3Data manager", func() {
 		m3m              *infrav1.Metal3Machine
 		machine          *clusterv1.Machine
 		bmh              *bmov1alpha1.BareMetalHost
+		cluster          *clusterv1.Cluster
 		poolAddresses    map[string]addressFromPool
+		sources          map[string]map[string]string
 		expectedMetaData map[string]string
 		expectError      bool
 	}
@@ -3220,7 +4618,7 @@ var _ = Describe("Metal3Data manager", func() {
 	DescribeTable("Test renderMetaData",
 		func(tc testCaseRenderMetaData) {
 			resultBytes, err := renderMetaData(tc.m3d, tc.m3dt, tc.m3m, tc.machine,
-				tc.bmh, tc.poolAddresses,
+				tc.bmh, tc.cluster, tc.poolAddresses, tc.sources,
 			)
 			if tc.expectError {
 				Expect(err).To(HaveOccurred())
@@ -3525,119 +4923,1052 @@ var _ = Describe("Metal3Data manager", func() {
 					},
 				},
 			},
-			expectError: true,
+			expectError: true,
+		}),
+		Entry("IP missing", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+				Spec: infrav1.Metal3DataSpec{
+					Index: 2,
+				},
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						IPAddressesFromPool: []infrav1.FromPool{
+							{
+								Key:  "Address-1",
+								Name: "abc",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Prefix missing", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+				Spec: infrav1.Metal3DataSpec{
+					Index: 2,
+				},
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						PrefixesFromPool: []infrav1.FromPool{
+							{
+								Key:  "Address-1",
+								Name: "abc",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Gateway missing", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+				Spec: infrav1.Metal3DataSpec{
+					Index: 2,
+				},
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						GatewaysFromPool: []infrav1.FromPool{
+							{
+								Key:  "Address-1",
+								Name: "abc",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Netmask missing", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+				Spec: infrav1.Metal3DataSpec{
+					Index: 2,
+				},
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						NetmasksFromPool: []infrav1.FromPool{
+							{
+								Key:  "Netmask-1",
+								Name: "abc",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Prefixes and netmasks", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+				Spec: infrav1.Metal3DataSpec{
+					Index: 2,
+				},
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						PrefixesFromPool: []infrav1.FromPool{
+							{
+								Key:  "Prefix-1",
+								Name: "abcd",
+							},
+							{
+								Key:  "Prefix-2",
+								Name: "bcde",
+							},
+						},
+						NetmasksFromPool: []infrav1.FromPool{
+							{
+								Key:  "Netmask-1",
+								Name: "abcd",
+							},
+							{
+								Key:  "Netmask-2",
+								Name: "bcde",
+							},
+						},
+					},
+				},
+			},
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta(metal3machineName, namespaceName, ""),
+			},
+			bmh: &bmov1alpha1.BareMetalHost{
+				ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, ""),
+			},
+			poolAddresses: map[string]addressFromPool{
+				"abcd": {
+					Address: "192.168.0.14",
+					Prefix:  26,
+				},
+				"bcde": {
+					Address: "2001:db8::14",
+					Prefix:  64,
+				},
+			},
+			expectedMetaData: map[string]string{
+				"Prefix-1":   "26",
+				"Prefix-2":   "64",
+				"Netmask-1":  "255.255.255.192",
+				"Netmask-2":  "ffff:ffff:ffff:ffff::",
+				"providerid": fmt.Sprintf("%s/%s/%s", namespaceName, baremetalhostName, metal3machineName),
+			},
+		}),
+		Entry("Netmask of an invalid address", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+				Spec: infrav1.Metal3DataSpec{
+					Index: 2,
+				},
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						NetmasksFromPool: []infrav1.FromPool{
+							{
+								Key:  "Netmask-1",
+								Name: "abcd",
+							},
+						},
+					},
+				},
+			},
+			poolAddresses: map[string]addressFromPool{
+				"abcd": {
+					Address: "192.168.0",
+					Prefix:  24,
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Wrong object in name", testCaseRenderMetaData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						ObjectNames: []infrav1.MetaDataObjectName{
+							{
+								Key:    "ObjectName-3",
+								Object: "baremetalhost2",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Wrong object in Label", testCaseRenderMetaData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						FromLabels: []infrav1.MetaDataFromLabel{
+							{
+								Key:    "ObjectName-3",
+								Object: "baremetalhost2",
+								Label:  "abc",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Wrong object in Annotation", testCaseRenderMetaData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						FromAnnotations: []infrav1.MetaDataFromAnnotation{
+							{
+								Key:        "ObjectName-3",
+								Object:     "baremetalhost2",
+								Annotation: "abc",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Cluster fields", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						FromCluster: []infrav1.MetaDataFromCluster{
+							{
+								Key:   "Cluster-1",
+								Field: "name",
+							},
+							{
+								Key:   "Cluster-2",
+								Field: "Namespace",
+							},
+						},
+					},
+				},
+			},
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta(metal3machineName, namespaceName, ""),
+			},
+			bmh: &bmov1alpha1.BareMetalHost{
+				ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, ""),
+			},
+			cluster: newCluster(clusterName),
+			expectedMetaData: map[string]string{
+				"Cluster-1":  clusterName,
+				"Cluster-2":  namespaceName,
+				"providerid": fmt.Sprintf("%s/%s/%s", namespaceName, baremetalhostName, metal3machineName),
+			},
+		}),
+		Entry("Secret and ConfigMap data", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						FromSecrets: []infrav1.MetaDataFromSource{
+							{
+								Key:     "token",
+								Name:    "credentials",
+								DataKey: "join-token",
+							},
+						},
+						FromConfigMaps: []infrav1.MetaDataFromSource{
+							{
+								Key:     "region",
+								Name:    "site",
+								DataKey: "region",
+							},
+						},
+					},
+				},
+			},
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta(metal3machineName, namespaceName, ""),
+			},
+			bmh: &bmov1alpha1.BareMetalHost{
+				ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, ""),
+			},
+			sources: map[string]map[string]string{
+				"Secret/credentials": {"join-token": "abcdef"},
+				"ConfigMap/site":     {"region": "eu-1"},
+			},
+			expectedMetaData: map[string]string{
+				"token":      "abcdef",
+				"region":     "eu-1",
+				"providerid": fmt.Sprintf("%s/%s/%s", namespaceName, baremetalhostName, metal3machineName),
+			},
+		}),
+		Entry("NTP servers", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						NTPServers: []infrav1.MetaDataNTPServers{
+							{
+								Key:     "ntp-static",
+								Servers: []string{"10.0.0.1", "ntp.example.com"},
+							},
+							{
+								Key: "ntp-site",
+								FromConfigMap: &infrav1.NTPServersFromConfigMap{
+									Name: "site",
+									Key:  "ntp",
+								},
+							},
+							{
+								Key:     "ntp-both",
+								Servers: []string{"pool.ntp.org"},
+								FromConfigMap: &infrav1.NTPServersFromConfigMap{
+									Name: "site",
+									Key:  "ntp",
+								},
+							},
+						},
+					},
+				},
+			},
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta(metal3machineName, namespaceName, ""),
+			},
+			bmh: &bmov1alpha1.BareMetalHost{
+				ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, ""),
+			},
+			sources: map[string]map[string]string{
+				"ConfigMap/site": {"ntp": "192.168.0.1, 2001:db8::1\n ntp1.site.example.com"},
+			},
+			expectedMetaData: map[string]string{
+				"ntp-static": "10.0.0.1,ntp.example.com",
+				"ntp-site":   "192.168.0.1,2001:db8::1,ntp1.site.example.com",
+				"ntp-both":   "pool.ntp.org,192.168.0.1,2001:db8::1,ntp1.site.example.com",
+				"providerid": fmt.Sprintf("%s/%s/%s", namespaceName, baremetalhostName, metal3machineName),
+			},
+		}),
+		Entry("Invalid NTP server in the ConfigMap", testCaseRenderMetaData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						NTPServers: []infrav1.MetaDataNTPServers{
+							{
+								Key: "ntp",
+								FromConfigMap: &infrav1.NTPServersFromConfigMap{
+									Name: "site",
+									Key:  "ntp",
+								},
+							},
+						},
+					},
+				},
+			},
+			sources: map[string]map[string]string{
+				"ConfigMap/site": {"ntp": "ntp_server.example.com"},
+			},
+			expectError: true,
+		}),
+		Entry("Secret data key missing", testCaseRenderMetaData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						FromSecrets: []infrav1.MetaDataFromSource{
+							{
+								Key:     "token",
+								Name:    "credentials",
+								DataKey: "join-token",
+							},
+						},
+					},
+				},
+			},
+			sources: map[string]map[string]string{
+				"Secret/credentials": {"token": "abcdef"},
+			},
+			expectError: true,
+		}),
+		Entry("ConfigMap missing", testCaseRenderMetaData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						FromConfigMaps: []infrav1.MetaDataFromSource{
+							{
+								Key:     "region",
+								Name:    "site",
+								DataKey: "region",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Cluster missing", testCaseRenderMetaData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						FromCluster: []infrav1.MetaDataFromCluster{
+							{
+								Key:   "Cluster-1",
+								Field: "name",
+							},
+						},
+					},
+				},
+			},
+			expectError: true,
+		}),
+		Entry("Wrong cluster field", testCaseRenderMetaData{
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						FromCluster: []infrav1.MetaDataFromCluster{
+							{
+								Key:   "Cluster-1",
+								Field: "uid",
+							},
+						},
+					},
+				},
+			},
+			cluster:     newCluster(clusterName),
+			expectError: true,
+		}),
+		Entry("Hostname format", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+				Spec: infrav1.Metal3DataSpec{
+					Index: 3,
+				},
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						HostnameFormat: "worker-{index}.{cluster}.example.com",
+					},
+				},
+			},
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta(metal3machineName, namespaceName, ""),
+			},
+			machine: &clusterv1.Machine{
+				ObjectMeta: testObjectMeta(machineName, namespaceName, ""),
+			},
+			bmh: &bmov1alpha1.BareMetalHost{
+				ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, ""),
+			},
+			cluster: newCluster(clusterName),
+			expectedMetaData: map[string]string{
+				"local-hostname": "worker-3." + clusterName + ".example.com",
+				"local_hostname": "worker-3." + clusterName + ".example.com",
+				"providerid":     fmt.Sprintf("%s/%s/%s", namespaceName, baremetalhostName, metal3machineName),
+			},
+		}),
+		Entry("Hostname format with machine name, overridden", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						HostnameFormat: "{machine}.example.com",
+						Strings: []infrav1.MetaDataString{
+							{
+								Key:   "local_hostname",
+								Value: "override",
+							},
+						},
+					},
+				},
+			},
+			m3m: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta(metal3machineName, namespaceName, ""),
+			},
+			machine: &clusterv1.Machine{
+				ObjectMeta: testObjectMeta(machineName, namespaceName, ""),
+			},
+			bmh: &bmov1alpha1.BareMetalHost{
+				ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, ""),
+			},
+			expectedMetaData: map[string]string{
+				"local-hostname": machineName + ".example.com",
+				"local_hostname": "override",
+				"providerid":     fmt.Sprintf("%s/%s/%s", namespaceName, baremetalhostName, metal3machineName),
+			},
+		}),
+		Entry("Hostname format, cluster missing", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						HostnameFormat: "{machine}.{cluster}",
+					},
+				},
+			},
+			machine: &clusterv1.Machine{
+				ObjectMeta: testObjectMeta(machineName, namespaceName, ""),
+			},
+			expectError: true,
+		}),
+		Entry("Hostname format, invalid hostname", testCaseRenderMetaData{
+			m3d: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
+			},
+			m3dt: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+				Spec: infrav1.Metal3DataTemplateSpec{
+					MetaData: &infrav1.MetaData{
+						HostnameFormat: "{machine}-",
+					},
+				},
+			},
+			machine: &clusterv1.Machine{
+				ObjectMeta: testObjectMeta(machineName, namespaceName, ""),
+			},
+			expectError: true,
+		}),
+	)
+
+	DescribeTable("Test getMetaDataFromAnnotation",
+		func(entry infrav1.MetaDataFromAnnotation, expectedValue string, expectError bool) {
+			m3m := &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"M3M": "metal3machine"}},
+			}
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"region": "eu-west", "empty": ""}},
+			}
+			bmh := &bmov1alpha1.BareMetalHost{}
+
+			value, err := getMetaDataFromAnnotation(entry, m3m, machine, bmh)
+			if expectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(expectedValue))
+		},
+		Entry("Machine annotation", infrav1.MetaDataFromAnnotation{
+			Key: "region", Object: "machine", Annotation: "region",
+		}, "eu-west", false),
+		Entry("Required Machine annotation", infrav1.MetaDataFromAnnotation{
+			Key: "region", Object: "machine", Annotation: "region", Required: true,
+		}, "eu-west", false),
+		Entry("Empty annotation takes precedence over the default", infrav1.MetaDataFromAnnotation{
+			Key: "empty", Object: "machine", Annotation: "empty", Default: "none",
+		}, "", false),
+		Entry("Metal3Machine annotation", infrav1.MetaDataFromAnnotation{
+			Key: "m3m", Object: "metal3machine", Annotation: "M3M",
+		}, "metal3machine", false),
+		Entry("Missing annotation", infrav1.MetaDataFromAnnotation{
+			Key: "zone", Object: "machine", Annotation: "zone",
+		}, "", false),
+		Entry("Missing annotation with a default", infrav1.MetaDataFromAnnotation{
+			Key: "zone", Object: "machine", Annotation: "zone", Default: "zone-a",
+		}, "zone-a", false),
+		Entry("Missing required annotation", infrav1.MetaDataFromAnnotation{
+			Key: "zone", Object: "machine", Annotation: "zone", Required: true,
+		}, "", true),
+		Entry("Unknown object", infrav1.MetaDataFromAnnotation{
+			Key: "zone", Object: "cluster", Annotation: "zone",
+		}, "", true),
+	)
+
+	type testCaseRenderUserData struct {
+		Files           []infrav1.UserDataFile
+		BootstrapFormat string
+		NoBootstrapData bool
+		ExpectedContent map[string]string
+		ExpectError     bool
+		ExpectRequeue   bool
+	}
+
+	DescribeTable("Test renderUserData",
+		func(tc testCaseRenderUserData) {
+			bootstrap := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "bootstrap", Namespace: namespaceName},
+				Data: map[string][]byte{
+					"value": []byte("#cloud-config\nruncmd:\n- kubeadm join\n"),
+				},
+			}
+			if tc.BootstrapFormat != "" {
+				bootstrap.Data[userDataFormatKey] = []byte(tc.BootstrapFormat)
+			}
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "registries", Namespace: namespaceName},
+				Data:       map[string]string{"registries.conf": "[[registry]]\nlocation = \"mirror\"\n"},
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: namespaceName},
+				Data:       map[string][]byte{"ca.pem": []byte("-----BEGIN CERTIFICATE-----")},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).
+				WithObjects(bootstrap, configMap, secret).Build()
+			dataMgr, err := NewDataManager(fakeClient, &infrav1.Metal3Data{}, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			m3dt := &infrav1.Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: namespaceName},
+				Spec: infrav1.Metal3DataTemplateSpec{
+					UserData: &infrav1.UserData{Files: tc.Files},
+				},
+			}
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: namespaceName},
+			}
+			if !tc.NoBootstrapData {
+				machine.Spec.Bootstrap.DataSecretName = ptr.To("bootstrap")
+			}
+
+			userData, format, err := dataMgr.renderUserData(context.TODO(), m3dt, machine)
+			if tc.ExpectError || tc.ExpectRequeue {
+				Expect(err).To(HaveOccurred())
+				var reconcileError ReconcileError
+				Expect(errors.As(err, &reconcileError) && reconcileError.IsTransient()).To(Equal(tc.ExpectRequeue))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(format).To(Equal(UserDataFormatCloudConfig))
+
+			// The bootstrap data is kept as is, followed by the files.
+			header, body, found := bytes.Cut(userData, []byte("\n\n"))
+			Expect(found).To(BeTrue())
+			Expect(string(header)).To(ContainSubstring("multipart/mixed"))
+			reader := multipart.NewReader(bytes.NewReader(body), userDataBoundary)
+			bootstrapPart, err := reader.NextPart()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bootstrapPart.Header.Get("Content-Type")).To(HavePrefix("text/cloud-config"))
+			Expect(io.ReadAll(bootstrapPart)).To(Equal(bootstrap.Data["value"]))
+			filesPart, err := reader.NextPart()
+			Expect(err).NotTo(HaveOccurred())
+			filesData, err := io.ReadAll(filesPart)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(filesData)).To(HavePrefix("#cloud-config\n"))
+
+			cloudConfig := struct {
+				WriteFiles []struct {
+					Path        string `yaml:"path"`
+					Content     string `yaml:"content"`
+					Encoding    string `yaml:"encoding"`
+					Permissions string `yaml:"permissions"`
+				} `yaml:"write_files"`
+			}{}
+			Expect(yaml.Unmarshal(filesData, &cloudConfig)).To(Succeed())
+			content := map[string]string{}
+			for _, file := range cloudConfig.WriteFiles {
+				Expect(file.Encoding).To(Equal("b64"))
+				decoded, err := base64.StdEncoding.DecodeString(file.Content)
+				Expect(err).NotTo(HaveOccurred())
+				content[file.Path] = string(decoded)
+			}
+			Expect(content).To(Equal(tc.ExpectedContent))
+		},
+		Entry("Inline file", testCaseRenderUserData{
+			Files: []infrav1.UserDataFile{
+				{Path: "/etc/motd", Content: "Welcome", Permissions: "0644"},
+			},
+			ExpectedContent: map[string]string{"/etc/motd": "Welcome"},
 		}),
-		Entry("IP missing", testCaseRenderMetaData{
-			m3d: &infrav1.Metal3Data{
-				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
-				Spec: infrav1.Metal3DataSpec{
-					Index: 2,
+		Entry("Files from a ConfigMap and a Secret", testCaseRenderUserData{
+			Files: []infrav1.UserDataFile{
+				{
+					Path:          "/etc/containers/registries.conf",
+					FromConfigMap: &infrav1.UserDataFileSource{Name: "registries", Key: "registries.conf"},
+				},
+				{
+					Path:       "/etc/pki/ca.pem",
+					FromSecret: &infrav1.UserDataFileSource{Name: "ca", Key: "ca.pem"},
 				},
 			},
-			m3dt: &infrav1.Metal3DataTemplate{
-				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+			ExpectedContent: map[string]string{
+				"/etc/containers/registries.conf": "[[registry]]\nlocation = \"mirror\"\n",
+				"/etc/pki/ca.pem":                 "-----BEGIN CERTIFICATE-----",
+			},
+		}),
+		Entry("Missing ConfigMap", testCaseRenderUserData{
+			Files: []infrav1.UserDataFile{
+				{Path: "/etc/motd", FromConfigMap: &infrav1.UserDataFileSource{Name: "motd", Key: "motd"}},
+			},
+			ExpectRequeue: true,
+		}),
+		Entry("Missing ConfigMap key", testCaseRenderUserData{
+			Files: []infrav1.UserDataFile{
+				{Path: "/etc/motd", FromConfigMap: &infrav1.UserDataFileSource{Name: "registries", Key: "motd"}},
+			},
+			ExpectError: true,
+		}),
+		Entry("Cloud-config bootstrap data", testCaseRenderUserData{
+			Files: []infrav1.UserDataFile{
+				{Path: "/etc/motd", Content: "Welcome"},
+			},
+			BootstrapFormat: UserDataFormatCloudConfig,
+			ExpectedContent: map[string]string{"/etc/motd": "Welcome"},
+		}),
+		Entry("Bootstrap data not ready", testCaseRenderUserData{
+			Files: []infrav1.UserDataFile{
+				{Path: "/etc/motd", Content: "Welcome"},
+			},
+			NoBootstrapData: true,
+			ExpectRequeue:   true,
+		}),
+	)
+
+	type testCaseRenderIgnitionUserData struct {
+		Bootstrap     string
+		ExpectedFiles []interface{}
+		ExpectError   bool
+	}
+
+	DescribeTable("Test renderUserData with ignition bootstrap data",
+		func(tc testCaseRenderIgnitionUserData) {
+			bootstrap := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "bootstrap", Namespace: namespaceName},
+				Data: map[string][]byte{
+					"value":           []byte(tc.Bootstrap),
+					userDataFormatKey: []byte(UserDataFormatIgnition),
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(bootstrap).Build()
+			dataMgr, err := NewDataManager(fakeClient, &infrav1.Metal3Data{}, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			m3dt := &infrav1.Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: namespaceName},
 				Spec: infrav1.Metal3DataTemplateSpec{
-					MetaData: &infrav1.MetaData{
-						IPAddressesFromPool: []infrav1.FromPool{
-							{
-								Key:  "Address-1",
-								Name: "abc",
-							},
-						},
-					},
+					UserData: &infrav1.UserData{Files: []infrav1.UserDataFile{
+						{Path: "/etc/motd", Content: "Welcome", Permissions: "0644"},
+					}},
+				},
+			}
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: namespaceName},
+			}
+			machine.Spec.Bootstrap.DataSecretName = ptr.To("bootstrap")
+
+			userData, format, err := dataMgr.renderUserData(context.TODO(), m3dt, machine)
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(format).To(Equal(UserDataFormatIgnition))
+
+			// The ignition config is kept, with the files appended.
+			config := map[string]interface{}{}
+			Expect(json.Unmarshal(userData, &config)).To(Succeed())
+			Expect(config).To(HaveKey("ignition"))
+			Expect(config["storage"]).To(HaveKeyWithValue("files", tc.ExpectedFiles))
+		},
+		Entry("Ignition spec 3", testCaseRenderIgnitionUserData{
+			Bootstrap: `{"ignition":{"version":"3.1.0"},"storage":{"files":[{"path":"/etc/kubeadm.yml"}]}}`,
+			ExpectedFiles: []interface{}{
+				map[string]interface{}{"path": "/etc/kubeadm.yml"},
+				map[string]interface{}{
+					"path":     "/etc/motd",
+					"contents": map[string]interface{}{"source": "data:;base64,V2VsY29tZQ=="},
+					"mode":     float64(0o644),
 				},
 			},
-			expectError: true,
 		}),
-		Entry("Prefix missing", testCaseRenderMetaData{
-			m3d: &infrav1.Metal3Data{
-				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
-				Spec: infrav1.Metal3DataSpec{
-					Index: 2,
+		Entry("Ignition spec 2 without storage", testCaseRenderIgnitionUserData{
+			Bootstrap: `{"ignition":{"version":"2.3.0"}}`,
+			ExpectedFiles: []interface{}{
+				map[string]interface{}{
+					"filesystem": "root",
+					"path":       "/etc/motd",
+					"contents":   map[string]interface{}{"source": "data:;base64,V2VsY29tZQ=="},
+					"mode":       float64(0o644),
 				},
 			},
-			m3dt: &infrav1.Metal3DataTemplate{
-				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
+		}),
+		Entry("Invalid ignition config", testCaseRenderIgnitionUserData{
+			Bootstrap:   "#cloud-config\n",
+			ExpectError: true,
+		}),
+	)
+
+	type testCaseCheckUserDataSize struct {
+		Threshold       int
+		UserData        []byte
+		ExpectCondition bool
+		ExpectTooLarge  bool
+	}
+
+	DescribeTable("Test checkUserDataSize",
+		func(tc testCaseCheckUserDataSize) {
+			defer func(threshold int) {
+				UserDataSizeThreshold = threshold
+			}(UserDataSizeThreshold)
+			UserDataSizeThreshold = tc.Threshold
+
+			m3d := &infrav1.Metal3Data{}
+			dataMgr, err := NewDataManager(nil, m3d, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+			dataMgr.checkUserDataSize(tc.UserData)
+
+			cond := conditions.Get(m3d, infrav1.UserDataSizeCondition)
+			if !tc.ExpectCondition {
+				Expect(cond).To(BeNil())
+				return
+			}
+			Expect(cond).NotTo(BeNil())
+			if tc.ExpectTooLarge {
+				Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(infrav1.UserDataTooLargeReason))
+				Expect(cond.Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+			} else {
+				Expect(cond.Status).To(Equal(corev1.ConditionTrue))
+			}
+		},
+		Entry("Userdata under the threshold", testCaseCheckUserDataSize{
+			Threshold:       8,
+			UserData:        []byte("userdata"),
+			ExpectCondition: true,
+		}),
+		Entry("Userdata over the threshold", testCaseCheckUserDataSize{
+			Threshold:       7,
+			UserData:        []byte("userdata"),
+			ExpectCondition: true,
+			ExpectTooLarge:  true,
+		}),
+		Entry("Check disabled", testCaseCheckUserDataSize{
+			UserData: []byte("userdata"),
+		}),
+	)
+
+	type testCaseGetConfigMapRoutes struct {
+		Networks       infrav1.NetworkDataNetwork
+		HostName       string
+		ExpectedRoutes map[infrav1.RoutesFromConfigMap][]configMapRoute
+		ExpectError    bool
+		ExpectRequeue  bool
+	}
+
+	DescribeTable("Test getConfigMapRoutes",
+		func(tc testCaseGetConfigMapRoutes) {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: namespaceName},
+				Data: map[string]string{
+					"default": "- destination: 10.10.0.0/16\n  gateway: 192.168.0.1\n" +
+						"- destination: 10.20.0.0/16\n  gateway: 192.168.0.1\n",
+					"ipv6":            "- destination: 2001:db8::/32\n  gateway: fd00::1\n",
+					baremetalhostName: "- destination: 10.30.0.0/16\n  gateway: 192.168.0.254\n",
+					"invalid":         "- destination: 10.10.0.0\n  gateway: 192.168.0.1\n",
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(configMap).Build()
+			dataMgr, err := NewDataManager(fakeClient, &infrav1.Metal3Data{}, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			m3dt := &infrav1.Metal3DataTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "abc", Namespace: namespaceName},
 				Spec: infrav1.Metal3DataTemplateSpec{
-					MetaData: &infrav1.MetaData{
-						PrefixesFromPool: []infrav1.FromPool{
-							{
-								Key:  "Address-1",
-								Name: "abc",
-							},
-						},
-					},
+					NetworkData: &infrav1.NetworkData{Networks: tc.Networks},
 				},
+			}
+			bmh := &bmov1alpha1.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Name: tc.HostName, Namespace: namespaceName},
+			}
+
+			routes, err := dataMgr.getConfigMapRoutes(context.TODO(), m3dt, bmh)
+			if tc.ExpectError || tc.ExpectRequeue {
+				Expect(err).To(HaveOccurred())
+				var reconcileError ReconcileError
+				Expect(errors.As(err, &reconcileError) && reconcileError.IsTransient()).To(Equal(tc.ExpectRequeue))
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(routes).To(Equal(tc.ExpectedRoutes))
+		},
+		Entry("No routes from a ConfigMap", testCaseGetConfigMapRoutes{
+			Networks: infrav1.NetworkDataNetwork{
+				IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{{ID: "abc", Link: "eth0"}},
 			},
-			expectError: true,
+			HostName:       "other-host",
+			ExpectedRoutes: map[infrav1.RoutesFromConfigMap][]configMapRoute{},
 		}),
-		Entry("Gateway missing", testCaseRenderMetaData{
-			m3d: &infrav1.Metal3Data{
-				ObjectMeta: testObjectMeta("data-abc", namespaceName, ""),
-				Spec: infrav1.Metal3DataSpec{
-					Index: 2,
+		Entry("Routes of the key", testCaseGetConfigMapRoutes{
+			Networks: infrav1.NetworkDataNetwork{
+				IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{{
+					ID: "abc", Link: "eth0",
+					RoutesFromConfigMap: &infrav1.RoutesFromConfigMap{Name: "routes", Key: "default"},
+				}},
+				IPv6SLAAC: []infrav1.NetworkDataIPv6DHCP{{
+					ID: "def", Link: "eth0",
+					RoutesFromConfigMap: &infrav1.RoutesFromConfigMap{Name: "routes", Key: "ipv6"},
+				}},
+			},
+			HostName: "other-host",
+			ExpectedRoutes: map[infrav1.RoutesFromConfigMap][]configMapRoute{
+				{Name: "routes", Key: "default"}: {
+					{Destination: "10.10.0.0/16", Gateway: "192.168.0.1"},
+					{Destination: "10.20.0.0/16", Gateway: "192.168.0.1"},
+				},
+				{Name: "routes", Key: "ipv6"}: {
+					{Destination: "2001:db8::/32", Gateway: "fd00::1"},
 				},
 			},
-			m3dt: &infrav1.Metal3DataTemplate{
-				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
-				Spec: infrav1.Metal3DataTemplateSpec{
-					MetaData: &infrav1.MetaData{
-						GatewaysFromPool: []infrav1.FromPool{
-							{
-								Key:  "Address-1",
-								Name: "abc",
-							},
-						},
-					},
+		}),
+		Entry("Routes of the key named after the host", testCaseGetConfigMapRoutes{
+			Networks: infrav1.NetworkDataNetwork{
+				IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{{
+					ID: "abc", Link: "eth0",
+					RoutesFromConfigMap: &infrav1.RoutesFromConfigMap{Name: "routes", Key: "default"},
+				}},
+			},
+			HostName: baremetalhostName,
+			ExpectedRoutes: map[infrav1.RoutesFromConfigMap][]configMapRoute{
+				{Name: "routes", Key: "default"}: {
+					{Destination: "10.30.0.0/16", Gateway: "192.168.0.254"},
 				},
 			},
-			expectError: true,
 		}),
-		Entry("Wrong object in name", testCaseRenderMetaData{
-			m3dt: &infrav1.Metal3DataTemplate{
-				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
-				Spec: infrav1.Metal3DataTemplateSpec{
-					MetaData: &infrav1.MetaData{
-						ObjectNames: []infrav1.MetaDataObjectName{
-							{
-								Key:    "ObjectName-3",
-								Object: "baremetalhost2",
-							},
-						},
+		Entry("Missing ConfigMap", testCaseGetConfigMapRoutes{
+			Networks: infrav1.NetworkDataNetwork{
+				IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{{
+					ID: "abc", Link: "eth0",
+					RoutesFromConfigMap: &infrav1.RoutesFromConfigMap{Name: "missing", Key: "default"},
+				}},
+			},
+			HostName:      "other-host",
+			ExpectRequeue: true,
+		}),
+		Entry("Missing ConfigMap key", testCaseGetConfigMapRoutes{
+			Networks: infrav1.NetworkDataNetwork{
+				IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{{
+					ID: "abc", Link: "eth0",
+					RoutesFromConfigMap: &infrav1.RoutesFromConfigMap{Name: "routes", Key: "missing"},
+				}},
+			},
+			HostName:    "other-host",
+			ExpectError: true,
+		}),
+		Entry("Invalid routes", testCaseGetConfigMapRoutes{
+			Networks: infrav1.NetworkDataNetwork{
+				IPv4DHCP: []infrav1.NetworkDataIPv4DHCP{{
+					ID: "abc", Link: "eth0",
+					RoutesFromConfigMap: &infrav1.RoutesFromConfigMap{Name: "routes", Key: "invalid"},
+				}},
+			},
+			HostName:    "other-host",
+			ExpectError: true,
+		}),
+	)
+
+	DescribeTable("Test parseConfigMapRoutes",
+		func(content string, expectedRoutes []configMapRoute, expectError bool) {
+			routes, err := parseConfigMapRoutes(content)
+			if expectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(routes).To(Equal(expectedRoutes))
+		},
+		Entry("Empty", "", []configMapRoute{}, false),
+		Entry("IPv4 and IPv6 routes",
+			"- destination: 10.10.0.0/16\n  gateway: 192.168.0.1\n- destination: 2001:db8::/32\n  gateway: fd00::1\n",
+			[]configMapRoute{
+				{Destination: "10.10.0.0/16", Gateway: "192.168.0.1"},
+				{Destination: "2001:db8::/32", Gateway: "fd00::1"},
+			}, false,
+		),
+		Entry("Destination without a prefix", "- destination: 10.10.0.0\n  gateway: 192.168.0.1\n", nil, true),
+		Entry("Missing gateway", "- destination: 10.10.0.0/16\n", nil, true),
+		Entry("Invalid gateway", "- destination: 10.10.0.0/16\n  gateway: gw\n", nil, true),
+		Entry("Gateway of another family", "- destination: 10.10.0.0/16\n  gateway: fd00::1\n", nil, true),
+		Entry("Unknown field", "- destination: 10.10.0.0/16\n  gateway: 192.168.0.1\n  metric: 10\n", nil, true),
+		Entry("Not a list", "destination: 10.10.0.0/16\n", nil, true),
+	)
+
+	type testCaseGetCluster struct {
+		machine     *clusterv1.Machine
+		cluster     *clusterv1.Cluster
+		expectError bool
+	}
+
+	DescribeTable("Test getCluster",
+		func(tc testCaseGetCluster) {
+			objects := []client.Object{}
+			if tc.cluster != nil {
+				objects = append(objects, tc.cluster)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(objects...).Build()
+			dataMgr, err := NewDataManager(fakeClient, &infrav1.Metal3Data{}, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := dataMgr.getCluster(context.TODO(), tc.machine)
+			if tc.expectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Name).To(Equal(tc.cluster.Name))
+			Expect(result.Namespace).To(Equal(tc.cluster.Namespace))
+		},
+		Entry("Machine with cluster label", testCaseGetCluster{
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      machineName,
+					Namespace: namespaceName,
+					Labels: map[string]string{
+						clusterv1.ClusterNameLabel: clusterName,
 					},
 				},
 			},
-			expectError: true,
+			cluster: newCluster(clusterName),
 		}),
-		Entry("Wrong object in Label", testCaseRenderMetaData{
-			m3dt: &infrav1.Metal3DataTemplate{
-				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
-				Spec: infrav1.Metal3DataTemplateSpec{
-					MetaData: &infrav1.MetaData{
-						FromLabels: []infrav1.MetaDataFromLabel{
-							{
-								Key:    "ObjectName-3",
-								Object: "baremetalhost2",
-								Label:  "abc",
-							},
-						},
-					},
+		Entry("Machine without cluster label", testCaseGetCluster{
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      machineName,
+					Namespace: namespaceName,
 				},
 			},
+			cluster:     newCluster(clusterName),
 			expectError: true,
 		}),
-		Entry("Wrong object in Annotation", testCaseRenderMetaData{
-			m3dt: &infrav1.Metal3DataTemplate{
-				ObjectMeta: testObjectMeta(metal3DataTemplateName+"-abc", "", ""),
-				Spec: infrav1.Metal3DataTemplateSpec{
-					MetaData: &infrav1.MetaData{
-						FromAnnotations: []infrav1.MetaDataFromAnnotation{
-							{
-								Key:        "ObjectName-3",
-								Object:     "baremetalhost2",
-								Annotation: "abc",
-							},
-						},
+		Entry("Cluster not found", testCaseGetCluster{
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      machineName,
+					Namespace: namespaceName,
+					Labels: map[string]string{
+						clusterv1.ClusterNameLabel: clusterName,
 					},
 				},
 			},
@@ -3750,6 +6081,34 @@ var _ = Describe("Metal3Data manager", func() {
 		}),
 	)
 
+	DescribeTable("Test hostInterfaceNames",
+		func(bmh *bmov1alpha1.BareMetalHost, expectedNames map[string]string, expectError bool) {
+			interfaceNames, err := hostInterfaceNames(bmh)
+			if expectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(interfaceNames).To(Equal(expectedNames))
+		},
+		Entry("No host", nil, nil, false),
+		Entry("No annotation", newInterfaceNamesHost(""), nil, false),
+		Entry("Interface names",
+			newInterfaceNamesHost("12:34:56:78:9a:bc=data0, 12:34:56:78:9A:BD=data1"),
+			map[string]string{"data0": "12:34:56:78:9A:BC", "data1": "12:34:56:78:9A:BD"}, false,
+		),
+		Entry("MAC address not on the host", newInterfaceNamesHost("12:34:56:78:9A:BE=data0"), nil, true),
+		Entry("Missing name", newInterfaceNamesHost("12:34:56:78:9A:BC"), nil, true),
+		Entry("Duplicate name",
+			newInterfaceNamesHost("12:34:56:78:9A:BC=data0,12:34:56:78:9A:BD=data0"), nil, true,
+		),
+		Entry("NICs not known", &bmov1alpha1.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{InterfaceNamesAnnotation: "12:34:56:78:9A:BC=data0"},
+			},
+		}, nil, true),
+	)
+
 	type testCaseGetM3Machine struct {
 		Machine       *infrav1.Metal3Machine
 		Data          *infrav1.Metal3Data
@@ -3890,6 +6249,29 @@ var _ = Describe("Metal3Data manager", func() {
 			},
 			ExpectEmpty: true,
 		}),
+		Entry("Object exists, default dataTemplate of the cluster", testCaseGetM3Machine{
+			Machine: &infrav1.Metal3Machine{
+				ObjectMeta: testObjectMeta(metal3machineName, namespaceName, m3muid),
+			},
+			DataTemplate: &infrav1.Metal3DataTemplate{
+				ObjectMeta: testObjectMeta(metal3DataTemplateName, namespaceName, m3dtuid),
+			},
+			Data: &infrav1.Metal3Data{
+				ObjectMeta: testObjectMetaWithOR(metal3DataName, metal3machineName),
+				Spec: infrav1.Metal3DataSpec{
+					Claim: *testObjectReference(metal3DataClaimName),
+				},
+			},
+			DataClaim: &infrav1.Metal3DataClaim{
+				ObjectMeta: testObjectMetaWithOR(metal3DataClaimName, metal3machineName),
+				Spec: infrav1.Metal3DataClaimSpec{
+					Template: corev1.ObjectReference{
+						Name:      metal3DataTemplateName,
+						Namespace: namespaceName,
+					},
+				},
+			},
+		}),
 		Entry("Object exists, dataTemplate name mismatch", testCaseGetM3Machine{
 			Machine: &infrav1.Metal3Machine{
 				ObjectMeta: testObjectMeta(metal3machineName, namespaceName, m3muid),
@@ -4188,3 +6570,23 @@ var _ = Describe("When using BMH name based pre-allocation", func() {
 	)
 
 })
+
+// newInterfaceNamesHost returns an inspected host with the eth0 and eth1 NICs
+// and the given InterfaceNamesAnnotation.
+func newInterfaceNamesHost(interfaceNames string) *bmov1alpha1.BareMetalHost {
+	bmh := &bmov1alpha1.BareMetalHost{
+		ObjectMeta: testObjectMeta(baremetalhostName, namespaceName, ""),
+		Status: bmov1alpha1.BareMetalHostStatus{
+			HardwareDetails: &bmov1alpha1.HardwareDetails{
+				NIC: []bmov1alpha1.NIC{
+					{Name: "eth0", MAC: "12:34:56:78:9A:BC"},
+					{Name: "eth1", MAC: "12:34:56:78:9A:BD"},
+				},
+			},
+		},
+	}
+	if interfaceNames != "" {
+		bmh.Annotations = map[string]string{InterfaceNamesAnnotation: interfaceNames}
+	}
+	return bmh
+}
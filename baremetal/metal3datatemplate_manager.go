@@ -56,7 +56,7 @@ type DataTemplateManager struct {
 func NewDataTemplateManager(client client.Client,
 	dataTemplate *infrav1.Metal3DataTemplate, dataTemplateLog logr.Logger) (*DataTemplateManager, error) {
 	return &DataTemplateManager{
-		client:       client,
+		client:       managerClient(client, dataTemplateLog),
 		DataTemplate: dataTemplate,
 		Log:          dataTemplateLog,
 	}, nil
@@ -133,6 +133,11 @@ func (m *DataTemplateManager) getIndexes(ctx context.Context) (map[int]string, e
 		}
 
 		claimName := dataObject.Spec.Claim.Name
+		if otherClaim, ok := indexes[dataObject.Spec.Index]; ok && otherClaim != claimName {
+			m.Log.Info("Duplicate index found", "index", dataObject.Spec.Index,
+				"Claim", claimName, "other Claim", otherClaim,
+			)
+		}
 		m.DataTemplate.Status.Indexes[claimName] = dataObject.Spec.Index
 		indexes[dataObject.Spec.Index] = claimName
 	}
@@ -251,6 +256,10 @@ func (m *DataTemplateManager) createData(ctx context.Context,
 		} else {
 			dataName = m.DataTemplate.Name + "-" + strconv.Itoa(dataClaimIndex)
 		}
+		if err := m.checkIndexAvailable(ctx, dataClaim, dataClaimIndex, dataName, indexes); err != nil {
+			dataClaim.Status.ErrorMessage = ptr.To(err.Error())
+			return indexes, err
+		}
 
 		dataClaim.Status.RenderedData = &corev1.ObjectReference{
 			Name:      dataName,
@@ -298,6 +307,10 @@ func (m *DataTemplateManager) createData(ctx context.Context,
 		dataName = m.DataTemplate.Name + "-" + strconv.Itoa(claimIndex)
 	}
 	m.Log.Info("Index", "Claim", dataClaim.Name, "index", claimIndex)
+	if err := m.checkIndexAvailable(ctx, dataClaim, claimIndex, dataName, indexes); err != nil {
+		dataClaim.Status.ErrorMessage = ptr.To(err.Error())
+		return indexes, err
+	}
 
 	// Create the Metal3Data object, with an Owner ref to the Metal3Machine
 	// (curOwnerRef) and to the Metal3DataTemplate. Also add a finalizer.
@@ -369,6 +382,37 @@ func (m *DataTemplateManager) createData(ctx context.Context,
 	return indexes, nil
 }
 
+// checkIndexAvailable returns an error if the index is already claimed by
+// another Metal3DataClaim, either in the indexes of this template or through
+// an existing Metal3Data object of the same name rendered by another template.
+// Conflicts with Metal3Data objects of this template are left to the creation,
+// that requeues until the indexes are up to date.
+func (m *DataTemplateManager) checkIndexAvailable(ctx context.Context,
+	dataClaim *infrav1.Metal3DataClaim, index int, dataName string, indexes map[int]string,
+) error {
+	if claimName, ok := indexes[index]; ok && claimName != dataClaim.Name {
+		return errors.Errorf("index %d is already claimed by %s", index, claimName)
+	}
+
+	dataObject := &infrav1.Metal3Data{}
+	key := client.ObjectKey{
+		Name:      dataName,
+		Namespace: m.DataTemplate.Namespace,
+	}
+	err := m.client.Get(ctx, key, dataObject)
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if dataObject.Spec.Claim.Name != dataClaim.Name && !m.dataObjectBelongsToTemplate(*dataObject) {
+		return errors.Errorf("index %d is already claimed by %s through %s",
+			index, dataObject.Spec.Claim.Name, dataObject.Spec.Template.Name,
+		)
+	}
+	return nil
+}
+
 // deleteData deletes the Metal3DataClaim and marks the Metal3Data for deletion.
 func (m *DataTemplateManager) deleteData(ctx context.Context,
 	dataClaim *infrav1.Metal3DataClaim, indexes map[int]string,
@@ -742,6 +742,64 @@ var _ = Describe("Metal3DataTemplate manager", func() {
 			expectedDatas:   []string{"abc-0"},
 			expectRequeue:   true,
 		}),
+		Entry("Already exists, duplicate index", testCaseCreateAddresses{
+			template: &infrav1.Metal3DataTemplate{
+				ObjectMeta: templateMeta,
+				Status: infrav1.Metal3DataTemplateStatus{
+					Indexes: map[string]int{
+						metal3DataClaimName: 0,
+						"bcd":               0,
+					},
+				},
+			},
+			indexes: map[int]string{0: "bcd"},
+			dataClaim: &infrav1.Metal3DataClaim{
+				ObjectMeta: testObjectMetaWithOR(metal3DataClaimName, metal3machineName),
+			},
+			expectedIndexes: map[string]int{
+				metal3DataClaimName: 0,
+				"bcd":               0,
+			},
+			expectedMap: map[int]string{
+				0: "bcd",
+			},
+			expectError: true,
+		}),
+		Entry("Not allocated yet, index claimed by another template", testCaseCreateAddresses{
+			template: &infrav1.Metal3DataTemplate{
+				ObjectMeta: templateMeta,
+				Spec:       infrav1.Metal3DataTemplateSpec{},
+				Status: infrav1.Metal3DataTemplateStatus{
+					Indexes: map[string]int{},
+				},
+			},
+			indexes: map[int]string{},
+			dataClaim: &infrav1.Metal3DataClaim{
+				ObjectMeta: testObjectMetaWithOR(metal3DataClaimName, metal3machineName),
+			},
+			datas: []*infrav1.Metal3Data{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "abc-0",
+						Namespace: namespaceName,
+					},
+					Spec: infrav1.Metal3DataSpec{
+						Index:             0,
+						TemplateReference: "abc",
+						Template: corev1.ObjectReference{
+							Name: "def",
+						},
+						Claim: corev1.ObjectReference{
+							Name: "bcd",
+						},
+					},
+				},
+			},
+			expectedIndexes: map[string]int{},
+			expectedMap:     map[int]string{},
+			expectedDatas:   []string{"abc-0"},
+			expectError:     true,
+		}),
 	)
 
 	type testCaseDeleteDatas struct {
@@ -17,12 +17,20 @@ limitations under the License.
 package baremetal
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -35,6 +43,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -42,8 +51,10 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
@@ -61,6 +72,21 @@ const (
 	// HostAnnotation is the key for an annotation that should go on a Metal3Machine to
 	// reference what BareMetalHost it corresponds to.
 	HostAnnotation = "metal3.io/BareMetalHost"
+	// ClusterNameAnnotation is the annotation set on a BMH holding the name of
+	// the cluster of the Metal3Machine it is associated with.
+	ClusterNameAnnotation = "metal3.io/cluster-name"
+	// LastMachineAnnotation is the annotation set on a BMH holding the
+	// namespaced name of the last Machine it was associated with. It is kept
+	// once the host is released, so that a Machine re-created with the same
+	// name prefers the host.
+	LastMachineAnnotation = "metal3.io/last-machine"
+	// PinnedImageAnnotation is the annotation set on a BMH holding the URL of
+	// the image it is always provisioned with, overriding the image of the
+	// Metal3Machine, e.g. for a hardware-specific driver image.
+	PinnedImageAnnotation = "metal3.io/pinned-image"
+	// PinnedImageChecksumAnnotation is the annotation set on a BMH holding the
+	// checksum of its pinned image.
+	PinnedImageChecksumAnnotation = "metal3.io/pinned-image-checksum"
 	// nodeReuseLabelName is the label set on BMH when node reuse feature is enabled.
 	nodeReuseLabelName = "infrastructure.cluster.x-k8s.io/node-reuse"
 	requeueAfter       = time.Second * 30
@@ -72,11 +98,115 @@ const (
 	ProviderIDPrefix = "metal3://"
 	// ProviderLabelPrefix is a label prefix for ProviderID.
 	ProviderLabelPrefix = "metal3.io/uuid"
+	// ReconcileNowAnnotation is the annotation set on a Metal3Machine to
+	// trigger a reconcile immediately. It is removed by the controller.
+	ReconcileNowAnnotation = "metal3.io/reconcile-now"
+	// DesiredPowerAnnotation is the annotation set on a Metal3Machine to power
+	// its BMH off or on while keeping it provisioned.
+	DesiredPowerAnnotation = "metal3.io/desired-power"
+	// DesiredPowerOff is the DesiredPowerAnnotation value to power the BMH off.
+	DesiredPowerOff = "off"
+	// DesiredPowerOn is the DesiredPowerAnnotation value to power the BMH on.
+	DesiredPowerOn = "on"
+	// PreDeprovisionHookAnnotation is the annotation set on a Metal3Machine
+	// once the pre-deprovision hook was called for its BMH, holding the outcome.
+	PreDeprovisionHookAnnotation = "metal3.io/pre-deprovision-hook"
+	// PreDeprovisionHookSucceeded is the PreDeprovisionHookAnnotation value when
+	// the hook succeeded.
+	PreDeprovisionHookSucceeded = "succeeded"
+	// PreDeprovisionHookFailedOpen is the PreDeprovisionHookAnnotation value when
+	// the hook failed and deprovisioning proceeded anyway.
+	PreDeprovisionHookFailedOpen = "failed-open"
+	// ReassociateAnnotation is the annotation set on a Metal3Machine, by the
+	// Reassociate remediation strategy, to release its fenced BMH and associate
+	// it with another one. Its value is the name of the fenced BMH, which is
+	// not chosen again.
+	ReassociateAnnotation = "metal3.io/reassociate"
+	// HostReferenceLostReclaim and HostReferenceLostFail are the values of
+	// HostReferenceLostPolicy.
+	HostReferenceLostReclaim = "Reclaim"
+	HostReferenceLostFail    = "Fail"
+	// BareReleaseAnnotation is the annotation set on a Metal3Machine to release
+	// its BMH on deletion without deprovisioning it, keeping the installed
+	// operating system for a manual re-use of the host.
+	BareReleaseAnnotation = "metal3.io/bare-release"
 )
 
 var (
 	// Capm3FastTrack is the variable fetched from the CAPM3_FAST_TRACK environment variable.
-	Capm3FastTrack    = os.Getenv("CAPM3_FAST_TRACK")
+	Capm3FastTrack = os.Getenv("CAPM3_FAST_TRACK")
+	// PowerOnGracePeriod is the duration a provisioned BareMetalHost must have
+	// been reported as powered on before the machine is considered running.
+	PowerOnGracePeriod time.Duration
+	// MachineFinalizer is the finalizer set on the Metal3Machines and their
+	// Metal3DataClaims. Instances sharing the resources must use distinct values.
+	MachineFinalizer = infrav1.MachineFinalizer
+	// PreDeprovisionHookURL is the URL called before a BareMetalHost is
+	// deprovisioned. The hook is disabled when empty.
+	PreDeprovisionHookURL string
+	// PreDeprovisionHookTimeout is the timeout of the pre-deprovision hook call.
+	PreDeprovisionHookTimeout = 30 * time.Second
+	// PreDeprovisionHookFailOpen lets deprovisioning proceed when the
+	// pre-deprovision hook fails, instead of retrying the hook.
+	PreDeprovisionHookFailOpen bool
+	// DeprovisionTimeout is the duration after which a BareMetalHost still
+	// deprovisioning is reported as stuck. Zero disables the timeout.
+	DeprovisionTimeout time.Duration
+	// ForceDeprovisionOnTimeout disables automated cleaning on the
+	// BareMetalHosts hitting DeprovisionTimeout, so that they become available.
+	ForceDeprovisionOnTimeout bool
+	// HostReservationTTL is the duration after which a BareMetalHost associated
+	// with a Metal3Machine but not being provisioned is released. Zero disables
+	// the release.
+	HostReservationTTL time.Duration
+	// HostReferenceLostPolicy is the behavior when the consumerRef of the
+	// BareMetalHost a Metal3Machine is annotated with no longer references it,
+	// e.g. after being cleared manually. Reclaim, the default, sets it again
+	// if the host is not consumed by anything else, Fail marks the
+	// Metal3Machine failed.
+	HostReferenceLostPolicy = HostReferenceLostReclaim
+	// MaxProvisioningAttempts is the number of provisioning attempts of a
+	// BareMetalHost after which a failed provisioning marks its Metal3Machine
+	// failed, rather than letting the BareMetal Operator retry it. Zero
+	// disables the limit.
+	MaxProvisioningAttempts int
+	// HostErrorGracePeriod is the duration a BareMetalHost must have been in
+	// an error state continuously before its Metal3Machine is marked failed.
+	// Zero disables marking the Metal3Machine failed.
+	HostErrorGracePeriod time.Duration
+	// HostAnnotationLabels is the list of Machine labels copied as annotations
+	// onto the associated BareMetalHost and kept in sync.
+	HostAnnotationLabels []string
+	// DetectImageDiskFormat enables inferring the disk format of the images that
+	// do not set it from the extension of their URL.
+	DetectImageDiskFormat = true
+	// HostRemediationCooldown is the duration after a remediation, according to
+	// RemediatedAtAnnotation, during which an available BareMetalHost is not
+	// chosen for a Metal3Machine. Zero disables the cooldown.
+	HostRemediationCooldown time.Duration
+	// NodeIPFallback enables resolving the node of a Metal3Machine by its
+	// InternalIP addresses, matched against the internal IP addresses of the
+	// Metal3Machine, when no node has the label of its BareMetalHost.
+	NodeIPFallback bool
+	// DisqualifyingHostAnnotations is the list of annotation keys excluding the
+	// BareMetalHosts carrying any of them from being chosen for a Metal3Machine.
+	DisqualifyingHostAnnotations []string
+	// MachineTopologyLabels maps the keys of Machine labels, e.g. set through
+	// the topology of a ClusterClass, to the keys of the BareMetalHost labels
+	// holding the same topology, e.g. the zone. Only the hosts with the value
+	// of the Machine label are chosen for its Metal3Machine.
+	MachineTopologyLabels map[string]string
+	// FailureDomainHostLabel is the key of the BareMetalHost label holding the
+	// failure domain of the host. When set, only the hosts in the failure
+	// domain of the Machine, if any, are chosen for its Metal3Machine.
+	FailureDomainHostLabel string
+	// PostDeprovisionPower is the power state, on or off, the BareMetalHosts
+	// are left in once deprovisioned. When empty, it depends on
+	// AutomatedCleaningMode and Capm3FastTrack.
+	PostDeprovisionPower string
+	// EventRecorder records the events of the managers. No events are
+	// recorded when nil.
+	EventRecorder     record.EventRecorder
 	notFoundErr       *NotFoundError
 	associateBMHMutex sync.Mutex
 )
@@ -86,7 +216,9 @@ type MachineManagerInterface interface {
 	SetFinalizer()
 	UnsetFinalizer()
 	IsProvisioned() bool
+	PowerScheduleRequeueAfter() time.Duration
 	IsBootstrapReady() bool
+	IsBootstrapDataAvailable(context.Context) (bool, error)
 	GetBaremetalHostID(context.Context) (*string, error)
 	Associate(context.Context) error
 	Delete(context.Context) error
@@ -94,6 +226,7 @@ type MachineManagerInterface interface {
 	HasAnnotation() bool
 	GetProviderIDAndBMHID() (string, *string)
 	SetNodeProviderID(context.Context, *string, ClientGetter) error
+	SetNodeRole(context.Context, string, ClientGetter) error
 	SetProviderID(string)
 	SetPauseAnnotation(context.Context) error
 	RemovePauseAnnotation(context.Context) error
@@ -117,6 +250,10 @@ type MachineManager struct {
 	MachineSet            *clusterv1.MachineSet
 	MachineSetList        *clusterv1.MachineSetList
 	Log                   logr.Logger
+
+	// hostBefore is a copy of the host taken when its patch helper was
+	// created, to audit the changes patched on the host.
+	hostBefore *bmov1alpha1.BareMetalHost
 }
 
 // NewMachineManager returns a new helper for managing a machine.
@@ -125,7 +262,7 @@ func NewMachineManager(client client.Client,
 	machine *clusterv1.Machine, metal3machine *infrav1.Metal3Machine,
 	machineLog logr.Logger) (*MachineManager, error) {
 	return &MachineManager{
-		client: client,
+		client: managerClient(client, machineLog),
 
 		Cluster:       cluster,
 		Metal3Cluster: metal3Cluster,
@@ -140,7 +277,7 @@ func NewMachineSetManager(client client.Client,
 	machine *clusterv1.Machine, machineSetList *clusterv1.MachineSetList,
 	machineLog logr.Logger) (*MachineManager, error) {
 	return &MachineManager{
-		client:         client,
+		client:         managerClient(client, machineLog),
 		Machine:        machine,
 		MachineSetList: machineSetList,
 		Log:            machineLog,
@@ -150,15 +287,15 @@ func NewMachineSetManager(client client.Client,
 // SetFinalizer sets finalizer.
 func (m *MachineManager) SetFinalizer() {
 	// If the Metal3Machine doesn't have finalizer, add it.
-	if !controllerutil.ContainsFinalizer(m.Metal3Machine, infrav1.MachineFinalizer) {
-		controllerutil.AddFinalizer(m.Metal3Machine, infrav1.MachineFinalizer)
+	if !controllerutil.ContainsFinalizer(m.Metal3Machine, MachineFinalizer) {
+		controllerutil.AddFinalizer(m.Metal3Machine, MachineFinalizer)
 	}
 }
 
 // UnsetFinalizer unsets finalizer.
 func (m *MachineManager) UnsetFinalizer() {
 	// Cluster is deleted so remove the finalizer.
-	controllerutil.RemoveFinalizer(m.Metal3Machine, infrav1.MachineFinalizer)
+	controllerutil.RemoveFinalizer(m.Metal3Machine, MachineFinalizer)
 }
 
 // IsProvisioned checks if the metal3machine is provisioned.
@@ -178,6 +315,23 @@ func (m *MachineManager) IsBootstrapReady() bool {
 	return m.Machine.Spec.Bootstrap.DataSecretName != nil
 }
 
+// IsBootstrapDataAvailable checks if the bootstrap data secret of the machine
+// exists, so that the host is not provisioned with incomplete user data.
+func (m *MachineManager) IsBootstrapDataAvailable(ctx context.Context) (bool, error) {
+	if m.Machine.Spec.Bootstrap.DataSecretName == nil {
+		return false, nil
+	}
+	_, err := checkSecretExists(ctx, m.client, *m.Machine.Spec.Bootstrap.DataSecretName,
+		m.Machine.Namespace,
+	)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to get the bootstrap data secret")
+	}
+	return true, nil
+}
+
 // isControlPlane returns true if the machine is a control plane.
 func (m *MachineManager) isControlPlane() bool {
 	return util.IsControlPlaneMachine(m.Machine)
@@ -218,6 +372,7 @@ func (m *MachineManager) RemovePauseAnnotation(ctx context.Context) error {
 			}
 		}
 	}
+	m.auditHostPatch(host)
 	return helper.Patch(ctx, host)
 }
 
@@ -268,6 +423,7 @@ func (m *MachineManager) SetPauseAnnotation(ctx context.Context) error {
 		return errors.Wrap(err, "failed to marshall status annotation")
 	}
 	host.Annotations[bmov1alpha1.StatusAnnotation] = string(newAnnotation)
+	m.auditHostPatch(host)
 	return helper.Patch(ctx, host)
 }
 
@@ -283,12 +439,46 @@ func (m *MachineManager) GetBaremetalHostID(ctx context.Context) (*string, error
 		m.Log.Info(errMessage)
 		return nil, WithTransientError(errors.New(errMessage), requeueAfter)
 	}
-	if host.Status.Provisioning.State == bmov1alpha1.StateProvisioned {
-		return ptr.To(string(host.ObjectMeta.UID)), nil
+	if host.Status.Provisioning.State != bmov1alpha1.StateProvisioned {
+		m.Log.Info("Provisioning BaremetalHost, requeuing")
+		// Do not requeue since BMH update will trigger a reconciliation
+		return nil, nil
 	}
-	m.Log.Info("Provisioning BaremetalHost, requeuing")
-	// Do not requeue since BMH update will trigger a reconciliation
-	return nil, nil
+	return m.checkHostPoweredOn(host)
+}
+
+// checkHostPoweredOn returns the BareMetalHost ID once the host has been
+// reported as powered on for at least its power-on grace period. Until then the
+// HostPoweredOn condition is set to false with WaitingForPowerOnReason.
+func (m *MachineManager) checkHostPoweredOn(host *bmov1alpha1.BareMetalHost) (*string, error) {
+	if !host.Status.PoweredOn {
+		m.SetConditionMetal3MachineToFalse(infrav1.HostPoweredOnCondition,
+			infrav1.WaitingForPowerOnReason, clusterv1.ConditionSeverityInfo,
+			"BareMetalHost %s is not powered on", host.Name,
+		)
+		m.Log.Info("BareMetalHost is not powered on, requeuing", "host", host.Name)
+		// Do not requeue since BMH update will trigger a reconciliation
+		return nil, nil
+	}
+
+	gracePeriod := m.powerOnGracePeriod()
+	if gracePeriod > 0 && !conditions.IsTrue(m.Metal3Machine, infrav1.HostPoweredOnCondition) {
+		// The transition time of the condition is only updated on a change of
+		// state, so it records when the host was first seen powered on.
+		m.SetConditionMetal3MachineToFalse(infrav1.HostPoweredOnCondition,
+			infrav1.WaitingForPowerOnReason, clusterv1.ConditionSeverityInfo,
+			"BareMetalHost %s is powered on, waiting for grace period", host.Name,
+		)
+		poweredOnSince := conditions.GetLastTransitionTime(m.Metal3Machine, infrav1.HostPoweredOnCondition)
+		if remaining := gracePeriod - time.Since(poweredOnSince.Time); remaining > 0 {
+			errMessage := "BareMetalHost power-on grace period not elapsed, requeuing"
+			m.Log.Info(errMessage, "host", host.Name, "remaining", remaining)
+			return nil, WithTransientError(errors.New(errMessage), remaining)
+		}
+	}
+
+	m.SetConditionMetal3MachineToTrue(infrav1.HostPoweredOnCondition)
+	return ptr.To(string(host.ObjectMeta.UID)), nil
 }
 
 // Associate associates a machine and is invoked by the Machine Controller.
@@ -313,6 +503,10 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 
 	// no BMH found, trying to choose from available ones
 	if host == nil {
+		// Do not reserve a host for an image that cannot be resolved.
+		if _, err = m.resolveImage(ctx); err != nil {
+			return err
+		}
 		host, helper, err = m.chooseHost(ctx)
 		if err != nil {
 			return err
@@ -323,6 +517,11 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 			return WithTransientError(errors.New(errMessage), requeueAfter)
 		}
 		m.Log.Info("Associating machine with host", "host", host.Name)
+		now := metav1.Now()
+		m.Metal3Machine.Status.AssociatedAt = &now
+		m.Metal3Machine.Status.ProvisioningDuration = nil
+		m.Metal3Machine.Status.ProvisioningAttempts = 0
+		m.Metal3Machine.Status.LastProvisioningStart = nil
 	} else {
 		m.Log.Info("Machine already associated with host", "host", host.Name)
 	}
@@ -332,15 +531,22 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 	m.getUserDataSecretName(ctx)
 
 	m.setHostLabel(ctx, host)
+	m.setHostAnnotationsFromLabels(host)
+	m.setLastMachineAnnotation(host)
 
 	err = m.setHostConsumerRef(ctx, host)
 	if err != nil {
 		return err
 	}
 
+	err = m.setHostBootMACAddress(host)
+	if err != nil {
+		return err
+	}
+
 	// If the user did not provide a DataTemplate, we can directly set the host
 	// specs, nothing to wait for.
-	if m.Metal3Machine.Spec.DataTemplate == nil {
+	if m.dataTemplate() == nil {
 		if err = m.setHostSpec(ctx, host); err != nil {
 			return err
 		}
@@ -350,7 +556,10 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	m.Metal3Machine.Status.BMCProtocol = bmcProtocol(host)
+	m.Metal3Machine.Status.BMCAddress = bmcAddress(host)
 
+	m.auditHostPatch(host)
 	err = helper.Patch(ctx, host)
 	if err != nil {
 		var aggr kerrors.Aggregate
@@ -368,8 +577,9 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	delete(m.Metal3Machine.Annotations, ReassociateAnnotation)
 
-	if m.Metal3Machine.Spec.DataTemplate != nil {
+	if m.dataTemplate() != nil {
 		// Requeue to get the DataTemplate output. We need to requeue to trigger the
 		// wait on the Metal3DataTemplate
 		if err := m.WaitForM3Metadata(ctx); err != nil {
@@ -390,6 +600,7 @@ func (m *MachineManager) Associate(ctx context.Context) error {
 		}
 
 		// Update the BMH object.
+		m.auditHostPatch(host)
 		err = helper.Patch(ctx, host)
 		if err != nil {
 			var aggr kerrors.Aggregate
@@ -417,8 +628,10 @@ func (m *MachineManager) getUserDataSecretName(_ context.Context) {
 		return
 	}
 
+	// The user data secret explicitly referenced is used as is.
 	if m.Metal3Machine.Spec.UserData != nil {
 		m.Metal3Machine.Status.UserData = m.Metal3Machine.Spec.UserData
+		return
 	}
 
 	// if datasecretname is set just pass the reference.
@@ -450,6 +663,10 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 	}
 	if host == nil {
 		m.Log.Info("host not found for metal3machine", "metal3machine", m.Metal3Machine.Name)
+		hostNamespace, hostName, err := cache.SplitMetaNamespaceKey(m.Metal3Machine.Annotations[HostAnnotation])
+		if err == nil && hostName != "" {
+			ForgetHostPowerCycles(hostNamespace, hostName)
+		}
 		return nil
 	}
 
@@ -468,101 +685,19 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 		}
 
 		// Remove clusterLabel from BMC secret.
-		tmpBMCSecret, errBMC := m.getBMCSecret(ctx, host)
-		if errBMC != nil && apierrors.IsNotFound(errBMC) {
-			m.Log.Info("BMC credential not found for BareMetalhost", "host", host.Name)
-		} else if errBMC == nil && tmpBMCSecret != nil {
-			m.Log.Info("Deleting cluster label from BMC credential", "bmccredential", host.Spec.BMC.CredentialsName)
-			if tmpBMCSecret.Labels != nil && tmpBMCSecret.Labels[clusterv1.ClusterNameLabel] == m.Machine.Spec.ClusterName {
-				delete(tmpBMCSecret.Labels, clusterv1.ClusterNameLabel)
-				errBMC = updateObject(ctx, m.client, tmpBMCSecret)
-				if errBMC != nil {
-					var reconcileError ReconcileError
-					if !(errors.As(errBMC, &reconcileError) && reconcileError.IsTransient()) {
-						m.Log.Info("Failed to delete the clusterLabel from BMC Secret")
-					}
-					return errBMC
-				}
-			}
-		}
-
-		bmhUpdated := false
-
-		if host.Spec.Image != nil {
-			host.Spec.Image = nil
-			bmhUpdated = true
-		}
-		if host.Spec.CustomDeploy != nil {
-			host.Spec.CustomDeploy = nil
-			bmhUpdated = true
-		}
-		if m.Metal3Machine.Status.UserData != nil && host.Spec.UserData != nil {
-			host.Spec.UserData = nil
-			bmhUpdated = true
-		}
-		if m.Metal3Machine.Status.MetaData != nil && host.Spec.MetaData != nil {
-			host.Spec.MetaData = nil
-			bmhUpdated = true
-		}
-		if m.Metal3Machine.Status.NetworkData != nil && host.Spec.NetworkData != nil {
-			host.Spec.NetworkData = nil
-			bmhUpdated = true
-		}
-
-		//	Change bmh's online status to on/off  based on AutomatedCleaningMode and Capm3FastTrack values
-		//	AutomatedCleaningMode |	Capm3FastTrack|   BMH
-		//		disabled				false 			turn off
-		//		disabled				true 			turn off
-		//		metadata				false 			turn off
-		//		metadata				true 			turn on
-
-		onlineStatus := host.Spec.Online
-
-		if host.Spec.AutomatedCleaningMode == "disabled" {
-			host.Spec.Online = false
-		} else if Capm3FastTrack == "true" {
-			host.Spec.Online = true
-		} else if Capm3FastTrack == "false" {
-			host.Spec.Online = false
-		}
-		m.Log.Info("Set host Online field by AutomatedCleaningMode",
-			"host", host.Name,
-			"automatedCleaningMode", host.Spec.AutomatedCleaningMode,
-			"hostSpecOnline", host.Spec.Online)
-
-		if onlineStatus != host.Spec.Online {
-			bmhUpdated = true
+		if err = m.removeBMCSecretLabel(ctx, host); err != nil {
+			return err
 		}
 
-		if bmhUpdated {
-			// Update the BMH object, if the errors are NotFound, do not return the
-			// errors.
-			if err := patchIfFound(ctx, helper, host); err != nil {
-				return err
-			}
-
-			errMessage := "Deprovisioning BareMetalHost, requeuing"
-			m.Log.Info(errMessage)
-			return WithTransientError(errors.New(errMessage), 0*time.Second)
-		}
-
-		waiting := true
-		switch host.Status.Provisioning.State {
-		case bmov1alpha1.StateRegistering,
-			bmov1alpha1.StateMatchProfile, bmov1alpha1.StateInspecting,
-			bmov1alpha1.StateReady, bmov1alpha1.StateAvailable, bmov1alpha1.StateNone,
-			bmov1alpha1.StateUnmanaged:
-			// Host is not provisioned.
-			waiting = false
-		case bmov1alpha1.StateExternallyProvisioned:
-			// We have no control over provisioning, so just wait until the
-			// host is powered off.
-			waiting = host.Status.PoweredOn
-		}
-		if waiting {
-			errMessage := "Deprovisioning BareMetalHost, requeuing"
-			m.Log.Info(errMessage)
-			return WithTransientError(errors.New(errMessage), requeueAfter)
+		if m.bareRelease() {
+			// The host keeps its image and power state, only the references
+			// to the data of the Metal3Machine are removed.
+			m.Log.Info("Releasing BareMetalHost without deprovisioning", "host", host.Name)
+			m.clearHostData(host)
+			m.recordEvent(corev1.EventTypeNormal, "BareReleased",
+				"Released BareMetalHost %s without deprovisioning", host.Name)
+		} else if err = m.deprovisionHost(ctx, host, helper); err != nil {
+			return err
 		}
 
 		if m.Cluster != nil {
@@ -629,8 +764,9 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 
 		host.Spec.ConsumerRef = nil
 
-		// Delete created secret, if data was set without DataSecretName
-		if m.Machine.Spec.Bootstrap.DataSecretName == nil {
+		// Delete created secret, if data was set without DataSecretName nor
+		// explicit user data secret
+		if m.Machine.Spec.Bootstrap.DataSecretName == nil && m.Metal3Machine.Spec.UserData == nil {
 			m.Log.Info("Deleting User data secret for machine")
 			if m.Metal3Machine.Status.UserData != nil {
 				err = deleteSecret(ctx, m.client, m.Metal3Machine.Status.UserData.Name,
@@ -650,9 +786,7 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 			return err
 		}
 
-		if host.Labels != nil && host.Labels[clusterv1.ClusterNameLabel] == m.Machine.Spec.ClusterName {
-			delete(host.Labels, clusterv1.ClusterNameLabel)
-		}
+		m.unsetHostLabel(host)
 
 		m.Log.Info("Removing Paused Annotation (if any)")
 		if host.Annotations != nil && host.Annotations[bmov1alpha1.PausedAnnotation] == PausedAnnotationKey {
@@ -661,14 +795,306 @@ func (m *MachineManager) Delete(ctx context.Context) error {
 
 		// Update the BMH object, if the errors are NotFound, do not return the
 		// errors.
+		m.auditHostPatch(host)
 		if err := patchIfFound(ctx, helper, host); err != nil {
 			return err
 		}
+		ForgetHostPowerCycles(host.Namespace, host.Name)
 	}
 	m.Log.Info("finished deleting metal3 machine")
 	return nil
 }
 
+// bareRelease returns true if the BareReleaseAnnotation is set on the
+// Metal3Machine, to release its host without deprovisioning it.
+func (m *MachineManager) bareRelease() bool {
+	_, ok := m.Metal3Machine.Annotations[BareReleaseAnnotation]
+	return ok
+}
+
+// clearHostData removes the references to the user data, meta data and
+// network data of the Metal3Machine from the host. It returns true if the
+// host was changed.
+func (m *MachineManager) clearHostData(host *bmov1alpha1.BareMetalHost) bool {
+	changed := false
+	if m.Metal3Machine.Status.UserData != nil && host.Spec.UserData != nil {
+		host.Spec.UserData = nil
+		changed = true
+	}
+	if m.Metal3Machine.Status.MetaData != nil && host.Spec.MetaData != nil {
+		host.Spec.MetaData = nil
+		changed = true
+	}
+	if m.Metal3Machine.Status.NetworkData != nil && host.Spec.NetworkData != nil {
+		host.Spec.NetworkData = nil
+		changed = true
+	}
+	return changed
+}
+
+// deprovisionHost deprovisions the host of the Metal3Machine being deleted. A
+// transient error is returned until the host is deprovisioned.
+func (m *MachineManager) deprovisionHost(ctx context.Context, host *bmov1alpha1.BareMetalHost,
+	helper *patch.Helper,
+) error {
+	// Wait for another host of the cluster to be deprovisioned before this
+	// one gets wiped, so that at most MaxConcurrentDeprovisions hosts are
+	// deprovisioned at once.
+	if MaxConcurrentDeprovisions > 0 && (host.Spec.Image != nil || host.Spec.CustomDeploy != nil) {
+		deprovisioning, err := countDeprovisioningHosts(ctx, m.client, host.Namespace, m.Machine.Spec.ClusterName)
+		if err != nil {
+			return err
+		}
+		if deprovisioning >= MaxConcurrentDeprovisions {
+			errMessage := "Too many BareMetalHosts deprovisioning, requeuing"
+			m.Log.Info(errMessage, "host", host.Name, "maxConcurrentDeprovisions", MaxConcurrentDeprovisions)
+			return WithTransientError(errors.New(errMessage), requeueAfter)
+		}
+	}
+
+	// Notify the pre-deprovision hook before the host gets wiped.
+	if err := m.runPreDeprovisionHook(ctx, host); err != nil {
+		return err
+	}
+
+	bmhUpdated := false
+
+	if host.Spec.Image != nil {
+		host.Spec.Image = nil
+		bmhUpdated = true
+	}
+	if host.Spec.CustomDeploy != nil {
+		host.Spec.CustomDeploy = nil
+		bmhUpdated = true
+	}
+	if m.clearHostData(host) {
+		bmhUpdated = true
+	}
+
+	//	Change bmh's online status to on/off  based on AutomatedCleaningMode and Capm3FastTrack values
+	//	AutomatedCleaningMode |	Capm3FastTrack|   BMH
+	//		disabled				false 			turn off
+	//		disabled				true 			turn off
+	//		metadata				false 			turn off
+	//		metadata				true 			turn on
+	//	unless a post-deprovision power state is configured.
+
+	onlineStatus := host.Spec.Online
+
+	if online, ok := m.postDeprovisionOnline(); ok {
+		host.Spec.Online = online
+		m.Log.Info("Set host Online field by post-deprovision power",
+			"host", host.Name,
+			"hostSpecOnline", host.Spec.Online)
+	} else {
+		if host.Spec.AutomatedCleaningMode == "disabled" {
+			host.Spec.Online = false
+		} else if Capm3FastTrack == "true" {
+			host.Spec.Online = true
+		} else if Capm3FastTrack == "false" {
+			host.Spec.Online = false
+		}
+		m.Log.Info("Set host Online field by AutomatedCleaningMode",
+			"host", host.Name,
+			"automatedCleaningMode", host.Spec.AutomatedCleaningMode,
+			"hostSpecOnline", host.Spec.Online)
+	}
+
+	if onlineStatus != host.Spec.Online {
+		recordHostPowerCycle(host)
+		bmhUpdated = true
+	}
+
+	if bmhUpdated {
+		// Update the BMH object, if the errors are NotFound, do not return the
+		// errors.
+		m.auditHostPatch(host)
+		if err := patchIfFound(ctx, helper, host); err != nil {
+			return err
+		}
+
+		errMessage := "Deprovisioning BareMetalHost, requeuing"
+		m.Log.Info(errMessage)
+		return WithTransientError(errors.New(errMessage), 0*time.Second)
+	}
+
+	waiting := true
+	switch host.Status.Provisioning.State {
+	case bmov1alpha1.StateRegistering,
+		bmov1alpha1.StateMatchProfile, bmov1alpha1.StateInspecting,
+		bmov1alpha1.StateReady, bmov1alpha1.StateAvailable, bmov1alpha1.StateNone,
+		bmov1alpha1.StateUnmanaged:
+		// Host is not provisioned.
+		waiting = false
+	case bmov1alpha1.StateExternallyProvisioned:
+		// We have no control over provisioning, so just wait until the
+		// host is powered off.
+		waiting = host.Status.PoweredOn
+	}
+	if waiting {
+		if host.Status.Provisioning.State == bmov1alpha1.StateDeprovisioning {
+			if err := m.checkDeprovisionTimeout(ctx, host, helper); err != nil {
+				return err
+			}
+		}
+		errMessage := "Deprovisioning BareMetalHost, requeuing"
+		m.Log.Info(errMessage)
+		return WithTransientError(errors.New(errMessage), requeueAfter)
+	}
+	return nil
+}
+
+// removeBMCSecretLabel removes the cluster label from the BMC secret of the
+// host, if the secret exists.
+func (m *MachineManager) removeBMCSecretLabel(ctx context.Context, host *bmov1alpha1.BareMetalHost) error {
+	tmpBMCSecret, errBMC := m.getBMCSecret(ctx, host)
+	if errBMC != nil && apierrors.IsNotFound(errBMC) {
+		m.Log.Info("BMC credential not found for BareMetalhost", "host", host.Name)
+	} else if errBMC == nil && tmpBMCSecret != nil {
+		m.Log.Info("Deleting cluster label from BMC credential", "bmccredential", host.Spec.BMC.CredentialsName)
+		if tmpBMCSecret.Labels != nil && tmpBMCSecret.Labels[clusterv1.ClusterNameLabel] == m.Machine.Spec.ClusterName {
+			delete(tmpBMCSecret.Labels, clusterv1.ClusterNameLabel)
+			errBMC = updateObject(ctx, m.client, tmpBMCSecret)
+			if errBMC != nil {
+				var reconcileError ReconcileError
+				if !(errors.As(errBMC, &reconcileError) && reconcileError.IsTransient()) {
+					m.Log.Info("Failed to delete the clusterLabel from BMC Secret")
+				}
+				return errBMC
+			}
+		}
+	}
+	return nil
+}
+
+// reservationExpired returns true if the host was associated with the
+// Metal3Machine for longer than its reservation TTL without its provisioning
+// being started. Once a provisioning of the host started, the reservation is
+// kept, also while the host is deprovisioned to be reprovisioned.
+func (m *MachineManager) reservationExpired(host *bmov1alpha1.BareMetalHost) bool {
+	ttl := m.hostReservationTTL()
+	if ttl <= 0 || m.Metal3Machine.Status.AssociatedAt == nil ||
+		m.Metal3Machine.Status.LastProvisioningStart != nil {
+		return false
+	}
+	switch host.Status.Provisioning.State {
+	case bmov1alpha1.StateReady, bmov1alpha1.StateAvailable, bmov1alpha1.StateNone:
+	default:
+		return false
+	}
+	return time.Since(m.Metal3Machine.Status.AssociatedAt.Time) > ttl
+}
+
+// releaseHost releases a host so that it can be chosen again, and dissociates
+// the Metal3Machine from it. The reason and message are set on the
+// AssociateBMH condition and recorded as a warning event. A transient error is
+// returned to requeue the Metal3Machine for a new association.
+func (m *MachineManager) releaseHost(ctx context.Context, host *bmov1alpha1.BareMetalHost, helper *patch.Helper,
+	reason, messageFormat string, messageArgs ...interface{},
+) error {
+	message := fmt.Sprintf(messageFormat, messageArgs...)
+	m.Log.Info("Releasing host", "host", host.Name, "reason", reason, "message", message)
+
+	if err := m.DissociateM3Metadata(ctx); err != nil {
+		return err
+	}
+	if err := m.removeBMCSecretLabel(ctx, host); err != nil {
+		return err
+	}
+
+	var err error
+	if consumerRefMatches(host.Spec.ConsumerRef, m.Metal3Machine) {
+		host.Spec.ConsumerRef = nil
+		host.Spec.Image = nil
+		host.Spec.CustomDeploy = nil
+		if m.Metal3Machine.Status.UserData != nil {
+			host.Spec.UserData = nil
+		}
+		if m.Metal3Machine.Status.MetaData != nil {
+			host.Spec.MetaData = nil
+		}
+		if m.Metal3Machine.Status.NetworkData != nil {
+			host.Spec.NetworkData = nil
+		}
+	}
+	host.OwnerReferences, err = m.DeleteOwnerRef(host.OwnerReferences)
+	if err != nil {
+		return err
+	}
+	m.unsetHostLabel(host)
+	m.auditHostPatch(host)
+	if err := patchIfFound(ctx, helper, host); err != nil {
+		return err
+	}
+	ForgetHostPowerCycles(host.Namespace, host.Name)
+
+	delete(m.Metal3Machine.Annotations, HostAnnotation)
+	m.Metal3Machine.Status.AssociatedAt = nil
+	m.Metal3Machine.Status.ProvisioningAttempts = 0
+	m.Metal3Machine.Status.LastProvisioningStart = nil
+	m.Metal3Machine.Status.Addresses = nil
+	m.Metal3Machine.Status.BMCProtocol = ""
+	m.Metal3Machine.Status.BMCAddress = ""
+	m.Metal3Machine.Status.LastInspected = nil
+	conditions.MarkFalse(m.Metal3Machine, infrav1.AssociateBMHCondition,
+		reason, clusterv1.ConditionSeverityWarning, "%s", message)
+	m.recordEvent(corev1.EventTypeWarning, reason, "Released BareMetalHost %s: %s", host.Name, message)
+
+	errMessage := "Host released, requeuing"
+	m.Log.Info(errMessage)
+	return WithTransientError(errors.New(errMessage), requeueAfter)
+}
+
+// isFenced returns true if the host was fenced by a remediation requesting
+// the Metal3Machine to be associated with another host.
+func (m *MachineManager) isFenced(host *bmov1alpha1.BareMetalHost) bool {
+	fenced, ok := m.Metal3Machine.Annotations[ReassociateAnnotation]
+	return ok && fenced == host.Name
+}
+
+// releaseFencedHost releases the fenced host and clears the provider ID of
+// the Metal3Machine, which is associated with another host while its Machine
+// is preserved.
+func (m *MachineManager) releaseFencedHost(ctx context.Context, host *bmov1alpha1.BareMetalHost, helper *patch.Helper) error {
+	m.Metal3Machine.Spec.ProviderID = nil
+	m.Metal3Machine.Status.Ready = false
+	return m.releaseHost(ctx, host, helper, infrav1.HostFencedReason,
+		"BareMetalHost %s fenced, associating the Metal3Machine with another host", host.Name)
+}
+
+// checkHostReference sets the HostReferenced condition according to whether
+// the consumerRef of the host references the Metal3Machine annotated with it.
+// The host is claimed again if it is not consumed by anything else and
+// HostReferenceLostPolicy is Reclaim, otherwise the Metal3Machine is marked
+// failed. It returns whether the host can be updated.
+func (m *MachineManager) checkHostReference(host *bmov1alpha1.BareMetalHost) bool {
+	if host.Spec.ConsumerRef != nil && consumerRefMatches(host.Spec.ConsumerRef, m.Metal3Machine) {
+		m.SetConditionMetal3MachineToTrue(infrav1.HostReferencedCondition)
+		return true
+	}
+
+	if host.Spec.ConsumerRef == nil && HostReferenceLostPolicy != HostReferenceLostFail {
+		m.Log.Info("BareMetalHost no longer references the Metal3Machine, reclaiming it", "host", host.Name)
+		m.recordEvent(corev1.EventTypeWarning, infrav1.HostReclaimedReason,
+			"Reclaimed BareMetalHost %s, whose consumerRef was cleared", host.Name)
+		// The consumerRef is set again when the host is updated.
+		m.SetConditionMetal3MachineToTrue(infrav1.HostReferencedCondition)
+		return true
+	}
+
+	message := fmt.Sprintf("BareMetalHost %s no longer references the Metal3Machine", host.Name)
+	if host.Spec.ConsumerRef != nil {
+		message = fmt.Sprintf("BareMetalHost %s is consumed by %s %s/%s", host.Name,
+			host.Spec.ConsumerRef.Kind, host.Spec.ConsumerRef.Namespace, host.Spec.ConsumerRef.Name)
+	}
+	m.Log.Info("BareMetalHost no longer references the Metal3Machine, marking it failed", "host", host.Name)
+	m.recordEvent(corev1.EventTypeWarning, infrav1.HostReferenceLostReason, "%s", message)
+	m.SetConditionMetal3MachineToFalse(infrav1.HostReferencedCondition, infrav1.HostReferenceLostReason,
+		clusterv1.ConditionSeverityError, "%s", message)
+	m.SetError(message, capierrors.UpdateMachineError)
+	return false
+}
+
 // Update updates a machine and is invoked by the Machine Controller.
 func (m *MachineManager) Update(ctx context.Context) error {
 	m.Log.Info("Updating machine")
@@ -682,10 +1108,27 @@ func (m *MachineManager) Update(ctx context.Context) error {
 		return WithTransientError(errors.New(errMessage), requeueAfter)
 	}
 
+	// Release the host if it was held for too long without being provisioned.
+	if m.reservationExpired(host) {
+		return m.releaseHost(ctx, host, helper, infrav1.HostReservationExpiredReason,
+			"BareMetalHost %s not provisioned within %s", host.Name, m.hostReservationTTL())
+	}
+	if m.isFenced(host) {
+		return m.releaseFencedHost(ctx, host, helper)
+	}
+	if !m.checkHostReference(host) {
+		return nil
+	}
+
 	if err := m.WaitForM3Metadata(ctx); err != nil {
 		return err
 	}
 
+	// Reprovisioning the host clears its image, before setHostSpec.
+	if err := m.checkBootstrapData(ctx, host); err != nil {
+		return err
+	}
+
 	// ensure that the BMH specs are correctly set.
 	err = m.setHostConsumerRef(ctx, host)
 	if err != nil {
@@ -698,6 +1141,9 @@ func (m *MachineManager) Update(ctx context.Context) error {
 		return err
 	}
 
+	m.setHostAnnotationsFromLabels(host)
+
+	m.auditHostPatch(host)
 	err = helper.Patch(ctx, host)
 	if err != nil {
 		return err
@@ -712,10 +1158,128 @@ func (m *MachineManager) Update(ctx context.Context) error {
 		return err
 	}
 
+	if m.provisioningAttemptsExhausted(host) {
+		return nil
+	}
+
+	if err := m.checkHostError(host); err != nil {
+		return err
+	}
+
 	m.Log.Info("Finished updating machine")
 	return nil
 }
 
+// preDeprovisionHookRequest is the payload sent to the pre-deprovision hook.
+type preDeprovisionHookRequest struct {
+	Namespace     string    `json:"namespace"`
+	Cluster       string    `json:"cluster,omitempty"`
+	Machine       string    `json:"machine,omitempty"`
+	Metal3Machine string    `json:"metal3Machine"`
+	Host          string    `json:"host"`
+	HostUID       types.UID `json:"hostUID"`
+}
+
+// runPreDeprovisionHook calls the pre-deprovision hook once for the host and
+// records the outcome on the Metal3Machine. When the hook fails, a transient
+// error is returned to retry it, unless PreDeprovisionHookFailOpen is set.
+func (m *MachineManager) runPreDeprovisionHook(ctx context.Context, host *bmov1alpha1.BareMetalHost) error {
+	if PreDeprovisionHookURL == "" {
+		return nil
+	}
+	if _, ok := m.Metal3Machine.Annotations[PreDeprovisionHookAnnotation]; ok {
+		return nil
+	}
+	if ReadOnly {
+		m.Log.Info("Read-only mode, skipping the write", "verb", "post", "kind", "PreDeprovisionHook",
+			"name", host.Name)
+		return nil
+	}
+
+	err := m.callPreDeprovisionHook(ctx, host)
+	outcome := PreDeprovisionHookSucceeded
+	if err != nil {
+		m.Log.Error(err, "Pre-deprovision hook failed", "host", host.Name)
+		if !PreDeprovisionHookFailOpen {
+			m.recordEvent(corev1.EventTypeWarning, "PreDeprovisionHookFailed",
+				"Pre-deprovision hook failed for host %s, retrying: %v", host.Name, err)
+			return WithTransientError(errors.Wrap(err, "pre-deprovision hook failed"), requeueAfter)
+		}
+		m.recordEvent(corev1.EventTypeWarning, "PreDeprovisionHookFailedOpen",
+			"Pre-deprovision hook failed for host %s, proceeding: %v", host.Name, err)
+		outcome = PreDeprovisionHookFailedOpen
+	} else {
+		m.recordEvent(corev1.EventTypeNormal, "PreDeprovisionHookSucceeded",
+			"Pre-deprovision hook succeeded for host %s", host.Name)
+	}
+
+	if m.Metal3Machine.Annotations == nil {
+		m.Metal3Machine.Annotations = make(map[string]string)
+	}
+	m.Metal3Machine.Annotations[PreDeprovisionHookAnnotation] = outcome
+	return nil
+}
+
+// callPreDeprovisionHook posts the host and machine details to the
+// pre-deprovision hook and fails unless it answers with a 2xx status code.
+func (m *MachineManager) callPreDeprovisionHook(ctx context.Context, host *bmov1alpha1.BareMetalHost) error {
+	payload := preDeprovisionHookRequest{
+		Namespace:     m.Metal3Machine.Namespace,
+		Metal3Machine: m.Metal3Machine.Name,
+		Host:          host.Name,
+		HostUID:       host.UID,
+	}
+	if m.Machine != nil {
+		payload.Cluster = m.Machine.Spec.ClusterName
+		payload.Machine = m.Machine.Name
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, PreDeprovisionHookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, PreDeprovisionHookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// newHostPatchHelper returns a patch helper for the host, and keeps a copy of
+// the host to audit the changes patched with it.
+func (m *MachineManager) newHostPatchHelper(host *bmov1alpha1.BareMetalHost) (*patch.Helper, error) {
+	helper, err := patch.NewHelper(host, m.client)
+	if err != nil {
+		return nil, err
+	}
+	m.hostBefore = host.DeepCopy()
+	return helper, nil
+}
+
+// auditHostPatch logs the changes about to be patched on the host.
+func (m *MachineManager) auditHostPatch(host *bmov1alpha1.BareMetalHost) {
+	auditHostPatch(m.Log, machineAuditActor, m.hostBefore, host)
+}
+
+// recordEvent records an event on the Metal3Machine if an EventRecorder is set.
+func (m *MachineManager) recordEvent(eventType, reason, messageFmt string, args ...interface{}) {
+	if EventRecorder == nil {
+		return
+	}
+	EventRecorder.Eventf(m.Metal3Machine, eventType, reason, messageFmt, args...)
+}
+
 // exists tests for the existence of a baremetalHost.
 func (m *MachineManager) exists(ctx context.Context) (bool, error) {
 	m.Log.Info("Checking if host exists.")
@@ -739,7 +1303,7 @@ func (m *MachineManager) getHost(ctx context.Context) (*bmov1alpha1.BareMetalHos
 	if err != nil || host == nil {
 		return host, nil, err
 	}
-	helper, err := patch.NewHelper(host, m.client)
+	helper, err := m.newHostPatchHelper(host)
 	return host, helper, err
 }
 
@@ -793,42 +1357,28 @@ func (m *MachineManager) chooseHost(ctx context.Context) (*bmov1alpha1.BareMetal
 
 	// Using the label selector on ListOptions above doesn't seem to work.
 	// I think it's because we have a local cache of all BareMetalHosts.
-	labelSelector := labels.NewSelector()
-	var reqs labels.Requirements
-
-	for labelKey, labelVal := range m.Metal3Machine.Spec.HostSelector.MatchLabels {
-		m.Log.Info("Adding requirement to match label",
-			"label key", labelKey,
-			"label value", labelVal)
-		r, err := labels.NewRequirement(labelKey, selection.Equals, []string{labelVal})
-		if err != nil {
-			m.Log.Error(err, "Failed to create MatchLabel requirement, not choosing host")
-			return nil, nil, err
-		}
-		reqs = append(reqs, *r)
-	}
-	for _, req := range m.Metal3Machine.Spec.HostSelector.MatchExpressions {
-		m.Log.Info("Adding requirement to match label",
-			"label key", req.Key,
-			"label operator", req.Operator,
-			"label value", req.Values)
-		lowercaseOperator := selection.Operator(strings.ToLower(string(req.Operator)))
-		r, err := labels.NewRequirement(req.Key, lowercaseOperator, req.Values)
+	// The fallback host selectors are tried in order after the host selector.
+	hostSelectors := append([]infrav1.HostSelector{m.Metal3Machine.Spec.HostSelector},
+		m.Metal3Machine.Spec.FallbackHostSelectors...,
+	)
+	labelSelectors := make([]labels.Selector, 0, len(hostSelectors))
+	for _, hostSelector := range hostSelectors {
+		labelSelector, err := m.newHostLabelSelector(hostSelector)
 		if err != nil {
-			m.Log.Error(err, "Failed to create MatchExpression requirement, not choosing host")
 			return nil, nil, err
 		}
-		reqs = append(reqs, *r)
+		labelSelectors = append(labelSelectors, labelSelector)
 	}
-	labelSelector = labelSelector.Add(reqs...)
 
-	availableHosts := []*bmov1alpha1.BareMetalHost{}
-	availableHostsWithNodeReuse := []*bmov1alpha1.BareMetalHost{}
+	topology := m.machineTopology()
+
+	availableHostsPerSelector := make([][]*bmov1alpha1.BareMetalHost, len(labelSelectors))
+	availableHostsWithNodeReusePerSelector := make([][]*bmov1alpha1.BareMetalHost, len(labelSelectors))
 
 	for i, host := range hosts.Items {
 		if host.Spec.ConsumerRef != nil && consumerRefMatches(host.Spec.ConsumerRef, m.Metal3Machine) {
 			m.Log.Info("Found host with existing ConsumerRef", "host", host.Name)
-			helper, err := patch.NewHelper(&hosts.Items[i], m.client)
+			helper, err := m.newHostPatchHelper(&hosts.Items[i])
 			return &hosts.Items[i], helper, err
 		}
 		if host.Spec.ConsumerRef != nil ||
@@ -839,6 +1389,10 @@ func (m *MachineManager) chooseHost(ctx context.Context) (*bmov1alpha1.BareMetal
 		if host.GetDeletionTimestamp() != nil {
 			continue
 		}
+		// Never choose the host fenced by a remediation again.
+		if m.isFenced(&host) {
+			continue
+		}
 		if host.Status.ErrorMessage != "" {
 			continue
 		}
@@ -852,31 +1406,126 @@ func (m *MachineManager) chooseHost(ctx context.Context) (*bmov1alpha1.BareMetal
 			if _, ok := annotations[infrav1.UnhealthyAnnotation]; ok {
 				continue
 			}
+			if _, ok := annotations[infrav1.PermanentlyFailedAnnotation]; ok {
+				m.Log.Info("Host excluded as it is permanently failed", "host", host.Name)
+				continue
+			}
+			if _, err := pinnedImage(&host); err != nil {
+				m.Log.Info("Host excluded by an invalid pinned image", "host", host.Name,
+					"error", err.Error())
+				continue
+			}
+			if key := disqualifyingAnnotation(annotations); key != "" {
+				m.Log.Info("Host excluded by a disqualifying annotation", "host", host.Name,
+					"annotation", key, "value", annotations[key])
+				continue
+			}
+			if recentlyRemediated(annotations, time.Now()) {
+				m.Log.Info("Host excluded as it was remediated recently", "host", host.Name,
+					"remediatedBy", annotations[RemediatedByAnnotation],
+					"remediatedAt", annotations[RemediatedAtAnnotation])
+				continue
+			}
 		}
 
-		if labelSelector.Matches(labels.Set(host.ObjectMeta.Labels)) {
-			if m.nodeReuseLabelExists(ctx, &host) && m.nodeReuseLabelMatches(ctx, &host) {
-				m.Log.Info("Found host with nodeReuseLabelName and it matches, adding it to availableHostsWithNodeReuse list", "host", host.Name)
-				availableHostsWithNodeReuse = append(availableHostsWithNodeReuse, &hosts.Items[i])
-			} else if !m.nodeReuseLabelExists(ctx, &host) {
-				switch host.Status.Provisioning.State {
-				case bmov1alpha1.StateReady, bmov1alpha1.StateAvailable:
-				default:
-					continue
-				}
-				m.Log.Info("Host matched hostSelector for Metal3Machine, adding it to availableHosts list", "host", host.Name)
-				availableHosts = append(availableHosts, &hosts.Items[i])
+		if key := topologyMismatch(&host, topology); key != "" {
+			m.Log.Info("Host is out of the topology of the Machine", "host", host.Name,
+				"label", key, "value", topology[key])
+			continue
+		}
+
+		if m.Metal3Machine.Spec.ProvisioningInterface != "" {
+			if _, err := m.hostBootMACAddress(&host); err != nil {
+				m.Log.Info("Host excluded by the provisioning interface of the Metal3Machine",
+					"host", host.Name, "error", err.Error())
+				continue
 			}
-		} else {
+		}
+
+		selectorIndex := -1
+		for j, labelSelector := range labelSelectors {
+			if !labelSelector.Matches(labels.Set(host.ObjectMeta.Labels)) {
+				continue
+			}
+			if capability := missingHostCapability(&host, hostSelectors[j].RequiredCapabilities); capability != "" {
+				m.Log.Info("Host lacks a capability required by hostSelector for Metal3Machine",
+					"host", host.Name, "capability", capability, "hostSelector", j,
+				)
+				continue
+			}
+			if !meetsMinimumHardware(&host, hostSelectors[j].MinimumHardware) {
+				m.Log.Info("Host lacks the minimum hardware required by hostSelector for Metal3Machine",
+					"host", host.Name, "hostSelector", j,
+				)
+				continue
+			}
+			selectorIndex = j
+			break
+		}
+		if selectorIndex < 0 {
 			m.Log.Info("Host did not match hostSelector for Metal3Machine", "host", host.Name)
+			continue
+		}
+
+		if m.nodeReuseLabelExists(ctx, &host) && m.nodeReuseLabelMatches(ctx, &host) {
+			m.Log.Info("Found host with nodeReuseLabelName and it matches, adding it to availableHostsWithNodeReuse list", "host", host.Name)
+			availableHostsWithNodeReusePerSelector[selectorIndex] = append(availableHostsWithNodeReusePerSelector[selectorIndex], &hosts.Items[i])
+		} else if !m.nodeReuseLabelExists(ctx, &host) {
+			switch host.Status.Provisioning.State {
+			case bmov1alpha1.StateReady, bmov1alpha1.StateAvailable:
+			default:
+				continue
+			}
+			m.Log.Info("Host matched hostSelector for Metal3Machine, adding it to availableHosts list", "host", host.Name, "hostSelector", selectorIndex)
+			availableHostsPerSelector[selectorIndex] = append(availableHostsPerSelector[selectorIndex], &hosts.Items[i])
 		}
 	}
 
-	m.Log.Info("Host count available with nodeReuseLabelName while choosing host for Metal3 machine", "hostcount", len(availableHostsWithNodeReuse))
-	m.Log.Info("Host count available while choosing host for Metal3 machine", "hostcount", len(availableHosts))
-	if len(availableHostsWithNodeReuse) == 0 && len(availableHosts) == 0 {
+	// Select among the hosts matching the first host selector that has any
+	// beyond its reserve. The hosts to reuse are not part of the reserve.
+	hostsReserved := false
+	selectorIndex := 0
+	for ; selectorIndex < len(labelSelectors); selectorIndex++ {
+		if len(availableHostsWithNodeReusePerSelector[selectorIndex]) > 0 ||
+			len(availableHostsPerSelector[selectorIndex]) > hostSelectors[selectorIndex].ReservedHosts {
+			break
+		}
+		if len(availableHostsPerSelector[selectorIndex]) > 0 {
+			m.Log.Info("Available hosts are kept in reserve by hostSelector", "hostcount",
+				len(availableHostsPerSelector[selectorIndex]), "hostSelector", selectorIndex)
+			hostsReserved = true
+		}
+	}
+	if selectorIndex == len(labelSelectors) {
+		m.Log.Info("No host available while choosing host for Metal3 machine")
+		if hostsReserved {
+			m.SetConditionMetal3MachineToFalse(infrav1.HostSelectorCondition, infrav1.HostsReservedReason,
+				clusterv1.ConditionSeverityInfo, "The available BareMetalHosts are kept in reserve",
+			)
+		} else {
+			m.SetConditionMetal3MachineToFalse(infrav1.HostSelectorCondition, infrav1.NoMatchingHostReason,
+				clusterv1.ConditionSeverityWarning, "No available BareMetalHost matches %s",
+				describeLabelSelectors(labelSelectors),
+			)
+		}
 		return nil, nil, nil
 	}
+	availableHosts := availableHostsPerSelector[selectorIndex]
+	availableHostsWithNodeReuse := availableHostsWithNodeReusePerSelector[selectorIndex]
+
+	// Prefer the host last used by a Machine of the same name, e.g. one
+	// re-created by a rolling update, over the placement policy.
+	lastMachineHost := m.lastMachineHost(availableHosts)
+
+	if len(availableHostsWithNodeReuse) == 0 && lastMachineHost == nil && m.Metal3Machine.Spec.HostPlacement != nil {
+		availableHosts, err = m.placementCandidates(ctx, hosts.Items, availableHosts)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	m.Log.Info("Host count available with nodeReuseLabelName while choosing host for Metal3 machine", "hostcount", len(availableHostsWithNodeReuse))
+	m.Log.Info("Host count available while choosing host for Metal3 machine", "hostcount", len(availableHosts))
 
 	// choose a host.
 	var chosenHost *bmov1alpha1.BareMetalHost
@@ -906,19 +1555,329 @@ func (m *MachineManager) chooseHost(ctx context.Context) (*bmov1alpha1.BareMetal
 				return nil, nil, WithTransientError(errors.New(errMessage), requeueAfter)
 			}
 		}
+	} else if lastMachineHost != nil {
+		m.Log.Info("Host last used by the Machine is available, choosing the host", "host", lastMachineHost.Name)
+		chosenHost = lastMachineHost
+	} else if minimum := hostSelectors[selectorIndex].MinimumHardware; minimum != nil {
+		// Choose the host exceeding the minimum hardware the least, to keep
+		// the larger hosts for the Metal3Machines requiring them.
+		chosenHost = bestFitHost(availableHosts, minimum, m.requestedImageURL(ctx))
+		m.Log.Info("host(s) count available, choosing the best fitting host", "availabeHostCount", len(availableHosts), "host", chosenHost.Name)
 	} else {
 		// If there are no hosts with nodeReuseLabelName, fall back
-		// to the current flow and select hosts randomly.
-		m.Log.Info("host(s) count available, choosing a random host", "availabeHostCount", len(availableHosts))
-		rHost, _ := rand.Int(rand.Reader, big.NewInt(int64(len(availableHosts))))
+		// to the current flow and select hosts randomly, preferring the
+		// hosts already holding the image.
+		candidates := hostsWithImage(availableHosts, m.requestedImageURL(ctx))
+		if len(candidates) == 0 {
+			candidates = availableHosts
+		}
+		m.Log.Info("host(s) count available, choosing a random host", "availabeHostCount", len(availableHosts),
+			"withImageCount", len(candidates))
+		rHost, _ := rand.Int(rand.Reader, big.NewInt(int64(len(candidates))))
 		randomHost := rHost.Int64()
-		chosenHost = availableHosts[randomHost]
+		chosenHost = candidates[randomHost]
 	}
 
-	helper, err := patch.NewHelper(chosenHost, m.client)
+	if selectorIndex == 0 {
+		m.SetConditionMetal3MachineToTrue(infrav1.HostSelectorCondition)
+	} else {
+		m.Log.Info("Host chosen with fallback host selector", "host", chosenHost.Name, "fallbackHostSelector", selectorIndex-1)
+		m.SetConditionMetal3MachineToFalse(infrav1.HostSelectorCondition, infrav1.FallbackHostSelectorReason,
+			clusterv1.ConditionSeverityInfo, "BareMetalHost %s chosen with fallback host selector %d",
+			chosenHost.Name, selectorIndex-1,
+		)
+	}
+
+	helper, err := m.newHostPatchHelper(chosenHost)
 	return chosenHost, helper, err
 }
 
+// placementCandidates returns the candidates in the placement domains, i.e.
+// the values of the topology label, preferred by the host placement policy
+// given the hosts consumed by the other Machines of the MachineDeployment.
+// The candidates are returned unchanged if the Machine is not part of a
+// MachineDeployment or none of them has the topology label.
+func (m *MachineManager) placementCandidates(ctx context.Context, hosts []bmov1alpha1.BareMetalHost,
+	candidates []*bmov1alpha1.BareMetalHost,
+) ([]*bmov1alpha1.BareMetalHost, error) {
+	placement := m.Metal3Machine.Spec.HostPlacement
+	mdName, ok := m.Machine.Labels[clusterv1.MachineDeploymentNameLabel]
+	if !ok {
+		return candidates, nil
+	}
+
+	machines := clusterv1.MachineList{}
+	if err := m.client.List(ctx, &machines, client.InNamespace(m.Machine.Namespace),
+		client.MatchingLabels{
+			clusterv1.ClusterNameLabel:           m.Machine.Spec.ClusterName,
+			clusterv1.MachineDeploymentNameLabel: mdName,
+		},
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list the Machines of the MachineDeployment")
+	}
+	siblings := map[string]bool{}
+	for _, machine := range machines.Items {
+		if machine.Name != m.Machine.Name {
+			siblings[machine.Spec.InfrastructureRef.Name] = true
+		}
+	}
+
+	placed := map[string]int{}
+	for _, host := range hosts {
+		domain, ok := host.Labels[placement.TopologyKey]
+		if !ok || host.Spec.ConsumerRef == nil || !siblings[host.Spec.ConsumerRef.Name] {
+			continue
+		}
+		placed[domain]++
+	}
+
+	preferred := []*bmov1alpha1.BareMetalHost{}
+	preferredCount := 0
+	for _, host := range candidates {
+		domain, ok := host.Labels[placement.TopologyKey]
+		if !ok {
+			continue
+		}
+		count := placed[domain]
+		better := count < preferredCount
+		if placement.Policy == infrav1.HostPlacementPack {
+			better = count > preferredCount
+		}
+		if len(preferred) == 0 || better {
+			preferred = []*bmov1alpha1.BareMetalHost{host}
+			preferredCount = count
+		} else if count == preferredCount {
+			preferred = append(preferred, host)
+		}
+	}
+	if len(preferred) == 0 {
+		return candidates, nil
+	}
+	m.Log.Info("Hosts preferred by the host placement", "policy", placement.Policy,
+		"topologyKey", placement.TopologyKey, "hostcount", len(preferred), "placedMachines", preferredCount,
+	)
+	return preferred, nil
+}
+
+// newHostLabelSelector builds a label selector matching the BareMetalHosts
+// selected by the given host selector.
+func (m *MachineManager) newHostLabelSelector(hostSelector infrav1.HostSelector) (labels.Selector, error) {
+	labelSelector, err := HostLabelSelector(hostSelector)
+	if err != nil {
+		m.Log.Error(err, "Failed to create host selector requirement, not choosing host")
+		return nil, err
+	}
+	m.Log.Info("Matching labels", "selector", labelSelector.String())
+	return labelSelector, nil
+}
+
+// HostLabelSelector builds a label selector from the labels and expressions of
+// the given host selector. The other criteria of the host selector are not
+// label based and are not part of the label selector.
+func HostLabelSelector(hostSelector infrav1.HostSelector) (labels.Selector, error) {
+	labelSelector := labels.NewSelector()
+	var reqs labels.Requirements
+
+	for labelKey, labelVal := range hostSelector.MatchLabels {
+		r, err := labels.NewRequirement(labelKey, selection.Equals, []string{labelVal})
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, *r)
+	}
+	for _, req := range hostSelector.MatchExpressions {
+		lowercaseOperator := selection.Operator(strings.ToLower(string(req.Operator)))
+		r, err := labels.NewRequirement(req.Key, lowercaseOperator, req.Values)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, *r)
+	}
+	return labelSelector.Add(reqs...), nil
+}
+
+// describeLabelSelectors describes the label selectors of the host selectors
+// for the conditions, the host selector first and then the fallbacks.
+func describeLabelSelectors(labelSelectors []labels.Selector) string {
+	descriptions := make([]string, 0, len(labelSelectors))
+	for _, labelSelector := range labelSelectors {
+		description := labelSelector.String()
+		if description == "" {
+			description = "<any>"
+		}
+		descriptions = append(descriptions, "["+description+"]")
+	}
+	if len(descriptions) == 1 {
+		return "the host selector " + descriptions[0]
+	}
+	return "the host selectors " + strings.Join(descriptions, ", ")
+}
+
+// disqualifyingAnnotation returns the first of the DisqualifyingHostAnnotations
+// keys present in the annotations, or an empty string if there is none.
+func disqualifyingAnnotation(annotations map[string]string) string {
+	for _, key := range DisqualifyingHostAnnotations {
+		if _, ok := annotations[key]; ok {
+			return key
+		}
+	}
+	return ""
+}
+
+// machineTopology returns the values the BareMetalHost labels must have, by
+// key, for the host to be in the topology of the Machine, according to
+// MachineTopologyLabels and FailureDomainHostLabel.
+func (m *MachineManager) machineTopology() map[string]string {
+	topology := map[string]string{}
+	if m.Machine == nil {
+		return topology
+	}
+	for machineKey, hostKey := range MachineTopologyLabels {
+		if value, ok := m.Machine.Labels[machineKey]; ok {
+			topology[hostKey] = value
+		}
+	}
+	if FailureDomainHostLabel != "" && m.Machine.Spec.FailureDomain != nil {
+		topology[FailureDomainHostLabel] = *m.Machine.Spec.FailureDomain
+	}
+	return topology
+}
+
+// topologyMismatch returns the key of the first host label not matching the
+// topology, in key order, or an empty string if the host is in the topology.
+func topologyMismatch(host *bmov1alpha1.BareMetalHost, topology map[string]string) string {
+	keys := make([]string, 0, len(topology))
+	for key := range topology {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if host.Labels[key] != topology[key] {
+			return key
+		}
+	}
+	return ""
+}
+
+// recentlyRemediated returns true if the annotations record a remediation
+// within HostRemediationCooldown before now. A malformed timestamp is
+// ignored.
+func recentlyRemediated(annotations map[string]string, now time.Time) bool {
+	if HostRemediationCooldown <= 0 {
+		return false
+	}
+	remediatedAt, err := time.Parse(time.RFC3339, annotations[RemediatedAtAnnotation])
+	if err != nil {
+		return false
+	}
+	return now.Sub(remediatedAt) < HostRemediationCooldown
+}
+
+// meetsMinimumHardware returns true if the host has the topology labels and its
+// inspected hardware meets the minimum hardware. Hosts which were not inspected
+// only meet a minimum without CPU count nor RAM.
+func meetsMinimumHardware(host *bmov1alpha1.BareMetalHost, minimum *infrav1.HardwareRequirements) bool {
+	if minimum == nil {
+		return true
+	}
+	for key, value := range minimum.Topology {
+		if actual, ok := host.Labels[infrav1.HostTopologyLabelPrefix+key]; !ok || actual != value {
+			return false
+		}
+	}
+	if minimum.CPUCount == 0 && minimum.RAMMebibytes == 0 {
+		return true
+	}
+	hardware := host.Status.HardwareDetails
+	if hardware == nil {
+		return false
+	}
+	return hardware.CPU.Count >= minimum.CPUCount && hardware.RAMMebibytes >= minimum.RAMMebibytes
+}
+
+// hardwareFitScore returns how much the hardware of the host exceeds the
+// minimum hardware, as the sum of the relative excess of each requirement.
+// The lower the score, the tighter the fit.
+func hardwareFitScore(host *bmov1alpha1.BareMetalHost, minimum *infrav1.HardwareRequirements) float64 {
+	hardware := host.Status.HardwareDetails
+	if hardware == nil {
+		return 0
+	}
+	score := 0.0
+	if minimum.CPUCount > 0 {
+		score += float64(hardware.CPU.Count-minimum.CPUCount) / float64(minimum.CPUCount)
+	}
+	if minimum.RAMMebibytes > 0 {
+		score += float64(hardware.RAMMebibytes-minimum.RAMMebibytes) / float64(minimum.RAMMebibytes)
+	}
+	return score
+}
+
+// bestFitHost returns the host with the lowest hardwareFitScore. Ties are
+// broken in favor of the hosts holding the image, then by the host name so
+// that the choice is deterministic.
+func bestFitHost(hosts []*bmov1alpha1.BareMetalHost, minimum *infrav1.HardwareRequirements,
+	imageURL string,
+) *bmov1alpha1.BareMetalHost {
+	var bestHost *bmov1alpha1.BareMetalHost
+	bestScore := 0.0
+	for _, host := range hosts {
+		score := hardwareFitScore(host, minimum)
+		better := bestHost == nil || score < bestScore
+		if !better && score == bestScore {
+			hasImage, bestHasImage := hostHasImage(host, imageURL), hostHasImage(bestHost, imageURL)
+			better = (hasImage && !bestHasImage) || (hasImage == bestHasImage && host.Name < bestHost.Name)
+		}
+		if better {
+			bestHost = host
+			bestScore = score
+		}
+	}
+	return bestHost
+}
+
+// requestedImageURL returns the URL of the image to provision, or an empty
+// string if it cannot be resolved yet.
+func (m *MachineManager) requestedImageURL(ctx context.Context) string {
+	image, err := m.resolveImage(ctx)
+	if err != nil {
+		return ""
+	}
+	return image.URL
+}
+
+// hostHasImage returns true if the host was last provisioned with the image,
+// so that it can be provisioned again without downloading it.
+func hostHasImage(host *bmov1alpha1.BareMetalHost, imageURL string) bool {
+	return imageURL != "" && host.Status.Provisioning.Image.URL == imageURL
+}
+
+// hostsWithImage returns the hosts last provisioned with the image.
+func hostsWithImage(hosts []*bmov1alpha1.BareMetalHost, imageURL string) []*bmov1alpha1.BareMetalHost {
+	matching := []*bmov1alpha1.BareMetalHost{}
+	for _, host := range hosts {
+		if hostHasImage(host, imageURL) {
+			matching = append(matching, host)
+		}
+	}
+	return matching
+}
+
+// missingHostCapability returns the first of the capabilities the host does not
+// have, or an empty string if it has all of them.
+func missingHostCapability(host *bmov1alpha1.BareMetalHost, capabilities []string) string {
+	for _, capability := range capabilities {
+		if host.Labels[infrav1.HostCapabilityLabelPrefix+capability] == "true" {
+			continue
+		}
+		if capability == infrav1.HostCapabilitySecureBoot && host.Spec.BootMode == bmov1alpha1.UEFISecureBoot {
+			continue
+		}
+		return capability
+	}
+	return ""
+}
+
 // consumerRefMatches returns a boolean based on whether the consumer
 // reference and bare metal machine metadata match.
 func consumerRefMatches(consumer *corev1.ObjectReference, m3machine *infrav1.Metal3Machine) bool {
@@ -1021,18 +1980,220 @@ func (m *MachineManager) setBMCSecretLabel(ctx context.Context, host *bmov1alpha
 	return nil
 }
 
-// setHostLabel will set the set cluster.x-k8s.io/cluster-name to bmh.
+// setHostLabel will set the set cluster.x-k8s.io/cluster-name label and the
+// metal3.io/cluster-name annotation to bmh.
 func (m *MachineManager) setHostLabel(_ context.Context, host *bmov1alpha1.BareMetalHost) {
 	if host.Labels == nil {
 		host.Labels = make(map[string]string)
 	}
 	host.Labels[clusterv1.ClusterNameLabel] = m.Machine.Spec.ClusterName
+	if host.Annotations == nil {
+		host.Annotations = make(map[string]string)
+	}
+	host.Annotations[ClusterNameAnnotation] = m.Machine.Spec.ClusterName
+}
+
+// setLastMachineAnnotation records the Machine in the LastMachineAnnotation of
+// the host.
+func (m *MachineManager) setLastMachineAnnotation(host *bmov1alpha1.BareMetalHost) {
+	if host.Annotations == nil {
+		host.Annotations = make(map[string]string)
+	}
+	host.Annotations[LastMachineAnnotation] = m.Machine.Namespace + "/" + m.Machine.Name
+}
+
+// lastMachineHost returns the host among hosts whose LastMachineAnnotation
+// holds the Machine, if any.
+func (m *MachineManager) lastMachineHost(hosts []*bmov1alpha1.BareMetalHost) *bmov1alpha1.BareMetalHost {
+	if m.Machine == nil {
+		return nil
+	}
+	machineKey := m.Machine.Namespace + "/" + m.Machine.Name
+	for _, host := range hosts {
+		if host.Annotations[LastMachineAnnotation] == machineKey {
+			return host
+		}
+	}
+	return nil
+}
+
+// unsetHostLabel removes the cluster label and annotation set by setHostLabel
+// from bmh, if they hold the cluster of the machine.
+func (m *MachineManager) unsetHostLabel(host *bmov1alpha1.BareMetalHost) {
+	if host.Labels != nil && host.Labels[clusterv1.ClusterNameLabel] == m.Machine.Spec.ClusterName {
+		delete(host.Labels, clusterv1.ClusterNameLabel)
+	}
+	if host.Annotations != nil && host.Annotations[ClusterNameAnnotation] == m.Machine.Spec.ClusterName {
+		delete(host.Annotations, ClusterNameAnnotation)
+	}
+}
+
+// setHostAnnotationsFromLabels copies the Machine labels listed in
+// HostAnnotationLabels as annotations of the host. The annotations of the
+// labels absent from the Machine are removed.
+func (m *MachineManager) setHostAnnotationsFromLabels(host *bmov1alpha1.BareMetalHost) {
+	for _, label := range HostAnnotationLabels {
+		value, ok := m.Machine.Labels[label]
+		if !ok {
+			delete(host.Annotations, label)
+			continue
+		}
+		if host.Annotations == nil {
+			host.Annotations = make(map[string]string)
+		}
+		host.Annotations[label] = value
+	}
+}
+
+// resolveImage returns the image to provision for the Metal3Machine. It is
+// either the inline image or the one held by the ConfigMap referenced by
+// ImageRef, read under the url, checksum, checksumType and format keys.
+func (m *MachineManager) resolveImage(ctx context.Context) (*infrav1.Image, error) {
+	if m.Metal3Machine.Spec.ImageRef == nil {
+		return &m.Metal3Machine.Spec.Image, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{
+		Name:      m.Metal3Machine.Spec.ImageRef.Name,
+		Namespace: m.Metal3Machine.Namespace,
+	}
+	if err := m.client.Get(ctx, key, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			errMessage := fmt.Sprintf("Image ConfigMap %s not found, requeuing", key.Name)
+			m.Log.Info(errMessage)
+			return nil, WithTransientError(errors.New(errMessage), requeueAfter)
+		}
+		return nil, errors.Wrapf(err, "failed to get image ConfigMap %s", key.Name)
+	}
+
+	image := &infrav1.Image{
+		URL:      configMap.Data["url"],
+		Checksum: configMap.Data["checksum"],
+	}
+	if checksumType := configMap.Data["checksumType"]; checksumType != "" {
+		image.ChecksumType = ptr.To(checksumType)
+	}
+	if format := configMap.Data["format"]; format != "" {
+		image.DiskFormat = ptr.To(format)
+	}
+	if errs := image.Validate(*field.NewPath("imageRef")); len(errs) > 0 {
+		return nil, errors.Wrapf(errs.ToAggregate(), "invalid image in ConfigMap %s", key.Name)
+	}
+	return image, nil
+}
+
+// pinnedImage returns the image pinned on the host by PinnedImageAnnotation
+// and PinnedImageChecksumAnnotation, or nil if there is none.
+func pinnedImage(host *bmov1alpha1.BareMetalHost) (*infrav1.Image, error) {
+	imageURL, ok := host.Annotations[PinnedImageAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	image := &infrav1.Image{
+		URL:      imageURL,
+		Checksum: host.Annotations[PinnedImageChecksumAnnotation],
+	}
+	if errs := image.Validate(*field.NewPath("metadata", "annotations")); len(errs) > 0 {
+		return nil, errors.Wrapf(errs.ToAggregate(), "invalid image pinned on host %s", host.Name)
+	}
+	return image, nil
+}
+
+// imageDiskFormat returns the disk format of the image. When the image does not
+// set it and DetectImageDiskFormat is enabled, it is inferred from the
+// extension of the image URL. Unknown extensions leave the format unset, for
+// Ironic to detect it.
+func (m *MachineManager) imageDiskFormat(image *infrav1.Image) *string {
+	if image.DiskFormat != nil || !DetectImageDiskFormat {
+		return image.DiskFormat
+	}
+	format := diskFormatFromURL(image.URL)
+	if format == "" {
+		return nil
+	}
+	m.Log.Info("Inferred the image disk format from its URL", "url", image.URL, "format", format)
+	return ptr.To(format)
+}
+
+// diskFormatFromURL returns the disk format matching the extension of the
+// path of the image URL, or an empty string if the extension is not known.
+func diskFormatFromURL(imageURL string) string {
+	imagePath := imageURL
+	if parsed, err := url.Parse(imageURL); err == nil {
+		imagePath = parsed.Path
+	}
+	switch strings.ToLower(path.Ext(imagePath)) {
+	case ".qcow2":
+		return "qcow2"
+	case ".raw":
+		return "raw"
+	case ".vdi":
+		return "vdi"
+	case ".vmdk":
+		return "vmdk"
+	}
+	return ""
+}
+
+// setHostBootMACAddress sets the boot MAC address of the host to the MAC
+// address of the provisioning interface of the Metal3Machine, if any.
+func (m *MachineManager) setHostBootMACAddress(host *bmov1alpha1.BareMetalHost) error {
+	if m.Metal3Machine.Spec.ProvisioningInterface == "" {
+		return nil
+	}
+	mac, err := m.hostBootMACAddress(host)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(host.Spec.BootMACAddress, mac) {
+		return nil
+	}
+	m.Log.Info("Setting the boot MAC address of the host", "host", host.Name, "mac", mac)
+	host.Spec.BootMACAddress = mac
+	return nil
+}
+
+// hostBootMACAddress returns the MAC address of the provisioning interface of
+// the Metal3Machine on the host. It fails if the interface cannot be resolved
+// or if the boot MAC address of the host is already set to another NIC.
+func (m *MachineManager) hostBootMACAddress(host *bmov1alpha1.BareMetalHost) (string, error) {
+	mac, err := provisioningInterfaceMAC(m.Metal3Machine.Spec.ProvisioningInterface, host)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve the provisioning interface %s of host %s",
+			m.Metal3Machine.Spec.ProvisioningInterface, host.Name,
+		)
+	}
+	// The BareMetal Operator does not allow changing the boot MAC address.
+	if host.Spec.BootMACAddress != "" && !strings.EqualFold(host.Spec.BootMACAddress, mac) {
+		return "", errors.Errorf("the boot MAC address of host %s is %s and cannot be changed to %s",
+			host.Name, host.Spec.BootMACAddress, mac,
+		)
+	}
+	return mac, nil
+}
+
+// provisioningInterfaceMAC returns the MAC address of the NIC of the host
+// given by name or MAC address, as found in its hardware details.
+func provisioningInterfaceMAC(nic string, host *bmov1alpha1.BareMetalHost) (string, error) {
+	if _, err := net.ParseMAC(nic); err != nil {
+		return getBMHMacByName(nic, host)
+	}
+	if host.Status.HardwareDetails == nil || host.Status.HardwareDetails.NIC == nil {
+		return "", errors.New("NICs list not populated")
+	}
+	for _, hostNIC := range host.Status.HardwareDetails.NIC {
+		if strings.EqualFold(hostNIC.MAC, nic) {
+			return hostNIC.MAC, nil
+		}
+	}
+	return "", errors.Errorf("NIC MAC address not found %s", nic)
 }
 
 // setHostSpec will ensure the host's Spec is set according to the machine's
 // details. It will then update the host via the kube API. If UserData does not
 // include a Namespace, it will default to the Metal3Machine's namespace.
-func (m *MachineManager) setHostSpec(_ context.Context, host *bmov1alpha1.BareMetalHost) error {
+func (m *MachineManager) setHostSpec(ctx context.Context, host *bmov1alpha1.BareMetalHost) error {
 	// We only want to update the image setting if the host does not
 	// already have an image.
 	//
@@ -1040,17 +2201,30 @@ func (m *MachineManager) setHostSpec(_ context.Context, host *bmov1alpha1.BareMe
 	// and upgrades are not supported at this time. To re-provision a
 	// host, we must fully deprovision it and then provision it again.
 	// Not provisioning while we do not have the UserData.
-	if host.Spec.Image == nil && host.Spec.CustomDeploy == nil && m.Metal3Machine.Status.UserData != nil {
+	// Not provisioning either while a host reprovisioned with new bootstrap
+	// data has not been deprovisioned yet.
+	if host.Spec.Image == nil && host.Spec.CustomDeploy == nil && m.Metal3Machine.Status.UserData != nil &&
+		host.Status.Provisioning.State != bmov1alpha1.StateProvisioned &&
+		host.Status.Provisioning.State != bmov1alpha1.StateDeprovisioning {
+		image, err := pinnedImage(host)
+		if err != nil {
+			return err
+		}
+		if image != nil {
+			m.Log.Info("Using the image pinned on the host", "host", host.Name, "url", image.URL)
+		} else if image, err = m.resolveImage(ctx); err != nil {
+			return err
+		}
 		checksumType := ""
-		if m.Metal3Machine.Spec.Image.ChecksumType != nil {
-			checksumType = *m.Metal3Machine.Spec.Image.ChecksumType
+		if image.ChecksumType != nil {
+			checksumType = *image.ChecksumType
 		}
-		if m.Metal3Machine.Spec.Image.URL != "" {
+		if image.URL != "" {
 			host.Spec.Image = &bmov1alpha1.Image{
-				URL:          m.Metal3Machine.Spec.Image.URL,
-				Checksum:     m.Metal3Machine.Spec.Image.Checksum,
+				URL:          image.URL,
+				Checksum:     image.Checksum,
 				ChecksumType: bmov1alpha1.ChecksumType(checksumType),
-				DiskFormat:   m.Metal3Machine.Spec.Image.DiskFormat,
+				DiskFormat:   m.imageDiskFormat(image),
 			}
 		}
 		if m.Metal3Machine.Spec.CustomDeploy != nil {
@@ -1062,6 +2236,9 @@ func (m *MachineManager) setHostSpec(_ context.Context, host *bmov1alpha1.BareMe
 		if host.Spec.UserData != nil && host.Spec.UserData.Namespace == "" {
 			host.Spec.UserData.Namespace = host.Namespace
 		}
+		if err := m.recordBootstrapData(ctx); err != nil {
+			return err
+		}
 
 		// Set metadata from gathering from Spec.metadata and from the template.
 		if m.Metal3Machine.Status.MetaData != nil {
@@ -1077,6 +2254,7 @@ func (m *MachineManager) setHostSpec(_ context.Context, host *bmov1alpha1.BareMe
 			host.Spec.NetworkData.Namespace = m.Machine.Namespace
 		}
 	}
+
 	// Set automatedCleaningMode from metal3Machine.spec.automatedCleaningMode.
 	if m.Metal3Machine.Spec.AutomatedCleaningMode != nil {
 		if host.Spec.AutomatedCleaningMode != bmov1alpha1.AutomatedCleaningMode(*m.Metal3Machine.Spec.AutomatedCleaningMode) {
@@ -1084,8 +2262,230 @@ func (m *MachineManager) setHostSpec(_ context.Context, host *bmov1alpha1.BareMe
 		}
 	}
 
-	host.Spec.Online = true
+	if online := m.desiredOnline(host); online != host.Spec.Online {
+		host.Spec.Online = online
+		recordHostPowerCycle(host)
+	}
+
+	return nil
+}
+
+// desiredOnline returns the desired power state of the host, as set through
+// DesiredPowerAnnotation on the Metal3Machine, or else through its power
+// schedule once the host is provisioned. Hosts are powered on unless
+// explicitly requested to be powered off.
+func (m *MachineManager) desiredOnline(host *bmov1alpha1.BareMetalHost) bool {
+	switch desiredPower := m.Metal3Machine.Annotations[DesiredPowerAnnotation]; desiredPower {
+	case "":
+		schedule := m.powerSchedule()
+		if schedule == nil || host.Status.Provisioning.State != bmov1alpha1.StateProvisioned {
+			return true
+		}
+		return schedule.online(time.Now())
+	case DesiredPowerOn:
+		return true
+	case DesiredPowerOff:
+		return false
+	default:
+		m.Log.Info("Ignoring unknown desired power, powering host on",
+			"annotation", DesiredPowerAnnotation, "value", desiredPower)
+		return true
+	}
+}
+
+// powerSchedule returns the power schedule set through
+// PowerScheduleAnnotation on the Metal3Machine, if any. Invalid schedules are
+// ignored.
+func (m *MachineManager) powerSchedule() powerSchedule {
+	value, ok := m.Metal3Machine.Annotations[PowerScheduleAnnotation]
+	if !ok {
+		return nil
+	}
+	schedule, err := parsePowerSchedule(value)
+	if err != nil {
+		m.Log.Info("Ignoring invalid power schedule, powering host on",
+			"annotation", PowerScheduleAnnotation, "value", value, "error", err.Error())
+		return nil
+	}
+	return schedule
+}
+
+// PowerScheduleRequeueAfter returns the duration until the power state of the
+// host changes according to the power schedule of the Metal3Machine, or zero
+// if it has none.
+func (m *MachineManager) PowerScheduleRequeueAfter() time.Duration {
+	if m.Metal3Machine.Annotations[DesiredPowerAnnotation] != "" {
+		return 0
+	}
+	schedule := m.powerSchedule()
+	if schedule == nil {
+		return 0
+	}
+	return schedule.nextChange(time.Now())
+}
+
+// postDeprovisionOnline returns the power state of the host once
+// deprovisioned, as set through metal3Machine.spec.postDeprovisionPower or
+// else PostDeprovisionPower. ok is false when none is configured.
+func (m *MachineManager) postDeprovisionOnline() (online bool, ok bool) {
+	power := PostDeprovisionPower
+	if m.Metal3Machine != nil && m.Metal3Machine.Spec.PostDeprovisionPower != nil {
+		power = *m.Metal3Machine.Spec.PostDeprovisionPower
+	}
+	switch power {
+	case infrav1.PowerOn:
+		return true, true
+	case infrav1.PowerOff:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// bootstrapDataChangePolicy returns the BootstrapDataChangePolicy of the
+// Metal3Machine, Ignore if it is not set.
+func (m *MachineManager) bootstrapDataChangePolicy() string {
+	if m.Metal3Machine.Spec.BootstrapDataChangePolicy == "" {
+		return infrav1.BootstrapDataChangeIgnore
+	}
+	return m.Metal3Machine.Spec.BootstrapDataChangePolicy
+}
+
+// bootstrapDataFingerprint returns a hash of the name and the data of the
+// bootstrap data secret of the Machine, or an empty string if the Machine
+// has none.
+func (m *MachineManager) bootstrapDataFingerprint(ctx context.Context) (string, error) {
+	if m.Machine.Spec.Bootstrap.DataSecretName == nil {
+		return "", nil
+	}
+	secret, err := checkSecretExists(ctx, m.client, *m.Machine.Spec.Bootstrap.DataSecretName,
+		m.Machine.Namespace,
+	)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "failed to get the bootstrap data secret")
+	}
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	hash.Write([]byte(secret.Name))
+	for _, key := range keys {
+		hash.Write([]byte("\n" + key + "="))
+		hash.Write(secret.Data[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// recordBootstrapData records the fingerprint of the bootstrap data the host
+// is provisioned with, unless changes of the bootstrap data are ignored.
+func (m *MachineManager) recordBootstrapData(ctx context.Context) error {
+	if m.bootstrapDataChangePolicy() == infrav1.BootstrapDataChangeIgnore {
+		return nil
+	}
+	fingerprint, err := m.bootstrapDataFingerprint(ctx)
+	if err != nil {
+		return err
+	}
+	m.Metal3Machine.Status.BootstrapDataFingerprint = fingerprint
+	m.SetConditionMetal3MachineToTrue(infrav1.BootstrapDataUpToDateCondition)
+	return nil
+}
+
+// checkBootstrapData applies the BootstrapDataChangePolicy of the
+// Metal3Machine when the bootstrap data secret changed after the host was
+// provisioned. Reprovision clears the image of the host, so that it is
+// deprovisioned and provisioned again by setHostSpec with the new data.
+func (m *MachineManager) checkBootstrapData(ctx context.Context, host *bmov1alpha1.BareMetalHost) error {
+	policy := m.bootstrapDataChangePolicy()
+	if policy == infrav1.BootstrapDataChangeIgnore ||
+		host.Status.Provisioning.State != bmov1alpha1.StateProvisioned ||
+		(host.Spec.Image == nil && host.Spec.CustomDeploy == nil) {
+		return nil
+	}
+	fingerprint, err := m.bootstrapDataFingerprint(ctx)
+	if err != nil || fingerprint == "" {
+		return err
+	}
+	// The host was provisioned before the policy was set.
+	if m.Metal3Machine.Status.BootstrapDataFingerprint == "" {
+		m.Metal3Machine.Status.BootstrapDataFingerprint = fingerprint
+	}
+	if fingerprint == m.Metal3Machine.Status.BootstrapDataFingerprint {
+		m.SetConditionMetal3MachineToTrue(infrav1.BootstrapDataUpToDateCondition)
+		return nil
+	}
+
+	secretName := *m.Machine.Spec.Bootstrap.DataSecretName
+	if policy == infrav1.BootstrapDataChangeWarn {
+		m.SetConditionMetal3MachineToFalse(infrav1.BootstrapDataUpToDateCondition,
+			infrav1.BootstrapDataChangedReason, clusterv1.ConditionSeverityWarning,
+			"bootstrap data secret %s changed after the host was provisioned", secretName,
+		)
+		return nil
+	}
+
+	// The user data rendered by the Metal3DataTemplate merges the bootstrap
+	// data, it is re-rendered before the host is provisioned again.
+	userData := m.Metal3Machine.Status.UserData
+	renderedUserData := m.Metal3Machine.Spec.UserData == nil && userData != nil &&
+		userData.Name == m.Metal3Machine.Name+userDataSuffix
+	if renderedUserData {
+		if err := m.requestUserDataRerender(ctx); err != nil {
+			return err
+		}
+	}
+
+	m.Log.Info("Bootstrap data changed, reprovisioning the host", "host", host.Name, "secret", secretName)
+	m.recordEvent(corev1.EventTypeNormal, infrav1.BootstrapDataChangedReason,
+		"Reprovisioning BareMetalHost %s with the bootstrap data secret %s", host.Name, secretName)
+	m.SetConditionMetal3MachineToFalse(infrav1.BootstrapDataUpToDateCondition,
+		infrav1.BootstrapDataChangedReason, clusterv1.ConditionSeverityInfo,
+		"reprovisioning the host with the bootstrap data secret %s", secretName,
+	)
+	host.Spec.Image = nil
+	host.Spec.CustomDeploy = nil
+	host.Spec.UserData = nil
+	m.Metal3Machine.Status.BootstrapDataFingerprint = ""
+	// The user data of the host is the bootstrap data unless set explicitly
+	// or rendered by the Metal3DataTemplate, follow a renamed secret.
+	if m.Metal3Machine.Spec.UserData == nil && userData != nil && !renderedUserData {
+		m.Metal3Machine.Status.UserData = &corev1.SecretReference{
+			Name:      secretName,
+			Namespace: m.Machine.Namespace,
+		}
+	}
+	return nil
+}
 
+// requestUserDataRerender requests the re-rendering of the user data from the
+// Metal3Data of the Metal3Machine. WaitForM3Metadata waits until it is done.
+func (m *MachineManager) requestUserDataRerender(ctx context.Context) error {
+	if m.Metal3Machine.Status.RenderedData == nil {
+		return errors.New("the rendered user data has no Metal3Data")
+	}
+	metal3Data, err := fetchM3Data(ctx, m.client, m.Log,
+		m.Metal3Machine.Status.RenderedData.Name, m.Metal3Machine.Namespace,
+	)
+	if err != nil {
+		return err
+	}
+	if metal3Data == nil {
+		return errors.New("Unexpected nil rendered data")
+	}
+	m.Log.Info("Requesting the re-rendering of the user data", "metal3data", metal3Data.Name)
+	original := metal3Data.DeepCopy()
+	if metal3Data.Annotations == nil {
+		metal3Data.Annotations = map[string]string{}
+	}
+	metal3Data.Annotations[RerenderAnnotation] = RerenderUserData
+	if err := m.client.Patch(ctx, metal3Data, client.MergeFrom(original)); err != nil {
+		return errors.Wrap(err, "failed to request the re-rendering of the user data")
+	}
 	return nil
 }
 
@@ -1174,6 +2574,113 @@ func (m *MachineManager) SetError(message string, reason capierrors.MachineStatu
 	m.Metal3Machine.Status.FailureReason = &reason
 }
 
+// checkDeprovisionTimeout sets the DeprovisionStuck condition once the host has
+// been deprovisioning for longer than its deprovision timeout, as recorded in its
+// operation history. If ForceDeprovisionOnTimeout is set, automated cleaning is
+// then disabled on the host so that deprovisioning completes without cleaning.
+func (m *MachineManager) checkDeprovisionTimeout(ctx context.Context,
+	host *bmov1alpha1.BareMetalHost, helper *patch.Helper,
+) error {
+	started := host.Status.OperationHistory.Deprovision.Start
+	timeout := m.deprovisionTimeout()
+	if timeout <= 0 || started.IsZero() || time.Since(started.Time) < timeout {
+		return nil
+	}
+
+	m.Log.Info("BareMetalHost deprovisioning timed out", "host", host.Name,
+		"deprovisioningSince", started.Time)
+	conditions.Set(m.Metal3Machine, &clusterv1.Condition{
+		Type:     infrav1.DeprovisionStuckCondition,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterv1.ConditionSeverityWarning,
+		Reason:   infrav1.DeprovisionTimeoutReason,
+		Message: fmt.Sprintf("BareMetalHost %s has been deprovisioning since %s",
+			host.Name, started.Time.Format(time.RFC3339)),
+	})
+
+	if !ForceDeprovisionOnTimeout || host.Spec.AutomatedCleaningMode == bmov1alpha1.CleaningModeDisabled {
+		return nil
+	}
+	m.Log.Info("Disabling automated cleaning of the stuck BareMetalHost", "host", host.Name)
+	host.Spec.AutomatedCleaningMode = bmov1alpha1.CleaningModeDisabled
+	m.auditHostPatch(host)
+	return patchIfFound(ctx, helper, host)
+}
+
+// hostReservationTTL returns the provisioning timeout of the Metal3Machine,
+// HostReservationTTL unless overridden.
+func (m *MachineManager) hostReservationTTL() time.Duration {
+	if timeouts := m.Metal3Machine.Spec.Timeouts; timeouts != nil && timeouts.Provisioning != nil {
+		return timeouts.Provisioning.Duration
+	}
+	return HostReservationTTL
+}
+
+// deprovisionTimeout returns the deprovision timeout of the Metal3Machine,
+// DeprovisionTimeout unless overridden.
+func (m *MachineManager) deprovisionTimeout() time.Duration {
+	if timeouts := m.Metal3Machine.Spec.Timeouts; timeouts != nil && timeouts.Deprovision != nil {
+		return timeouts.Deprovision.Duration
+	}
+	return DeprovisionTimeout
+}
+
+// powerOnGracePeriod returns the power-on grace period of the Metal3Machine,
+// PowerOnGracePeriod unless overridden.
+func (m *MachineManager) powerOnGracePeriod() time.Duration {
+	if timeouts := m.Metal3Machine.Spec.Timeouts; timeouts != nil && timeouts.PowerOn != nil {
+		return timeouts.PowerOn.Duration
+	}
+	return PowerOnGracePeriod
+}
+
+// hostErrorGracePeriod returns the host error grace period of the
+// Metal3Machine, HostErrorGracePeriod unless overridden.
+func (m *MachineManager) hostErrorGracePeriod() time.Duration {
+	if timeouts := m.Metal3Machine.Spec.Timeouts; timeouts != nil && timeouts.HostError != nil {
+		return timeouts.HostError.Duration
+	}
+	return HostErrorGracePeriod
+}
+
+// checkHostError sets the HostHealthy condition to false while the host
+// reports an error, and back to true once it recovers. A transient error is
+// returned until the host has been in error for its host error grace period,
+// then the Metal3Machine is marked failed.
+func (m *MachineManager) checkHostError(host *bmov1alpha1.BareMetalHost) error {
+	if host.Status.ErrorMessage == "" {
+		m.SetConditionMetal3MachineToTrue(infrav1.HostHealthyCondition)
+		return nil
+	}
+
+	// The transition time of the condition is only updated on a change of
+	// state, so it records when the host was first seen in error. The error
+	// message of the host is not part of the condition, since a change of the
+	// message would reset the grace period.
+	m.SetConditionMetal3MachineToFalse(infrav1.HostHealthyCondition,
+		infrav1.HostErrorReason, clusterv1.ConditionSeverityError,
+		"BareMetalHost %s is in error", host.Name,
+	)
+	gracePeriod := m.hostErrorGracePeriod()
+	if gracePeriod <= 0 {
+		return nil
+	}
+	errorSince := conditions.GetLastTransitionTime(m.Metal3Machine, infrav1.HostHealthyCondition)
+	if remaining := gracePeriod - time.Since(errorSince.Time); remaining > 0 {
+		errMessage := "BareMetalHost in error, requeuing until the grace period elapsed"
+		m.Log.Info(errMessage, "host", host.Name, "errorType", host.Status.ErrorType,
+			"remaining", remaining)
+		return WithTransientError(errors.New(errMessage), remaining)
+	}
+	reason := capierrors.CreateMachineError
+	if m.IsProvisioned() {
+		reason = capierrors.UpdateMachineError
+	}
+	m.SetError(fmt.Sprintf("BareMetalHost %s in error for more than %s: %s",
+		host.Name, gracePeriod, host.Status.ErrorMessage), reason)
+	return nil
+}
+
 // SetConditionMetal3MachineToFalse sets Metal3Machine condition status to False.
 func (m *MachineManager) SetConditionMetal3MachineToFalse(t clusterv1.ConditionType, reason string, severity clusterv1.ConditionSeverity, messageFormat string, messageArgs ...interface{}) {
 	conditions.MarkFalse(m.Metal3Machine, t, reason, severity, messageFormat, messageArgs...)
@@ -1185,12 +2692,22 @@ func (m *MachineManager) SetConditionMetal3MachineToTrue(t clusterv1.ConditionTy
 }
 
 // updateMachineStatus updates a Metal3Machine object's status.
-func (m *MachineManager) updateMachineStatus(_ context.Context, host *bmov1alpha1.BareMetalHost) error {
+func (m *MachineManager) updateMachineStatus(ctx context.Context, host *bmov1alpha1.BareMetalHost) error {
 	addrs := m.nodeAddresses(host)
+	firmwareVersions, err := m.firmwareVersions(ctx, host)
+	if err != nil {
+		return err
+	}
 
 	metal3MachineOld := m.Metal3Machine.DeepCopy()
 
 	m.Metal3Machine.Status.Addresses = addrs
+	m.Metal3Machine.Status.BMCProtocol = bmcProtocol(host)
+	m.Metal3Machine.Status.BMCAddress = bmcAddress(host)
+	m.Metal3Machine.Status.FirmwareVersions = firmwareVersions
+	m.Metal3Machine.Status.LastInspected = lastInspected(host)
+	m.setProvisioningDuration(host)
+	m.countProvisioningAttempt(host)
 	conditions.MarkTrue(m.Metal3Machine, infrav1.AssociateBMHCondition)
 
 	if equality.Semantic.DeepEqual(m.Metal3Machine.Status, metal3MachineOld.Status) {
@@ -1203,6 +2720,138 @@ func (m *MachineManager) updateMachineStatus(_ context.Context, host *bmov1alpha
 	return nil
 }
 
+// firmwareVersions returns the current versions of the firmware components of
+// the host, as reported by its HostFirmwareComponents, or nil if they are not
+// available.
+func (m *MachineManager) firmwareVersions(ctx context.Context, host *bmov1alpha1.BareMetalHost) (map[string]string, error) {
+	hfc := bmov1alpha1.HostFirmwareComponents{}
+	key := client.ObjectKey{
+		Name:      host.Name,
+		Namespace: host.Namespace,
+	}
+	if err := m.client.Get(ctx, key, &hfc); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var versions map[string]string
+	for _, component := range hfc.Status.Components {
+		version := component.CurrentVersion
+		if version == "" {
+			version = component.InitialVersion
+		}
+		if version == "" {
+			continue
+		}
+		if versions == nil {
+			versions = make(map[string]string)
+		}
+		versions[component.Component] = version
+	}
+	return versions, nil
+}
+
+// lastInspected returns the time at which the last inspection of the host
+// completed, or nil if the host has not been inspected.
+func lastInspected(host *bmov1alpha1.BareMetalHost) *metav1.Time {
+	end := host.Status.OperationHistory.Inspect.End
+	if end.IsZero() {
+		return nil
+	}
+	return end.DeepCopy()
+}
+
+// setProvisioningDuration records the time elapsed since the association with
+// the host once the host is provisioned. The duration is only set once.
+func (m *MachineManager) setProvisioningDuration(host *bmov1alpha1.BareMetalHost) {
+	if m.Metal3Machine.Status.ProvisioningDuration != nil ||
+		m.Metal3Machine.Status.AssociatedAt == nil {
+		return
+	}
+	if host.Status.Provisioning.State != bmov1alpha1.StateProvisioned {
+		return
+	}
+	m.Metal3Machine.Status.ProvisioningDuration = &metav1.Duration{
+		Duration: time.Since(m.Metal3Machine.Status.AssociatedAt.Time).Round(time.Second),
+	}
+}
+
+// countProvisioningAttempt increments the provisioning attempts of the
+// Metal3Machine when the host started provisioning since the last attempt,
+// according to its operation history. Provisioning started before the
+// association is not counted.
+func (m *MachineManager) countProvisioningAttempt(host *bmov1alpha1.BareMetalHost) {
+	start := host.Status.OperationHistory.Provision.Start
+	if start.IsZero() {
+		return
+	}
+	if associatedAt := m.Metal3Machine.Status.AssociatedAt; associatedAt != nil && start.Before(associatedAt) {
+		return
+	}
+	if last := m.Metal3Machine.Status.LastProvisioningStart; last != nil && !last.Before(&start) {
+		return
+	}
+	m.Metal3Machine.Status.ProvisioningAttempts++
+	m.Metal3Machine.Status.LastProvisioningStart = start.DeepCopy()
+	if m.Metal3Machine.Status.ProvisioningAttempts > 1 {
+		m.Log.Info("BareMetalHost provisioning restarted", "host", host.Name,
+			"attempts", m.Metal3Machine.Status.ProvisioningAttempts)
+	}
+}
+
+// provisioningAttemptsExhausted marks the Metal3Machine failed when the
+// provisioning of the host failed and MaxProvisioningAttempts were made, and
+// returns whether it did.
+func (m *MachineManager) provisioningAttemptsExhausted(host *bmov1alpha1.BareMetalHost) bool {
+	attempts := m.Metal3Machine.Status.ProvisioningAttempts
+	if MaxProvisioningAttempts <= 0 || attempts < MaxProvisioningAttempts ||
+		host.Status.ErrorType != bmov1alpha1.ProvisioningError {
+		return false
+	}
+	message := fmt.Sprintf("BareMetalHost %s failed to provision %d times: %s",
+		host.Name, attempts, host.Status.ErrorMessage)
+	m.Log.Info("BareMetalHost provisioning attempts exhausted", "host", host.Name, "attempts", attempts)
+	m.recordEvent(corev1.EventTypeWarning, "ProvisioningAttemptsExhausted", "%s", message)
+	m.SetError(message, capierrors.CreateMachineError)
+	return true
+}
+
+// bmcProtocol returns the protocol of the BMC of the host, parsed from the
+// scheme of its BMC address without the transport, e.g. redfish for
+// redfish+https://. Addresses without scheme default to ipmi, as in the
+// BareMetal Operator.
+func bmcProtocol(host *bmov1alpha1.BareMetalHost) string {
+	if host == nil || host.Spec.BMC.Address == "" {
+		return ""
+	}
+	scheme, _, found := strings.Cut(host.Spec.BMC.Address, "://")
+	if !found {
+		return "ipmi"
+	}
+	protocol, _, _ := strings.Cut(strings.ToLower(scheme), "+")
+	return protocol
+}
+
+// bmcAddress returns the BMC address of the host without the user
+// information it may embed.
+func bmcAddress(host *bmov1alpha1.BareMetalHost) string {
+	if host == nil {
+		return ""
+	}
+	address := host.Spec.BMC.Address
+	if !strings.Contains(address, "://") {
+		return address
+	}
+	bmcURL, err := url.Parse(address)
+	if err != nil {
+		// Never mirror an address that could not be stripped of credentials.
+		return ""
+	}
+	bmcURL.User = nil
+	return bmcURL.String()
+}
+
 // NodeAddresses returns a slice of corev1.NodeAddress objects for a
 // given Metal3 machine.
 func (m *MachineManager) nodeAddresses(host *bmov1alpha1.BareMetalHost) []clusterv1.MachineAddress {
@@ -1325,6 +2974,20 @@ func (m *MachineManager) SetNodeProviderID(ctx context.Context, providerIDOnM3M
 
 		return WithTransientError(errors.New(errMessage), requeueAfter)
 	}
+	if countNodesWithLabel == 0 && NodeIPFallback {
+		nodes, countNodesWithLabel, err = m.getNodesWithInternalIP(ctx, clientFactory)
+		if err != nil {
+			errMessage := "error retrieving node, requeuing"
+			m.Log.Info(errMessage)
+			return WithTransientError(errors.New(errMessage), requeueAfter)
+		}
+		if countNodesWithLabel == 1 {
+			m.Log.Info("Found the target node by its internal IP", "node", nodes.Items[0].Name)
+		}
+		if countNodesWithLabel > 1 {
+			return errors.New("Found multiple target nodes with the internal IPs of the Metal3Machine")
+		}
+	}
 	if countNodesWithLabel == 0 {
 		// The node could either be still running cloud-init or have been
 		// deleted manually. TODO: handle a manual deletion case.
@@ -1360,6 +3023,10 @@ func (m *MachineManager) SetNodeProviderID(ctx context.Context, providerIDOnM3M
 		if err != nil {
 			return fmt.Errorf("failed to create patch for node %q: %w", node.GetName(), err)
 		}
+		if ReadOnly {
+			m.Log.Info("Read-only mode, skipping the write", "verb", "patch", "kind", "Node", "name", nodeVar.Name)
+			continue
+		}
 		_, err = corev1Remote.Nodes().Patch(ctx, nodeVar.Name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
 		if err != nil {
 			return errors.Wrap(err, "unable to update the target node with providerID")
@@ -1369,6 +3036,56 @@ func (m *MachineManager) SetNodeProviderID(ctx context.Context, providerIDOnM3M
 	return nil
 }
 
+// SetNodeRole applies the NodeRole of the Metal3Machine as node-role label on
+// the kubernetes node with the given provider ID.
+func (m *MachineManager) SetNodeRole(ctx context.Context, providerID string, clientFactory ClientGetter) error {
+	role := m.Metal3Machine.Spec.NodeRole
+	if role == "" {
+		return nil
+	}
+	corev1Remote, err := clientFactory(ctx, m.client, m.Cluster)
+	if err != nil {
+		return errors.Wrap(err, "Error creating a remote client")
+	}
+	nodes, err := corev1Remote.Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		m.Log.Error(err, "error while retrieving nodes")
+		return WithTransientError(errors.New("error retrieving node, requeuing"), requeueAfter)
+	}
+
+	roleLabel := infrav1.NodeRoleLabelPrefix + role
+	for _, node := range nodes.Items {
+		if providerID == "" || node.Spec.ProviderID != providerID {
+			continue
+		}
+		if _, ok := node.Labels[roleLabel]; ok {
+			return nil
+		}
+		patchBytes, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]string{roleLabel: ""},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to json.Marshal node role label: %w", err)
+		}
+		if ReadOnly {
+			m.Log.Info("Read-only mode, skipping the write", "verb", "patch", "kind", "Node", "name", node.Name)
+			return nil
+		}
+		_, err = corev1Remote.Nodes().Patch(ctx, node.Name, types.StrategicMergePatchType, patchBytes, metav1.PatchOptions{})
+		if err != nil {
+			return errors.Wrap(err, "unable to set the node role label on the target node")
+		}
+		m.Log.Info("Node role label set on target node", "node", node.Name, "label", roleLabel)
+		return nil
+	}
+
+	errMessage := fmt.Sprintf("requeuing, could not find node with providerID: %s", providerID)
+	m.Log.Info(errMessage)
+	return WithTransientError(errors.New(errMessage), requeueAfter)
+}
+
 // SetProviderID sets the metal3 provider ID on the Metal3Machine.
 func (m *MachineManager) SetProviderID(providerID string) {
 	m.Log.Info("ProviderID set on the Metal3Machine", "providerID", providerID)
@@ -1475,10 +3192,26 @@ func findOwnerRefFromList(refList []metav1.OwnerReference, objType metav1.TypeMe
 	return 0, &NotFoundError{}
 }
 
+// dataTemplate returns the Metal3DataTemplate referenced by the Metal3Machine,
+// or else a copy of the default one of the Metal3Cluster. An explicit
+// reference always takes precedence. The Metal3Machine is left unchanged.
+func (m *MachineManager) dataTemplate() *corev1.ObjectReference {
+	if m.Metal3Machine.Spec.DataTemplate != nil {
+		return m.Metal3Machine.Spec.DataTemplate
+	}
+	if m.Metal3Cluster == nil || m.Metal3Cluster.Spec.DefaultDataTemplate == nil {
+		return nil
+	}
+	return m.Metal3Cluster.Spec.DefaultDataTemplate.DeepCopy()
+}
+
 // AssociateM3Metadata fetches the Metal3DataTemplate object and sets the
 // owner references.
 func (m *MachineManager) AssociateM3Metadata(ctx context.Context) error {
 	// If the secrets were provided by the user, use them.
+	if err := m.checkProvidedSecretsExist(ctx); err != nil {
+		return err
+	}
 	if m.Metal3Machine.Spec.MetaData != nil {
 		m.Metal3Machine.Status.MetaData = m.Metal3Machine.Spec.MetaData
 	}
@@ -1492,11 +3225,15 @@ func (m *MachineManager) AssociateM3Metadata(ctx context.Context) error {
 		return nil
 	}
 
-	if m.Metal3Machine.Spec.DataTemplate == nil {
+	dataTemplate := m.dataTemplate()
+	if dataTemplate == nil {
 		return nil
 	}
-	if m.Metal3Machine.Spec.DataTemplate.Namespace == "" {
-		m.Metal3Machine.Spec.DataTemplate.Namespace = m.Metal3Machine.Namespace
+	if dataTemplate.Namespace == "" {
+		dataTemplate.Namespace = m.Metal3Machine.Namespace
+	}
+	if m.Metal3Machine.Spec.DataTemplate == nil {
+		m.Log.Info("Using the default Metal3DataTemplate of the cluster", "dataTemplate", dataTemplate.Name)
 	}
 	_, err := fetchM3DataClaim(ctx, m.client, m.Log,
 		m.Metal3Machine.Name, m.Metal3Machine.Namespace,
@@ -1515,7 +3252,7 @@ func (m *MachineManager) AssociateM3Metadata(ctx context.Context) error {
 			Name:      m.Metal3Machine.Name,
 			Namespace: m.Metal3Machine.Namespace,
 			Finalizers: []string{
-				infrav1.MachineFinalizer,
+				MachineFinalizer,
 			},
 			OwnerReferences: []metav1.OwnerReference{
 				{
@@ -1529,7 +3266,7 @@ func (m *MachineManager) AssociateM3Metadata(ctx context.Context) error {
 			Labels: m.Metal3Machine.Labels,
 		},
 		Spec: infrav1.Metal3DataClaimSpec{
-			Template: *m.Metal3Machine.Spec.DataTemplate,
+			Template: *dataTemplate,
 		},
 	}
 
@@ -1540,6 +3277,35 @@ func (m *MachineManager) AssociateM3Metadata(ctx context.Context) error {
 	return nil
 }
 
+// checkProvidedSecretsExist checks that the user data, metadata and network
+// data secrets explicitly referenced by the Metal3Machine exist. The secrets
+// default to the namespace of the Metal3Machine.
+func (m *MachineManager) checkProvidedSecretsExist(ctx context.Context) error {
+	for _, ref := range []*corev1.SecretReference{
+		m.Metal3Machine.Spec.UserData,
+		m.Metal3Machine.Spec.MetaData,
+		m.Metal3Machine.Spec.NetworkData,
+	} {
+		if ref == nil {
+			continue
+		}
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = m.Metal3Machine.Namespace
+		}
+		_, err := checkSecretExists(ctx, m.client, ref.Name, namespace)
+		if apierrors.IsNotFound(err) {
+			errMessage := fmt.Sprintf("Waiting for secret %s/%s referenced by the Metal3Machine", namespace, ref.Name)
+			m.Log.Info(errMessage)
+			return WithTransientError(errors.New(errMessage), requeueAfter)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // WaitForM3Metadata fetches the Metal3DataTemplate object and sets the
 // owner references.
 func (m *MachineManager) WaitForM3Metadata(ctx context.Context) error {
@@ -1547,10 +3313,10 @@ func (m *MachineManager) WaitForM3Metadata(ctx context.Context) error {
 	// Metal3DataTemplate. If it is not there yet, it means that the reconciliation
 	// of Metal3DataTemplate did not yet complete, requeue.
 	if m.Metal3Machine.Status.RenderedData == nil {
-		if m.Metal3Machine.Spec.DataTemplate == nil {
+		if m.dataTemplate() == nil {
 			return nil
 		}
-		if m.Metal3Machine.Spec.DataTemplate.Namespace == "" {
+		if m.Metal3Machine.Spec.DataTemplate != nil && m.Metal3Machine.Spec.DataTemplate.Namespace == "" {
 			m.Metal3Machine.Spec.DataTemplate.Namespace = m.Metal3Machine.Namespace
 		}
 		metal3DataClaim, err := fetchM3DataClaim(ctx, m.client, m.Log,
@@ -1591,6 +3357,14 @@ func (m *MachineManager) WaitForM3Metadata(ctx context.Context) error {
 		return WithTransientError(errors.New(errMessage), requeueAfter)
 	}
 
+	// Wait for the requested secrets to be rendered again.
+	if metal3Data.Annotations[RerenderAnnotation] != "" {
+		errMessage := "Waiting for Metal3Data to be rendered again"
+		m.Log.Info(errMessage)
+		m.SetConditionMetal3MachineToFalse(infrav1.Metal3DataReadyCondition, infrav1.WaitingForMetal3DataReason, clusterv1.ConditionSeverityInfo, "")
+		return WithTransientError(errors.New(errMessage), requeueAfter)
+	}
+
 	// At this point, Metal3Data is ready
 	m.Log.Info("Metal3data is ready")
 	m.SetConditionMetal3MachineToTrue(infrav1.Metal3DataReadyCondition)
@@ -1616,6 +3390,15 @@ func (m *MachineManager) WaitForM3Metadata(ctx context.Context) error {
 		}
 	}
 
+	// The rendered UserData merges the bootstrap data, use it in its place.
+	if m.Metal3Machine.Spec.UserData == nil &&
+		metal3Data.Spec.UserData != nil && metal3Data.Spec.UserData.Name != "" {
+		m.Metal3Machine.Status.UserData = &corev1.SecretReference{
+			Name:      metal3Data.Spec.UserData.Name,
+			Namespace: metal3Data.Namespace,
+		}
+	}
+
 	return nil
 }
 
@@ -1629,6 +3412,12 @@ func (m *MachineManager) DissociateM3Metadata(ctx context.Context) error {
 		m.Metal3Machine.Status.NetworkData = nil
 	}
 
+	// Fall back to the bootstrap data if the UserData was rendered.
+	if m.Metal3Machine.Status.UserData != nil && m.Metal3Machine.Spec.UserData == nil &&
+		m.Metal3Machine.Status.UserData.Name == m.Metal3Machine.Name+userDataSuffix {
+		m.Metal3Machine.Status.UserData = nil
+	}
+
 	m.Metal3Machine.Status.RenderedData = nil
 
 	// Get the Metal3DataClaim object.
@@ -1646,7 +3435,7 @@ func (m *MachineManager) DissociateM3Metadata(ctx context.Context) error {
 		return nil
 	}
 
-	controllerutil.RemoveFinalizer(metal3DataClaim, infrav1.MachineFinalizer)
+	controllerutil.RemoveFinalizer(metal3DataClaim, MachineFinalizer)
 	err = updateObject(ctx, m.client, metal3DataClaim)
 	if err != nil && !apierrors.IsNotFound(err) {
 		m.Log.Info("Unable to remove finalizers from Metal3DataClaim", "Metal3DataClaim", metal3DataClaim.Name)
@@ -1801,6 +3590,40 @@ func (m *MachineManager) getNodesWithLabel(ctx context.Context, nodeLabel string
 	return nodes, nodesCount, err
 }
 
+// getNodesWithInternalIP gets the kubernetes nodes with an InternalIP address
+// among the internal IP addresses of the Metal3Machine.
+func (m *MachineManager) getNodesWithInternalIP(ctx context.Context, clientFactory ClientGetter) (*corev1.NodeList, int, error) {
+	machineIPs := map[string]struct{}{}
+	for _, address := range m.Metal3Machine.Status.Addresses {
+		if address.Type == clusterv1.MachineInternalIP && address.Address != "" {
+			machineIPs[address.Address] = struct{}{}
+		}
+	}
+	matchingNodes := &corev1.NodeList{}
+	if len(machineIPs) == 0 {
+		return matchingNodes, 0, nil
+	}
+
+	corev1Remote, err := clientFactory(ctx, m.client, m.Cluster)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "Error creating a remote client")
+	}
+	nodes, err := corev1Remote.Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		m.Log.Error(err, "error while retrieving nodes")
+		return nil, 0, err
+	}
+	for _, node := range nodes.Items {
+		for _, address := range node.Status.Addresses {
+			if _, ok := machineIPs[address.Address]; ok && address.Type == corev1.NodeInternalIP {
+				matchingNodes.Items = append(matchingNodes.Items, node)
+				break
+			}
+		}
+	}
+	return matchingNodes, len(matchingNodes.Items), nil
+}
+
 // getMatchingNodesWithoutLabelCount tLabel gets kubernetes nodes based on their Spec.providerID field.
 func (m *MachineManager) getMatchingNodesWithoutLabelCount(ctx context.Context, providerIDLegacy, providerIDNew string, providerIDonM3M *string, clientFactory ClientGetter) (int, error) {
 	corev1Remote, err := clientFactory(ctx, m.client, m.Cluster)
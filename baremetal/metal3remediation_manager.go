@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -30,6 +31,7 @@ import (
 	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -44,8 +46,44 @@ const (
 	powerOffAnnotation              = "reboot.metal3.io/metal3-remediation-%s"
 	nodeAnnotationsBackupAnnotation = "remediation.metal3.io/node-annotations-backup"
 	nodeLabelsBackupAnnotation      = "remediation.metal3.io/node-labels-backup"
+	healthySinceAnnotation          = "remediation.metal3.io/healthy-since"
+	rebootRequestedAnnotation       = "remediation.metal3.io/reboot-requested-at"
 )
 
+const (
+	// RemediatedByAnnotation is set on a BareMetalHost powered off by a
+	// Metal3Remediation. Its value is the namespaced name of the remediation.
+	RemediatedByAnnotation = "remediation.metal3.io/remediated-by"
+	// RemediatedAtAnnotation is set along RemediatedByAnnotation, with the
+	// time of the power off in RFC 3339 format.
+	RemediatedAtAnnotation = "remediation.metal3.io/remediated-at"
+)
+
+// UnhealthyAnnotationGracePeriod is the duration a node must have been healthy
+// before the unhealthy annotation is removed from its BareMetalHost.
+var UnhealthyAnnotationGracePeriod time.Duration
+
+// RemediationFinalizer is the finalizer set on the Metal3Remediations.
+// Instances sharing the resources must use distinct values.
+var RemediationFinalizer = infrav1.RemediationFinalizer
+
+// RebootAnnotationTimeout is the duration after which a power off annotation
+// not acted upon by the BareMetal Operator is considered stale.
+var RebootAnnotationTimeout time.Duration
+
+// HostGoneTimeout is the duration after which a remediation whose unhealthy
+// host cannot be found anymore is given up. Zero disables the timeout.
+var HostGoneTimeout time.Duration
+
+// WorkloadClusterTimeout bounds each call to the API server of the workload
+// cluster, so that an unreachable cluster does not block the reconcile. Zero
+// disables the timeout.
+var WorkloadClusterTimeout = 30 * time.Second
+
+// HostGoneDeleteMachine defines whether the Machine of a remediation whose
+// host is gone is handed over to Cluster API for deletion.
+var HostGoneDeleteMachine bool
+
 // RemediationManagerInterface is an interface for a RemediationManager.
 type RemediationManagerInterface interface {
 	SetFinalizer()
@@ -55,9 +93,14 @@ type RemediationManagerInterface interface {
 	SetPowerOffAnnotation(ctx context.Context) error
 	RemovePowerOffAnnotation(ctx context.Context) error
 	IsPowerOffRequested(ctx context.Context) (bool, error)
+	RebootAnnotationExpired(timeout time.Duration) bool
+	RecordRebootRequestedTime()
 	IsPoweredOn(ctx context.Context) (bool, error)
 	SetUnhealthyAnnotation(ctx context.Context) error
+	ClearUnhealthyAnnotation(ctx context.Context) (bool, error)
 	GetUnhealthyHost(ctx context.Context) (*bmov1alpha1.BareMetalHost, *patch.Helper, error)
+	HostGoneTimedOut(timeout time.Duration) (bool, time.Duration)
+	ResetHostNotFoundTime()
 	OnlineStatus(host *bmov1alpha1.BareMetalHost) bool
 	GetRemediationType() infrav1.RemediationType
 	RetryLimitIsSet() bool
@@ -68,8 +111,12 @@ type RemediationManagerInterface interface {
 	SetLastRemediationTime(remediationTime *metav1.Time)
 	GetTimeout() *metav1.Duration
 	IncreaseRetryCount()
+	IncreaseRebootCount()
+	HasReachRebootsBeforeEscalation() bool
+	Escalate()
 	SetOwnerRemediatedConditionNew(ctx context.Context) error
 	GetCapiMachine(ctx context.Context) (*clusterv1.Machine, error)
+	ResolveMachineSelector(ctx context.Context) (*clusterv1.Machine, error)
 	GetNode(ctx context.Context, clusterClient v1.CoreV1Interface) (*corev1.Node, error)
 	UpdateNode(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) error
 	DeleteNode(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) error
@@ -81,6 +128,12 @@ type RemediationManagerInterface interface {
 	RemoveOutOfServiceTaint(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) error
 	HasOutOfServiceTaint(node *corev1.Node) bool
 	IsNodeDrained(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) bool
+	CordonNode(ctx context.Context) error
+	RequestReassociation(ctx context.Context, host *bmov1alpha1.BareMetalHost) error
+	NodeIsHealthy(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) (bool, error)
+	AddRemediationTaint(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) error
+	RemoveRemediationTaint(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) error
+	RecordEvent(eventType, reason, messageFmt string, args ...interface{})
 }
 
 var outOfServiceTaint = &corev1.Taint{
@@ -89,6 +142,13 @@ var outOfServiceTaint = &corev1.Taint{
 	Effect: corev1.TaintEffectNoExecute,
 }
 
+// remediationTaint is set on the node while it is remediated, so that no new
+// workload is scheduled on it.
+var remediationTaint = corev1.Taint{
+	Key:    "metal3.io/remediation",
+	Effect: corev1.TaintEffectNoSchedule,
+}
+
 // RemediationManager is responsible for performing remediation reconciliation.
 type RemediationManager struct {
 	Client            client.Client
@@ -107,7 +167,7 @@ func NewRemediationManager(client client.Client, capiClientGetter ClientGetter,
 	metal3remediation *infrav1.Metal3Remediation, metal3Machine *infrav1.Metal3Machine, machine *clusterv1.Machine,
 	remediationLog logr.Logger) (*RemediationManager, error) {
 	return &RemediationManager{
-		Client:            client,
+		Client:            managerClient(client, remediationLog),
 		CapiClientGetter:  capiClientGetter,
 		Metal3Remediation: metal3remediation,
 		Metal3Machine:     metal3Machine,
@@ -118,17 +178,17 @@ func NewRemediationManager(client client.Client, capiClientGetter ClientGetter,
 
 // SetFinalizer sets finalizer. Return if it was set.
 func (r *RemediationManager) SetFinalizer() {
-	controllerutil.AddFinalizer(r.Metal3Remediation, infrav1.RemediationFinalizer)
+	controllerutil.AddFinalizer(r.Metal3Remediation, RemediationFinalizer)
 }
 
 // UnsetFinalizer unsets finalizer.
 func (r *RemediationManager) UnsetFinalizer() {
-	controllerutil.RemoveFinalizer(r.Metal3Remediation, infrav1.RemediationFinalizer)
+	controllerutil.RemoveFinalizer(r.Metal3Remediation, RemediationFinalizer)
 }
 
 // HasFinalizer returns if finalizer is set.
 func (r *RemediationManager) HasFinalizer() bool {
-	return controllerutil.ContainsFinalizer(r.Metal3Remediation, infrav1.RemediationFinalizer)
+	return controllerutil.ContainsFinalizer(r.Metal3Remediation, RemediationFinalizer)
 }
 
 // TimeToRemediate checks if it is time to execute a next remediation step
@@ -169,11 +229,22 @@ func (r *RemediationManager) SetPowerOffAnnotation(ctx context.Context) error {
 		return err
 	}
 
-	if host.Annotations == nil {
-		host.Annotations = make(map[string]string)
+	r.setRebootRequestedTime(time.Now())
+	if err := patchWithConflictRetry(ctx, r.Client, host, helper, func() {
+		before := host.DeepCopy()
+		if host.Annotations == nil {
+			host.Annotations = make(map[string]string)
+		}
+		host.Annotations[r.getPowerOffAnnotationKey()] = string(marshalledMode)
+		host.Annotations[RemediatedByAnnotation] = r.Metal3Remediation.Namespace + "/" + r.Metal3Remediation.Name
+		host.Annotations[RemediatedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+		r.auditHostPatch(before, host)
+	}); err != nil {
+		return err
 	}
-	host.Annotations[r.getPowerOffAnnotationKey()] = string(marshalledMode)
-	return helper.Patch(ctx, host)
+	recordHostPowerCycle(host)
+	r.RecordEvent(corev1.EventTypeNormal, "RebootIssued", "Powering off host %s for a reboot", host.Name)
+	return nil
 }
 
 // RemovePowerOffAnnotation removes poweroff annotation from unhealthy host.
@@ -187,8 +258,41 @@ func (r *RemediationManager) RemovePowerOffAnnotation(ctx context.Context) error
 	}
 
 	r.Log.Info("Removing PowerOff annotation from host", "host name", host.Name)
-	delete(host.Annotations, r.getPowerOffAnnotationKey())
-	return helper.Patch(ctx, host)
+	delete(r.Metal3Remediation.Annotations, rebootRequestedAnnotation)
+	return patchWithConflictRetry(ctx, r.Client, host, helper, func() {
+		before := host.DeepCopy()
+		delete(host.Annotations, r.getPowerOffAnnotationKey())
+		r.auditHostPatch(before, host)
+	})
+}
+
+// RebootAnnotationExpired returns true if the power off annotation was set on
+// the host for longer than the timeout. The time the annotation was set is kept
+// in an annotation on the remediation, the annotation is not expired without a
+// valid time.
+func (r *RemediationManager) RebootAnnotationExpired(timeout time.Duration) bool {
+	requestedAt, err := time.Parse(time.RFC3339, r.Metal3Remediation.Annotations[rebootRequestedAnnotation])
+	if err != nil {
+		return false
+	}
+	return time.Since(requestedAt) > timeout
+}
+
+// RecordRebootRequestedTime records now as the time the power off annotation
+// was set, unless a valid time is recorded already. The time is missing for
+// example if the controller stopped before persisting it.
+func (r *RemediationManager) RecordRebootRequestedTime() {
+	if _, err := time.Parse(time.RFC3339, r.Metal3Remediation.Annotations[rebootRequestedAnnotation]); err != nil {
+		r.setRebootRequestedTime(time.Now())
+	}
+}
+
+// setRebootRequestedTime records when the power off annotation was set.
+func (r *RemediationManager) setRebootRequestedTime(requestedAt time.Time) {
+	if r.Metal3Remediation.Annotations == nil {
+		r.Metal3Remediation.Annotations = make(map[string]string)
+	}
+	r.Metal3Remediation.Annotations[rebootRequestedAnnotation] = requestedAt.Format(time.RFC3339)
 }
 
 // IsPowerOffRequested returns true if poweroff annotation is set.
@@ -231,17 +335,79 @@ func (r *RemediationManager) SetUnhealthyAnnotation(ctx context.Context) error {
 	}
 
 	r.Log.Info("Adding Unhealthy annotation to host", "host", host.Name)
-	if host.Annotations == nil {
-		host.Annotations = make(map[string]string, 1)
+	return patchWithConflictRetry(ctx, r.Client, host, helper, func() {
+		before := host.DeepCopy()
+		if host.Annotations == nil {
+			host.Annotations = make(map[string]string, 1)
+		}
+		host.Annotations[infrav1.UnhealthyAnnotation] = "capm3/UnhealthyNode"
+		delete(host.Annotations, healthySinceAnnotation)
+		r.auditHostPatch(before, host)
+	})
+}
+
+// ClearUnhealthyAnnotation removes capm3.UnhealthyAnnotation from the host once the
+// machine has passed its health check continuously for UnhealthyAnnotationGracePeriod.
+// The time the machine was first seen healthy is kept in an annotation on the host,
+// which is removed again when the machine turns unhealthy. Returns true when the host
+// no longer carries the unhealthy annotation.
+func (r *RemediationManager) ClearUnhealthyAnnotation(ctx context.Context) (bool, error) {
+	host, helper, err := r.GetUnhealthyHost(ctx)
+	if err != nil {
+		return false, err
+	}
+	if host == nil {
+		return false, errors.New("Unable to clear the Unhealthy Annotation, Host not found")
+	}
+	before := host.DeepCopy()
+	if _, ok := host.Annotations[infrav1.UnhealthyAnnotation]; !ok {
+		return true, nil
+	}
+	if r.Machine == nil {
+		return false, errors.New("Unable to clear the Unhealthy Annotation, Machine not found")
 	}
-	host.Annotations[infrav1.UnhealthyAnnotation] = "capm3/UnhealthyNode"
-	return helper.Patch(ctx, host)
+
+	if !conditions.IsTrue(r.Machine, clusterv1.MachineHealthCheckSucceededCondition) {
+		if _, ok := host.Annotations[healthySinceAnnotation]; !ok {
+			return false, nil
+		}
+		r.Log.Info("Machine is unhealthy again, resetting the healthy grace period", "host", host.Name)
+		delete(host.Annotations, healthySinceAnnotation)
+		r.auditHostPatch(before, host)
+		return false, helper.Patch(ctx, host)
+	}
+
+	now := time.Now()
+	healthySince, err := time.Parse(time.RFC3339, host.Annotations[healthySinceAnnotation])
+	if err != nil {
+		// The machine was not seen healthy before, start the grace period.
+		healthySince = now
+		host.Annotations[healthySinceAnnotation] = now.Format(time.RFC3339)
+	}
+
+	if now.Sub(healthySince) < UnhealthyAnnotationGracePeriod {
+		r.auditHostPatch(before, host)
+		return false, helper.Patch(ctx, host)
+	}
+
+	r.Log.Info("Removing Unhealthy annotation from host", "host", host.Name)
+	delete(host.Annotations, infrav1.UnhealthyAnnotation)
+	delete(host.Annotations, healthySinceAnnotation)
+	r.auditHostPatch(before, host)
+	return true, helper.Patch(ctx, host)
 }
 
 // GetUnhealthyHost gets the associated host for unhealthy machine. Returns nil if not found. Assumes the
-// host is in the same namespace as the unhealthy machine.
+// host is in the same namespace as the unhealthy machine. When there is no Metal3Machine, the host
+// referenced by the Metal3Remediation is returned instead.
 func (r *RemediationManager) GetUnhealthyHost(ctx context.Context) (*bmov1alpha1.BareMetalHost, *patch.Helper, error) {
-	host, err := getUnhealthyHost(ctx, r.Metal3Machine, r.Client, r.Log)
+	var host *bmov1alpha1.BareMetalHost
+	var err error
+	if r.Metal3Machine == nil {
+		host, err = getReferencedHost(ctx, r.Metal3Remediation, r.Client, r.Log)
+	} else {
+		host, err = getUnhealthyHost(ctx, r.Metal3Machine, r.Client, r.Log)
+	}
 	if err != nil || host == nil {
 		return host, nil, err
 	}
@@ -268,14 +434,38 @@ func getUnhealthyHost(ctx context.Context, m3Machine *infrav1.Metal3Machine, cl
 		return nil, err
 	}
 
+	return fetchUnhealthyHost(ctx, hostName, hostNamespace, cl, rLog)
+}
+
+// getReferencedHost returns the host referenced by a Metal3Remediation which is not
+// owned by a Machine. The host namespace defaults to the Metal3Remediation namespace.
+func getReferencedHost(ctx context.Context, remediation *infrav1.Metal3Remediation, cl client.Client,
+	rLog logr.Logger,
+) (*bmov1alpha1.BareMetalHost, error) {
+	hostRef := remediation.Spec.HostRef
+	if hostRef == nil || hostRef.Name == "" {
+		err := fmt.Errorf("unable to get %s hostRef", remediation.Name)
+		return nil, err
+	}
+	hostNamespace := hostRef.Namespace
+	if hostNamespace == "" {
+		hostNamespace = remediation.Namespace
+	}
+
+	return fetchUnhealthyHost(ctx, hostRef.Name, hostNamespace, cl, rLog)
+}
+
+func fetchUnhealthyHost(ctx context.Context, hostName, hostNamespace string, cl client.Client,
+	rLog logr.Logger,
+) (*bmov1alpha1.BareMetalHost, error) {
 	host := bmov1alpha1.BareMetalHost{}
 	key := client.ObjectKey{
 		Name:      hostName,
 		Namespace: hostNamespace,
 	}
-	err = cl.Get(ctx, key, &host)
+	err := cl.Get(ctx, key, &host)
 	if apierrors.IsNotFound(err) {
-		rLog.Info("Annotated host not found", "host", hostKey)
+		rLog.Info("Unhealthy host not found", "host", key.String())
 		return nil, err
 	} else if err != nil {
 		return nil, err
@@ -283,13 +473,54 @@ func getUnhealthyHost(ctx context.Context, m3Machine *infrav1.Metal3Machine, cl
 	return &host, nil
 }
 
+// HostGoneTimedOut records when the unhealthy host was first found to be missing and
+// checks if it has been missing for longer than timeout. It returns the duration
+// until the timeout expires otherwise.
+func (r *RemediationManager) HostGoneTimedOut(timeout time.Duration) (bool, time.Duration) {
+	now := time.Now()
+
+	if r.Metal3Remediation.Status.HostNotFoundSince == nil {
+		r.Log.Info("Unhealthy host is missing, starting host gone timeout", "timeout", timeout)
+		r.Metal3Remediation.Status.HostNotFoundSince = &metav1.Time{Time: now}
+		return false, timeout
+	}
+
+	missingFor := now.Sub(r.Metal3Remediation.Status.HostNotFoundSince.Time)
+	if missingFor >= timeout {
+		return true, time.Duration(0)
+	}
+	return false, timeout - missingFor + time.Second
+}
+
+// ResetHostNotFoundTime clears the time the unhealthy host was found to be missing.
+func (r *RemediationManager) ResetHostNotFoundTime() {
+	r.Metal3Remediation.Status.HostNotFoundSince = nil
+}
+
 // OnlineStatus returns hosts Online field value.
 func (r *RemediationManager) OnlineStatus(host *bmov1alpha1.BareMetalHost) bool {
 	return host.Spec.Online
 }
 
+// IsActiveRemediation returns true if the remediation is neither being deleted
+// nor finished. Quarantined remediations count as active, the host is kept
+// unhealthy on purpose.
+func IsActiveRemediation(remediation *infrav1.Metal3Remediation) bool {
+	if !remediation.DeletionTimestamp.IsZero() {
+		return false
+	}
+	switch remediation.Status.Phase {
+	case infrav1.PhaseFailed, infrav1.PhaseHostGone, infrav1.PhaseSucceeded:
+		return false
+	}
+	return true
+}
+
 // GetRemediationType return type of remediation strategy.
 func (r *RemediationManager) GetRemediationType() infrav1.RemediationType {
+	if r.Metal3Remediation.Status.EscalatedType != "" {
+		return r.Metal3Remediation.Status.EscalatedType
+	}
 	if r.Metal3Remediation.Spec.Strategy == nil {
 		return ""
 	}
@@ -312,10 +543,62 @@ func (r *RemediationManager) HasReachRetryLimit() bool {
 	return r.Metal3Remediation.Spec.Strategy.RetryLimit == r.Metal3Remediation.Status.RetryCount
 }
 
-// SetRemediationPhase setting the state of the remediation.
+// SetRemediationPhase setting the state of the remediation. The transitions
+// of the remediation timeline are recorded as events.
 func (r *RemediationManager) SetRemediationPhase(phase string) {
 	r.Log.Info("Switching remediation phase", "remediationPhase", phase)
+	previous := r.Metal3Remediation.Status.Phase
 	r.Metal3Remediation.Status.Phase = phase
+	if phase == previous {
+		return
+	}
+	switch phase {
+	case infrav1.PhaseRunning:
+		if previous == "" {
+			r.RecordEvent(corev1.EventTypeNormal, "RemediationStarted", "Started remediation of host %s", r.hostName())
+		}
+	case infrav1.PhaseWaiting:
+		r.RecordEvent(corev1.EventTypeNormal, "WaitingForRecovery", "Waiting for host %s to recover", r.hostName())
+	case infrav1.PhaseSucceeded:
+		r.RecordEvent(corev1.EventTypeNormal, "RemediationSucceeded", "Host %s remediated", r.hostName())
+	case infrav1.PhaseReassociating:
+		r.RecordEvent(corev1.EventTypeNormal, "HostFenced",
+			"Host %s fenced, associating the Metal3Machine with another host", r.hostName())
+	case infrav1.PhaseDeleting:
+		r.RecordEvent(corev1.EventTypeWarning, "RetryLimitReached",
+			"Host %s still unhealthy after %d retries", r.hostName(), r.Metal3Remediation.Status.RetryCount)
+	}
+}
+
+// auditHostPatch logs the changes about to be patched on the host. before is
+// the host as its patch helper was created from, i.e. as last read.
+func (r *RemediationManager) auditHostPatch(before, host *bmov1alpha1.BareMetalHost) {
+	auditHostPatch(r.Log, remediationAuditActor, before, host)
+}
+
+// RecordEvent records an event on the Metal3Remediation if an EventRecorder
+// is set.
+func (r *RemediationManager) RecordEvent(eventType, reason, messageFmt string, args ...interface{}) {
+	if EventRecorder == nil {
+		return
+	}
+	EventRecorder.Eventf(r.Metal3Remediation, eventType, reason, messageFmt, args...)
+}
+
+// hostName returns the name of the remediated host, from the Metal3Machine
+// host annotation or the host reference, without fetching the host.
+func (r *RemediationManager) hostName() string {
+	if r.Metal3Machine == nil {
+		if r.Metal3Remediation.Spec.HostRef == nil {
+			return ""
+		}
+		return r.Metal3Remediation.Spec.HostRef.Name
+	}
+	_, name, err := cache.SplitMetaNamespaceKey(r.Metal3Machine.Annotations[HostAnnotation])
+	if err != nil {
+		return ""
+	}
+	return name
 }
 
 // GetRemediationPhase returns current status of the remediation.
@@ -344,6 +627,38 @@ func (r *RemediationManager) IncreaseRetryCount() {
 	r.Metal3Remediation.Status.RetryCount++
 }
 
+// IncreaseRebootCount increases the reboot count on Status.
+func (r *RemediationManager) IncreaseRebootCount() {
+	r.Metal3Remediation.Status.RebootCount++
+}
+
+// HasReachRebootsBeforeEscalation returns true if the remediation has not
+// escalated yet and the host has been rebooted RebootsBeforeEscalation times.
+func (r *RemediationManager) HasReachRebootsBeforeEscalation() bool {
+	strategy := r.Metal3Remediation.Spec.Strategy
+	if strategy == nil || strategy.RebootsBeforeEscalation <= 0 || strategy.EscalationType == "" {
+		return false
+	}
+	if r.Metal3Remediation.Status.EscalatedType != "" {
+		return false
+	}
+	return r.Metal3Remediation.Status.RebootCount >= strategy.RebootsBeforeEscalation
+}
+
+// Escalate switches the remediation to the EscalationType strategy, which
+// starts over from the initial phase. The finalizer guarding the restore of
+// the node after a reboot is removed, the escalation strategies don't restore
+// the node.
+func (r *RemediationManager) Escalate() {
+	escalationType := r.Metal3Remediation.Spec.Strategy.EscalationType
+	r.RecordEvent(corev1.EventTypeWarning, "RemediationEscalated",
+		"Host %s still unhealthy after %d reboots, escalating to the %s strategy",
+		r.hostName(), r.Metal3Remediation.Status.RebootCount, escalationType)
+	r.Metal3Remediation.Status.EscalatedType = escalationType
+	r.UnsetFinalizer()
+	r.SetRemediationPhase("")
+}
+
 // SetOwnerRemediatedConditionNew sets MachineOwnerRemediatedCondition on CAPI machine object
 // that have failed a healthcheck.
 func (r *RemediationManager) SetOwnerRemediatedConditionNew(ctx context.Context) error {
@@ -377,6 +692,56 @@ func (r *RemediationManager) GetCapiMachine(ctx context.Context) (*clusterv1.Mac
 	return capiMachine, nil
 }
 
+// ResolveMachineSelector returns the Machine matched by the Machine selector of
+// the remediation, which must match exactly one Machine of its namespace. The
+// Machine is set as owner of the remediation, so that the remediation keeps
+// targeting it. It returns nil if the remediation has no Machine selector.
+func (r *RemediationManager) ResolveMachineSelector(ctx context.Context) (*clusterv1.Machine, error) {
+	if r.Metal3Remediation.Spec.MachineSelector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(r.Metal3Remediation.Spec.MachineSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid Machine selector")
+	}
+	if selector.Empty() {
+		return nil, errors.New("the Machine selector must not be empty")
+	}
+
+	machines := clusterv1.MachineList{}
+	if err := r.Client.List(ctx, &machines,
+		client.InNamespace(r.Metal3Remediation.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list the Machines matching the Machine selector")
+	}
+	switch len(machines.Items) {
+	case 0:
+		return nil, errors.Errorf("no Machine matches the Machine selector %s", selector)
+	case 1:
+	default:
+		names := make([]string, 0, len(machines.Items))
+		for _, machine := range machines.Items {
+			names = append(names, machine.Name)
+		}
+		return nil, errors.Errorf("the Machine selector %s matches several Machines: %s",
+			selector, strings.Join(names, ", "),
+		)
+	}
+
+	machine := &machines.Items[0]
+	r.Log.Info("Machine selector resolved", "machine", machine.Name)
+	r.Metal3Remediation.OwnerReferences = util.EnsureOwnerRef(r.Metal3Remediation.OwnerReferences,
+		metav1.OwnerReference{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Machine",
+			Name:       machine.Name,
+			UID:        machine.UID,
+		},
+	)
+	return machine, nil
+}
+
 // GetNode returns the Node associated with the machine in the current context.
 func (r *RemediationManager) GetNode(ctx context.Context, clusterClient v1.CoreV1Interface) (*corev1.Node, error) {
 	capiMachine, err := r.GetCapiMachine(ctx)
@@ -389,7 +754,9 @@ func (r *RemediationManager) GetNode(ctx context.Context, clusterClient v1.CoreV
 		return nil, errors.Errorf("metal3Remediation's node could not be retrieved, machine's nodeRef is nil")
 	}
 
-	node, err := clusterClient.Nodes().Get(ctx, capiMachine.Status.NodeRef.Name, metav1.GetOptions{})
+	wctx, cancel := workloadClusterContext(ctx)
+	defer cancel()
+	node, err := clusterClient.Nodes().Get(wctx, capiMachine.Status.NodeRef.Name, metav1.GetOptions{})
 	if apierrors.IsNotFound(err) {
 		return nil, nil
 	} else if err != nil {
@@ -399,13 +766,32 @@ func (r *RemediationManager) GetNode(ctx context.Context, clusterClient v1.CoreV
 	return node, nil
 }
 
+// workloadClusterContext returns the context of a call to the workload
+// cluster, bounded by WorkloadClusterTimeout.
+func workloadClusterContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if WorkloadClusterTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, WorkloadClusterTimeout)
+}
+
 // UpdateNode updates the given node.
 func (r *RemediationManager) UpdateNode(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) error {
-	_, err := clusterClient.Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	if ReadOnly {
+		r.Log.Info("Read-only mode, skipping the write", "verb", "update", "kind", "Node", "name", node.Name)
+		return nil
+	}
+	wctx, cancel := workloadClusterContext(ctx)
+	defer cancel()
+	updatedNode, err := clusterClient.Nodes().Update(wctx, node, metav1.UpdateOptions{})
 	if err != nil {
 		r.Log.Error(err, "Could not update cluster node")
 		return errors.Wrapf(err, "Could not update cluster node")
 	}
+	// Keep the resource version up to date for further updates of the node.
+	if updatedNode != nil {
+		*node = *updatedNode
+	}
 	return nil
 }
 
@@ -415,7 +801,13 @@ func (r *RemediationManager) DeleteNode(ctx context.Context, clusterClient v1.Co
 		return nil
 	}
 
-	err := clusterClient.Nodes().Delete(ctx, node.Name, metav1.DeleteOptions{})
+	if ReadOnly {
+		r.Log.Info("Read-only mode, skipping the write", "verb", "delete", "kind", "Node", "name", node.Name)
+		return nil
+	}
+	wctx, cancel := workloadClusterContext(ctx)
+	defer cancel()
+	err := clusterClient.Nodes().Delete(wctx, node.Name, metav1.DeleteOptions{})
 	if err != nil {
 		r.Log.Error(err, "Could not delete cluster node")
 		return errors.Wrapf(err, "Could not delete cluster node")
@@ -423,6 +815,106 @@ func (r *RemediationManager) DeleteNode(ctx context.Context, clusterClient v1.Co
 	return nil
 }
 
+// CordonNode marks the node of the unhealthy machine unschedulable in the
+// workload cluster. Nothing is done if the node does not exist.
+func (r *RemediationManager) CordonNode(ctx context.Context) error {
+	clusterClient, err := r.GetClusterClient(ctx)
+	if err != nil {
+		return err
+	}
+	node, err := r.GetNode(ctx, clusterClient)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		r.Log.Info("Node not found, nothing to cordon")
+		return nil
+	}
+	if node.Spec.Unschedulable {
+		return nil
+	}
+
+	r.Log.Info("Cordoning node", "node", node.Name)
+	node.Spec.Unschedulable = true
+	return r.UpdateNode(ctx, clusterClient, node)
+}
+
+// RequestReassociation annotates the Metal3Machine with the fenced host, for
+// the Metal3Machine controller to release it and associate the Metal3Machine
+// with another host.
+func (r *RemediationManager) RequestReassociation(ctx context.Context, host *bmov1alpha1.BareMetalHost) error {
+	if r.Metal3Machine == nil {
+		return errors.New("Unable to request the reassociation, Metal3Machine not found")
+	}
+	helper, err := patch.NewHelper(r.Metal3Machine, r.Client)
+	if err != nil {
+		return errors.Wrap(err, "failed to init patch helper")
+	}
+
+	r.Log.Info("Requesting the association of the Metal3Machine with another host", "host", host.Name)
+	return patchWithConflictRetry(ctx, r.Client, r.Metal3Machine, helper, func() {
+		if r.Metal3Machine.Annotations == nil {
+			r.Metal3Machine.Annotations = make(map[string]string, 1)
+		}
+		r.Metal3Machine.Annotations[ReassociateAnnotation] = host.Name
+	})
+}
+
+// NodeIsHealthy returns true if the node has recovered: it is Ready and, when
+// the remediation strategy has a readiness check, a pod matching its selector
+// is Running on the node. The recovery time since the last reboot is then
+// observed, as the remediation ends once the node is healthy.
+func (r *RemediationManager) NodeIsHealthy(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) (bool, error) {
+	healthy, err := r.nodeIsHealthy(ctx, clusterClient, node)
+	if err != nil || !healthy {
+		return healthy, err
+	}
+	if lastRemediated := r.GetLastRemediatedTime(); lastRemediated != nil {
+		recordRemediationRecovery(r.GetRemediationType(), time.Since(lastRemediated.Time))
+	}
+	return true, nil
+}
+
+// nodeIsHealthy checks the health of the node for NodeIsHealthy.
+func (r *RemediationManager) nodeIsHealthy(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) (bool, error) {
+	ready := false
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			ready = condition.Status == corev1.ConditionTrue
+			break
+		}
+	}
+	if !ready {
+		return false, nil
+	}
+
+	strategy := r.Metal3Remediation.Spec.Strategy
+	if strategy == nil || strategy.ReadinessCheck == nil {
+		return true, nil
+	}
+	check := strategy.ReadinessCheck
+	selector, err := metav1.LabelSelectorAsSelector(&check.PodSelector)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid readiness check pod selector")
+	}
+	wctx, cancel := workloadClusterContext(ctx)
+	defer cancel()
+	pods, err := clusterClient.Pods(check.Namespace).List(wctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+		FieldSelector: "spec.nodeName=" + node.Name,
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list the readiness check pods")
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == node.Name && pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp.IsZero() {
+			return true, nil
+		}
+	}
+	r.Log.Info("Node is waiting for the readiness check pod", "node", node.Name)
+	return false, nil
+}
+
 // GetClusterClient returns the client for interacting with the target cluster.
 func (r *RemediationManager) GetClusterClient(ctx context.Context) (v1.CoreV1Interface, error) {
 	capiMachine, err := r.GetCapiMachine(ctx)
@@ -541,15 +1033,67 @@ func (r *RemediationManager) RemoveOutOfServiceTaint(ctx context.Context, cluste
 	return nil
 }
 
+// AddRemediationTaint adds the remediation taint to the node, if not present yet.
+func (r *RemediationManager) AddRemediationTaint(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) error {
+	for _, taint := range node.Spec.Taints {
+		if taint.MatchTaint(&remediationTaint) {
+			return nil
+		}
+	}
+	taint := remediationTaint
+	now := metav1.Now()
+	taint.TimeAdded = &now
+	node.Spec.Taints = append(node.Spec.Taints, taint)
+	if err := r.UpdateNode(ctx, clusterClient, node); err != nil {
+		return errors.Wrapf(err, "failed to add remediation taint on node %s", node.Name)
+	}
+	r.Log.Info("Remediation taint added", "node", node.Name)
+	return nil
+}
+
+// RemoveRemediationTaint removes the remediation taint from the node, if present.
+func (r *RemediationManager) RemoveRemediationTaint(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) error {
+	newTaints := []corev1.Taint{}
+	for _, taint := range node.Spec.Taints {
+		if !taint.MatchTaint(&remediationTaint) {
+			newTaints = append(newTaints, taint)
+		}
+	}
+	if len(newTaints) == len(node.Spec.Taints) {
+		return nil
+	}
+	node.Spec.Taints = newTaints
+	if err := r.UpdateNode(ctx, clusterClient, node); err != nil {
+		return errors.Wrapf(err, "failed to remove remediation taint on node %s", node.Name)
+	}
+	r.Log.Info("Remediation taint removed", "node", node.Name)
+	return nil
+}
+
+// IsNodeDrained returns true if no pod of the node is terminating and no
+// volume is attached to it anymore. The pods matching the DrainSkipPodSelector
+// of the strategy are not waited for.
 func (r *RemediationManager) IsNodeDrained(ctx context.Context, clusterClient v1.CoreV1Interface, node *corev1.Node) bool {
-	pods, err := clusterClient.Pods("").List(ctx, metav1.ListOptions{})
+	skipSelector := labels.Nothing()
+	if strategy := r.Metal3Remediation.Spec.Strategy; strategy != nil && strategy.DrainSkipPodSelector != nil {
+		var err error
+		skipSelector, err = metav1.LabelSelectorAsSelector(strategy.DrainSkipPodSelector)
+		if err != nil {
+			r.Log.Error(err, "invalid drain skip pod selector")
+			return false
+		}
+	}
+
+	wctx, cancel := workloadClusterContext(ctx)
+	defer cancel()
+	pods, err := clusterClient.Pods("").List(wctx, metav1.ListOptions{})
 	if err != nil {
 		r.Log.Error(err, "failed to get pod list in the cluster")
 		return false
 	}
 
 	for _, pod := range pods.Items {
-		if pod.Spec.NodeName == node.Name {
+		if pod.Spec.NodeName == node.Name && !skipSelector.Matches(labels.Set(pod.Labels)) {
 			if pod.ObjectMeta.DeletionTimestamp != nil {
 				r.Log.Info("Waiting for terminating pod", "node", node.Name, "pod name", pod.Name, "phase", pod.Status.Phase)
 				return false
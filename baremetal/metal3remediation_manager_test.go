@@ -15,6 +15,8 @@ package baremetal
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -22,15 +24,27 @@ import (
 	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	_ "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
 	clientfake "k8s.io/client-go/kubernetes/fake"
 	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 type testCaseRemediationManager struct {
@@ -112,6 +126,40 @@ var _ = Describe("Metal3Remediation manager", func() {
 		}),
 	)
 
+	DescribeTable("Test custom Finalizer",
+		func(tc testCaseRemediationManager) {
+			RemediationFinalizer = "custom.infrastructure.cluster.x-k8s.io"
+			defer func() { RemediationFinalizer = infrav1.RemediationFinalizer }()
+			initialFinalizers := append([]string{}, tc.Metal3Remediation.ObjectMeta.Finalizers...)
+			remediationMgr, err := NewRemediationManager(nil, nil, tc.Metal3Remediation, nil, nil,
+				logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(remediationMgr.HasFinalizer()).To(BeFalse())
+			remediationMgr.SetFinalizer()
+			Expect(remediationMgr.HasFinalizer()).To(BeTrue())
+
+			Expect(tc.Metal3Remediation.ObjectMeta.Finalizers).To(ContainElement(
+				"custom.infrastructure.cluster.x-k8s.io",
+			))
+
+			remediationMgr.UnsetFinalizer()
+
+			Expect(tc.Metal3Remediation.ObjectMeta.Finalizers).To(ConsistOf(initialFinalizers))
+		},
+		Entry("No finalizers", testCaseRemediationManager{
+			Metal3Remediation: &infrav1.Metal3Remediation{},
+		}),
+		Entry("Finalizer of another instance", testCaseRemediationManager{
+			Metal3Remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: metav1.ObjectMeta{
+					Finalizers: []string{infrav1.RemediationFinalizer},
+				},
+			},
+		}),
+	)
+
 	type testCaseRetryLimitSet struct {
 		Metal3Remediation *infrav1.Metal3Remediation
 		ExpectTrue        bool
@@ -292,7 +340,7 @@ var _ = Describe("Metal3Remediation manager", func() {
 			}
 			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(&host).Build()
 
-			remediationMgr, err := NewRemediationManager(fakeClient, nil, nil, tc.M3Machine, nil,
+			remediationMgr, err := NewRemediationManager(fakeClient, nil, tc.Metal3Remediation, tc.M3Machine, nil,
 				logr.Discard(),
 			)
 			Expect(err).NotTo(HaveOccurred())
@@ -369,17 +417,72 @@ var _ = Describe("Metal3Remediation manager", func() {
 			},
 			ExpectPresent: false,
 		}),
+		Entry("Should find the host referenced without Metal3Machine", testCaseGetUnhealthyHost{
+			Metal3Remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: testObjectMeta("myremediation", namespaceName, ""),
+				Spec: infrav1.Metal3RemediationSpec{
+					HostRef: &corev1.ObjectReference{
+						Name: baremetalhostName,
+					},
+				},
+			},
+			ExpectPresent: true,
+		}),
+		Entry("Should find the host referenced in another namespace without Metal3Machine", testCaseGetUnhealthyHost{
+			Metal3Remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: testObjectMeta("myremediation", "myns", ""),
+				Spec: infrav1.Metal3RemediationSpec{
+					HostRef: &corev1.ObjectReference{
+						Name:      baremetalhostName,
+						Namespace: namespaceName,
+					},
+				},
+			},
+			ExpectPresent: true,
+		}),
+		Entry("Should not find the host, wrong reference without Metal3Machine", testCaseGetUnhealthyHost{
+			Metal3Remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: testObjectMeta("myremediation", namespaceName, ""),
+				Spec: infrav1.Metal3RemediationSpec{
+					HostRef: &corev1.ObjectReference{
+						Name: "wronghostname",
+					},
+				},
+			},
+			ExpectPresent: false,
+		}),
+		Entry("Should not find the host, no reference without Metal3Machine", testCaseGetUnhealthyHost{
+			Metal3Remediation: &infrav1.Metal3Remediation{
+				ObjectMeta: testObjectMeta("myremediation", namespaceName, ""),
+			},
+			ExpectPresent: false,
+		}),
 	)
 
 	type testCaseSetAnnotation struct {
 		Host       *bmov1alpha1.BareMetalHost
 		M3Machine  *infrav1.Metal3Machine
+		Conflicts  int
 		ExpectTrue bool
 	}
 
 	DescribeTable("Test SetUnhealthyAnnotation",
 		func(tc testCaseSetAnnotation) {
-			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(tc.Host).Build()
+			// Fail the first patches with a conflict, as a concurrent update would.
+			conflicts := tc.Conflicts
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(tc.Host).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch,
+						opts ...client.PatchOption,
+					) error {
+						if conflicts > 0 {
+							conflicts--
+							return apierrors.NewConflict(bmov1alpha1.GroupVersion.WithResource("baremetalhosts").GroupResource(),
+								obj.GetName(), errors.New("object was modified"))
+						}
+						return c.Patch(ctx, obj, patch, opts...)
+					},
+				}).Build()
 			remediationMgr, err := NewRemediationManager(fakeClient, nil, nil, tc.M3Machine, nil,
 				logr.Discard(),
 			)
@@ -389,6 +492,9 @@ var _ = Describe("Metal3Remediation manager", func() {
 
 			if tc.ExpectTrue {
 				Expect(patchError).ToNot(HaveOccurred())
+				host := &bmov1alpha1.BareMetalHost{}
+				Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(tc.Host), host)).To(Succeed())
+				Expect(host.Annotations).To(HaveKeyWithValue(infrav1.UnhealthyAnnotation, "capm3/UnhealthyNode"))
 			} else {
 				Expect(patchError).To(HaveOccurred())
 			}
@@ -413,6 +519,46 @@ var _ = Describe("Metal3Remediation manager", func() {
 			},
 			ExpectTrue: true,
 		}),
+		Entry("Should set the unhealthy annotation after a conflict", testCaseSetAnnotation{
+			M3Machine: &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            metal3machineName,
+					Namespace:       "myns",
+					OwnerReferences: []metav1.OwnerReference{},
+					Annotations: map[string]string{
+						HostAnnotation: "myns/" + baremetalhostName,
+					},
+				},
+			},
+			Host: &bmov1alpha1.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      baremetalhostName,
+					Namespace: "myns",
+				},
+			},
+			Conflicts:  1,
+			ExpectTrue: true,
+		}),
+		Entry("Should not set the unhealthy annotation, conflicts exceed the retries", testCaseSetAnnotation{
+			M3Machine: &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            metal3machineName,
+					Namespace:       "myns",
+					OwnerReferences: []metav1.OwnerReference{},
+					Annotations: map[string]string{
+						HostAnnotation: "myns/" + baremetalhostName,
+					},
+				},
+			},
+			Host: &bmov1alpha1.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      baremetalhostName,
+					Namespace: "myns",
+				},
+			},
+			Conflicts:  PatchConflictRetries + 1,
+			ExpectTrue: false,
+		}),
 		Entry("Should not set the unhealthy annotation, annotation is empty", testCaseSetAnnotation{
 			M3Machine: &infrav1.Metal3Machine{
 				ObjectMeta: metav1.ObjectMeta{
@@ -453,6 +599,151 @@ var _ = Describe("Metal3Remediation manager", func() {
 		}),
 	)
 
+	type testCaseClearAnnotation struct {
+		HostAnnotations        map[string]string
+		MachineHealthy         bool
+		ExpectCleared          bool
+		ExpectUnhealthy        bool
+		ExpectHealthySinceKept bool
+	}
+
+	DescribeTable("Test ClearUnhealthyAnnotation",
+		func(tc testCaseClearAnnotation) {
+			gracePeriod := UnhealthyAnnotationGracePeriod
+			UnhealthyAnnotationGracePeriod = 5 * time.Minute
+			defer func() { UnhealthyAnnotationGracePeriod = gracePeriod }()
+
+			host := &bmov1alpha1.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        baremetalhostName,
+					Namespace:   "myns",
+					Annotations: tc.HostAnnotations,
+				},
+			}
+			m3Machine := &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      metal3machineName,
+					Namespace: "myns",
+					Annotations: map[string]string{
+						HostAnnotation: "myns/" + baremetalhostName,
+					},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(host).Build()
+			remediationMgr, err := NewRemediationManager(fakeClient, nil, nil, m3Machine,
+				testHealthCheckedMachine(tc.MachineHealthy), logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			cleared, err := remediationMgr.ClearUnhealthyAnnotation(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cleared).To(Equal(tc.ExpectCleared))
+
+			savedHost := &bmov1alpha1.BareMetalHost{}
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(host), savedHost)).To(Succeed())
+			_, unhealthy := savedHost.Annotations[infrav1.UnhealthyAnnotation]
+			Expect(unhealthy).To(Equal(tc.ExpectUnhealthy))
+			if tc.ExpectHealthySinceKept {
+				Expect(savedHost.Annotations[healthySinceAnnotation]).To(Equal(tc.HostAnnotations[healthySinceAnnotation]))
+			}
+		},
+		Entry("Should report cleared when the host is not annotated", testCaseClearAnnotation{
+			HostAnnotations: map[string]string{},
+			MachineHealthy:  true,
+			ExpectCleared:   true,
+			ExpectUnhealthy: false,
+		}),
+		Entry("Should start the grace period when the machine becomes healthy", testCaseClearAnnotation{
+			HostAnnotations: map[string]string{infrav1.UnhealthyAnnotation: "capm3/UnhealthyNode"},
+			MachineHealthy:  true,
+			ExpectCleared:   false,
+			ExpectUnhealthy: true,
+		}),
+		Entry("Should keep the annotation while the grace period has not passed", testCaseClearAnnotation{
+			HostAnnotations: map[string]string{
+				infrav1.UnhealthyAnnotation: "capm3/UnhealthyNode",
+				healthySinceAnnotation:      time.Now().Add(-time.Minute).Format(time.RFC3339),
+			},
+			MachineHealthy:         true,
+			ExpectCleared:          false,
+			ExpectUnhealthy:        true,
+			ExpectHealthySinceKept: true,
+		}),
+		Entry("Should clear the annotation once the grace period has passed", testCaseClearAnnotation{
+			HostAnnotations: map[string]string{
+				infrav1.UnhealthyAnnotation: "capm3/UnhealthyNode",
+				healthySinceAnnotation:      time.Now().Add(-10 * time.Minute).Format(time.RFC3339),
+			},
+			MachineHealthy:  true,
+			ExpectCleared:   true,
+			ExpectUnhealthy: false,
+		}),
+		Entry("Should keep the annotation while the machine is unhealthy", testCaseClearAnnotation{
+			HostAnnotations: map[string]string{infrav1.UnhealthyAnnotation: "capm3/UnhealthyNode"},
+			MachineHealthy:  false,
+			ExpectCleared:   false,
+			ExpectUnhealthy: true,
+		}),
+	)
+
+	It("Should not clear the unhealthy annotation when the machine flaps", func() {
+		gracePeriod := UnhealthyAnnotationGracePeriod
+		UnhealthyAnnotationGracePeriod = 5 * time.Minute
+		defer func() { UnhealthyAnnotationGracePeriod = gracePeriod }()
+
+		host := &bmov1alpha1.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      baremetalhostName,
+				Namespace: "myns",
+				Annotations: map[string]string{
+					infrav1.UnhealthyAnnotation: "capm3/UnhealthyNode",
+					healthySinceAnnotation:      time.Now().Add(-10 * time.Minute).Format(time.RFC3339),
+				},
+			},
+		}
+		m3Machine := &infrav1.Metal3Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      metal3machineName,
+				Namespace: "myns",
+				Annotations: map[string]string{
+					HostAnnotation: "myns/" + baremetalhostName,
+				},
+			},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(host).Build()
+		getHost := func() *bmov1alpha1.BareMetalHost {
+			savedHost := &bmov1alpha1.BareMetalHost{}
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(host), savedHost)).To(Succeed())
+			return savedHost
+		}
+
+		By("Reporting the machine unhealthy again")
+		remediationMgr, err := NewRemediationManager(fakeClient, nil, nil, m3Machine,
+			testHealthCheckedMachine(false), logr.Discard(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		cleared, err := remediationMgr.ClearUnhealthyAnnotation(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cleared).To(BeFalse())
+		Expect(getHost().Annotations).NotTo(HaveKey(healthySinceAnnotation))
+
+		By("Reporting the machine healthy right after")
+		remediationMgr, err = NewRemediationManager(fakeClient, nil, nil, m3Machine,
+			testHealthCheckedMachine(true), logr.Discard(),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		cleared, err = remediationMgr.ClearUnhealthyAnnotation(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cleared).To(BeFalse())
+		savedHost := getHost()
+		Expect(savedHost.Annotations).To(HaveKey(infrav1.UnhealthyAnnotation))
+		Expect(savedHost.Annotations).To(HaveKey(healthySinceAnnotation))
+
+		By("Setting the unhealthy annotation again")
+		Expect(remediationMgr.SetUnhealthyAnnotation(context.TODO())).To(Succeed())
+		Expect(getHost().Annotations).NotTo(HaveKey(healthySinceAnnotation))
+	})
+
 	type testCaseGetRemediationType struct {
 		Metal3Remediation  *infrav1.Metal3Remediation
 		RemediationType    *infrav1.RemediationType
@@ -615,6 +906,120 @@ var _ = Describe("Metal3Remediation manager", func() {
 		}),
 	)
 
+	type testCaseRebootAnnotationExpired struct {
+		RequestedAt   *string
+		ExpectExpired bool
+	}
+
+	DescribeTable("Test RebootAnnotationExpired",
+		func(tc testCaseRebootAnnotationExpired) {
+			remediation := &infrav1.Metal3Remediation{}
+			if tc.RequestedAt != nil {
+				remediation.Annotations = map[string]string{
+					rebootRequestedAnnotation: *tc.RequestedAt,
+				}
+			}
+			remediationMgr, err := NewRemediationManager(nil, nil, remediation, nil, nil,
+				logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(remediationMgr.RebootAnnotationExpired(10 * time.Minute)).To(Equal(tc.ExpectExpired))
+			// The request time is left unchanged.
+			if tc.RequestedAt != nil {
+				Expect(remediation.Annotations[rebootRequestedAnnotation]).To(Equal(*tc.RequestedAt))
+			} else {
+				Expect(remediation.Annotations).NotTo(HaveKey(rebootRequestedAnnotation))
+			}
+		},
+		Entry("Expired reboot annotation", testCaseRebootAnnotationExpired{
+			RequestedAt:   ptr.To(time.Now().Add(-15 * time.Minute).Format(time.RFC3339)),
+			ExpectExpired: true,
+		}),
+		Entry("Fresh reboot annotation", testCaseRebootAnnotationExpired{
+			RequestedAt:   ptr.To(time.Now().Add(-5 * time.Minute).Format(time.RFC3339)),
+			ExpectExpired: false,
+		}),
+		Entry("Missing request time", testCaseRebootAnnotationExpired{
+			ExpectExpired: false,
+		}),
+		Entry("Invalid request time", testCaseRebootAnnotationExpired{
+			RequestedAt:   ptr.To("yesterday"),
+			ExpectExpired: false,
+		}),
+	)
+
+	DescribeTable("Test RecordRebootRequestedTime",
+		func(requestedAt *string, expectRecorded bool) {
+			remediation := &infrav1.Metal3Remediation{}
+			if requestedAt != nil {
+				remediation.Annotations = map[string]string{
+					rebootRequestedAnnotation: *requestedAt,
+				}
+			}
+			remediationMgr, err := NewRemediationManager(nil, nil, remediation, nil, nil,
+				logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			remediationMgr.RecordRebootRequestedTime()
+			recorded, err := time.Parse(time.RFC3339, remediation.Annotations[rebootRequestedAnnotation])
+			Expect(err).NotTo(HaveOccurred())
+			if expectRecorded {
+				Expect(recorded).To(BeTemporally("~", time.Now(), time.Minute))
+			} else {
+				Expect(remediation.Annotations[rebootRequestedAnnotation]).To(Equal(*requestedAt))
+			}
+		},
+		Entry("Missing request time is recorded", nil, true),
+		Entry("Invalid request time is reset", ptr.To("yesterday"), true),
+		Entry("Valid request time is kept",
+			ptr.To(time.Now().Add(-15*time.Minute).Format(time.RFC3339)), false,
+		),
+	)
+
+	type testCaseHostGoneTimedOut struct {
+		HostNotFoundSince *metav1.Time
+		ExpectTimedOut    bool
+	}
+
+	DescribeTable("Test HostGoneTimedOut",
+		func(tc testCaseHostGoneTimedOut) {
+			remediation := &infrav1.Metal3Remediation{
+				Status: infrav1.Metal3RemediationStatus{
+					HostNotFoundSince: tc.HostNotFoundSince,
+				},
+			}
+			remediationMgr, err := NewRemediationManager(nil, nil, remediation, nil, nil,
+				logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			timedOut, requeueAfter := remediationMgr.HostGoneTimedOut(10 * time.Minute)
+			Expect(timedOut).To(Equal(tc.ExpectTimedOut))
+			if tc.ExpectTimedOut {
+				Expect(requeueAfter).To(Equal(time.Duration(0)))
+			} else {
+				Expect(requeueAfter).To(BeNumerically(">", 0))
+			}
+			Expect(remediation.Status.HostNotFoundSince).NotTo(BeNil())
+
+			remediationMgr.ResetHostNotFoundTime()
+			Expect(remediation.Status.HostNotFoundSince).To(BeNil())
+		},
+		Entry("Missing host is recorded", testCaseHostGoneTimedOut{
+			ExpectTimedOut: false,
+		}),
+		Entry("Host missing for less than the timeout", testCaseHostGoneTimedOut{
+			HostNotFoundSince: &metav1.Time{Time: time.Now().Add(-5 * time.Minute)},
+			ExpectTimedOut:    false,
+		}),
+		Entry("Host missing for longer than the timeout", testCaseHostGoneTimedOut{
+			HostNotFoundSince: &metav1.Time{Time: time.Now().Add(-15 * time.Minute)},
+			ExpectTimedOut:    true,
+		}),
+	)
+
 	type testCaseGetTimeout struct {
 		Metal3Remediation *infrav1.Metal3Remediation
 		TimeoutSet        bool
@@ -734,6 +1139,72 @@ var _ = Describe("Metal3Remediation manager", func() {
 		}),
 	)
 
+	type testCaseRebootsBeforeEscalation struct {
+		Strategy   *infrav1.RemediationStrategy
+		Status     infrav1.Metal3RemediationStatus
+		ExpectTrue bool
+	}
+
+	DescribeTable("Test HasReachRebootsBeforeEscalation",
+		func(tc testCaseRebootsBeforeEscalation) {
+			remediation := &infrav1.Metal3Remediation{
+				Spec:   infrav1.Metal3RemediationSpec{Strategy: tc.Strategy},
+				Status: tc.Status,
+			}
+			remediationMgr, err := NewRemediationManager(nil, nil, remediation, nil, nil,
+				logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(remediationMgr.HasReachRebootsBeforeEscalation()).To(Equal(tc.ExpectTrue))
+		},
+		Entry("reboots before escalation are reached", testCaseRebootsBeforeEscalation{
+			Strategy: &infrav1.RemediationStrategy{
+				Type:                    infrav1.RebootRemediationStrategy,
+				RetryLimit:              5,
+				RebootsBeforeEscalation: 2,
+				EscalationType:          infrav1.ReassociateRemediationStrategy,
+			},
+			Status:     infrav1.Metal3RemediationStatus{RebootCount: 2},
+			ExpectTrue: true,
+		}),
+		Entry("reboots before escalation are not reached", testCaseRebootsBeforeEscalation{
+			Strategy: &infrav1.RemediationStrategy{
+				Type:                    infrav1.RebootRemediationStrategy,
+				RetryLimit:              5,
+				RebootsBeforeEscalation: 2,
+				EscalationType:          infrav1.ReassociateRemediationStrategy,
+			},
+			Status:     infrav1.Metal3RemediationStatus{RebootCount: 1},
+			ExpectTrue: false,
+		}),
+		Entry("remediation already escalated", testCaseRebootsBeforeEscalation{
+			Strategy: &infrav1.RemediationStrategy{
+				Type:                    infrav1.RebootRemediationStrategy,
+				RetryLimit:              5,
+				RebootsBeforeEscalation: 2,
+				EscalationType:          infrav1.ReassociateRemediationStrategy,
+			},
+			Status: infrav1.Metal3RemediationStatus{
+				RebootCount:   2,
+				EscalatedType: infrav1.ReassociateRemediationStrategy,
+			},
+			ExpectTrue: false,
+		}),
+		Entry("escalation not set", testCaseRebootsBeforeEscalation{
+			Strategy: &infrav1.RemediationStrategy{
+				Type:       infrav1.RebootRemediationStrategy,
+				RetryLimit: 5,
+			},
+			Status:     infrav1.Metal3RemediationStatus{RebootCount: 2},
+			ExpectTrue: false,
+		}),
+		Entry("strategy not set", testCaseRebootsBeforeEscalation{
+			Status:     infrav1.Metal3RemediationStatus{RebootCount: 2},
+			ExpectTrue: false,
+		}),
+	)
+
 	type testCaseGetRemediationPhase struct {
 		Metal3Remediation *infrav1.Metal3Remediation
 		Succeed           bool
@@ -858,13 +1329,306 @@ var _ = Describe("Metal3Remediation manager", func() {
 
 			ensureNotExists()
 
+			powerCycles := hostPowerCycles.WithLabelValues(bmhost.Namespace, bmhost.Name)
+			cyclesBefore := testutil.ToFloat64(powerCycles)
+
 			By("Setting annotation")
 			Expect(remediationMgr.SetPowerOffAnnotation(context.TODO())).To(Succeed(), "SetPowerOffAnnotation should succeed")
 			ensureExists()
+			Expect(testutil.ToFloat64(powerCycles)).To(Equal(cyclesBefore+1), "power cycles counter should be incremented")
+			Expect(bmhost.Annotations).To(HaveKeyWithValue(RemediatedByAnnotation, remediation.Namespace+"/"+remediation.Name))
+			remediatedAt, err := time.Parse(time.RFC3339, bmhost.Annotations[RemediatedAtAnnotation])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(remediatedAt).To(BeTemporally("~", time.Now(), time.Minute))
 
 			By("Removing annotation")
 			Expect(remediationMgr.RemovePowerOffAnnotation(context.TODO())).To(Succeed(), "RemovePowerOffAnnotation should succeed")
 			ensureNotExists()
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(bmhost), bmhost)).To(Succeed())
+			Expect(bmhost.Annotations).To(HaveKey(RemediatedAtAnnotation), "the remediation history should be kept")
+		})
+
+		It("should retry setting the power off annotation on a conflict", func() {
+			patches := 0
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(bmhost, m3machine, remediation).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch,
+						opts ...client.PatchOption,
+					) error {
+						patches++
+						if patches == 1 {
+							return apierrors.NewConflict(bmov1alpha1.GroupVersion.WithResource("baremetalhosts").GroupResource(),
+								obj.GetName(), errors.New("object was modified"))
+						}
+						return c.Patch(ctx, obj, patch, opts...)
+					},
+				}).Build()
+
+			remediationMgr, err := NewRemediationManager(fakeClient, nil, remediation, m3machine, nil,
+				logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(remediationMgr.SetPowerOffAnnotation(context.TODO())).To(Succeed())
+			Expect(patches).To(Equal(2))
+			Expect(remediationMgr.IsPowerOffRequested(context.TODO())).To(BeTrue())
+		})
+	})
+
+	Describe("Test RequestReassociation", func() {
+		It("Should annotate the Metal3Machine with the fenced host", func() {
+			bmhost := &bmov1alpha1.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Name: "myhost", Namespace: namespaceName},
+			}
+			m3machine := &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "mym3machine",
+					Namespace:   namespaceName,
+					Annotations: map[string]string{HostAnnotation: namespaceName + "/myhost"},
+				},
+			}
+			remediation := &infrav1.Metal3Remediation{
+				ObjectMeta: metav1.ObjectMeta{Name: "myremediation", Namespace: namespaceName},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(bmhost, m3machine, remediation).Build()
+			remediationMgr, err := NewRemediationManager(fakeClient, nil, remediation, m3machine, nil, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(remediationMgr.RequestReassociation(context.TODO(), bmhost)).To(Succeed())
+
+			savedM3Machine := &infrav1.Metal3Machine{}
+			Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(m3machine), savedM3Machine)).To(Succeed())
+			Expect(savedM3Machine.Annotations).To(HaveKeyWithValue(ReassociateAnnotation, "myhost"))
+			Expect(savedM3Machine.Annotations).To(HaveKey(HostAnnotation))
+		})
+
+		It("Should fail without a Metal3Machine", func() {
+			remediation := &infrav1.Metal3Remediation{
+				ObjectMeta: metav1.ObjectMeta{Name: "myremediation", Namespace: namespaceName},
+			}
+			remediationMgr, err := NewRemediationManager(nil, nil, remediation, nil, nil, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(remediationMgr.RequestReassociation(context.TODO(), &bmov1alpha1.BareMetalHost{})).NotTo(Succeed())
+		})
+	})
+
+	Describe("Test ResolveMachineSelector", func() {
+		newSelectableMachine := func(name, rack string) *clusterv1.Machine {
+			return &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespaceName,
+					UID:       types.UID(name + "-uid"),
+					Labels:    map[string]string{"rack": rack},
+				},
+			}
+		}
+
+		type testCaseResolveMachineSelector struct {
+			Selector        *metav1.LabelSelector
+			ExpectedMachine string
+			ExpectedError   string
+		}
+
+		DescribeTable("Test ResolveMachineSelector",
+			func(tc testCaseResolveMachineSelector) {
+				remediation := &infrav1.Metal3Remediation{
+					ObjectMeta: metav1.ObjectMeta{Name: "myremediation", Namespace: namespaceName},
+					Spec:       infrav1.Metal3RemediationSpec{MachineSelector: tc.Selector},
+				}
+				otherNamespaceMachine := newSelectableMachine("machine-other", "2")
+				otherNamespaceMachine.Namespace = "other"
+				fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(
+					newSelectableMachine("machine-0", "1"),
+					newSelectableMachine("machine-1", "2"),
+					newSelectableMachine("machine-2", "2"),
+					otherNamespaceMachine,
+				).Build()
+				remediationMgr, err := NewRemediationManager(fakeClient, nil, remediation, nil, nil, logr.Discard())
+				Expect(err).NotTo(HaveOccurred())
+
+				machine, err := remediationMgr.ResolveMachineSelector(context.TODO())
+				if tc.ExpectedError != "" {
+					Expect(err).To(MatchError(ContainSubstring(tc.ExpectedError)))
+					Expect(remediation.OwnerReferences).To(BeEmpty())
+					return
+				}
+				Expect(err).NotTo(HaveOccurred())
+				if tc.ExpectedMachine == "" {
+					Expect(machine).To(BeNil())
+					Expect(remediation.OwnerReferences).To(BeEmpty())
+					return
+				}
+				Expect(machine.Name).To(Equal(tc.ExpectedMachine))
+				Expect(remediation.OwnerReferences).To(ConsistOf(metav1.OwnerReference{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       tc.ExpectedMachine,
+					UID:        types.UID(tc.ExpectedMachine + "-uid"),
+				}))
+
+				// The selected Machine is then the owner Machine of the remediation.
+				ownerMachine, err := remediationMgr.GetCapiMachine(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ownerMachine.Name).To(Equal(tc.ExpectedMachine))
+			},
+			Entry("No Machine selector", testCaseResolveMachineSelector{}),
+			Entry("Machine selector matching a single Machine", testCaseResolveMachineSelector{
+				Selector:        &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "1"}},
+				ExpectedMachine: "machine-0",
+			}),
+			Entry("Machine selector matching no Machine", testCaseResolveMachineSelector{
+				Selector:      &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "3"}},
+				ExpectedError: "no Machine matches",
+			}),
+			Entry("Ambiguous Machine selector", testCaseResolveMachineSelector{
+				Selector:      &metav1.LabelSelector{MatchLabels: map[string]string{"rack": "2"}},
+				ExpectedError: "matches several Machines: machine-1, machine-2",
+			}),
+		)
+	})
+
+	Describe("Test remediation events", func() {
+		var recorder *record.FakeRecorder
+
+		BeforeEach(func() {
+			eventRecorder := EventRecorder
+			DeferCleanup(func() {
+				EventRecorder = eventRecorder
+			})
+			recorder = record.NewFakeRecorder(10)
+			EventRecorder = recorder
+		})
+
+		receivedEvents := func() []string {
+			events := []string{}
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			return events
+		}
+
+		It("Should record the timeline of a remediation", func() {
+			bmhost := &bmov1alpha1.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{Name: "myhost", Namespace: namespaceName},
+			}
+			m3machine := &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "mym3machine",
+					Namespace:   namespaceName,
+					Annotations: map[string]string{HostAnnotation: namespaceName + "/myhost"},
+				},
+			}
+			remediation := &infrav1.Metal3Remediation{
+				ObjectMeta: metav1.ObjectMeta{Name: "myremediation", Namespace: namespaceName},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(bmhost, m3machine, remediation).Build()
+			remediationMgr, err := NewRemediationManager(fakeClient, nil, remediation, m3machine, nil, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			remediationMgr.SetRemediationPhase(infrav1.PhaseRunning)
+			Expect(remediationMgr.SetPowerOffAnnotation(context.TODO())).To(Succeed())
+			remediationMgr.SetRemediationPhase(infrav1.PhaseWaiting)
+			// A retry goes through the same steps without starting again.
+			remediationMgr.IncreaseRetryCount()
+			remediationMgr.SetRemediationPhase(infrav1.PhaseRunning)
+			remediationMgr.SetRemediationPhase(infrav1.PhaseRunning)
+			Expect(remediationMgr.SetPowerOffAnnotation(context.TODO())).To(Succeed())
+			remediationMgr.SetRemediationPhase(infrav1.PhaseWaiting)
+			remediationMgr.SetRemediationPhase(infrav1.PhaseDeleting)
+
+			Expect(receivedEvents()).To(Equal([]string{
+				"Normal RemediationStarted Started remediation of host myhost",
+				"Normal RebootIssued Powering off host myhost for a reboot",
+				"Normal WaitingForRecovery Waiting for host myhost to recover",
+				"Normal RebootIssued Powering off host myhost for a reboot",
+				"Normal WaitingForRecovery Waiting for host myhost to recover",
+				"Warning RetryLimitReached Host myhost still unhealthy after 1 retries",
+			}))
+		})
+
+		It("Should record the fencing of a host to reassociate", func() {
+			m3machine := &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "mym3machine",
+					Namespace:   namespaceName,
+					Annotations: map[string]string{HostAnnotation: namespaceName + "/myhost"},
+				},
+			}
+			remediation := &infrav1.Metal3Remediation{
+				ObjectMeta: metav1.ObjectMeta{Name: "myremediation", Namespace: namespaceName},
+				Status:     infrav1.Metal3RemediationStatus{Phase: infrav1.PhaseRunning},
+			}
+			remediationMgr, err := NewRemediationManager(nil, nil, remediation, m3machine, nil, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			remediationMgr.SetRemediationPhase(infrav1.PhaseReassociating)
+
+			Expect(receivedEvents()).To(Equal([]string{
+				"Normal HostFenced Host myhost fenced, associating the Metal3Machine with another host",
+			}))
+		})
+
+		It("Should escalate once the reboots before escalation are reached", func() {
+			m3machine := &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "mym3machine",
+					Namespace:   namespaceName,
+					Annotations: map[string]string{HostAnnotation: namespaceName + "/myhost"},
+				},
+			}
+			remediation := &infrav1.Metal3Remediation{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "myremediation",
+					Namespace:  namespaceName,
+					Finalizers: []string{infrav1.RemediationFinalizer},
+				},
+				Spec: infrav1.Metal3RemediationSpec{
+					Strategy: &infrav1.RemediationStrategy{
+						Type:                    infrav1.RebootRemediationStrategy,
+						RetryLimit:              5,
+						RebootsBeforeEscalation: 2,
+						EscalationType:          infrav1.ReassociateRemediationStrategy,
+					},
+				},
+				Status: infrav1.Metal3RemediationStatus{Phase: infrav1.PhaseWaiting},
+			}
+			remediationMgr, err := NewRemediationManager(nil, nil, remediation, m3machine, nil, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			remediationMgr.IncreaseRebootCount()
+			Expect(remediationMgr.HasReachRebootsBeforeEscalation()).To(BeFalse())
+			remediationMgr.IncreaseRebootCount()
+			Expect(remediationMgr.HasReachRebootsBeforeEscalation()).To(BeTrue())
+
+			remediationMgr.Escalate()
+			Expect(remediationMgr.GetRemediationType()).To(Equal(infrav1.ReassociateRemediationStrategy))
+			Expect(remediationMgr.GetRemediationPhase()).To(BeEmpty())
+			Expect(remediationMgr.HasFinalizer()).To(BeFalse())
+			Expect(remediationMgr.HasReachRebootsBeforeEscalation()).To(BeFalse())
+			Expect(remediation.Status.RebootCount).To(Equal(2))
+			Expect(receivedEvents()).To(Equal([]string{
+				"Warning RemediationEscalated Host myhost still unhealthy after 2 reboots, escalating to the Reassociate strategy",
+			}))
+		})
+
+		It("Should record the success of a host remediation", func() {
+			remediation := &infrav1.Metal3Remediation{
+				ObjectMeta: metav1.ObjectMeta{Name: "myremediation", Namespace: namespaceName},
+				Spec: infrav1.Metal3RemediationSpec{
+					HostRef: &corev1.ObjectReference{Name: "myhost"},
+				},
+				Status: infrav1.Metal3RemediationStatus{Phase: infrav1.PhaseWaiting},
+			}
+			remediationMgr, err := NewRemediationManager(nil, nil, remediation, nil, nil, logr.Discard())
+			Expect(err).NotTo(HaveOccurred())
+
+			remediationMgr.SetRemediationPhase(infrav1.PhaseSucceeded)
+
+			Expect(receivedEvents()).To(Equal([]string{
+				"Normal RemediationSucceeded Host myhost remediated",
+			}))
 		})
 	})
 
@@ -987,5 +1751,356 @@ var _ = Describe("Metal3Remediation manager", func() {
 			Expect(apierrors.IsNotFound(err)).To(BeTrue(), "expected NotFound error")
 		})
 
+		It("Should cordon node", func() {
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(cluster, m3Remediation, capiMachine).Build()
+			corev1Client := clientfake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{
+				Name: node.Name,
+			}}).CoreV1()
+			clientGetter := func(_ context.Context, _ client.Client, _ *clusterv1.Cluster) (clientcorev1.CoreV1Interface, error) {
+				return corev1Client, nil
+			}
+			remediationMgr, err := NewRemediationManager(fakeClient, clientGetter, m3Remediation, nil, capiMachine,
+				logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(remediationMgr.CordonNode(context.TODO())).To(Succeed(), "should cordon node without error")
+			newNode, err := corev1Client.Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred(), "should get cordoned node")
+			Expect(newNode.Spec.Unschedulable).To(BeTrue())
+
+			By("Cordoning a node already cordoned")
+			Expect(remediationMgr.CordonNode(context.TODO())).To(Succeed())
+
+			By("Cordoning a missing node")
+			Expect(corev1Client.Nodes().Delete(context.TODO(), node.Name, metav1.DeleteOptions{})).To(Succeed())
+			Expect(remediationMgr.CordonNode(context.TODO())).To(Succeed())
+		})
+
+		It("Should add and remove the remediation taint", func() {
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(cluster, m3Remediation, capiMachine).Build()
+			otherTaint := corev1.Taint{Key: "foo", Effect: corev1.TaintEffectNoSchedule}
+			corev1Client := clientfake.NewSimpleClientset(&corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: node.Name},
+				Spec:       corev1.NodeSpec{Taints: []corev1.Taint{otherTaint}},
+			}).CoreV1()
+			remediationMgr, err := NewRemediationManager(fakeClient, nil, m3Remediation, nil, capiMachine,
+				logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			taintedNode, err := corev1Client.Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(remediationMgr.AddRemediationTaint(context.TODO(), corev1Client, taintedNode)).To(Succeed())
+			By("Adding the taint again")
+			Expect(remediationMgr.AddRemediationTaint(context.TODO(), corev1Client, taintedNode)).To(Succeed())
+			newNode, err := corev1Client.Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newNode.Spec.Taints).To(HaveLen(2))
+			Expect(newNode.Spec.Taints[1].Key).To(Equal("metal3.io/remediation"))
+			Expect(newNode.Spec.Taints[1].Effect).To(Equal(corev1.TaintEffectNoSchedule))
+
+			Expect(remediationMgr.RemoveRemediationTaint(context.TODO(), corev1Client, taintedNode)).To(Succeed())
+			By("Removing the taint again")
+			Expect(remediationMgr.RemoveRemediationTaint(context.TODO(), corev1Client, taintedNode)).To(Succeed())
+			newNode, err = corev1Client.Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(newNode.Spec.Taints).To(Equal([]corev1.Taint{otherTaint}))
+
+			By("Adding the taint to a missing node")
+			Expect(corev1Client.Nodes().Delete(context.TODO(), node.Name, metav1.DeleteOptions{})).To(Succeed())
+			Expect(remediationMgr.AddRemediationTaint(context.TODO(), corev1Client, taintedNode)).NotTo(Succeed())
+		})
+
+		It("Should time out on an unresponsive workload cluster", func() {
+			release := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-release:
+				}
+			}))
+			defer server.Close()
+			defer close(release)
+			clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+			Expect(err).NotTo(HaveOccurred())
+			corev1Client := clientset.CoreV1()
+
+			defer func(timeout time.Duration) { WorkloadClusterTimeout = timeout }(WorkloadClusterTimeout)
+			WorkloadClusterTimeout = 100 * time.Millisecond
+
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(cluster, m3Remediation, capiMachine).Build()
+			clientGetter := func(_ context.Context, _ client.Client, _ *clusterv1.Cluster) (clientcorev1.CoreV1Interface, error) {
+				return corev1Client, nil
+			}
+			remediationMgr, err := NewRemediationManager(fakeClient, clientGetter, m3Remediation, nil, capiMachine,
+				logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Getting the node")
+			start := time.Now()
+			_, err = remediationMgr.GetNode(context.TODO(), corev1Client)
+			Expect(err).To(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+
+			By("Updating the node")
+			start = time.Now()
+			Expect(remediationMgr.UpdateNode(context.TODO(), corev1Client, node.DeepCopy())).NotTo(Succeed())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+
+			By("Deleting the node")
+			start = time.Now()
+			Expect(remediationMgr.DeleteNode(context.TODO(), corev1Client, node.DeepCopy())).NotTo(Succeed())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+
+			By("Cordoning the node")
+			start = time.Now()
+			Expect(remediationMgr.CordonNode(context.TODO())).NotTo(Succeed())
+			Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		})
+
+	})
+
+	Describe("Test NodeIsHealthy", func() {
+		newNode := func(ready corev1.ConditionStatus) *corev1.Node {
+			return &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mynode",
+				},
+				Status: corev1.NodeStatus{
+					Conditions: []corev1.NodeCondition{
+						{Type: corev1.NodeReady, Status: ready},
+					},
+				},
+			}
+		}
+
+		newPod := func(nodeName string, phase corev1.PodPhase) *corev1.Pod {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "agent-" + nodeName,
+					Namespace: "monitoring",
+					Labels:    map[string]string{"app": "agent"},
+				},
+				Spec: corev1.PodSpec{
+					NodeName: nodeName,
+				},
+				Status: corev1.PodStatus{
+					Phase: phase,
+				},
+			}
+		}
+
+		readinessCheck := &infrav1.NodeReadinessCheck{
+			Namespace: "monitoring",
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": "agent"},
+			},
+		}
+
+		type testCaseNodeIsHealthy struct {
+			Node            *corev1.Node
+			Pods            []runtime.Object
+			ReadinessCheck  *infrav1.NodeReadinessCheck
+			ExpectedHealthy bool
+		}
+
+		DescribeTable("Test NodeIsHealthy",
+			func(tc testCaseNodeIsHealthy) {
+				m3Remediation := &infrav1.Metal3Remediation{
+					Spec: infrav1.Metal3RemediationSpec{
+						Strategy: &infrav1.RemediationStrategy{
+							Type:           infrav1.RebootRemediationStrategy,
+							ReadinessCheck: tc.ReadinessCheck,
+						},
+					},
+				}
+				fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).Build()
+				remediationMgr, err := NewRemediationManager(fakeClient, nil, m3Remediation, nil, nil,
+					logr.Discard(),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				corev1Client := clientfake.NewSimpleClientset(tc.Pods...).CoreV1()
+				healthy, err := remediationMgr.NodeIsHealthy(context.TODO(), corev1Client, tc.Node)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(healthy).To(Equal(tc.ExpectedHealthy))
+			},
+			Entry("Ready node without readiness check", testCaseNodeIsHealthy{
+				Node:            newNode(corev1.ConditionTrue),
+				ExpectedHealthy: true,
+			}),
+			Entry("Not ready node without readiness check", testCaseNodeIsHealthy{
+				Node:            newNode(corev1.ConditionFalse),
+				ExpectedHealthy: false,
+			}),
+			Entry("Ready node with the readiness check pod running", testCaseNodeIsHealthy{
+				Node:            newNode(corev1.ConditionTrue),
+				Pods:            []runtime.Object{newPod("mynode", corev1.PodRunning)},
+				ReadinessCheck:  readinessCheck,
+				ExpectedHealthy: true,
+			}),
+			Entry("Ready node with the readiness check pod pending", testCaseNodeIsHealthy{
+				Node:            newNode(corev1.ConditionTrue),
+				Pods:            []runtime.Object{newPod("mynode", corev1.PodPending)},
+				ReadinessCheck:  readinessCheck,
+				ExpectedHealthy: false,
+			}),
+			Entry("Ready node with the readiness check pod running on another node", testCaseNodeIsHealthy{
+				Node:            newNode(corev1.ConditionTrue),
+				Pods:            []runtime.Object{newPod("othernode", corev1.PodRunning)},
+				ReadinessCheck:  readinessCheck,
+				ExpectedHealthy: false,
+			}),
+			Entry("Not ready node with the readiness check pod running", testCaseNodeIsHealthy{
+				Node:            newNode(corev1.ConditionFalse),
+				Pods:            []runtime.Object{newPod("mynode", corev1.PodRunning)},
+				ReadinessCheck:  readinessCheck,
+				ExpectedHealthy: false,
+			}),
+		)
+
+		DescribeTable("Test the recovery time observed by NodeIsHealthy",
+			func(ready corev1.ConditionStatus, expectObserved bool) {
+				strategy := infrav1.RemediationType("RecoveryTest" + string(ready))
+				m3Remediation := &infrav1.Metal3Remediation{
+					Spec: infrav1.Metal3RemediationSpec{
+						Strategy: &infrav1.RemediationStrategy{Type: strategy},
+					},
+					Status: infrav1.Metal3RemediationStatus{
+						LastRemediated: &metav1.Time{Time: time.Now().Add(-2 * time.Minute)},
+					},
+				}
+				fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).Build()
+				remediationMgr, err := NewRemediationManager(fakeClient, nil, m3Remediation, nil, nil,
+					logr.Discard(),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				healthy, err := remediationMgr.NodeIsHealthy(context.TODO(),
+					clientfake.NewSimpleClientset().CoreV1(), newNode(ready),
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(healthy).To(Equal(expectObserved))
+
+				observed := remediationRecoverySeconds.WithLabelValues(string(strategy)).(prometheus.Histogram)
+				metric := &dto.Metric{}
+				Expect(observed.Write(metric)).To(Succeed())
+				if !expectObserved {
+					Expect(metric.GetHistogram().GetSampleCount()).To(BeZero())
+					return
+				}
+				Expect(metric.GetHistogram().GetSampleCount()).To(BeEquivalentTo(1))
+				Expect(metric.GetHistogram().GetSampleSum()).To(BeNumerically("~", 120, 5))
+			},
+			Entry("Recovered node", corev1.ConditionTrue, true),
+			Entry("Node not recovered yet", corev1.ConditionFalse, false),
+		)
+	})
+
+	Describe("Test IsNodeDrained", func() {
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "mynode"}}
+
+		newTerminatingPod := func(name, nodeName string, podLabels map[string]string) runtime.Object {
+			return &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					Namespace:         "default",
+					Labels:            podLabels,
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+					Finalizers:        []string{"test"},
+				},
+				Spec: corev1.PodSpec{NodeName: nodeName},
+			}
+		}
+
+		storageSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "storage"}}
+
+		type testCaseIsNodeDrained struct {
+			Pods                 []runtime.Object
+			VolumeAttachments    []client.Object
+			DrainSkipPodSelector *metav1.LabelSelector
+			ExpectedDrained      bool
+		}
+
+		DescribeTable("Test IsNodeDrained",
+			func(tc testCaseIsNodeDrained) {
+				m3Remediation := &infrav1.Metal3Remediation{
+					Spec: infrav1.Metal3RemediationSpec{
+						Strategy: &infrav1.RemediationStrategy{
+							Type:                 infrav1.RebootRemediationStrategy,
+							DrainSkipPodSelector: tc.DrainSkipPodSelector,
+						},
+					},
+				}
+				fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).
+					WithObjects(tc.VolumeAttachments...).Build()
+				remediationMgr, err := NewRemediationManager(fakeClient, nil, m3Remediation, nil, nil,
+					logr.Discard(),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				corev1Client := clientfake.NewSimpleClientset(tc.Pods...).CoreV1()
+				Expect(remediationMgr.IsNodeDrained(context.TODO(), corev1Client, node)).To(Equal(tc.ExpectedDrained))
+			},
+			Entry("No pods", testCaseIsNodeDrained{
+				ExpectedDrained: true,
+			}),
+			Entry("Terminating pod", testCaseIsNodeDrained{
+				Pods:            []runtime.Object{newTerminatingPod("app", "mynode", map[string]string{"app": "web"})},
+				ExpectedDrained: false,
+			}),
+			Entry("Terminating pod on another node", testCaseIsNodeDrained{
+				Pods:            []runtime.Object{newTerminatingPod("app", "othernode", map[string]string{"app": "web"})},
+				ExpectedDrained: true,
+			}),
+			Entry("Terminating storage pod without skip selector", testCaseIsNodeDrained{
+				Pods:            []runtime.Object{newTerminatingPod("storage", "mynode", map[string]string{"app": "storage"})},
+				ExpectedDrained: false,
+			}),
+			Entry("Terminating storage pod skipped", testCaseIsNodeDrained{
+				Pods:                 []runtime.Object{newTerminatingPod("storage", "mynode", map[string]string{"app": "storage"})},
+				DrainSkipPodSelector: storageSelector,
+				ExpectedDrained:      true,
+			}),
+			Entry("Terminating pods, only the storage pod skipped", testCaseIsNodeDrained{
+				Pods: []runtime.Object{
+					newTerminatingPod("storage", "mynode", map[string]string{"app": "storage"}),
+					newTerminatingPod("app", "mynode", map[string]string{"app": "web"}),
+				},
+				DrainSkipPodSelector: storageSelector,
+				ExpectedDrained:      false,
+			}),
+			Entry("Volume still attached", testCaseIsNodeDrained{
+				VolumeAttachments: []client.Object{&storagev1.VolumeAttachment{
+					ObjectMeta: metav1.ObjectMeta{Name: "va"},
+					Spec:       storagev1.VolumeAttachmentSpec{NodeName: "mynode"},
+				}},
+				DrainSkipPodSelector: storageSelector,
+				ExpectedDrained:      false,
+			}),
+		)
 	})
 })
+
+func testHealthCheckedMachine(healthy bool) *clusterv1.Machine {
+	status := corev1.ConditionFalse
+	if healthy {
+		status = corev1.ConditionTrue
+	}
+	return &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mymachine",
+			Namespace: "myns",
+		},
+		Status: clusterv1.MachineStatus{
+			Conditions: clusterv1.Conditions{
+				{
+					Type:   clusterv1.MachineHealthCheckSucceededCondition,
+					Status: status,
+				},
+			},
+		},
+	}
+}
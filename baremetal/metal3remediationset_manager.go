@@ -0,0 +1,243 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RemediationSetManagerInterface is an interface for a RemediationSetManager.
+type RemediationSetManagerInterface interface {
+	UpdateRemediations(context.Context) error
+}
+
+// RemediationSetManager is responsible for performing the remediation of the
+// Machines selected by a Metal3RemediationSet.
+type RemediationSetManager struct {
+	client client.Client
+
+	Metal3RemediationSet *infrav1.Metal3RemediationSet
+	Log                  logr.Logger
+}
+
+// NewRemediationSetManager returns a new helper for managing a Metal3RemediationSet.
+func NewRemediationSetManager(client client.Client,
+	remediationSet *infrav1.Metal3RemediationSet,
+	remediationSetLog logr.Logger) (*RemediationSetManager, error) {
+	return &RemediationSetManager{
+		client: client,
+
+		Metal3RemediationSet: remediationSet,
+		Log:                  remediationSetLog,
+	}, nil
+}
+
+// UpdateRemediations drives the selected Machines through remediation, in the
+// order of their names. The Metal3Remediations that are over are recorded in
+// the status, and a Metal3Remediation is created for the next Machines as long
+// as less than MaxConcurrency of them are in progress. A Machine already
+// remediated by a Metal3Remediation not owned by the set, for example one
+// created by a MachineHealthCheck, is left alone until that one is deleted.
+func (m *RemediationSetManager) UpdateRemediations(ctx context.Context) error {
+	if m.Metal3RemediationSet.Spec.Strategy == nil {
+		return errors.New("strategy must be set")
+	}
+
+	machines, err := m.selectedMachines(ctx)
+	if err != nil {
+		return err
+	}
+
+	remediations := &infrav1.Metal3RemediationList{}
+	if err := m.client.List(ctx, remediations, client.InNamespace(m.Metal3RemediationSet.Namespace)); err != nil {
+		return errors.Wrap(err, "failed to list Metal3Remediations")
+	}
+	remediationsByName := map[string]*infrav1.Metal3Remediation{}
+	for i := range remediations.Items {
+		remediationsByName[remediations.Items[i].Name] = &remediations.Items[i]
+	}
+
+	status := &m.Metal3RemediationSet.Status
+	status.InProgress = nil
+	pending := []*clusterv1.Machine{}
+	for _, machine := range machines {
+		if slices.Contains(status.Remediated, machine.Name) || slices.Contains(status.Failed, machine.Name) {
+			continue
+		}
+
+		remediation, ok := remediationsByName[machine.Name]
+		if !ok {
+			if machine.DeletionTimestamp.IsZero() {
+				pending = append(pending, machine)
+			}
+			continue
+		}
+		if !metav1.IsControlledBy(remediation, m.Metal3RemediationSet) {
+			m.Log.Info("Machine is remediated outside of the set, waiting", "machine", machine.Name)
+			pending = append(pending, machine)
+			continue
+		}
+
+		remediationMgr, err := NewRemediationManager(m.client, nil, remediation, nil, machine, m.Log)
+		if err != nil {
+			return err
+		}
+		done, succeeded := remediationOutcome(remediationMgr)
+		switch {
+		case !done:
+			status.InProgress = append(status.InProgress, machine.Name)
+		case succeeded:
+			m.Log.Info("Machine remediated", "machine", machine.Name)
+			// The Metal3Remediation is deleted like a MachineHealthCheck does
+			// once the Machine is healthy again.
+			if err := m.client.Delete(ctx, remediation); err != nil && !apierrors.IsNotFound(err) {
+				return errors.Wrapf(err, "failed to delete Metal3Remediation %s", remediation.Name)
+			}
+			status.Remediated = append(status.Remediated, machine.Name)
+		default:
+			m.Log.Info("Machine remediation failed", "machine", machine.Name,
+				"phase", remediationMgr.GetRemediationPhase())
+			status.Failed = append(status.Failed, machine.Name)
+		}
+	}
+
+	waiting := 0
+	for _, machine := range pending {
+		if len(status.InProgress) >= m.maxConcurrency() {
+			waiting++
+			continue
+		}
+		if _, ok := remediationsByName[machine.Name]; ok {
+			waiting++
+			continue
+		}
+		created, err := m.createRemediation(ctx, machine)
+		if err != nil {
+			return err
+		}
+		if !created {
+			waiting++
+			continue
+		}
+		status.InProgress = append(status.InProgress, machine.Name)
+	}
+
+	if waiting == 0 && len(status.InProgress) == 0 {
+		status.Phase = infrav1.PhaseCompleted
+	} else {
+		status.Phase = infrav1.PhaseRunning
+	}
+	return nil
+}
+
+// selectedMachines returns the Machines selected by the set, sorted by name.
+func (m *RemediationSetManager) selectedMachines(ctx context.Context) ([]*clusterv1.Machine, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&m.Metal3RemediationSet.Spec.Selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid selector")
+	}
+	if selector.Empty() {
+		return nil, errors.New("selector must not be empty")
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := m.client.List(ctx, machineList,
+		client.InNamespace(m.Metal3RemediationSet.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list Machines")
+	}
+
+	machines := make([]*clusterv1.Machine, 0, len(machineList.Items))
+	for i := range machineList.Items {
+		machines = append(machines, &machineList.Items[i])
+	}
+	sort.Slice(machines, func(i, j int) bool {
+		return machines[i].Name < machines[j].Name
+	})
+	return machines, nil
+}
+
+// createRemediation creates the Metal3Remediation of the Machine, named after
+// it as done by a MachineHealthCheck. It returns false if a Metal3Remediation
+// with that name already exists.
+func (m *RemediationSetManager) createRemediation(ctx context.Context, machine *clusterv1.Machine) (bool, error) {
+	remediation := &infrav1.Metal3Remediation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      machine.Name,
+			Namespace: machine.Namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       machine.Name,
+					UID:        machine.UID,
+				},
+				*metav1.NewControllerRef(m.Metal3RemediationSet,
+					infrav1.GroupVersion.WithKind("Metal3RemediationSet"),
+				),
+			},
+		},
+		Spec: infrav1.Metal3RemediationSpec{
+			Strategy: m.Metal3RemediationSet.Spec.Strategy.DeepCopy(),
+		},
+	}
+
+	m.Log.Info("Creating Metal3Remediation", "machine", machine.Name)
+	if err := m.client.Create(ctx, remediation); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to create Metal3Remediation for Machine %s", machine.Name)
+	}
+	return true, nil
+}
+
+// maxConcurrency returns the maximum number of Machines remediated at the
+// same time, at least one.
+func (m *RemediationSetManager) maxConcurrency() int {
+	if m.Metal3RemediationSet.Spec.MaxConcurrency < 1 {
+		return 1
+	}
+	return m.Metal3RemediationSet.Spec.MaxConcurrency
+}
+
+// remediationOutcome returns whether the remediation handled by remediationMgr
+// is over and, if so, whether it succeeded. A remediation of a Machine is done
+// once the node has been restored, which removes the finalizer in the waiting
+// phase, or once it has been quarantined.
+func remediationOutcome(remediationMgr RemediationManagerInterface) (bool, bool) {
+	switch remediationMgr.GetRemediationPhase() {
+	case infrav1.PhaseSucceeded, infrav1.PhaseQuarantined:
+		return true, true
+	case infrav1.PhaseWaiting:
+		return !remediationMgr.HasFinalizer(), true
+	case infrav1.PhaseDeleting, infrav1.PhaseFailed, infrav1.PhaseHostGone:
+		return true, false
+	}
+	return false, false
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Metal3RemediationSet manager", func() {
+	newRemediationSet := func(maxConcurrency int, status infrav1.Metal3RemediationSetStatus) *infrav1.Metal3RemediationSet {
+		return &infrav1.Metal3RemediationSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "maintenance",
+				Namespace: namespaceName,
+				UID:       "set-uid",
+			},
+			Spec: infrav1.Metal3RemediationSetSpec{
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"group": "maintenance"},
+				},
+				MaxConcurrency: maxConcurrency,
+				Strategy: &infrav1.RemediationStrategy{
+					Type:       infrav1.RebootRemediationStrategy,
+					RetryLimit: 1,
+				},
+			},
+			Status: status,
+		}
+	}
+
+	newGroupMachine := func(name string, selected bool) *clusterv1.Machine {
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+				UID:       types.UID("uid-" + name),
+			},
+		}
+		if selected {
+			machine.Labels = map[string]string{"group": "maintenance"}
+		}
+		return machine
+	}
+
+	newChildRemediation := func(name, phase string, finalizer bool, owned bool) *infrav1.Metal3Remediation {
+		remediation := &infrav1.Metal3Remediation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+			},
+			Status: infrav1.Metal3RemediationStatus{
+				Phase: phase,
+			},
+		}
+		if owned {
+			remediationSet := newRemediationSet(1, infrav1.Metal3RemediationSetStatus{})
+			remediation.Spec.Strategy = remediationSet.Spec.Strategy
+			remediation.OwnerReferences = []metav1.OwnerReference{
+				{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       name,
+					UID:        types.UID("uid-" + name),
+				},
+				*metav1.NewControllerRef(remediationSet,
+					infrav1.GroupVersion.WithKind("Metal3RemediationSet"),
+				),
+			}
+		}
+		if finalizer {
+			remediation.Finalizers = []string{RemediationFinalizer}
+		}
+		return remediation
+	}
+
+	machines := func() []client.Object {
+		return []client.Object{
+			newGroupMachine("machine-d", true),
+			newGroupMachine("machine-b", true),
+			newGroupMachine("machine-c", true),
+			newGroupMachine("machine-a", true),
+			newGroupMachine("machine-x", false),
+		}
+	}
+
+	type testCaseUpdateRemediations struct {
+		RemediationSet       *infrav1.Metal3RemediationSet
+		Remediations         []client.Object
+		ExpectError          bool
+		ExpectedPhase        string
+		ExpectedInProgress   []string
+		ExpectedRemediated   []string
+		ExpectedFailed       []string
+		ExpectedRemediations []string
+	}
+
+	DescribeTable("Test UpdateRemediations",
+		func(tc testCaseUpdateRemediations) {
+			objects := append(machines(), tc.Remediations...)
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(objects...).Build()
+
+			remediationSetMgr, err := NewRemediationSetManager(fakeClient, tc.RemediationSet,
+				logr.Discard(),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = remediationSetMgr.UpdateRemediations(context.TODO())
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+
+			status := tc.RemediationSet.Status
+			Expect(status.Phase).To(Equal(tc.ExpectedPhase))
+			Expect(status.InProgress).To(Equal(tc.ExpectedInProgress))
+			Expect(status.Remediated).To(Equal(tc.ExpectedRemediated))
+			Expect(status.Failed).To(Equal(tc.ExpectedFailed))
+
+			remediations := infrav1.Metal3RemediationList{}
+			Expect(fakeClient.List(context.TODO(), &remediations)).To(Succeed())
+			names := []string{}
+			for _, remediation := range remediations.Items {
+				names = append(names, remediation.Name)
+				if !metav1.IsControlledBy(&remediation, tc.RemediationSet) {
+					continue
+				}
+				Expect(remediation.Spec.Strategy).To(Equal(tc.RemediationSet.Spec.Strategy))
+				Expect(remediation.OwnerReferences).To(ContainElement(HaveField("Kind", "Machine")))
+			}
+			Expect(names).To(ConsistOf(tc.ExpectedRemediations))
+		},
+		Entry("Remediations are created in order up to the max concurrency", testCaseUpdateRemediations{
+			RemediationSet:       newRemediationSet(2, infrav1.Metal3RemediationSetStatus{}),
+			ExpectedPhase:        infrav1.PhaseRunning,
+			ExpectedInProgress:   []string{"machine-a", "machine-b"},
+			ExpectedRemediations: []string{"machine-a", "machine-b"},
+		}),
+		Entry("Max concurrency defaults to one", testCaseUpdateRemediations{
+			RemediationSet:       newRemediationSet(0, infrav1.Metal3RemediationSetStatus{}),
+			ExpectedPhase:        infrav1.PhaseRunning,
+			ExpectedInProgress:   []string{"machine-a"},
+			ExpectedRemediations: []string{"machine-a"},
+		}),
+		Entry("No remediation is created while the max concurrency is reached", testCaseUpdateRemediations{
+			RemediationSet: newRemediationSet(2, infrav1.Metal3RemediationSetStatus{}),
+			Remediations: []client.Object{
+				newChildRemediation("machine-a", infrav1.PhaseRunning, true, true),
+				newChildRemediation("machine-b", infrav1.PhaseWaiting, true, true),
+			},
+			ExpectedPhase:        infrav1.PhaseRunning,
+			ExpectedInProgress:   []string{"machine-a", "machine-b"},
+			ExpectedRemediations: []string{"machine-a", "machine-b"},
+		}),
+		Entry("Finished remediations are recorded and the next ones created", testCaseUpdateRemediations{
+			RemediationSet: newRemediationSet(2, infrav1.Metal3RemediationSetStatus{}),
+			Remediations: []client.Object{
+				newChildRemediation("machine-a", infrav1.PhaseWaiting, false, true),
+				newChildRemediation("machine-b", infrav1.PhaseDeleting, true, true),
+			},
+			ExpectedPhase:        infrav1.PhaseRunning,
+			ExpectedInProgress:   []string{"machine-c", "machine-d"},
+			ExpectedRemediated:   []string{"machine-a"},
+			ExpectedFailed:       []string{"machine-b"},
+			ExpectedRemediations: []string{"machine-b", "machine-c", "machine-d"},
+		}),
+		Entry("Machines remediated outside of the set are skipped", testCaseUpdateRemediations{
+			RemediationSet: newRemediationSet(1, infrav1.Metal3RemediationSetStatus{}),
+			Remediations: []client.Object{
+				newChildRemediation("machine-a", infrav1.PhaseRunning, true, false),
+			},
+			ExpectedPhase:        infrav1.PhaseRunning,
+			ExpectedInProgress:   []string{"machine-b"},
+			ExpectedRemediations: []string{"machine-a", "machine-b"},
+		}),
+		Entry("The set completes once all Machines are remediated", testCaseUpdateRemediations{
+			RemediationSet: newRemediationSet(2, infrav1.Metal3RemediationSetStatus{
+				Remediated: []string{"machine-a", "machine-b"},
+				Failed:     []string{"machine-c"},
+			}),
+			Remediations: []client.Object{
+				newChildRemediation("machine-d", infrav1.PhaseWaiting, false, true),
+			},
+			ExpectedPhase:        infrav1.PhaseCompleted,
+			ExpectedRemediated:   []string{"machine-a", "machine-b", "machine-d"},
+			ExpectedFailed:       []string{"machine-c"},
+			ExpectedRemediations: []string{},
+		}),
+		Entry("Empty selector", testCaseUpdateRemediations{
+			RemediationSet: &infrav1.Metal3RemediationSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "maintenance",
+					Namespace: namespaceName,
+				},
+				Spec: infrav1.Metal3RemediationSetSpec{
+					Strategy: &infrav1.RemediationStrategy{
+						Type: infrav1.RebootRemediationStrategy,
+					},
+				},
+			},
+			ExpectError: true,
+		}),
+		Entry("Missing strategy", testCaseUpdateRemediations{
+			RemediationSet: &infrav1.Metal3RemediationSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "maintenance",
+					Namespace: namespaceName,
+				},
+				Spec: infrav1.Metal3RemediationSetSpec{
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{"group": "maintenance"},
+					},
+				},
+			},
+			ExpectError: true,
+		}),
+	)
+})
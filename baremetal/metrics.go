@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"math/big"
+	"net/netip"
+	"time"
+
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// hostPowerCycles counts the power state changes requested on the
+// BareMetalHosts, either through the reboot annotation or the online field.
+var hostPowerCycles = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "metal3_host_power_cycles_total",
+		Help: "Number of power state changes requested on a BareMetalHost.",
+	},
+	[]string{"namespace", "host"},
+)
+
+// ipPoolAllocated and ipPoolCapacity report the utilization of the Metal3
+// IPPools, as observed by the data manager when it claims or releases
+// addresses.
+var (
+	ipPoolAllocated = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "metal3_ippool_allocated",
+			Help: "Number of IPClaims on a Metal3 IPPool.",
+		},
+		[]string{"namespace", "pool"},
+	)
+	ipPoolCapacity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "metal3_ippool_capacity",
+			Help: "Number of addresses in the ranges of a Metal3 IPPool.",
+		},
+		[]string{"namespace", "pool"},
+	)
+)
+
+// remediationRecoverySeconds observes the time from the last reboot of a
+// remediated host until its node is healthy again.
+var remediationRecoverySeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "metal3_remediation_recovery_seconds",
+		Help:    "Time from the last reboot of a remediated host until its node is healthy.",
+		Buckets: prometheus.ExponentialBuckets(30, 2, 8),
+	},
+	[]string{"strategy"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(hostPowerCycles, ipPoolAllocated, ipPoolCapacity, remediationRecoverySeconds)
+}
+
+// recordHostPowerCycle increments the power cycles counter of the host.
+func recordHostPowerCycle(host *bmov1alpha1.BareMetalHost) {
+	hostPowerCycles.WithLabelValues(host.Namespace, host.Name).Inc()
+}
+
+// ForgetHostPowerCycles deletes the power cycles counter of the host once it
+// is released or removed, so that the series of the past hosts are not kept.
+func ForgetHostPowerCycles(namespace, name string) {
+	hostPowerCycles.DeleteLabelValues(namespace, name)
+}
+
+// recordRemediationRecovery observes the recovery time of a node remediated
+// with the given strategy.
+func recordRemediationRecovery(strategy infrav1.RemediationType, recovery time.Duration) {
+	remediationRecoverySeconds.WithLabelValues(string(strategy)).Observe(recovery.Seconds())
+}
+
+// recordIPPoolUtilization sets the utilization gauges of the pool.
+func recordIPPoolUtilization(pool *ipamv1.IPPool, allocated int) {
+	ipPoolAllocated.WithLabelValues(pool.Namespace, pool.Name).Set(float64(allocated))
+	ipPoolCapacity.WithLabelValues(pool.Namespace, pool.Name).Set(ipPoolSize(pool))
+}
+
+// ipPoolSize returns the number of addresses in the ranges of the pool. As in
+// the IPAM controller, a range without start or end is bounded by its subnet,
+// excluding the network and broadcast addresses. Ranges that cannot be
+// bounded are not counted.
+func ipPoolSize(pool *ipamv1.IPPool) float64 {
+	size := new(big.Int)
+	for _, entry := range pool.Spec.Pools {
+		var subnet netip.Prefix
+		if entry.Subnet != nil {
+			var err error
+			if subnet, err = netip.ParsePrefix(string(*entry.Subnet)); err != nil {
+				continue
+			}
+			subnet = subnet.Masked()
+		}
+		var start, end netip.Addr
+		switch {
+		case entry.Start != nil:
+			start, _ = netip.ParseAddr(string(*entry.Start))
+		case subnet.IsValid():
+			start = subnet.Addr().Next()
+		}
+		switch {
+		case entry.End != nil:
+			end, _ = netip.ParseAddr(string(*entry.End))
+		case subnet.IsValid():
+			end = lastAddr(subnet).Prev()
+		}
+		if !start.IsValid() || !end.IsValid() || start.BitLen() != end.BitLen() || end.Less(start) {
+			continue
+		}
+		first, last := start.As16(), end.As16()
+		rangeSize := new(big.Int).Sub(new(big.Int).SetBytes(last[:]), new(big.Int).SetBytes(first[:]))
+		size.Add(size, rangeSize.Add(rangeSize, big.NewInt(1)))
+	}
+	result, _ := new(big.Float).SetInt(size).Float64()
+	return result
+}
+
+// lastAddr returns the last address of the subnet.
+func lastAddr(subnet netip.Prefix) netip.Addr {
+	addr := subnet.Addr().As16()
+	hostBits := subnet.Addr().BitLen() - subnet.Bits()
+	for i := len(addr) - 1; i >= 0 && hostBits > 0; i-- {
+		bits := min(hostBits, 8)
+		addr[i] |= byte(1<<bits - 1)
+		hostBits -= bits
+	}
+	last := netip.AddrFrom16(addr)
+	if subnet.Addr().Is4() {
+		return last.Unmap()
+	}
+	return last
+}
@@ -144,3 +144,18 @@ func (mr *MockManagerFactoryInterfaceMockRecorder) NewRemediationManager(arg0, a
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewRemediationManager", reflect.TypeOf((*MockManagerFactoryInterface)(nil).NewRemediationManager), arg0, arg1, arg2, arg3)
 }
+
+// NewRemediationSetManager mocks base method.
+func (m *MockManagerFactoryInterface) NewRemediationSetManager(arg0 *v1beta1.Metal3RemediationSet, arg1 logr.Logger) (baremetal.RemediationSetManagerInterface, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewRemediationSetManager", arg0, arg1)
+	ret0, _ := ret[0].(baremetal.RemediationSetManagerInterface)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewRemediationSetManager indicates an expected call of NewRemediationSetManager.
+func (mr *MockManagerFactoryInterfaceMockRecorder) NewRemediationSetManager(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewRemediationSetManager", reflect.TypeOf((*MockManagerFactoryInterface)(nil).NewRemediationSetManager), arg0, arg1)
+}
@@ -119,6 +119,20 @@ func (mr *MockClusterManagerInterfaceMockRecorder) UnsetFinalizer() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnsetFinalizer", reflect.TypeOf((*MockClusterManagerInterface)(nil).UnsetFinalizer))
 }
 
+// UpdateActiveRemediations mocks base method.
+func (m *MockClusterManagerInterface) UpdateActiveRemediations(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateActiveRemediations", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateActiveRemediations indicates an expected call of UpdateActiveRemediations.
+func (mr *MockClusterManagerInterfaceMockRecorder) UpdateActiveRemediations(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActiveRemediations", reflect.TypeOf((*MockClusterManagerInterface)(nil).UpdateActiveRemediations), arg0)
+}
+
 // UpdateClusterStatus mocks base method.
 func (m *MockClusterManagerInterface) UpdateClusterStatus() error {
 	m.ctrl.T.Helper()
@@ -132,3 +146,17 @@ func (mr *MockClusterManagerInterfaceMockRecorder) UpdateClusterStatus() *gomock
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClusterStatus", reflect.TypeOf((*MockClusterManagerInterface)(nil).UpdateClusterStatus))
 }
+
+// UpdateHostsAvailability mocks base method.
+func (m *MockClusterManagerInterface) UpdateHostsAvailability(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHostsAvailability", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHostsAvailability indicates an expected call of UpdateHostsAvailability.
+func (mr *MockClusterManagerInterfaceMockRecorder) UpdateHostsAvailability(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHostsAvailability", reflect.TypeOf((*MockClusterManagerInterface)(nil).UpdateHostsAvailability), arg0)
+}
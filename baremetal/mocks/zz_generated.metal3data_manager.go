@@ -80,6 +80,20 @@ func (mr *MockDataManagerInterfaceMockRecorder) ReleaseLeases(ctx interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLeases", reflect.TypeOf((*MockDataManagerInterface)(nil).ReleaseLeases), ctx)
 }
 
+// ReleaseSecrets mocks base method.
+func (m *MockDataManagerInterface) ReleaseSecrets(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseSecrets", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseSecrets indicates an expected call of ReleaseSecrets.
+func (mr *MockDataManagerInterfaceMockRecorder) ReleaseSecrets(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseSecrets", reflect.TypeOf((*MockDataManagerInterface)(nil).ReleaseSecrets), ctx)
+}
+
 // SetFinalizer mocks base method.
 func (m *MockDataManagerInterface) SetFinalizer() {
 	m.ctrl.T.Helper()
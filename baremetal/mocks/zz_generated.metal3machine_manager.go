@@ -25,6 +25,7 @@ package baremetal_mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	baremetal "github.com/metal3-io/cluster-api-provider-metal3/baremetal"
@@ -155,6 +156,21 @@ func (mr *MockMachineManagerInterfaceMockRecorder) HasAnnotation() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasAnnotation", reflect.TypeOf((*MockMachineManagerInterface)(nil).HasAnnotation))
 }
 
+// IsBootstrapDataAvailable mocks base method.
+func (m *MockMachineManagerInterface) IsBootstrapDataAvailable(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBootstrapDataAvailable", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsBootstrapDataAvailable indicates an expected call of IsBootstrapDataAvailable.
+func (mr *MockMachineManagerInterfaceMockRecorder) IsBootstrapDataAvailable(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBootstrapDataAvailable", reflect.TypeOf((*MockMachineManagerInterface)(nil).IsBootstrapDataAvailable), arg0)
+}
+
 // IsBootstrapReady mocks base method.
 func (m *MockMachineManagerInterface) IsBootstrapReady() bool {
 	m.ctrl.T.Helper()
@@ -183,6 +199,20 @@ func (mr *MockMachineManagerInterfaceMockRecorder) IsProvisioned() *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsProvisioned", reflect.TypeOf((*MockMachineManagerInterface)(nil).IsProvisioned))
 }
 
+// PowerScheduleRequeueAfter mocks base method.
+func (m *MockMachineManagerInterface) PowerScheduleRequeueAfter() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PowerScheduleRequeueAfter")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// PowerScheduleRequeueAfter indicates an expected call of PowerScheduleRequeueAfter.
+func (mr *MockMachineManagerInterfaceMockRecorder) PowerScheduleRequeueAfter() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PowerScheduleRequeueAfter", reflect.TypeOf((*MockMachineManagerInterface)(nil).PowerScheduleRequeueAfter))
+}
+
 // RemovePauseAnnotation mocks base method.
 func (m *MockMachineManagerInterface) RemovePauseAnnotation(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -264,6 +294,20 @@ func (mr *MockMachineManagerInterfaceMockRecorder) SetNodeProviderID(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNodeProviderID", reflect.TypeOf((*MockMachineManagerInterface)(nil).SetNodeProviderID), arg0, arg1, arg2)
 }
 
+// SetNodeRole mocks base method.
+func (m *MockMachineManagerInterface) SetNodeRole(arg0 context.Context, arg1 string, arg2 baremetal.ClientGetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNodeRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNodeRole indicates an expected call of SetNodeRole.
+func (mr *MockMachineManagerInterfaceMockRecorder) SetNodeRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNodeRole", reflect.TypeOf((*MockMachineManagerInterface)(nil).SetNodeRole), arg0, arg1, arg2)
+}
+
 // SetPauseAnnotation mocks base method.
 func (m *MockMachineManagerInterface) SetPauseAnnotation(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -74,6 +74,49 @@ func (mr *MockRemediationManagerInterfaceMockRecorder) AddOutOfServiceTaint(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOutOfServiceTaint", reflect.TypeOf((*MockRemediationManagerInterface)(nil).AddOutOfServiceTaint), ctx, clusterClient, node)
 }
 
+// AddRemediationTaint mocks base method.
+func (m *MockRemediationManagerInterface) AddRemediationTaint(ctx context.Context, clusterClient v11.CoreV1Interface, node *v1.Node) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRemediationTaint", ctx, clusterClient, node)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddRemediationTaint indicates an expected call of AddRemediationTaint.
+func (mr *MockRemediationManagerInterfaceMockRecorder) AddRemediationTaint(ctx, clusterClient, node interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRemediationTaint", reflect.TypeOf((*MockRemediationManagerInterface)(nil).AddRemediationTaint), ctx, clusterClient, node)
+}
+
+// ClearUnhealthyAnnotation mocks base method.
+func (m *MockRemediationManagerInterface) ClearUnhealthyAnnotation(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearUnhealthyAnnotation", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClearUnhealthyAnnotation indicates an expected call of ClearUnhealthyAnnotation.
+func (mr *MockRemediationManagerInterfaceMockRecorder) ClearUnhealthyAnnotation(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearUnhealthyAnnotation", reflect.TypeOf((*MockRemediationManagerInterface)(nil).ClearUnhealthyAnnotation), ctx)
+}
+
+// CordonNode mocks base method.
+func (m *MockRemediationManagerInterface) CordonNode(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CordonNode", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CordonNode indicates an expected call of CordonNode.
+func (mr *MockRemediationManagerInterfaceMockRecorder) CordonNode(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CordonNode", reflect.TypeOf((*MockRemediationManagerInterface)(nil).CordonNode), ctx)
+}
+
 // DeleteNode mocks base method.
 func (m *MockRemediationManagerInterface) DeleteNode(ctx context.Context, clusterClient v11.CoreV1Interface, node *v1.Node) error {
 	m.ctrl.T.Helper()
@@ -88,6 +131,18 @@ func (mr *MockRemediationManagerInterfaceMockRecorder) DeleteNode(ctx, clusterCl
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNode", reflect.TypeOf((*MockRemediationManagerInterface)(nil).DeleteNode), ctx, clusterClient, node)
 }
 
+// Escalate mocks base method.
+func (m *MockRemediationManagerInterface) Escalate() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Escalate")
+}
+
+// Escalate indicates an expected call of Escalate.
+func (mr *MockRemediationManagerInterfaceMockRecorder) Escalate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Escalate", reflect.TypeOf((*MockRemediationManagerInterface)(nil).Escalate))
+}
+
 // GetCapiMachine mocks base method.
 func (m *MockRemediationManagerInterface) GetCapiMachine(ctx context.Context) (*v1beta10.Machine, error) {
 	m.ctrl.T.Helper()
@@ -248,6 +303,20 @@ func (mr *MockRemediationManagerInterfaceMockRecorder) HasOutOfServiceTaint(node
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasOutOfServiceTaint", reflect.TypeOf((*MockRemediationManagerInterface)(nil).HasOutOfServiceTaint), node)
 }
 
+// HasReachRebootsBeforeEscalation mocks base method.
+func (m *MockRemediationManagerInterface) HasReachRebootsBeforeEscalation() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasReachRebootsBeforeEscalation")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// HasReachRebootsBeforeEscalation indicates an expected call of HasReachRebootsBeforeEscalation.
+func (mr *MockRemediationManagerInterfaceMockRecorder) HasReachRebootsBeforeEscalation() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasReachRebootsBeforeEscalation", reflect.TypeOf((*MockRemediationManagerInterface)(nil).HasReachRebootsBeforeEscalation))
+}
+
 // HasReachRetryLimit mocks base method.
 func (m *MockRemediationManagerInterface) HasReachRetryLimit() bool {
 	m.ctrl.T.Helper()
@@ -262,6 +331,33 @@ func (mr *MockRemediationManagerInterfaceMockRecorder) HasReachRetryLimit() *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasReachRetryLimit", reflect.TypeOf((*MockRemediationManagerInterface)(nil).HasReachRetryLimit))
 }
 
+// HostGoneTimedOut mocks base method.
+func (m *MockRemediationManagerInterface) HostGoneTimedOut(timeout time.Duration) (bool, time.Duration) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HostGoneTimedOut", timeout)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(time.Duration)
+	return ret0, ret1
+}
+
+// HostGoneTimedOut indicates an expected call of HostGoneTimedOut.
+func (mr *MockRemediationManagerInterfaceMockRecorder) HostGoneTimedOut(timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HostGoneTimedOut", reflect.TypeOf((*MockRemediationManagerInterface)(nil).HostGoneTimedOut), timeout)
+}
+
+// IncreaseRebootCount mocks base method.
+func (m *MockRemediationManagerInterface) IncreaseRebootCount() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "IncreaseRebootCount")
+}
+
+// IncreaseRebootCount indicates an expected call of IncreaseRebootCount.
+func (mr *MockRemediationManagerInterfaceMockRecorder) IncreaseRebootCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseRebootCount", reflect.TypeOf((*MockRemediationManagerInterface)(nil).IncreaseRebootCount))
+}
+
 // IncreaseRetryCount mocks base method.
 func (m *MockRemediationManagerInterface) IncreaseRetryCount() {
 	m.ctrl.T.Helper()
@@ -318,6 +414,21 @@ func (mr *MockRemediationManagerInterfaceMockRecorder) IsPoweredOn(ctx interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPoweredOn", reflect.TypeOf((*MockRemediationManagerInterface)(nil).IsPoweredOn), ctx)
 }
 
+// NodeIsHealthy mocks base method.
+func (m *MockRemediationManagerInterface) NodeIsHealthy(ctx context.Context, clusterClient v11.CoreV1Interface, node *v1.Node) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeIsHealthy", ctx, clusterClient, node)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NodeIsHealthy indicates an expected call of NodeIsHealthy.
+func (mr *MockRemediationManagerInterfaceMockRecorder) NodeIsHealthy(ctx, clusterClient, node interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeIsHealthy", reflect.TypeOf((*MockRemediationManagerInterface)(nil).NodeIsHealthy), ctx, clusterClient, node)
+}
+
 // OnlineStatus mocks base method.
 func (m *MockRemediationManagerInterface) OnlineStatus(host *v1alpha1.BareMetalHost) bool {
 	m.ctrl.T.Helper()
@@ -332,6 +443,49 @@ func (mr *MockRemediationManagerInterfaceMockRecorder) OnlineStatus(host interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnlineStatus", reflect.TypeOf((*MockRemediationManagerInterface)(nil).OnlineStatus), host)
 }
 
+// RebootAnnotationExpired mocks base method.
+func (m *MockRemediationManagerInterface) RebootAnnotationExpired(timeout time.Duration) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebootAnnotationExpired", timeout)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// RebootAnnotationExpired indicates an expected call of RebootAnnotationExpired.
+func (mr *MockRemediationManagerInterfaceMockRecorder) RebootAnnotationExpired(timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebootAnnotationExpired", reflect.TypeOf((*MockRemediationManagerInterface)(nil).RebootAnnotationExpired), timeout)
+}
+
+// RecordEvent mocks base method.
+func (m *MockRemediationManagerInterface) RecordEvent(eventType, reason, messageFmt string, args ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{eventType, reason, messageFmt}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "RecordEvent", varargs...)
+}
+
+// RecordEvent indicates an expected call of RecordEvent.
+func (mr *MockRemediationManagerInterfaceMockRecorder) RecordEvent(eventType, reason, messageFmt interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{eventType, reason, messageFmt}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordEvent", reflect.TypeOf((*MockRemediationManagerInterface)(nil).RecordEvent), varargs...)
+}
+
+// RecordRebootRequestedTime mocks base method.
+func (m *MockRemediationManagerInterface) RecordRebootRequestedTime() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordRebootRequestedTime")
+}
+
+// RecordRebootRequestedTime indicates an expected call of RecordRebootRequestedTime.
+func (mr *MockRemediationManagerInterfaceMockRecorder) RecordRebootRequestedTime() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordRebootRequestedTime", reflect.TypeOf((*MockRemediationManagerInterface)(nil).RecordRebootRequestedTime))
+}
+
 // RemoveNodeBackupAnnotations mocks base method.
 func (m *MockRemediationManagerInterface) RemoveNodeBackupAnnotations() {
 	m.ctrl.T.Helper()
@@ -372,6 +526,61 @@ func (mr *MockRemediationManagerInterfaceMockRecorder) RemovePowerOffAnnotation(
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePowerOffAnnotation", reflect.TypeOf((*MockRemediationManagerInterface)(nil).RemovePowerOffAnnotation), ctx)
 }
 
+// RemoveRemediationTaint mocks base method.
+func (m *MockRemediationManagerInterface) RemoveRemediationTaint(ctx context.Context, clusterClient v11.CoreV1Interface, node *v1.Node) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveRemediationTaint", ctx, clusterClient, node)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveRemediationTaint indicates an expected call of RemoveRemediationTaint.
+func (mr *MockRemediationManagerInterfaceMockRecorder) RemoveRemediationTaint(ctx, clusterClient, node interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveRemediationTaint", reflect.TypeOf((*MockRemediationManagerInterface)(nil).RemoveRemediationTaint), ctx, clusterClient, node)
+}
+
+// RequestReassociation mocks base method.
+func (m *MockRemediationManagerInterface) RequestReassociation(ctx context.Context, host *v1alpha1.BareMetalHost) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestReassociation", ctx, host)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestReassociation indicates an expected call of RequestReassociation.
+func (mr *MockRemediationManagerInterfaceMockRecorder) RequestReassociation(ctx, host interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestReassociation", reflect.TypeOf((*MockRemediationManagerInterface)(nil).RequestReassociation), ctx, host)
+}
+
+// ResetHostNotFoundTime mocks base method.
+func (m *MockRemediationManagerInterface) ResetHostNotFoundTime() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ResetHostNotFoundTime")
+}
+
+// ResetHostNotFoundTime indicates an expected call of ResetHostNotFoundTime.
+func (mr *MockRemediationManagerInterfaceMockRecorder) ResetHostNotFoundTime() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetHostNotFoundTime", reflect.TypeOf((*MockRemediationManagerInterface)(nil).ResetHostNotFoundTime))
+}
+
+// ResolveMachineSelector mocks base method.
+func (m *MockRemediationManagerInterface) ResolveMachineSelector(ctx context.Context) (*v1beta10.Machine, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveMachineSelector", ctx)
+	ret0, _ := ret[0].(*v1beta10.Machine)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveMachineSelector indicates an expected call of ResolveMachineSelector.
+func (mr *MockRemediationManagerInterfaceMockRecorder) ResolveMachineSelector(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveMachineSelector", reflect.TypeOf((*MockRemediationManagerInterface)(nil).ResolveMachineSelector), ctx)
+}
+
 // RetryLimitIsSet mocks base method.
 func (m *MockRemediationManagerInterface) RetryLimitIsSet() bool {
 	m.ctrl.T.Helper()
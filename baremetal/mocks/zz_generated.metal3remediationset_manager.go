@@ -0,0 +1,67 @@
+// /*
+// Copyright The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// */
+//
+//
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./baremetal/metal3remediationset_manager.go
+
+// Package baremetal_mocks is a generated GoMock package.
+package baremetal_mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRemediationSetManagerInterface is a mock of RemediationSetManagerInterface interface.
+type MockRemediationSetManagerInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRemediationSetManagerInterfaceMockRecorder
+}
+
+// MockRemediationSetManagerInterfaceMockRecorder is the mock recorder for MockRemediationSetManagerInterface.
+type MockRemediationSetManagerInterfaceMockRecorder struct {
+	mock *MockRemediationSetManagerInterface
+}
+
+// NewMockRemediationSetManagerInterface creates a new mock instance.
+func NewMockRemediationSetManagerInterface(ctrl *gomock.Controller) *MockRemediationSetManagerInterface {
+	mock := &MockRemediationSetManagerInterface{ctrl: ctrl}
+	mock.recorder = &MockRemediationSetManagerInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRemediationSetManagerInterface) EXPECT() *MockRemediationSetManagerInterfaceMockRecorder {
+	return m.recorder
+}
+
+// UpdateRemediations mocks base method.
+func (m *MockRemediationSetManagerInterface) UpdateRemediations(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRemediations", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRemediations indicates an expected call of UpdateRemediations.
+func (mr *MockRemediationSetManagerInterfaceMockRecorder) UpdateRemediations(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRemediations", reflect.TypeOf((*MockRemediationSetManagerInterface)(nil).UpdateRemediations), arg0)
+}
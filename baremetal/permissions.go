@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"slices"
+
+	"github.com/pkg/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// Permission is a verb on a resource the controllers need. Namespaced
+// permissions are checked in the watched namespace, or cluster-wide when all
+// namespaces are watched.
+type Permission struct {
+	Group      string
+	Resource   string
+	Verb       string
+	Namespaced bool
+}
+
+// String returns the permission as "verb resource.group".
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource += "." + p.Group
+	}
+	return p.Verb + " " + resource
+}
+
+// RequiredPermissions are the key permissions checked at startup.
+var RequiredPermissions = slices.Concat(
+	resourcePermissions("metal3.io", "baremetalhosts", true, "get", "list", "watch", "update", "patch"),
+	resourcePermissions("infrastructure.cluster.x-k8s.io", "metal3machines", true, "get", "list", "watch", "update", "patch"),
+	resourcePermissions("ipam.metal3.io", "ipclaims", true, "get", "list", "watch", "create", "delete"),
+	resourcePermissions("", "secrets", true, "get", "list", "watch", "create", "update", "delete"),
+	resourcePermissions("", "nodes", false, "get", "list", "watch", "update", "patch"),
+)
+
+// resourcePermissions returns the permissions for the verbs on a resource.
+func resourcePermissions(group, resource string, namespaced bool, verbs ...string) []Permission {
+	permissions := make([]Permission, 0, len(verbs))
+	for _, verb := range verbs {
+		permissions = append(permissions, Permission{
+			Group:      group,
+			Resource:   resource,
+			Verb:       verb,
+			Namespaced: namespaced,
+		})
+	}
+	return permissions
+}
+
+// MissingPermissions returns the permissions the controllers are not granted,
+// checked with SelfSubjectAccessReviews. The namespaced permissions are
+// checked in namespace, all namespaces if it is empty.
+func MissingPermissions(ctx context.Context, reviews authorizationv1client.SelfSubjectAccessReviewInterface,
+	namespace string, permissions []Permission,
+) ([]Permission, error) {
+	missing := []Permission{}
+	for _, permission := range permissions {
+		attributes := &authorizationv1.ResourceAttributes{
+			Group:    permission.Group,
+			Resource: permission.Resource,
+			Verb:     permission.Verb,
+		}
+		if permission.Namespaced {
+			attributes.Namespace = namespace
+		}
+		review, err := reviews.Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attributes},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to review the %s permission", permission)
+		}
+		if !review.Status.Allowed {
+			missing = append(missing, permission)
+		}
+	}
+	return missing, nil
+}
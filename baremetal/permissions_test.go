@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("RBAC permissions", func() {
+	// fakeAuthorizer returns a clientset answering the SelfSubjectAccessReviews
+	// with allowed, recording the namespace of each reviewed resource.
+	fakeAuthorizer := func(allowed func(*authorizationv1.ResourceAttributes) bool, reviewErr error,
+		namespaces map[string]string,
+	) *clientfake.Clientset {
+		clientSet := clientfake.NewSimpleClientset()
+		clientSet.PrependReactor("create", "selfsubjectaccessreviews",
+			func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if reviewErr != nil {
+					return true, nil, reviewErr
+				}
+				review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+				attributes := review.Spec.ResourceAttributes
+				namespaces[attributes.Resource] = attributes.Namespace
+				review.Status.Allowed = allowed(attributes)
+				return true, review, nil
+			},
+		)
+		return clientSet
+	}
+
+	type testCaseMissingPermissions struct {
+		Allowed         func(*authorizationv1.ResourceAttributes) bool
+		ReviewError     error
+		Namespace       string
+		ExpectedMissing []string
+		ExpectError     bool
+	}
+
+	DescribeTable("Test MissingPermissions",
+		func(tc testCaseMissingPermissions) {
+			namespaces := map[string]string{}
+			clientSet := fakeAuthorizer(tc.Allowed, tc.ReviewError, namespaces)
+
+			missing, err := MissingPermissions(context.TODO(),
+				clientSet.AuthorizationV1().SelfSubjectAccessReviews(), tc.Namespace, RequiredPermissions,
+			)
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			missingNames := []string{}
+			for _, permission := range missing {
+				missingNames = append(missingNames, permission.String())
+			}
+			Expect(missingNames).To(ConsistOf(tc.ExpectedMissing))
+
+			// Namespaced resources are reviewed in the watched namespace, the
+			// nodes cluster-wide.
+			Expect(namespaces).To(HaveKeyWithValue("baremetalhosts", tc.Namespace))
+			Expect(namespaces).To(HaveKeyWithValue("secrets", tc.Namespace))
+			Expect(namespaces).To(HaveKeyWithValue("nodes", ""))
+		},
+		Entry("All permissions granted", testCaseMissingPermissions{
+			Allowed:         func(*authorizationv1.ResourceAttributes) bool { return true },
+			ExpectedMissing: []string{},
+		}),
+		Entry("Permissions missing on a resource", testCaseMissingPermissions{
+			Allowed: func(attributes *authorizationv1.ResourceAttributes) bool {
+				return attributes.Resource != "ipclaims" ||
+					(attributes.Verb != "create" && attributes.Verb != "delete")
+			},
+			Namespace: namespaceName,
+			ExpectedMissing: []string{
+				"create ipclaims.ipam.metal3.io",
+				"delete ipclaims.ipam.metal3.io",
+			},
+		}),
+		Entry("Core resources", testCaseMissingPermissions{
+			Allowed: func(attributes *authorizationv1.ResourceAttributes) bool {
+				return attributes.Group != "" || attributes.Verb == "get"
+			},
+			ExpectedMissing: []string{
+				"list secrets", "watch secrets", "create secrets", "update secrets", "delete secrets",
+				"list nodes", "watch nodes", "update nodes", "patch nodes",
+			},
+		}),
+		Entry("Review error", testCaseMissingPermissions{
+			ReviewError: errors.New("forbidden"),
+			ExpectError: true,
+		}),
+	)
+})
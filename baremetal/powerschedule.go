@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PowerScheduleAnnotation is the annotation set on a Metal3Machine to power
+// its provisioned BMH on only during the given windows, and off outside them.
+// It holds windows separated by semicolons, each made of days and a UTC time
+// range, e.g. "Mon-Fri 07:00-19:00; Sat 09:00-12:00". The days are "*" or a
+// comma-separated list of days and day ranges. A range ending before it
+// starts ends the next day.
+const PowerScheduleAnnotation = "metal3.io/power-schedule"
+
+// weekdays maps the day names of a power schedule to their weekday.
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// powerWindow is a window of a power schedule, starting on the given days.
+type powerWindow struct {
+	days  [7]bool
+	start time.Duration
+	end   time.Duration
+}
+
+// powerSchedule is the list of windows during which a host is powered on.
+type powerSchedule []powerWindow
+
+// parsePowerSchedule parses the value of PowerScheduleAnnotation.
+func parsePowerSchedule(value string) (powerSchedule, error) {
+	schedule := powerSchedule{}
+	for _, entry := range strings.Split(value, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		fields := strings.Fields(entry)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("invalid power window %q, expected days and a time range", entry)
+		}
+		window := powerWindow{}
+		if err := parsePowerDays(fields[0], &window.days); err != nil {
+			return nil, err
+		}
+		start, end, found := strings.Cut(fields[1], "-")
+		if !found {
+			return nil, errors.Errorf("invalid time range %q, expected HH:MM-HH:MM", fields[1])
+		}
+		var err error
+		if window.start, err = parseTimeOfDay(start); err != nil {
+			return nil, err
+		}
+		if window.end, err = parseTimeOfDay(end); err != nil {
+			return nil, err
+		}
+		if window.end <= window.start {
+			window.end += 24 * time.Hour
+		}
+		schedule = append(schedule, window)
+	}
+	if len(schedule) == 0 {
+		return nil, errors.New("power schedule without window")
+	}
+	return schedule, nil
+}
+
+// parsePowerDays sets the days of a power window, given as "*" or a
+// comma-separated list of days and day ranges.
+func parsePowerDays(value string, days *[7]bool) error {
+	if value == "*" {
+		for day := range days {
+			days[day] = true
+		}
+		return nil
+	}
+	for _, dayRange := range strings.Split(value, ",") {
+		first, last, isRange := strings.Cut(dayRange, "-")
+		if !isRange {
+			last = first
+		}
+		firstDay, ok := weekdays[strings.ToLower(first)]
+		if !ok {
+			return errors.Errorf("invalid day %q", first)
+		}
+		lastDay, ok := weekdays[strings.ToLower(last)]
+		if !ok {
+			return errors.Errorf("invalid day %q", last)
+		}
+		for day := firstDay; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == lastDay {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseTimeOfDay parses a HH:MM time into its offset from midnight.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, errors.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// windowBounds calls f with the bounds of the occurrences of the windows
+// starting from the day before now until a week after it.
+func (s powerSchedule) windowBounds(now time.Time, f func(start, end time.Time)) {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for offset := -1; offset <= 7; offset++ {
+		day := midnight.AddDate(0, 0, offset)
+		for _, window := range s {
+			if window.days[day.Weekday()] {
+				f(day.Add(window.start), day.Add(window.end))
+			}
+		}
+	}
+}
+
+// online returns whether the host is powered on at the given time.
+func (s powerSchedule) online(now time.Time) bool {
+	online := false
+	s.windowBounds(now, func(start, end time.Time) {
+		if !now.Before(start) && now.Before(end) {
+			online = true
+		}
+	})
+	return online
+}
+
+// nextChange returns the duration from now until the next window starts or
+// ends.
+func (s powerSchedule) nextChange(now time.Time) time.Duration {
+	var next time.Duration
+	s.windowBounds(now, func(start, end time.Time) {
+		for _, bound := range []time.Time{start, end} {
+			if until := bound.Sub(now); until > 0 && (next == 0 || until < next) {
+				next = until
+			}
+		}
+	})
+	return next
+}
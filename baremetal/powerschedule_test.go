@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Power schedule", func() {
+	// 2024-06-03 is a Monday.
+	at := func(day int, hour, minute int) time.Time {
+		return time.Date(2024, time.June, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	DescribeTable("Test parsePowerSchedule",
+		func(value string, expectError bool) {
+			schedule, err := parsePowerSchedule(value)
+			if expectError {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedule).NotTo(BeEmpty())
+		},
+		Entry("Single window", "Mon-Fri 07:00-19:00", false),
+		Entry("Several windows", "Mon-Fri 07:00-19:00; Sat,Sun 09:00-12:00;", false),
+		Entry("Every day", "* 08:00-18:00", false),
+		Entry("Empty schedule", " ; ", true),
+		Entry("Missing time range", "Mon-Fri", true),
+		Entry("Invalid day", "Mon-Fry 07:00-19:00", true),
+		Entry("Invalid time", "Mon 7h-19h", true),
+		Entry("Time out of range", "Mon 07:00-25:00", true),
+	)
+
+	type testCasePowerScheduleOnline struct {
+		Schedule       string
+		Now            time.Time
+		ExpectedOnline bool
+		ExpectedChange time.Duration
+	}
+
+	DescribeTable("Test power schedule evaluation",
+		func(tc testCasePowerScheduleOnline) {
+			schedule, err := parsePowerSchedule(tc.Schedule)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedule.online(tc.Now)).To(Equal(tc.ExpectedOnline))
+			Expect(schedule.nextChange(tc.Now)).To(Equal(tc.ExpectedChange))
+		},
+		Entry("In the window", testCasePowerScheduleOnline{
+			Schedule:       "Mon-Fri 07:00-19:00",
+			Now:            at(3, 10, 0),
+			ExpectedOnline: true,
+			ExpectedChange: 9 * time.Hour,
+		}),
+		Entry("At the start of the window", testCasePowerScheduleOnline{
+			Schedule:       "Mon-Fri 07:00-19:00",
+			Now:            at(3, 7, 0),
+			ExpectedOnline: true,
+			ExpectedChange: 12 * time.Hour,
+		}),
+		Entry("Before the window", testCasePowerScheduleOnline{
+			Schedule:       "Mon-Fri 07:00-19:00",
+			Now:            at(3, 6, 30),
+			ExpectedOnline: false,
+			ExpectedChange: 30 * time.Minute,
+		}),
+		Entry("At the end of the window", testCasePowerScheduleOnline{
+			Schedule:       "Mon-Fri 07:00-19:00",
+			Now:            at(3, 19, 0),
+			ExpectedOnline: false,
+			ExpectedChange: 12 * time.Hour,
+		}),
+		Entry("Out of the days of the window", testCasePowerScheduleOnline{
+			Schedule:       "Mon-Fri 07:00-19:00",
+			Now:            at(8, 10, 0),
+			ExpectedOnline: false,
+			ExpectedChange: 45 * time.Hour,
+		}),
+		Entry("In a window of another day", testCasePowerScheduleOnline{
+			Schedule:       "Mon-Fri 07:00-19:00; Sat 09:00-12:00",
+			Now:            at(8, 10, 0),
+			ExpectedOnline: true,
+			ExpectedChange: 2 * time.Hour,
+		}),
+		Entry("In an overnight window, after midnight", testCasePowerScheduleOnline{
+			Schedule:       "Mon 22:00-06:00",
+			Now:            at(4, 2, 0),
+			ExpectedOnline: true,
+			ExpectedChange: 4 * time.Hour,
+		}),
+		Entry("Out of an overnight window, on its day", testCasePowerScheduleOnline{
+			Schedule:       "Mon 22:00-06:00",
+			Now:            at(3, 21, 0),
+			ExpectedOnline: false,
+			ExpectedChange: time.Hour,
+		}),
+		Entry("In a day range wrapping around the week", testCasePowerScheduleOnline{
+			Schedule:       "Fri-Mon 00:00-00:00",
+			Now:            at(9, 12, 0),
+			ExpectedOnline: true,
+			ExpectedChange: 12 * time.Hour,
+		}),
+	)
+})
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ReadOnly makes the managers and the controllers skip all their writes, to
+// the BareMetalHosts as well as to the other objects, the nodes of the
+// workload clusters and the pre-deprovision hook, logging them instead. The controllers still
+// compute the desired state and update the status and conditions of the
+// objects they reconcile, which allows observing CAPM3 safely in a new
+// environment.
+var ReadOnly bool
+
+// managerClient returns the client of a manager, which skips the writes in
+// read-only mode.
+func managerClient(cl client.Client, log logr.Logger) client.Client {
+	if !ReadOnly || cl == nil {
+		return cl
+	}
+	return &readOnlyClient{Client: cl, log: log}
+}
+
+// readOnlyClient is a client whose writes are no-ops with a log line.
+type readOnlyClient struct {
+	client.Client
+	log logr.Logger
+}
+
+// skipWrite logs the write of obj skipped in read-only mode.
+func (c *readOnlyClient) skipWrite(verb string, obj client.Object, subResource string) {
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+	keysAndValues := []interface{}{"verb", verb, "kind", kind, "namespace", obj.GetNamespace(), "name", obj.GetName()}
+	if subResource != "" {
+		keysAndValues = append(keysAndValues, "subresource", subResource)
+	}
+	c.log.Info("Read-only mode, skipping the write", keysAndValues...)
+}
+
+// Create implements client.Writer.
+func (c *readOnlyClient) Create(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+	c.skipWrite("create", obj, "")
+	return nil
+}
+
+// Update implements client.Writer.
+func (c *readOnlyClient) Update(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+	c.skipWrite("update", obj, "")
+	return nil
+}
+
+// Patch implements client.Writer.
+func (c *readOnlyClient) Patch(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+	c.skipWrite("patch", obj, "")
+	return nil
+}
+
+// Delete implements client.Writer.
+func (c *readOnlyClient) Delete(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+	c.skipWrite("delete", obj, "")
+	return nil
+}
+
+// DeleteAllOf implements client.Writer.
+func (c *readOnlyClient) DeleteAllOf(_ context.Context, obj client.Object, _ ...client.DeleteAllOfOption) error {
+	c.skipWrite("deletecollection", obj, "")
+	return nil
+}
+
+// Status implements client.StatusClient.
+func (c *readOnlyClient) Status() client.SubResourceWriter {
+	return c.SubResource("status")
+}
+
+// SubResource implements client.SubResourceClientConstructor.
+func (c *readOnlyClient) SubResource(subResource string) client.SubResourceClient {
+	return &readOnlySubResourceClient{
+		SubResourceClient: c.Client.SubResource(subResource),
+		client:            c,
+		subResource:       subResource,
+	}
+}
+
+// readOnlySubResourceClient is a subresource client whose writes are no-ops
+// with a log line.
+type readOnlySubResourceClient struct {
+	client.SubResourceClient
+	client      *readOnlyClient
+	subResource string
+}
+
+// Create implements client.SubResourceWriter.
+func (c *readOnlySubResourceClient) Create(_ context.Context, obj client.Object, _ client.Object,
+	_ ...client.SubResourceCreateOption,
+) error {
+	c.client.skipWrite("create", obj, c.subResource)
+	return nil
+}
+
+// Update implements client.SubResourceWriter.
+func (c *readOnlySubResourceClient) Update(_ context.Context, obj client.Object,
+	_ ...client.SubResourceUpdateOption,
+) error {
+	c.client.skipWrite("update", obj, c.subResource)
+	return nil
+}
+
+// Patch implements client.SubResourceWriter.
+func (c *readOnlySubResourceClient) Patch(_ context.Context, obj client.Object, _ client.Patch,
+	_ ...client.SubResourcePatchOption,
+) error {
+	c.client.skipWrite("patch", obj, c.subResource)
+	return nil
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baremetal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientfake "k8s.io/client-go/kubernetes/fake"
+	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Read-only mode", func() {
+	BeforeEach(func() {
+		ReadOnly = true
+		DeferCleanup(func() { ReadOnly = false })
+	})
+
+	// readOnlyLogger returns a logger recording the skipped writes in lines.
+	readOnlyLogger := func(lines *[]string) logr.Logger {
+		return funcr.New(func(_, args string) {
+			if strings.Contains(args, `"msg"="Read-only mode, skipping the write"`) {
+				*lines = append(*lines, args)
+			}
+		}, funcr.Options{})
+	}
+
+	newHost := func() *bmov1alpha1.BareMetalHost {
+		return &bmov1alpha1.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        baremetalhostName,
+				Namespace:   namespaceName,
+				Annotations: map[string]string{"foo": "bar"},
+			},
+			Spec: bmov1alpha1.BareMetalHostSpec{Online: true},
+		}
+	}
+
+	getHost := func(cl client.Client) *bmov1alpha1.BareMetalHost {
+		host := &bmov1alpha1.BareMetalHost{}
+		Expect(cl.Get(context.TODO(), client.ObjectKey{
+			Name: baremetalhostName, Namespace: namespaceName,
+		}, host)).To(Succeed())
+		return host
+	}
+
+	newReadOnlyMetal3Machine := func() *infrav1.Metal3Machine {
+		return newMetal3Machine(metal3machineName, nil, nil, &metav1.ObjectMeta{
+			Name:      metal3machineName,
+			Namespace: namespaceName,
+			Annotations: map[string]string{
+				HostAnnotation: namespaceName + "/" + baremetalhostName,
+			},
+		})
+	}
+
+	It("Skips the writes of the client", func() {
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(newHost()).Build()
+		lines := []string{}
+		cl := managerClient(fakeClient, readOnlyLogger(&lines))
+
+		host := getHost(cl)
+		host.Spec.Online = false
+		Expect(cl.Update(context.TODO(), host)).To(Succeed())
+		Expect(cl.Patch(context.TODO(), host, client.MergeFrom(getHost(cl)))).To(Succeed())
+		host.Status.ErrorMessage = "error"
+		Expect(cl.Status().Update(context.TODO(), host)).To(Succeed())
+		Expect(cl.Delete(context.TODO(), host)).To(Succeed())
+		Expect(cl.Create(context.TODO(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespaceName},
+		})).To(Succeed())
+
+		host = getHost(fakeClient)
+		Expect(host.Spec.Online).To(BeTrue())
+		Expect(host.Status.ErrorMessage).To(BeEmpty())
+		err := fakeClient.Get(context.TODO(), client.ObjectKey{Name: "secret", Namespace: namespaceName},
+			&corev1.Secret{},
+		)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		Expect(lines).To(HaveLen(5))
+		Expect(lines[0]).To(ContainSubstring(`"verb"="update" "kind"="BareMetalHost" "namespace"="` +
+			namespaceName + `" "name"="` + baremetalhostName + `"`,
+		))
+		Expect(lines[2]).To(ContainSubstring(`"subresource"="status"`))
+		Expect(lines[4]).To(ContainSubstring(`"verb"="create" "kind"="Secret"`))
+	})
+
+	It("Writes through the client when not in read-only mode", func() {
+		ReadOnly = false
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).Build()
+		Expect(managerClient(fakeClient, logr.Discard())).To(BeIdenticalTo(fakeClient))
+	})
+
+	It("Does not mutate the host from the Metal3Machine manager", func() {
+		m3m := newReadOnlyMetal3Machine()
+		fakeClient := fake.NewClientBuilder().WithScheme(setupSchemeMm()).WithObjects(newHost(), m3m).Build()
+		lines := []string{}
+		machineMgr, err := NewMachineManager(fakeClient, nil, nil, nil, m3m, readOnlyLogger(&lines))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(machineMgr.SetPauseAnnotation(context.TODO())).To(Succeed())
+		Expect(getHost(fakeClient).Annotations).To(Equal(map[string]string{"foo": "bar"}))
+		Expect(lines).NotTo(BeEmpty())
+	})
+
+	It("Does not patch the nodes from the Metal3Machine manager", func() {
+		host := newHost()
+		host.UID = Bmhuid
+		m3m := newReadOnlyMetal3Machine()
+		m3m.Spec.NodeRole = "worker"
+		fakeClient := fake.NewClientBuilder().WithScheme(setupSchemeMm()).WithObjects(host, m3m).Build()
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "mynode",
+				Labels: map[string]string{ProviderLabelPrefix: string(Bmhuid)},
+			},
+		}
+		corev1Client := clientfake.NewSimpleClientset(node).CoreV1()
+		clientFactory := func(_ context.Context, _ client.Client, _ *clusterv1.Cluster) (
+			clientcorev1.CoreV1Interface, error,
+		) {
+			return corev1Client, nil
+		}
+		lines := []string{}
+		machineMgr, err := NewMachineManager(fakeClient, newCluster(clusterName),
+			newMetal3Cluster(metal3ClusterName, nil, nil, nil), &clusterv1.Machine{}, m3m,
+			readOnlyLogger(&lines),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		providerID := ""
+		Expect(machineMgr.SetNodeProviderID(context.TODO(), &providerID, clientFactory)).To(Succeed())
+		Expect(providerID).NotTo(BeEmpty())
+		storedNode, err := corev1Client.Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storedNode.Spec.ProviderID).To(BeEmpty())
+
+		storedNode.Spec.ProviderID = providerID
+		_, err = corev1Client.Nodes().Update(context.TODO(), storedNode, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(machineMgr.SetNodeRole(context.TODO(), providerID, clientFactory)).To(Succeed())
+		storedNode, err = corev1Client.Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storedNode.Labels).To(Equal(map[string]string{ProviderLabelPrefix: string(Bmhuid)}))
+
+		Expect(lines).To(HaveLen(2))
+		Expect(lines).To(HaveEach(ContainSubstring(`"verb"="patch" "kind"="Node" "name"="mynode"`)))
+	})
+
+	It("Does not call the pre-deprovision hook from the Metal3Machine manager", func() {
+		called := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			called = true
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		defer func(url string) { PreDeprovisionHookURL = url }(PreDeprovisionHookURL)
+		PreDeprovisionHookURL = server.URL
+
+		m3m := newReadOnlyMetal3Machine()
+		lines := []string{}
+		machineMgr, err := NewMachineManager(nil, nil, nil, nil, m3m, readOnlyLogger(&lines))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(machineMgr.runPreDeprovisionHook(context.TODO(), newHost())).To(Succeed())
+		Expect(called).To(BeFalse())
+		Expect(m3m.Annotations).NotTo(HaveKey(PreDeprovisionHookAnnotation))
+		Expect(lines).To(ConsistOf(ContainSubstring(`"verb"="post" "kind"="PreDeprovisionHook"`)))
+	})
+
+	It("Does not create the secrets from the Metal3Data manager", func() {
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).Build()
+		dataMgr, err := NewDataManager(fakeClient, &infrav1.Metal3Data{}, logr.Discard())
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(createSecret(context.TODO(), dataMgr.client, "data-metadata", namespaceName, clusterName,
+			nil, map[string][]byte{"metaData": []byte("foo")},
+		)).To(Succeed())
+		err = fakeClient.Get(context.TODO(), client.ObjectKey{Name: "data-metadata", Namespace: namespaceName},
+			&corev1.Secret{},
+		)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Does not mutate the host nor the node from the Metal3Remediation manager", func() {
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(newHost()).Build()
+		lines := []string{}
+		remediationMgr, err := NewRemediationManager(fakeClient, nil, &infrav1.Metal3Remediation{},
+			newReadOnlyMetal3Machine(), nil, readOnlyLogger(&lines),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(remediationMgr.SetUnhealthyAnnotation(context.TODO())).To(Succeed())
+		Expect(getHost(fakeClient).Annotations).NotTo(HaveKey(infrav1.UnhealthyAnnotation))
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "mynode"}}
+		corev1Client := clientfake.NewSimpleClientset(node.DeepCopy()).CoreV1()
+		node.Spec.Unschedulable = true
+		Expect(remediationMgr.UpdateNode(context.TODO(), corev1Client, node)).To(Succeed())
+		Expect(remediationMgr.DeleteNode(context.TODO(), corev1Client, node)).To(Succeed())
+		storedNode, err := corev1Client.Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(storedNode.Spec.Unschedulable).To(BeFalse())
+
+		Expect(lines).To(ContainElements(
+			ContainSubstring(`"verb"="update" "kind"="Node" "name"="mynode"`),
+			ContainSubstring(`"verb"="delete" "kind"="Node" "name"="mynode"`),
+		))
+	})
+})
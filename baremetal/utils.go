@@ -26,6 +26,7 @@ import (
 	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -42,6 +43,10 @@ const (
 	metal3MachineKind = "Metal3Machine"
 )
 
+// PatchConflictRetries is the number of times a patch failing with a conflict
+// is retried, on the object fetched again, before the error is returned.
+var PatchConflictRetries = 3
+
 // Contains returns true if a list contains a string.
 func Contains(list []string, strToSearch string) bool {
 	for _, item := range list {
@@ -85,6 +90,42 @@ func patchIfFound(ctx context.Context, helper *patch.Helper, host client.Object)
 	return err
 }
 
+// patchWithConflictRetry applies mutate to obj and patches it with helper. On
+// a conflict, obj is fetched again and mutate applied to it again, at most
+// PatchConflictRetries times.
+func patchWithConflictRetry(ctx context.Context, cl client.Client, obj client.Object,
+	helper *patch.Helper, mutate func(),
+) error {
+	mutate()
+	err := helper.Patch(ctx, obj)
+	for retry := 0; retry < PatchConflictRetries && isConflict(err); retry++ {
+		if err = cl.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			return err
+		}
+		if helper, err = patch.NewHelper(obj, cl); err != nil {
+			return err
+		}
+		mutate()
+		err = helper.Patch(ctx, obj)
+	}
+	return err
+}
+
+// isConflict returns true if err, or one of the errors it aggregates as
+// returned by the patch helper, is a conflict.
+func isConflict(err error) bool {
+	var aggr kerrors.Aggregate
+	if errors.As(err, &aggr) {
+		for _, kerr := range aggr.Errors() {
+			if apierrors.IsConflict(kerr) {
+				return true
+			}
+		}
+		return false
+	}
+	return apierrors.IsConflict(err)
+}
+
 func updateObject(ctx context.Context, cl client.Client, obj client.Object) error {
 	err := cl.Update(ctx, obj.DeepCopyObject().(client.Object))
 	if apierrors.IsConflict(err) {
@@ -125,6 +166,7 @@ func createSecret(ctx context.Context, cl client.Client, name string,
 				clusterv1.ClusterNameLabel: clusterName,
 			},
 			OwnerReferences: ownerRefs,
+			Finalizers:      []string{infrav1.DataSecretFinalizer},
 		},
 		Data: content,
 		Type: metal3SecretType,
@@ -132,6 +174,10 @@ func createSecret(ctx context.Context, cl client.Client, name string,
 
 	secret, err := checkSecretExists(ctx, cl, name, namespace)
 	if err == nil {
+		// Skip the update if the secret is unchanged, to avoid needless writes.
+		if secretUpToDate(&secret, bootstrapSecret) {
+			return nil
+		}
 		// Update the secret with user data.
 		secret.ObjectMeta.Labels = bootstrapSecret.ObjectMeta.Labels
 		secret.ObjectMeta.OwnerReferences = bootstrapSecret.ObjectMeta.OwnerReferences
@@ -144,6 +190,15 @@ func createSecret(ctx context.Context, cl client.Client, name string,
 	return err
 }
 
+// secretUpToDate returns true if the existing secret already holds the
+// content, labels, owner references and type of the desired secret.
+func secretUpToDate(existing, desired *corev1.Secret) bool {
+	return existing.Type == desired.Type &&
+		equality.Semantic.DeepEqual(existing.Labels, desired.Labels) &&
+		equality.Semantic.DeepEqual(existing.OwnerReferences, desired.OwnerReferences) &&
+		equality.Semantic.DeepEqual(existing.Data, desired.Data)
+}
+
 func checkSecretExists(ctx context.Context, cl client.Client, name string,
 	namespace string,
 ) (corev1.Secret, error) {
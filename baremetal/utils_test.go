@@ -31,6 +31,8 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 var _ = Describe("Metal3 manager utils", func() {
@@ -392,6 +394,101 @@ var _ = Describe("Metal3 manager utils", func() {
 		Entry("Object exists", true),
 	)
 
+	type testCaseCreateSecretUnchanged struct {
+		labels          map[string]string
+		ownerRefs       []metav1.OwnerReference
+		content         map[string][]byte
+		expectedUpdates int
+	}
+
+	DescribeTable("Test createSecret skips unchanged secrets",
+		func(tc testCaseCreateSecretUnchanged) {
+			ownerRef := []metav1.OwnerReference{{
+				Name:       "abcd",
+				Kind:       "Metal3Machine",
+				APIVersion: infrav1.GroupVersion.String(),
+				UID:        "7df7fe8e-9cdb-4c57-8144-0a30bf6b9496",
+			}}
+			content := map[string][]byte{
+				"abc": []byte("def"),
+			}
+			existing := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "abc",
+					Namespace:       namespaceName,
+					Labels:          tc.labels,
+					OwnerReferences: tc.ownerRefs,
+				},
+				Data: tc.content,
+				Type: metal3SecretType,
+			}
+			updates := 0
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).
+				WithObjects(existing).WithInterceptorFuncs(interceptor.Funcs{
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					updates++
+					return c.Update(ctx, obj, opts...)
+				},
+			}).Build()
+
+			err := createSecret(context.TODO(), fakeClient, "abc", namespaceName, "ghi",
+				ownerRef, content,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updates).To(Equal(tc.expectedUpdates))
+
+			savedSecret := corev1.Secret{}
+			err = fakeClient.Get(context.TODO(),
+				client.ObjectKey{
+					Name:      "abc",
+					Namespace: namespaceName,
+				},
+				&savedSecret,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(savedSecret.ObjectMeta.OwnerReferences).To(Equal(ownerRef))
+			Expect(savedSecret.Data).To(Equal(content))
+		},
+		Entry("Unchanged secret", testCaseCreateSecretUnchanged{
+			labels: map[string]string{clusterv1.ClusterNameLabel: "ghi"},
+			ownerRefs: []metav1.OwnerReference{{
+				Name:       "abcd",
+				Kind:       "Metal3Machine",
+				APIVersion: infrav1.GroupVersion.String(),
+				UID:        "7df7fe8e-9cdb-4c57-8144-0a30bf6b9496",
+			}},
+			content:         map[string][]byte{"abc": []byte("def")},
+			expectedUpdates: 0,
+		}),
+		Entry("Content changed", testCaseCreateSecretUnchanged{
+			labels: map[string]string{clusterv1.ClusterNameLabel: "ghi"},
+			ownerRefs: []metav1.OwnerReference{{
+				Name:       "abcd",
+				Kind:       "Metal3Machine",
+				APIVersion: infrav1.GroupVersion.String(),
+				UID:        "7df7fe8e-9cdb-4c57-8144-0a30bf6b9496",
+			}},
+			content:         map[string][]byte{"abc": []byte("old")},
+			expectedUpdates: 1,
+		}),
+		Entry("Owner references changed", testCaseCreateSecretUnchanged{
+			labels:          map[string]string{clusterv1.ClusterNameLabel: "ghi"},
+			content:         map[string][]byte{"abc": []byte("def")},
+			expectedUpdates: 1,
+		}),
+		Entry("Labels changed", testCaseCreateSecretUnchanged{
+			labels: map[string]string{"foo": "bar"},
+			ownerRefs: []metav1.OwnerReference{{
+				Name:       "abcd",
+				Kind:       "Metal3Machine",
+				APIVersion: infrav1.GroupVersion.String(),
+				UID:        "7df7fe8e-9cdb-4c57-8144-0a30bf6b9496",
+			}},
+			content:         map[string][]byte{"abc": []byte("def")},
+			expectedUpdates: 1,
+		}),
+	)
+
 	DescribeTable("Test deleteSecret",
 		func(secretExists bool) {
 			if secretExists {
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const hostSelectorWebhookPath = "/validate-infrastructure-cluster-x-k8s-io-v1beta1-hostselector"
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-hostselector,mutating=false,failurePolicy=ignore,groups=infrastructure.cluster.x-k8s.io,resources=metal3machines;metal3machinetemplates,versions=v1beta1,name=hostselector.metal3machine.infrastructure.cluster.x-k8s.io,matchPolicy=Equivalent,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+// HostSelectorWebhook warns at admission about Metal3Machines and
+// Metal3MachineTemplates whose host selectors match none of the BareMetalHosts
+// of their namespace, which usually comes from a typo in the labels. It never
+// rejects a request, the hosts may be created later.
+type HostSelectorWebhook struct {
+	Client  client.Reader
+	decoder admission.Decoder
+}
+
+// SetupWebhookWithManager registers the webhook on the webhook server of the
+// manager.
+func (w *HostSelectorWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	w.decoder = admission.NewDecoder(mgr.GetScheme())
+	mgr.GetWebhookServer().Register(hostSelectorWebhookPath, &webhook.Admission{Handler: w})
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (w *HostSelectorWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	var spec *infrav1.Metal3MachineSpec
+	switch req.Kind.Kind {
+	case "Metal3Machine":
+		m3m := &infrav1.Metal3Machine{}
+		if err := w.decoder.Decode(req, m3m); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		spec = &m3m.Spec
+	case "Metal3MachineTemplate":
+		m3mt := &infrav1.Metal3MachineTemplate{}
+		if err := w.decoder.Decode(req, m3mt); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		spec = &m3mt.Spec.Template.Spec
+	default:
+		return admission.Allowed("")
+	}
+
+	warning, err := w.hostSelectorWarning(ctx, req.Namespace, spec)
+	if err != nil {
+		return admission.Allowed("").WithWarnings(
+			fmt.Sprintf("unable to check the host selector: %v", err),
+		)
+	}
+	if warning == "" {
+		return admission.Allowed("")
+	}
+	return admission.Allowed("").WithWarnings(warning)
+}
+
+// hostSelectorWarning returns a warning if none of the host selectors of the
+// spec, the fallback ones included, match the labels of a BareMetalHost of the
+// namespace. Selectors without labels or expressions are not checked.
+func (w *HostSelectorWebhook) hostSelectorWarning(ctx context.Context, namespace string,
+	spec *infrav1.Metal3MachineSpec,
+) (string, error) {
+	hostSelectors := append([]infrav1.HostSelector{spec.HostSelector}, spec.FallbackHostSelectors...)
+	checked := false
+	for _, hostSelector := range hostSelectors {
+		if len(hostSelector.MatchLabels) == 0 && len(hostSelector.MatchExpressions) == 0 {
+			continue
+		}
+		labelSelector, err := baremetal.HostLabelSelector(hostSelector)
+		if err != nil {
+			// Invalid selectors are not checked, they are left to the
+			// validating webhooks.
+			continue
+		}
+		checked = true
+
+		hosts := bmov1alpha1.BareMetalHostList{}
+		if err := w.Client.List(ctx, &hosts,
+			client.InNamespace(namespace),
+			client.MatchingLabelsSelector{Selector: labelSelector},
+		); err != nil {
+			return "", err
+		}
+		if len(hosts.Items) > 0 {
+			return "", nil
+		}
+	}
+	if !checked {
+		return "", nil
+	}
+	return fmt.Sprintf("no BareMetalHost in namespace %s matches the host selector, "+
+		"check its labels for typos", namespace), nil
+}
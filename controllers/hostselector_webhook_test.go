@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("HostSelector webhook", func() {
+	ssdHost := &bmov1alpha1.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ssd-host",
+			Namespace: namespaceName,
+			Labels:    map[string]string{"disk": "ssd"},
+		},
+	}
+
+	newSpec := func(hostSelector infrav1.HostSelector, fallbacks ...infrav1.HostSelector) infrav1.Metal3MachineSpec {
+		return infrav1.Metal3MachineSpec{
+			HostSelector:          hostSelector,
+			FallbackHostSelectors: fallbacks,
+		}
+	}
+
+	type testCaseHostSelectorWebhook struct {
+		Template        bool
+		Spec            infrav1.Metal3MachineSpec
+		ExpectedWarning bool
+	}
+
+	DescribeTable("Test HostSelector webhook Handle",
+		func(tc testCaseHostSelectorWebhook) {
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(ssdHost).Build()
+			w := &HostSelectorWebhook{
+				Client:  fakeClient,
+				decoder: admission.NewDecoder(setupScheme()),
+			}
+
+			var obj runtime.Object = &infrav1.Metal3Machine{
+				ObjectMeta: metav1.ObjectMeta{Name: "m3m", Namespace: namespaceName},
+				Spec:       tc.Spec,
+			}
+			kind := "Metal3Machine"
+			if tc.Template {
+				obj = &infrav1.Metal3MachineTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "m3mt", Namespace: namespaceName},
+					Spec: infrav1.Metal3MachineTemplateSpec{
+						Template: infrav1.Metal3MachineTemplateResource{Spec: tc.Spec},
+					},
+				}
+				kind = "Metal3MachineTemplate"
+			}
+			raw, err := json.Marshal(obj)
+			Expect(err).NotTo(HaveOccurred())
+
+			response := w.Handle(context.TODO(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Kind: metav1.GroupVersionKind{
+						Group:   infrav1.GroupVersion.Group,
+						Version: infrav1.GroupVersion.Version,
+						Kind:    kind,
+					},
+					Namespace: namespaceName,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+
+			Expect(response.Allowed).To(BeTrue())
+			if tc.ExpectedWarning {
+				Expect(response.Warnings).To(ConsistOf(ContainSubstring("no BareMetalHost")))
+			} else {
+				Expect(response.Warnings).To(BeEmpty())
+			}
+		},
+		Entry("No host selector", testCaseHostSelectorWebhook{
+			Spec: newSpec(infrav1.HostSelector{}),
+		}),
+		Entry("Host selector matching a host", testCaseHostSelectorWebhook{
+			Spec: newSpec(infrav1.HostSelector{MatchLabels: map[string]string{"disk": "ssd"}}),
+		}),
+		Entry("Host selector matching no host", testCaseHostSelectorWebhook{
+			Spec:            newSpec(infrav1.HostSelector{MatchLabels: map[string]string{"disk": "sdd"}}),
+			ExpectedWarning: true,
+		}),
+		Entry("Host selector expression matching no host", testCaseHostSelectorWebhook{
+			Spec: newSpec(infrav1.HostSelector{MatchExpressions: []infrav1.HostSelectorRequirement{
+				{Key: "disk", Operator: "in", Values: []string{"hdd", "nvme"}},
+			}}),
+			ExpectedWarning: true,
+		}),
+		Entry("Fallback host selector matching a host", testCaseHostSelectorWebhook{
+			Spec: newSpec(infrav1.HostSelector{MatchLabels: map[string]string{"disk": "hdd"}},
+				infrav1.HostSelector{MatchLabels: map[string]string{"disk": "ssd"}},
+			),
+		}),
+		Entry("Template host selector matching a host", testCaseHostSelectorWebhook{
+			Template: true,
+			Spec:     newSpec(infrav1.HostSelector{MatchLabels: map[string]string{"disk": "ssd"}}),
+		}),
+		Entry("Template host selector matching no host", testCaseHostSelectorWebhook{
+			Template:        true,
+			Spec:            newSpec(infrav1.HostSelector{MatchLabels: map[string]string{"disk": "sdd"}}),
+			ExpectedWarning: true,
+		}),
+	)
+})
@@ -22,10 +22,12 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
 	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/clustercache"
@@ -63,6 +65,10 @@ type Metal3ClusterReconciler struct {
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3clusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3clusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machinedeployments;machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=kubeadmcontrolplanes,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3remediations,verbs=get;list;watch
 
 // Reconcile reads that state of the cluster for a Metal3Cluster object and makes changes based on the state read
 // and what is in the Metal3Cluster.Spec.
@@ -176,6 +182,7 @@ func patchMetal3Cluster(ctx context.Context, patchHelper *patch.Helper, metal3Cl
 		patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
 			clusterv1.ReadyCondition,
 			infrav1.BaremetalInfrastructureReadyCondition,
+			infrav1.HostsAvailableCondition,
 		}},
 		patch.WithStatusObservedGeneration{},
 	)
@@ -196,6 +203,16 @@ func reconcileNormal(ctx context.Context, clusterMgr baremetal.ClusterManagerInt
 		return ctrl.Result{}, errors.Wrap(err, "failed to get ip for the API endpoint")
 	}
 
+	// Report whether there are enough hosts for the cluster
+	if err := clusterMgr.UpdateHostsAvailability(ctx); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to check the availability of hosts")
+	}
+
+	// Report the number of Machines of the cluster being remediated
+	if err := clusterMgr.UpdateActiveRemediations(ctx); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to count the active remediations")
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -269,7 +286,78 @@ func (r *Metal3ClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl
 			// predicates.ClusterUnpaused will handle cluster unpaused logic
 			builder.WithPredicates(predicates.ClusterUnpaused(mgr.GetScheme(), ctrl.LoggerFrom(ctx))),
 		).
+		// The HostsAvailable condition depends on the hosts and on the
+		// replicas of the MachineDeployments of the cluster.
+		Watches(
+			&bmov1alpha1.BareMetalHost{},
+			handler.EnqueueRequestsFromMapFunc(r.BareMetalHostToMetal3Clusters),
+		).
+		Watches(
+			&clusterv1.MachineDeployment{},
+			handler.EnqueueRequestsFromMapFunc(r.MachineDeploymentToMetal3Cluster),
+		).
 		WithEventFilter(predicates.ResourceIsNotExternallyManaged(mgr.GetScheme(), mgr.GetLogger())).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(mgr.GetScheme(), ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
 		Complete(r)
 }
+
+// BareMetalHostToMetal3Clusters will return reconcile requests for all the
+// Metal3Clusters in the namespace of the BareMetalHost.
+func (r *Metal3ClusterReconciler) BareMetalHostToMetal3Clusters(ctx context.Context, obj client.Object) []ctrl.Request {
+	host, ok := obj.(*bmov1alpha1.BareMetalHost)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a BareMetalHost but got a %T", obj),
+			"failed to get Metal3Clusters for BareMetalHost",
+		)
+		return nil
+	}
+
+	metal3Clusters := &infrav1.Metal3ClusterList{}
+	if err := r.Client.List(ctx, metal3Clusters, client.InNamespace(host.Namespace)); err != nil {
+		r.Log.Error(err, "failed to list Metal3Clusters")
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	for _, metal3Cluster := range metal3Clusters.Items {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{
+				Name:      metal3Cluster.Name,
+				Namespace: metal3Cluster.Namespace,
+			},
+		})
+	}
+	return requests
+}
+
+// MachineDeploymentToMetal3Cluster will return a reconcile request for the
+// Metal3Cluster of the cluster the MachineDeployment belongs to.
+func (r *Metal3ClusterReconciler) MachineDeploymentToMetal3Cluster(ctx context.Context, obj client.Object) []ctrl.Request {
+	machineDeployment, ok := obj.(*clusterv1.MachineDeployment)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a MachineDeployment but got a %T", obj),
+			"failed to get Metal3Cluster for MachineDeployment",
+		)
+		return nil
+	}
+
+	cluster, err := util.GetClusterByName(ctx, r.Client, machineDeployment.Namespace,
+		machineDeployment.Spec.ClusterName,
+	)
+	if err != nil {
+		r.Log.V(4).Error(err, "failed to get Cluster of MachineDeployment")
+		return nil
+	}
+	ref := cluster.Spec.InfrastructureRef
+	if ref == nil || ref.Kind != "Metal3Cluster" || ref.GroupVersionKind().Group != infrav1.GroupVersion.Group {
+		return nil
+	}
+	return []ctrl.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Name:      ref.Name,
+				Namespace: cluster.Namespace,
+			},
+		},
+	}
+}
@@ -17,11 +17,16 @@ package controllers
 import (
 	"context"
 
+	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
 	baremetal_mocks "github.com/metal3-io/cluster-api-provider-metal3/baremetal/mocks"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = Describe("Metal3Cluster controller", func() {
@@ -29,6 +34,7 @@ var _ = Describe("Metal3Cluster controller", func() {
 	type testCaseClusterNormal struct {
 		CreateError   bool
 		UpdateError   bool
+		HostsError    bool
 		ExpectError   bool
 		ExpectRequeue bool
 	}
@@ -61,11 +67,19 @@ var _ = Describe("Metal3Cluster controller", func() {
 			if tc.CreateError {
 				returnedError = errors.New("Error")
 				m.EXPECT().UpdateClusterStatus().MaxTimes(0)
+				m.EXPECT().UpdateHostsAvailability(context.TODO()).MaxTimes(0)
 			} else {
 				if tc.UpdateError {
 					returnedError = errors.New("Error")
+					m.EXPECT().UpdateHostsAvailability(context.TODO()).MaxTimes(0)
 				} else {
 					returnedError = nil
+					if tc.HostsError {
+						m.EXPECT().UpdateHostsAvailability(context.TODO()).Return(errors.New("Error"))
+					} else {
+						m.EXPECT().UpdateHostsAvailability(context.TODO()).Return(nil)
+						m.EXPECT().UpdateActiveRemediations(context.TODO()).Return(nil)
+					}
 				}
 				m.EXPECT().UpdateClusterStatus().Return(returnedError)
 				returnedError = nil
@@ -104,6 +118,13 @@ var _ = Describe("Metal3Cluster controller", func() {
 			ExpectError:   true,
 			ExpectRequeue: false,
 		}),
+		Entry("Hosts availability error", testCaseClusterNormal{
+			CreateError:   false,
+			UpdateError:   false,
+			HostsError:    true,
+			ExpectError:   true,
+			ExpectRequeue: false,
+		}),
 	)
 
 	DescribeTable("Test ClusterReconcileDelete",
@@ -181,4 +202,73 @@ var _ = Describe("Metal3Cluster controller", func() {
 			ExpectRequeue:    false,
 		}),
 	)
+
+	It("Test BareMetalHostToMetal3Clusters", func() {
+		otherCluster := newMetal3Cluster("other-cluster", nil, nil, nil, nil, false)
+		otherCluster.Namespace = "other-namespace"
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(
+			newMetal3Cluster(metal3ClusterName, bmcOwnerRef(), bmcSpec(), nil, nil, false),
+			otherCluster,
+		).Build()
+		r := &Metal3ClusterReconciler{
+			Client: fakeClient,
+			Log:    logr.Discard(),
+		}
+
+		requests := r.BareMetalHostToMetal3Clusters(context.TODO(),
+			newBareMetalHost("host", nil, nil, nil, false),
+		)
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Name).To(Equal(metal3ClusterName))
+		Expect(requests[0].Namespace).To(Equal(namespaceName))
+	})
+
+	type machineDeploymentToMetal3ClusterTestCase struct {
+		ClusterSpec     *clusterv1.ClusterSpec
+		ExpectedRequest bool
+	}
+
+	DescribeTable("Test MachineDeploymentToMetal3Cluster",
+		func(tc machineDeploymentToMetal3ClusterTestCase) {
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(
+				newCluster(clusterName, tc.ClusterSpec, nil),
+			).Build()
+			r := &Metal3ClusterReconciler{
+				Client: fakeClient,
+				Log:    logr.Discard(),
+			}
+			machineDeployment := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "md",
+					Namespace: namespaceName,
+				},
+				Spec: clusterv1.MachineDeploymentSpec{
+					ClusterName: clusterName,
+				},
+			}
+
+			requests := r.MachineDeploymentToMetal3Cluster(context.TODO(), machineDeployment)
+			if tc.ExpectedRequest {
+				Expect(requests).To(HaveLen(1))
+				Expect(requests[0].Name).To(Equal(metal3ClusterName))
+				Expect(requests[0].Namespace).To(Equal(namespaceName))
+			} else {
+				Expect(requests).To(BeEmpty())
+			}
+		},
+		Entry("Metal3Cluster infrastructure", machineDeploymentToMetal3ClusterTestCase{
+			ExpectedRequest: true,
+		}),
+		Entry("Other infrastructure", machineDeploymentToMetal3ClusterTestCase{
+			ClusterSpec: &clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{
+					Name:       "other",
+					Namespace:  namespaceName,
+					Kind:       "OtherCluster",
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1",
+				},
+			},
+			ExpectedRequest: false,
+		}),
+	)
 })
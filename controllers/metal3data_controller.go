@@ -20,10 +20,12 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
 	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
 	ipamv1 "github.com/metal3-io/ip-address-manager/api/v1alpha1"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -41,6 +43,11 @@ import (
 
 const (
 	dataControllerName = "Metal3Data-controller"
+	// dataTemplateSourceIndex indexes the Metal3DataTemplates by the Secrets
+	// and ConfigMaps their metadata is rendered from.
+	dataTemplateSourceIndex = "metal3DataTemplateMetaDataSource"
+	// dataTemplateIndex indexes the Metal3Datas by their Metal3DataTemplate.
+	dataTemplateIndex = "metal3DataTemplate"
 )
 
 // Metal3DataReconciler reconciles a Metal3Data object.
@@ -56,6 +63,7 @@ type Metal3DataReconciler struct {
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3datas/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
 
 // Reconcile handles Metal3Data events.
 func (r *Metal3DataReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
@@ -146,7 +154,12 @@ func (r *Metal3DataReconciler) reconcileNormal(ctx context.Context,
 func (r *Metal3DataReconciler) reconcileDelete(ctx context.Context,
 	metadataMgr baremetal.DataManagerInterface,
 ) (ctrl.Result, error) {
-	err := metadataMgr.ReleaseLeases(ctx)
+	err := metadataMgr.ReleaseSecrets(ctx)
+	if err != nil {
+		return checkReconcileError(err, "Failed to release the rendered secrets")
+	}
+
+	err = metadataMgr.ReleaseLeases(ctx)
 	if err != nil {
 		return checkReconcileError(err, "Failed to release IP address leases")
 	}
@@ -158,17 +171,121 @@ func (r *Metal3DataReconciler) reconcileDelete(ctx context.Context,
 
 // SetupWithManager will add watches for this controller.
 func (r *Metal3DataReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&infrav1.Metal3Data{}).
 		WithOptions(options).
 		Watches(
 			&ipamv1.IPClaim{},
 			handler.EnqueueRequestsFromMapFunc(r.Metal3IPClaimToMetal3Data),
 		).
+		Watches(
+			&bmov1alpha1.BareMetalHost{},
+			handler.EnqueueRequestsFromMapFunc(r.BareMetalHostToMetal3Data),
+		)
+
+	// Re-render the Metal3Datas when the Secrets and ConfigMaps their metadata
+	// is rendered from change. The sources are indexed to map them to the
+	// Metal3Datas without listing all the Metal3DataTemplates and Metal3Datas.
+	if baremetal.RerenderMetaDataOnSourceChange {
+		indexer := mgr.GetFieldIndexer()
+		if err := indexer.IndexField(ctx, &infrav1.Metal3DataTemplate{}, dataTemplateSourceIndex, indexDataTemplateBySource); err != nil {
+			return errors.Wrap(err, "failed to index Metal3DataTemplates by metadata source")
+		}
+		if err := indexer.IndexField(ctx, &infrav1.Metal3Data{}, dataTemplateIndex, indexDataByTemplate); err != nil {
+			return errors.Wrap(err, "failed to index Metal3Datas by Metal3DataTemplate")
+		}
+		b = b.Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.SecretToMetal3Data),
+		).Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.ConfigMapToMetal3Data),
+		)
+	}
+
+	return b.
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(mgr.GetScheme(), ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
 		Complete(r)
 }
 
+// indexDataTemplateBySource returns the keys of the Secrets and ConfigMaps the
+// metadata of a Metal3DataTemplate is rendered from.
+func indexDataTemplateBySource(obj client.Object) []string {
+	m3dt, ok := obj.(*infrav1.Metal3DataTemplate)
+	if !ok || m3dt.Spec.MetaData == nil {
+		return nil
+	}
+	keys := []string{}
+	for _, entry := range m3dt.Spec.MetaData.FromSecrets {
+		keys = append(keys, baremetal.MetaDataSourceKey(baremetal.MetaDataSourceSecret, entry.Name))
+	}
+	for _, entry := range m3dt.Spec.MetaData.FromConfigMaps {
+		keys = append(keys, baremetal.MetaDataSourceKey(baremetal.MetaDataSourceConfigMap, entry.Name))
+	}
+	for _, entry := range m3dt.Spec.MetaData.NTPServers {
+		if entry.FromConfigMap != nil {
+			keys = append(keys, baremetal.MetaDataSourceKey(baremetal.MetaDataSourceConfigMap, entry.FromConfigMap.Name))
+		}
+	}
+	return keys
+}
+
+// indexDataByTemplate returns the namespaced name of the Metal3DataTemplate of
+// a Metal3Data.
+func indexDataByTemplate(obj client.Object) []string {
+	m3d, ok := obj.(*infrav1.Metal3Data)
+	if !ok || m3d.Spec.Template.Name == "" {
+		return nil
+	}
+	namespace := m3d.Spec.Template.Namespace
+	if namespace == "" {
+		namespace = m3d.Namespace
+	}
+	return []string{types.NamespacedName{Namespace: namespace, Name: m3d.Spec.Template.Name}.String()}
+}
+
+// SecretToMetal3Data will return a reconcile request for each Metal3Data whose
+// metadata is rendered from the Secret.
+func (r *Metal3DataReconciler) SecretToMetal3Data(ctx context.Context, obj client.Object) []ctrl.Request {
+	return r.metaDataSourceToMetal3Data(ctx, baremetal.MetaDataSourceSecret, obj)
+}
+
+// ConfigMapToMetal3Data will return a reconcile request for each Metal3Data
+// whose metadata is rendered from the ConfigMap.
+func (r *Metal3DataReconciler) ConfigMapToMetal3Data(ctx context.Context, obj client.Object) []ctrl.Request {
+	return r.metaDataSourceToMetal3Data(ctx, baremetal.MetaDataSourceConfigMap, obj)
+}
+
+func (r *Metal3DataReconciler) metaDataSourceToMetal3Data(ctx context.Context, kind string,
+	obj client.Object,
+) []ctrl.Request {
+	requests := []ctrl.Request{}
+	m3dts := &infrav1.Metal3DataTemplateList{}
+	err := r.Client.List(ctx, m3dts, client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{dataTemplateSourceIndex: baremetal.MetaDataSourceKey(kind, obj.GetName())},
+	)
+	if err != nil {
+		r.Log.Error(err, "failed to list Metal3DataTemplates", "kind", kind, "name", obj.GetName())
+		return requests
+	}
+	for _, m3dt := range m3dts.Items {
+		m3ds := &infrav1.Metal3DataList{}
+		err := r.Client.List(ctx, m3ds,
+			client.MatchingFields{dataTemplateIndex: client.ObjectKeyFromObject(&m3dt).String()},
+		)
+		if err != nil {
+			r.Log.Error(err, "failed to list Metal3Datas", "metal3datatemplate", m3dt.Name)
+			continue
+		}
+		for _, m3d := range m3ds.Items {
+			requests = append(requests, ctrl.Request{
+				NamespacedName: client.ObjectKeyFromObject(&m3d),
+			})
+		}
+	}
+	return requests
+}
+
 // Metal3IPClaimToMetal3Data will return a reconcile request for a Metal3Data if the event is for a
 // Metal3IPClaim and that Metal3IPClaim references a Metal3Data.
 func (r *Metal3DataReconciler) Metal3IPClaimToMetal3Data(_ context.Context, obj client.Object) []ctrl.Request {
@@ -196,3 +313,47 @@ func (r *Metal3DataReconciler) Metal3IPClaimToMetal3Data(_ context.Context, obj
 	}
 	return requests
 }
+
+// BareMetalHostToMetal3Data will return a reconcile request for the Metal3Datas
+// owning the secrets of a provisioned BareMetalHost, to release the secrets.
+func (r *Metal3DataReconciler) BareMetalHostToMetal3Data(ctx context.Context, obj client.Object) []ctrl.Request {
+	requests := []ctrl.Request{}
+	host, ok := obj.(*bmov1alpha1.BareMetalHost)
+	if !ok {
+		return requests
+	}
+	if host.Status.Provisioning.State != bmov1alpha1.StateProvisioned &&
+		host.Status.Provisioning.State != bmov1alpha1.StateExternallyProvisioned {
+		return requests
+	}
+	for _, ref := range []*corev1.SecretReference{host.Spec.UserData, host.Spec.MetaData, host.Spec.NetworkData} {
+		if ref == nil || ref.Name == "" {
+			continue
+		}
+		secret := &corev1.Secret{}
+		key := client.ObjectKey{Name: ref.Name, Namespace: host.Namespace}
+		if err := r.Client.Get(ctx, key, secret); err != nil {
+			continue
+		}
+		for _, ownerRef := range secret.OwnerReferences {
+			if ownerRef.Kind != "Metal3Data" {
+				continue
+			}
+			aGV, err := schema.ParseGroupVersion(ownerRef.APIVersion)
+			if err != nil {
+				r.Log.Error(err, "failed to parse the API version")
+				continue
+			}
+			if aGV.Group != infrav1.GroupVersion.Group {
+				continue
+			}
+			requests = append(requests, ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      ownerRef.Name,
+					Namespace: host.Namespace,
+				},
+			})
+		}
+	}
+	return requests
+}
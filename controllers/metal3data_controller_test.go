@@ -21,6 +21,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
 	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
 	baremetal_mocks "github.com/metal3-io/cluster-api-provider-metal3/baremetal/mocks"
@@ -28,6 +29,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -42,16 +44,17 @@ var _ = Describe("Metal3Data manager", func() {
 	Describe("Test Data Reconcile functions", func() {
 
 		type testCaseReconcile struct {
-			expectError          bool
-			expectRequeue        bool
-			expectManager        bool
-			m3d                  *infrav1.Metal3Data
-			cluster              *clusterv1.Cluster
-			managerError         bool
-			reconcileNormal      bool
-			reconcileNormalError bool
-			releaseLeasesRequeue bool
-			releaseLeasesError   bool
+			expectError           bool
+			expectRequeue         bool
+			expectManager         bool
+			m3d                   *infrav1.Metal3Data
+			cluster               *clusterv1.Cluster
+			managerError          bool
+			reconcileNormal       bool
+			reconcileNormalError  bool
+			releaseLeasesRequeue  bool
+			releaseLeasesError    bool
+			releaseSecretsRequeue bool
 		}
 
 		DescribeTable("Test Reconcile",
@@ -77,13 +80,18 @@ var _ = Describe("Metal3Data manager", func() {
 					mf.EXPECT().NewDataManager(gomock.Any(), gomock.Any()).MaxTimes(0)
 				}
 				if tc.m3d != nil && !tc.m3d.DeletionTimestamp.IsZero() {
-					if tc.releaseLeasesRequeue {
-						m.EXPECT().ReleaseLeases(context.Background()).Return(baremetal.WithTransientError(errors.New(""), requeueAfter))
-					} else if tc.releaseLeasesError {
-						m.EXPECT().ReleaseLeases(context.Background()).Return(errors.New(""))
+					if tc.releaseSecretsRequeue {
+						m.EXPECT().ReleaseSecrets(context.Background()).Return(baremetal.WithTransientError(errors.New(""), requeueAfter))
 					} else {
-						m.EXPECT().ReleaseLeases(context.Background()).Return(nil)
-						m.EXPECT().UnsetFinalizer()
+						m.EXPECT().ReleaseSecrets(context.Background()).Return(nil)
+						if tc.releaseLeasesRequeue {
+							m.EXPECT().ReleaseLeases(context.Background()).Return(baremetal.WithTransientError(errors.New(""), requeueAfter))
+						} else if tc.releaseLeasesError {
+							m.EXPECT().ReleaseLeases(context.Background()).Return(errors.New(""))
+						} else {
+							m.EXPECT().ReleaseLeases(context.Background()).Return(nil)
+							m.EXPECT().UnsetFinalizer()
+						}
 					}
 				}
 
@@ -175,6 +183,22 @@ var _ = Describe("Metal3Data manager", func() {
 				expectRequeue:        true,
 				releaseLeasesRequeue: true,
 			}),
+			Entry("Deletion, secrets release requeue", testCaseReconcile{
+				m3d: &infrav1.Metal3Data{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      metal3DataName,
+						Namespace: namespaceName,
+						Labels: map[string]string{
+							clusterv1.ClusterNameLabel: "abc",
+						},
+						DeletionTimestamp: &timestampNow,
+						Finalizers:        []string{"foo"},
+					},
+				},
+				expectManager:         true,
+				expectRequeue:         true,
+				releaseSecretsRequeue: true,
+			}),
 			Entry("Deletion, release error", testCaseReconcile{
 				m3d: &infrav1.Metal3Data{
 					ObjectMeta: metav1.ObjectMeta{
@@ -334,10 +358,11 @@ var _ = Describe("Metal3Data manager", func() {
 	})
 
 	type reconcileDeleteTestCase struct {
-		ExpectError          bool
-		ExpectRequeue        bool
-		ReleaseLeasesRequeue bool
-		ReleaseLeasesError   bool
+		ExpectError           bool
+		ExpectRequeue         bool
+		ReleaseLeasesRequeue  bool
+		ReleaseLeasesError    bool
+		ReleaseSecretsRequeue bool
 	}
 
 	DescribeTable("ReconcileDelete tests",
@@ -354,13 +379,18 @@ var _ = Describe("Metal3Data manager", func() {
 			}
 			m := baremetal_mocks.NewMockDataManagerInterface(gomockCtrl)
 
-			if tc.ReleaseLeasesRequeue {
-				m.EXPECT().ReleaseLeases(context.TODO()).Return(baremetal.WithTransientError(errors.New(""), requeueAfter))
-			} else if tc.ReleaseLeasesError {
-				m.EXPECT().ReleaseLeases(context.TODO()).Return(errors.New(""))
+			if tc.ReleaseSecretsRequeue {
+				m.EXPECT().ReleaseSecrets(context.TODO()).Return(baremetal.WithTransientError(errors.New(""), requeueAfter))
 			} else {
-				m.EXPECT().ReleaseLeases(context.TODO()).Return(nil)
-				m.EXPECT().UnsetFinalizer()
+				m.EXPECT().ReleaseSecrets(context.TODO()).Return(nil)
+				if tc.ReleaseLeasesRequeue {
+					m.EXPECT().ReleaseLeases(context.TODO()).Return(baremetal.WithTransientError(errors.New(""), requeueAfter))
+				} else if tc.ReleaseLeasesError {
+					m.EXPECT().ReleaseLeases(context.TODO()).Return(errors.New(""))
+				} else {
+					m.EXPECT().ReleaseLeases(context.TODO()).Return(nil)
+					m.EXPECT().UnsetFinalizer()
+				}
 			}
 
 			res, err := dataReconcile.reconcileDelete(context.TODO(), m)
@@ -391,6 +421,11 @@ var _ = Describe("Metal3Data manager", func() {
 			ExpectRequeue:        true,
 			ReleaseLeasesRequeue: true,
 		}),
+		Entry("Secrets are not released", reconcileDeleteTestCase{
+			ExpectError:           false,
+			ExpectRequeue:         true,
+			ReleaseSecretsRequeue: true,
+		}),
 	)
 
 	type testCaseMetal3IPClaimToMetal3Data struct {
@@ -446,4 +481,211 @@ var _ = Describe("Metal3Data manager", func() {
 		}),
 	)
 
+	type testCaseBareMetalHostToMetal3Data struct {
+		state            bmov1alpha1.ProvisioningState
+		ownerRefs        []metav1.OwnerReference
+		expectedRequests []ctrl.Request
+	}
+
+	DescribeTable("test BareMetalHostToMetal3Data",
+		func(tc testCaseBareMetalHostToMetal3Data) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "networkdata",
+					Namespace:       namespaceName,
+					OwnerReferences: tc.ownerRefs,
+				},
+			}
+			host := &bmov1alpha1.BareMetalHost{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "host",
+					Namespace: namespaceName,
+				},
+				Spec: bmov1alpha1.BareMetalHostSpec{
+					NetworkData: &corev1.SecretReference{Name: secret.Name},
+					UserData:    &corev1.SecretReference{Name: "missing"},
+				},
+				Status: bmov1alpha1.BareMetalHostStatus{
+					Provisioning: bmov1alpha1.ProvisionStatus{State: tc.state},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(secret).Build()
+			m3DataReconciler := Metal3DataReconciler{
+				Client: fakeClient,
+			}
+			reqs := m3DataReconciler.BareMetalHostToMetal3Data(context.Background(), host)
+			Expect(reqs).To(Equal(tc.expectedRequests))
+		},
+		Entry("Host provisioning", testCaseBareMetalHostToMetal3Data{
+			state: bmov1alpha1.StateProvisioning,
+			ownerRefs: []metav1.OwnerReference{
+				{
+					APIVersion: infrav1.GroupVersion.String(),
+					Kind:       "Metal3Data",
+					Name:       "abc",
+				},
+			},
+			expectedRequests: []ctrl.Request{},
+		}),
+		Entry("Host provisioned", testCaseBareMetalHostToMetal3Data{
+			state: bmov1alpha1.StateProvisioned,
+			ownerRefs: []metav1.OwnerReference{
+				{
+					APIVersion: infrav1.GroupVersion.String(),
+					Kind:       "Metal3Data",
+					Name:       "abc",
+				},
+				{
+					APIVersion: "foo.bar/v1",
+					Kind:       "Metal3Data",
+					Name:       "cde",
+				},
+			},
+			expectedRequests: []ctrl.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "abc",
+						Namespace: namespaceName,
+					},
+				},
+			},
+		}),
+	)
+
+	type testCaseMetaDataSourceToMetal3Data struct {
+		source           client.Object
+		expectedRequests []ctrl.Request
+	}
+
+	DescribeTable("test metadata source to Metal3Data mapping",
+		func(tc testCaseMetaDataSourceToMetal3Data) {
+			m3dts := []client.Object{
+				&infrav1.Metal3DataTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-secret", Namespace: namespaceName},
+					Spec: infrav1.Metal3DataTemplateSpec{
+						MetaData: &infrav1.MetaData{
+							FromSecrets: []infrav1.MetaDataFromSource{
+								{Key: "token", Name: "credentials", DataKey: "token"},
+							},
+						},
+					},
+				},
+				&infrav1.Metal3DataTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-configmap", Namespace: namespaceName},
+					Spec: infrav1.Metal3DataTemplateSpec{
+						MetaData: &infrav1.MetaData{
+							FromConfigMaps: []infrav1.MetaDataFromSource{
+								{Key: "token", Name: "credentials", DataKey: "token"},
+							},
+						},
+					},
+				},
+				&infrav1.Metal3DataTemplate{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-ntp-servers", Namespace: namespaceName},
+					Spec: infrav1.Metal3DataTemplateSpec{
+						MetaData: &infrav1.MetaData{
+							NTPServers: []infrav1.MetaDataNTPServers{
+								{Key: "ntp", FromConfigMap: &infrav1.NTPServersFromConfigMap{Name: "site", Key: "ntp"}},
+							},
+						},
+					},
+				},
+			}
+			m3ds := []client.Object{
+				&infrav1.Metal3Data{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-secret-0", Namespace: namespaceName},
+					Spec: infrav1.Metal3DataSpec{
+						Template: corev1.ObjectReference{Name: "with-secret"},
+					},
+				},
+				&infrav1.Metal3Data{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-configmap-0", Namespace: namespaceName},
+					Spec: infrav1.Metal3DataSpec{
+						Template: corev1.ObjectReference{Name: "with-configmap", Namespace: namespaceName},
+					},
+				},
+				&infrav1.Metal3Data{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-ntp-servers-0", Namespace: namespaceName},
+					Spec: infrav1.Metal3DataSpec{
+						Template: corev1.ObjectReference{Name: "with-ntp-servers"},
+					},
+				},
+				&infrav1.Metal3Data{
+					ObjectMeta: metav1.ObjectMeta{Name: "with-secret-0", Namespace: "other"},
+					Spec: infrav1.Metal3DataSpec{
+						Template: corev1.ObjectReference{Name: "with-secret"},
+					},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).
+				WithObjects(append(m3dts, m3ds...)...).
+				WithIndex(&infrav1.Metal3DataTemplate{}, dataTemplateSourceIndex, indexDataTemplateBySource).
+				WithIndex(&infrav1.Metal3Data{}, dataTemplateIndex, indexDataByTemplate).
+				Build()
+			m3DataReconciler := Metal3DataReconciler{
+				Client: fakeClient,
+				Log:    logr.Discard(),
+			}
+			var reqs []ctrl.Request
+			switch tc.source.(type) {
+			case *corev1.Secret:
+				reqs = m3DataReconciler.SecretToMetal3Data(context.Background(), tc.source)
+			case *corev1.ConfigMap:
+				reqs = m3DataReconciler.ConfigMapToMetal3Data(context.Background(), tc.source)
+			}
+			Expect(reqs).To(Equal(tc.expectedRequests))
+		},
+		Entry("Secret change enqueues the dependent Metal3Data", testCaseMetaDataSourceToMetal3Data{
+			source: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: namespaceName},
+			},
+			expectedRequests: []ctrl.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "with-secret-0",
+						Namespace: namespaceName,
+					},
+				},
+			},
+		}),
+		Entry("ConfigMap change enqueues the dependent Metal3Data", testCaseMetaDataSourceToMetal3Data{
+			source: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: namespaceName},
+			},
+			expectedRequests: []ctrl.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "with-configmap-0",
+						Namespace: namespaceName,
+					},
+				},
+			},
+		}),
+		Entry("NTP servers ConfigMap change enqueues the dependent Metal3Data", testCaseMetaDataSourceToMetal3Data{
+			source: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: namespaceName},
+			},
+			expectedRequests: []ctrl.Request{
+				{
+					NamespacedName: types.NamespacedName{
+						Name:      "with-ntp-servers-0",
+						Namespace: namespaceName,
+					},
+				},
+			},
+		}),
+		Entry("Unreferenced Secret", testCaseMetaDataSourceToMetal3Data{
+			source: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespaceName},
+			},
+			expectedRequests: []ctrl.Request{},
+		}),
+		Entry("Secret in another namespace", testCaseMetaDataSourceToMetal3Data{
+			source: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "other"},
+			},
+			expectedRequests: []ctrl.Request{},
+		}),
+	)
+
 })
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+const hostInventoryControllerName = "metal3-host-inventory-controller"
+
+// Metal3HostInventoryReconciler removes stale unhealthy annotations from the
+// BareMetalHosts. A host keeps the annotation after its node recovered out of
+// band, without a Metal3Remediation clearing it.
+type Metal3HostInventoryReconciler struct {
+	Client           client.Client
+	Log              logr.Logger
+	CapiClientGetter baremetal.ClientGetter
+	WatchFilterValue string
+}
+
+// +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3remediations,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;clusters,verbs=get;list;watch
+
+// Reconcile removes the unhealthy annotation of a BareMetalHost whose node has
+// been Ready for UnhealthyAnnotationGracePeriod and whose Machine is not being
+// remediated.
+func (r *Metal3HostInventoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithName(hostInventoryControllerName).WithValues("baremetalhost", req.NamespacedName)
+
+	host := &bmov1alpha1.BareMetalHost{}
+	if err := r.Client.Get(ctx, req.NamespacedName, host); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if _, ok := host.Annotations[infrav1.UnhealthyAnnotation]; !ok {
+		return ctrl.Result{}, nil
+	}
+	if _, ok := host.Annotations[bmov1alpha1.PausedAnnotation]; ok {
+		log.Info("BaremetalHost is currently paused. Remove pause to continue reconciliation.")
+		return ctrl.Result{RequeueAfter: bmhSyncInterval}, nil
+	}
+
+	// Only the hosts consumed by a Metal3Machine have a node to check.
+	consumer := host.Spec.ConsumerRef
+	if consumer == nil || consumer.Kind != Metal3Machine {
+		return ctrl.Result{}, nil
+	}
+	capm3Machine := &infrav1.Metal3Machine{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: consumer.Name, Namespace: consumer.Namespace}, capm3Machine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	capiMachine, err := util.GetOwnerMachine(ctx, r.Client, capm3Machine.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "Metal3Machine's owner Machine could not be retrieved")
+	}
+	if capiMachine == nil || capiMachine.Status.NodeRef == nil {
+		return ctrl.Result{}, nil
+	}
+
+	remediated, err := r.isRemediated(ctx, host, capiMachine)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if remediated {
+		// The remediation controller clears the annotation itself.
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := util.GetClusterFromMetadata(ctx, r.Client, capiMachine.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to get the Cluster of the Machine")
+	}
+	clusterClient, err := r.CapiClientGetter(ctx, r.Client, cluster)
+	if err != nil {
+		log.Info("Unable to reach the workload cluster, will retry", "error", err.Error())
+		return ctrl.Result{RequeueAfter: bmhSyncInterval}, nil
+	}
+	node, err := clusterClient.Nodes().Get(ctx, capiMachine.Status.NodeRef.Name, metav1.GetOptions{})
+	if err != nil {
+		log.Info("Unable to get the node, will retry", "error", err.Error())
+		return ctrl.Result{RequeueAfter: bmhSyncInterval}, nil
+	}
+
+	readyFor := nodeReadyDuration(node, time.Now())
+	if readyFor < 0 {
+		return ctrl.Result{RequeueAfter: bmhSyncInterval}, nil
+	}
+	if readyFor < baremetal.UnhealthyAnnotationGracePeriod {
+		return ctrl.Result{RequeueAfter: baremetal.UnhealthyAnnotationGracePeriod - readyFor}, nil
+	}
+
+	helper, err := patch.NewHelper(host, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to init patch helper")
+	}
+	log.Info("Removing stale Unhealthy annotation from host", "node", node.Name)
+	delete(host.Annotations, infrav1.UnhealthyAnnotation)
+	if baremetal.ReadOnly {
+		log.Info("Read-only mode, skipping the write", "verb", "patch", "kind", "BareMetalHost",
+			"namespace", host.Namespace, "name", host.Name)
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, helper.Patch(ctx, host)
+}
+
+// isRemediated returns true if an active Metal3Remediation targets the Machine
+// or the host.
+func (r *Metal3HostInventoryReconciler) isRemediated(ctx context.Context,
+	host *bmov1alpha1.BareMetalHost, machine *clusterv1.Machine,
+) (bool, error) {
+	remediations := &infrav1.Metal3RemediationList{}
+	if err := r.Client.List(ctx, remediations, client.InNamespace(machine.Namespace)); err != nil {
+		return false, errors.Wrap(err, "failed to list Metal3Remediations")
+	}
+	for _, remediation := range remediations.Items {
+		if !baremetal.IsActiveRemediation(&remediation) {
+			continue
+		}
+		if remediation.Spec.HostRef != nil && remediation.Spec.HostRef.Name == host.Name {
+			return true, nil
+		}
+		for _, ref := range remediation.OwnerReferences {
+			if ref.Kind == "Machine" && ref.Name == machine.Name {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// nodeReadyDuration returns for how long the node has been Ready, or a
+// negative duration if it is not Ready.
+func nodeReadyDuration(node *corev1.Node, now time.Time) time.Duration {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != corev1.NodeReady {
+			continue
+		}
+		if condition.Status != corev1.ConditionTrue {
+			return -1
+		}
+		return now.Sub(condition.LastTransitionTime.Time)
+	}
+	return -1
+}
+
+// SetupWithManager will add watches for this controller.
+func (r *Metal3HostInventoryReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(hostInventoryControllerName).
+		For(&bmov1alpha1.BareMetalHost{}).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(mgr.GetScheme(), ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Complete(r)
+}
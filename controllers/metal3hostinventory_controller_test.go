@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientfake "k8s.io/client-go/kubernetes/fake"
+	clientcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Metal3HostInventory controller", func() {
+	nodeName := "testNode"
+
+	newUnhealthyHost := func(consumed bool) *bmov1alpha1.BareMetalHost {
+		spec := &bmov1alpha1.BareMetalHostSpec{}
+		if consumed {
+			spec.ConsumerRef = &corev1.ObjectReference{
+				Name:       metal3machineName,
+				Namespace:  namespaceName,
+				Kind:       "Metal3Machine",
+				APIVersion: infrav1.GroupVersion.String(),
+			}
+		}
+		host := newBareMetalHost(baremetalhostName, spec, nil, nil, false)
+		host.Annotations = map[string]string{infrav1.UnhealthyAnnotation: "capm3/unhealthy"}
+		return host
+	}
+
+	newNode := func(ready corev1.ConditionStatus, since time.Duration) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{
+					{
+						Type:               corev1.NodeReady,
+						Status:             ready,
+						LastTransitionTime: metav1.NewTime(time.Now().Add(-since)),
+					},
+				},
+			},
+		}
+	}
+
+	newRemediation := func(phase string) *infrav1.Metal3Remediation {
+		return &infrav1.Metal3Remediation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      machineName,
+				Namespace: namespaceName,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: clusterv1.GroupVersion.String(),
+						Kind:       "Machine",
+						Name:       machineName,
+					},
+				},
+			},
+			Status: infrav1.Metal3RemediationStatus{Phase: phase},
+		}
+	}
+
+	type testCaseHostInventoryReconcile struct {
+		Host                   *bmov1alpha1.BareMetalHost
+		Node                   *corev1.Node
+		Remediation            *infrav1.Metal3Remediation
+		ReadOnly               bool
+		ExpectRequeue          bool
+		ExpectUnhealthyCleared bool
+	}
+
+	DescribeTable("Test reconcile",
+		func(tc testCaseHostInventoryReconcile) {
+			defer func(gracePeriod time.Duration) {
+				baremetal.UnhealthyAnnotationGracePeriod = gracePeriod
+			}(baremetal.UnhealthyAnnotationGracePeriod)
+			baremetal.UnhealthyAnnotationGracePeriod = 5 * time.Minute
+			defer func(readOnly bool) { baremetal.ReadOnly = readOnly }(baremetal.ReadOnly)
+			baremetal.ReadOnly = tc.ReadOnly
+
+			objects := []client.Object{
+				tc.Host,
+				newMetal3Machine(metal3machineName, m3mObjectMetaWithOwnerRef(), nil, nil, false),
+				newMachine(clusterName, machineName, metal3machineName, nodeName),
+				newCluster(clusterName, nil, nil),
+			}
+			if tc.Remediation != nil {
+				objects = append(objects, tc.Remediation)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(objects...).Build()
+			nodes := clientfake.NewSimpleClientset()
+			if tc.Node != nil {
+				nodes = clientfake.NewSimpleClientset(tc.Node)
+			}
+			r := &Metal3HostInventoryReconciler{
+				Client: fakeClient,
+				Log:    logr.Discard(),
+				CapiClientGetter: func(_ context.Context, _ client.Client, _ *clusterv1.Cluster) (
+					clientcorev1.CoreV1Interface, error,
+				) {
+					return nodes.CoreV1(), nil
+				},
+			}
+
+			key := types.NamespacedName{Name: baremetalhostName, Namespace: namespaceName}
+			result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter > 0).To(Equal(tc.ExpectRequeue))
+
+			host := &bmov1alpha1.BareMetalHost{}
+			Expect(fakeClient.Get(context.TODO(), key, host)).To(Succeed())
+			if tc.ExpectUnhealthyCleared {
+				Expect(host.Annotations).NotTo(HaveKey(infrav1.UnhealthyAnnotation))
+			} else {
+				Expect(host.Annotations).To(HaveKey(infrav1.UnhealthyAnnotation))
+			}
+		},
+		Entry("Stale annotation of a host whose node is Ready for the grace period", testCaseHostInventoryReconcile{
+			Host:                   newUnhealthyHost(true),
+			Node:                   newNode(corev1.ConditionTrue, 10*time.Minute),
+			ExpectUnhealthyCleared: true,
+		}),
+		Entry("Stale annotation in read-only mode", testCaseHostInventoryReconcile{
+			Host:     newUnhealthyHost(true),
+			Node:     newNode(corev1.ConditionTrue, 10*time.Minute),
+			ReadOnly: true,
+		}),
+		Entry("Node Ready for less than the grace period", testCaseHostInventoryReconcile{
+			Host:          newUnhealthyHost(true),
+			Node:          newNode(corev1.ConditionTrue, time.Minute),
+			ExpectRequeue: true,
+		}),
+		Entry("Node not Ready", testCaseHostInventoryReconcile{
+			Host:          newUnhealthyHost(true),
+			Node:          newNode(corev1.ConditionFalse, 10*time.Minute),
+			ExpectRequeue: true,
+		}),
+		Entry("Node unreachable", testCaseHostInventoryReconcile{
+			Host:          newUnhealthyHost(true),
+			ExpectRequeue: true,
+		}),
+		Entry("Machine being remediated", testCaseHostInventoryReconcile{
+			Host:        newUnhealthyHost(true),
+			Node:        newNode(corev1.ConditionTrue, 10*time.Minute),
+			Remediation: newRemediation(infrav1.PhaseWaiting),
+		}),
+		Entry("Machine quarantined", testCaseHostInventoryReconcile{
+			Host:        newUnhealthyHost(true),
+			Node:        newNode(corev1.ConditionTrue, 10*time.Minute),
+			Remediation: newRemediation(infrav1.PhaseQuarantined),
+		}),
+		Entry("Remediation of the Machine failed", testCaseHostInventoryReconcile{
+			Host:                   newUnhealthyHost(true),
+			Node:                   newNode(corev1.ConditionTrue, 10*time.Minute),
+			Remediation:            newRemediation(infrav1.PhaseFailed),
+			ExpectUnhealthyCleared: true,
+		}),
+		Entry("Host not consumed", testCaseHostInventoryReconcile{
+			Host: newUnhealthyHost(false),
+			Node: newNode(corev1.ConditionTrue, 10*time.Minute),
+		}),
+	)
+})
@@ -86,6 +86,8 @@ func (r *Metal3LabelSyncReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	host := &bmov1alpha1.BareMetalHost{}
 	if err := r.Client.Get(ctx, req.NamespacedName, host); err != nil {
 		if apierrors.IsNotFound(err) {
+			// The host is removed, drop its metrics.
+			baremetal.ForgetHostPowerCycles(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, err
@@ -102,6 +104,11 @@ func (r *Metal3LabelSyncReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		return ctrl.Result{}, errors.Wrap(err, "failed to init patch helper")
 	}
 	defer func() {
+		if baremetal.ReadOnly {
+			controllerLog.Info("Read-only mode, skipping the write", "verb", "patch", "kind", "BareMetalHost",
+				"namespace", host.Namespace, "name", host.Name)
+			return
+		}
 		err := helper.Patch(ctx, host)
 		if err != nil {
 			controllerLog.Info("Failed to Patch BareMetalHost")
@@ -219,6 +226,11 @@ func (r *Metal3LabelSyncReconciler) reconcileBMHLabels(ctx context.Context, host
 	}
 	nodeLabelSyncSet := buildLabelSyncSet(prefixSet, node.Labels)
 	synchronizeLabelSyncSetsOnNode(hostLabelSyncSet, nodeLabelSyncSet, node)
+	if baremetal.ReadOnly {
+		r.Log.WithName(labelSyncControllerName).Info("Read-only mode, skipping the write",
+			"verb", "update", "kind", "Node", "name", node.Name)
+		return nil
+	}
 	_, err = corev1Remote.Nodes().Update(ctx, node, metav1.UpdateOptions{})
 	if err != nil {
 		return errors.Wrap(err, "unable to update the target node")
@@ -379,10 +379,13 @@ var _ = Describe("Metal3LabelSync controller", func() {
 			expectRequeue   bool
 			expectLabelsync map[string]string
 			debug           bool
+			readOnly        bool
 		}
 		DescribeTable("Test reconcile",
 
 			func(tc testCaseReconcile) {
+				defer func(readOnly bool) { baremetal.ReadOnly = readOnly }(baremetal.ReadOnly)
+				baremetal.ReadOnly = tc.readOnly
 
 				objects := []client.Object{}
 				if tc.host != nil {
@@ -516,6 +519,15 @@ var _ = Describe("Metal3LabelSync controller", func() {
 					"foo.metal3.io/bar": "blue",
 				},
 			}),
+			Entry("Read-only mode", testCaseReconcile{
+				host:          newBareMetalHost(baremetalhostName, &metal3MachineSpec, nil, Labels, false),
+				machine:       newMachine(clusterName, machineName, metal3machineName, nodeName),
+				metal3Machine: newMetal3Machine(metal3machineName, m3mObjectMetaWithOwnerRef(), nil, nil, false),
+				cluster:       newCluster(clusterName, nil, nil),
+				metal3Cluster: newMetal3Cluster(metal3ClusterName, bmcOwnerRef(), bmcSpec(), nil, annotation, false),
+				readOnly:      true,
+				expectRequeue: true,
+			}),
 		)
 		type TestCaseReconcileBMHLabels struct {
 			PrefixSet   map[string]struct{}
@@ -74,6 +74,7 @@ type Metal3MachineReconciler struct {
 // Add RBAC rules to access cluster-api resources
 // +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=metal3.io,resources=baremetalhosts/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=metal3.io,resources=hostfirmwarecomponents,verbs=get;list;watch
 
 // Reconcile handles Metal3Machine events.
 func (r *Metal3MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
@@ -100,6 +101,13 @@ func (r *Metal3MachineReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}()
 
+	// Consume the reconcile-now annotation, setting it already triggered this
+	// reconcile and removing it allows setting it again.
+	if _, ok := capm3Machine.Annotations[baremetal.ReconcileNowAnnotation]; ok {
+		machineLog.Info("Reconcile requested through the annotation", "annotation", baremetal.ReconcileNowAnnotation)
+		delete(capm3Machine.Annotations, baremetal.ReconcileNowAnnotation)
+	}
+
 	// Fetch the Machine.
 	capiMachine, err := util.GetOwnerMachine(ctx, r.Client, capm3Machine.ObjectMeta)
 
@@ -156,7 +164,7 @@ func (r *Metal3MachineReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		err := machineMgr.RemovePauseAnnotation(ctx)
 		if err != nil {
 			machineLog.Info("failed to check pause annotation on associated bmh")
-			conditions.MarkFalse(capm3Machine, infrav1.AssociateBMHCondition, infrav1.PauseAnnotationRemoveFailedReason, clusterv1.ConditionSeverityInfo, "")
+			conditions.MarkFalse(capm3Machine, infrav1.AssociateBMHCondition, infrav1.PauseAnnotationRemoveFailedReason, clusterv1.ConditionSeverityWarning, "")
 			return ctrl.Result{}, nil
 		}
 	} else {
@@ -164,7 +172,7 @@ func (r *Metal3MachineReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		err := machineMgr.SetPauseAnnotation(ctx)
 		if err != nil {
 			machineLog.Info("failed to set pause annotation on associated bmh")
-			conditions.MarkFalse(capm3Machine, infrav1.AssociateBMHCondition, infrav1.PauseAnnotationSetFailedReason, clusterv1.ConditionSeverityInfo, "")
+			conditions.MarkFalse(capm3Machine, infrav1.AssociateBMHCondition, infrav1.PauseAnnotationSetFailedReason, clusterv1.ConditionSeverityWarning, "")
 			return ctrl.Result{}, nil
 		}
 	}
@@ -202,6 +210,11 @@ func patchMetal3Machine(ctx context.Context, patchHelper *patch.Helper, metal3Ma
 			infrav1.AssociateBMHCondition,
 			infrav1.Metal3DataReadyCondition,
 			infrav1.KubernetesNodeReadyCondition,
+			infrav1.HostPoweredOnCondition,
+			infrav1.HostHealthyCondition,
+			infrav1.HostSelectorCondition,
+			infrav1.DeprovisionStuckCondition,
+			infrav1.HostReferencedCondition,
 		}},
 		patch.WithStatusObservedGeneration{},
 	)
@@ -218,8 +231,12 @@ func (r *Metal3MachineReconciler) reconcileNormal(ctx context.Context,
 	if machineMgr.IsProvisioned() {
 		errType := capierrors.UpdateMachineError
 		err := machineMgr.Update(ctx)
-		return checkMachineError(machineMgr, err,
-			"Failed to update the Metal3Machine", errType)
+		if err != nil {
+			return checkMachineError(machineMgr, err,
+				"Failed to update the Metal3Machine", errType)
+		}
+		// Reconcile again when the power schedule changes the power state.
+		return ctrl.Result{RequeueAfter: machineMgr.PowerScheduleRequeueAfter()}, nil
 	}
 
 	// Make sure bootstrap data is available and populated. If not, return, we
@@ -229,6 +246,17 @@ func (r *Metal3MachineReconciler) reconcileNormal(ctx context.Context,
 		return ctrl.Result{}, nil
 	}
 
+	// Make sure the bootstrap data secret exists, otherwise the host would be
+	// provisioned with incomplete user data. The secret is not watched, so requeue.
+	available, err := machineMgr.IsBootstrapDataAvailable(ctx)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to check the bootstrap data secret")
+	}
+	if !available {
+		machineMgr.SetConditionMetal3MachineToFalse(infrav1.AssociateBMHCondition, infrav1.WaitingForBootstrapDataReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{Requeue: true, RequeueAfter: requeueAfter}, nil
+	}
+
 	errType := capierrors.CreateMachineError
 
 	// Check if the metal3machine was associated with a baremetalhost
@@ -245,7 +273,7 @@ func (r *Metal3MachineReconciler) reconcileNormal(ctx context.Context,
 	machineMgr.SetConditionMetal3MachineToTrue(infrav1.AssociateBMHCondition)
 
 	// Make sure that the metadata is ready if any
-	err := machineMgr.AssociateM3Metadata(ctx)
+	err = machineMgr.AssociateM3Metadata(ctx)
 	if err != nil {
 		machineMgr.SetConditionMetal3MachineToFalse(infrav1.KubernetesNodeReadyCondition, infrav1.AssociateM3MetaDataFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 		return checkMachineError(machineMgr, err,
@@ -277,6 +305,14 @@ func (r *Metal3MachineReconciler) reconcileNormal(ctx context.Context,
 			return checkMachineError(machineMgr, err,
 				"failed to set the target node providerID", errType)
 		}
+		// Apply the node role label on the node if requested
+		err = machineMgr.SetNodeRole(ctx, providerID, r.CapiClientGetter)
+		if err != nil {
+			r.Log.Error(err, "Failed to set the target node role", "providerID", providerID)
+			machineMgr.SetConditionMetal3MachineToFalse(infrav1.KubernetesNodeReadyCondition, infrav1.SettingNodeRoleFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+			return checkMachineError(machineMgr, err,
+				"failed to set the target node role", errType)
+		}
 		// Make sure Spec.ProviderID is set and mark the capm3Machine ready
 		machineMgr.SetProviderID(providerID)
 	}
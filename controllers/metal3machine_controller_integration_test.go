@@ -92,6 +92,12 @@ func m3mMetaWithAnnotation() *metav1.ObjectMeta {
 	}
 }
 
+func m3mMetaWithReconcileNowAnnotation() *metav1.ObjectMeta {
+	objectMeta := m3mMetaWithAnnotation()
+	objectMeta.Annotations[baremetal.ReconcileNowAnnotation] = ""
+	return objectMeta
+}
+
 func m3mMetaWithIncorrectAnnotation() *metav1.ObjectMeta {
 	return &metav1.ObjectMeta{
 		Name:            metal3machineName,
@@ -131,6 +137,16 @@ func userDataSecret() *corev1.Secret {
 	}
 }
 
+func bootstrapDataSecret() *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      bootstrapDataSecretName,
+			Namespace: namespaceName,
+		},
+		Type: "Opaque",
+	}
+}
+
 func m3mSpecWithSecret() *infrav1.Metal3MachineSpec {
 	return &infrav1.Metal3MachineSpec{
 		UserData: &corev1.SecretReference{
@@ -186,6 +202,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 		CheckBMHostCleaned         bool
 		CheckBMHostProvisioned     bool
 		ExpectedOnlineStatus       bool
+		CheckReconcileNowConsumed  bool
 	}
 
 	DescribeTable("Reconcile tests",
@@ -251,6 +268,9 @@ var _ = Describe("Reconcile metal3machine", func() {
 				if condExp.Reason != "" {
 					Expect(condGot.Reason).To(Equal(condExp.Reason))
 				}
+				if condExp.Severity != "" {
+					Expect(condGot.Severity).To(Equal(condExp.Severity))
+				}
 			}
 			if tc.LabelExpected {
 				Expect(objMeta.Labels[clusterv1.ClusterNameLabel]).NotTo(BeNil())
@@ -287,7 +307,12 @@ var _ = Describe("Reconcile metal3machine", func() {
 			if tc.CheckBMHostProvisioned {
 				Expect(testBMHost.Spec.Image.URL).Should(BeEquivalentTo(testBMmachine.Spec.Image.URL))
 				Expect(testBMHost.Spec.Image.Checksum).Should(BeEquivalentTo(testBMmachine.Spec.Image.Checksum))
-				Expect(testBMHost.Spec.Image.DiskFormat).Should(BeEquivalentTo(testBMmachine.Spec.Image.DiskFormat))
+				if testBMmachine.Spec.Image.DiskFormat != nil {
+					Expect(testBMHost.Spec.Image.DiskFormat).Should(BeEquivalentTo(testBMmachine.Spec.Image.DiskFormat))
+				} else {
+					// The disk format is inferred from the qcow2 image URL.
+					Expect(testBMHost.Spec.Image.DiskFormat).Should(Equal(ptr.To("qcow2")))
+				}
 				if testBMmachine.Spec.Image.ChecksumType != nil {
 					Expect(testBMHost.Spec.Image.ChecksumType).Should(BeEquivalentTo(*testBMmachine.Spec.Image.ChecksumType))
 				} else {
@@ -296,6 +321,9 @@ var _ = Describe("Reconcile metal3machine", func() {
 				Expect(testBMHost.Spec.UserData).NotTo(BeNil())
 				Expect(testBMHost.Spec.ConsumerRef.Name).To(Equal(testBMmachine.Name))
 			}
+			if tc.CheckReconcileNowConsumed {
+				Expect(testBMmachine.Annotations).NotTo(HaveKey(baremetal.ReconcileNowAnnotation))
+			}
 			if tc.ClusterInfraReady {
 				Expect(testcluster.Status.InfrastructureReady).To(BeTrue())
 			} else {
@@ -405,6 +433,30 @@ var _ = Describe("Reconcile metal3machine", func() {
 				},
 			},
 		),
+		//Given: Machine with Bootstrap data secret name, Metal3Machine, Cluster, Metal3Cluster. Bootstrap data secret does not exist.
+		//Expected: No Error, Requeue. Condition AssociateBMHCondition should be false with WaitingForBootstrapDataReason.
+		Entry("Should requeue when machine bootstrap data secret does not exist",
+			TestCaseReconcile{
+				Objects: []client.Object{
+					newCluster(clusterName, nil, nil),
+					newMetal3Cluster(metal3ClusterName, nil, nil, nil, nil, false),
+					metal3machineWithOwnerRefs(),
+					machineWithBootstrap(),
+				},
+				ErrorExpected:           false,
+				RequeueExpected:         true,
+				ExpectedRequeueDuration: requeueAfter,
+				ClusterInfraReady:       true,
+				CheckBootStrapReady:     true,
+				ConditionsExpected: clusterv1.Conditions{
+					clusterv1.Condition{
+						Type:   infrav1.AssociateBMHCondition,
+						Status: corev1.ConditionFalse,
+						Reason: infrav1.WaitingForBootstrapDataReason,
+					},
+				},
+			},
+		),
 		//Given: Machine, Metal3Machine, Cluster. No Metal3Cluster. Cluster Infra ready
 		//Expected: No error. Reconciler should wait for BMC Controller to create the BMCluster
 		Entry("Should not return an error when owner Cluster infrastructure is ready and BMCluster does not exist",
@@ -508,6 +560,34 @@ var _ = Describe("Reconcile metal3machine", func() {
 				CheckBootStrapReady: false,
 			},
 		),
+		//Given: M3Machine (Spec: Provider ID, Status: Ready) with the reconcile-now annotation, BMHost(Provisioned).
+		//Expected: The annotation is removed and the Metal3Machine is reconciled.
+		Entry("Should consume the reconcile-now annotation and reconcile",
+			TestCaseReconcile{
+				Objects: []client.Object{
+					newMetal3Machine(metal3machineName, m3mMetaWithReconcileNowAnnotation(),
+						&infrav1.Metal3MachineSpec{
+							ProviderID: &providerID,
+						},
+						&infrav1.Metal3MachineStatus{
+							Ready: true,
+						},
+						false,
+					),
+					machineWithInfra(),
+					newCluster(clusterName, nil, nil),
+					newMetal3Cluster(metal3ClusterName, nil, nil, nil, nil, false),
+					newBareMetalHost(baremetalhostName, nil, nil, nil, false),
+				},
+				ErrorExpected:             false,
+				RequeueExpected:           false,
+				ClusterInfraReady:         true,
+				CheckBMFinalizer:          true,
+				CheckBMState:              true,
+				CheckBootStrapReady:       false,
+				CheckReconcileNowConsumed: true,
+			},
+		),
 		//Given: Machine has Bootstrap data available while M3Machine has no Host Annotation
 		// BMH is in available state
 		//Expected: Requeue Expected
@@ -531,6 +611,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 						}, nil, false,
 					),
 					machineWithBootstrap(),
+					bootstrapDataSecret(),
 					newCluster(clusterName, nil, nil),
 					newMetal3Cluster(metal3ClusterName, nil, nil, nil, nil, false),
 					newBareMetalHost(baremetalhostName, nil, &bmov1alpha1.BareMetalHostStatus{
@@ -569,6 +650,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 						}, nil, false,
 					),
 					machineWithBootstrap(),
+					bootstrapDataSecret(),
 					newCluster(clusterName, nil, nil),
 					newMetal3Cluster(metal3ClusterName, nil, nil, nil, nil, false),
 					newBareMetalHost(baremetalhostName, nil, &bmov1alpha1.BareMetalHostStatus{
@@ -601,6 +683,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 						}, nil, false,
 					),
 					machineWithBootstrap(),
+					bootstrapDataSecret(),
 					newCluster(clusterName, nil, nil),
 					newMetal3Cluster(metal3ClusterName, nil, nil, nil, nil, false),
 					newBareMetalHost(baremetalhostName, nil, nil, nil, false),
@@ -653,6 +736,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 						}, nil, false,
 					),
 					machineWithBootstrap(),
+					bootstrapDataSecret(),
 					newCluster(clusterName, nil, nil),
 					newMetal3Cluster(metal3ClusterName, nil, nil, nil, nil, false),
 					newBareMetalHost(baremetalhostName, nil, nil, nil, false),
@@ -711,6 +795,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 						},
 					}, nil, false),
 					machineWithBootstrap(),
+					bootstrapDataSecret(),
 					newCluster(clusterName, nil, nil),
 					newMetal3Cluster(metal3ClusterName, nil, nil, nil, nil, false),
 					newBareMetalHost(baremetalhostName, nil, &bmov1alpha1.BareMetalHostStatus{
@@ -735,6 +820,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 				Objects: []client.Object{
 					newMetal3Machine(metal3machineName, m3mMetaWithAnnotation(), nil, nil, false),
 					machineWithBootstrap(),
+					bootstrapDataSecret(),
 					newCluster(clusterName, nil, nil),
 					newMetal3Cluster(metal3ClusterName, bmcOwnerRef(), bmcSpec(), nil, nil, false),
 					newBareMetalHost(baremetalhostName, nil, nil, nil, false),
@@ -771,6 +857,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 				Objects: []client.Object{
 					newMetal3Machine(metal3machineName, m3mMetaWithAnnotation(), nil, nil, false),
 					machineWithBootstrap(),
+					bootstrapDataSecret(),
 					newCluster(clusterName, nil, nil),
 					newMetal3Cluster(metal3ClusterName, bmcOwnerRef(), bmcSpec(), nil, nil, false),
 					newBareMetalHost(baremetalhostName, nil, nil, nil, false),
@@ -853,6 +940,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 				Objects: []client.Object{
 					newMetal3Machine(metal3machineName, m3mMetaWithIncorrectAnnotation(), nil, nil, false),
 					machineWithBootstrap(),
+					bootstrapDataSecret(),
 					newCluster(clusterName, nil, nil),
 					newMetal3Cluster(metal3ClusterName, nil, nil, nil, nil, false),
 				},
@@ -863,9 +951,10 @@ var _ = Describe("Reconcile metal3machine", func() {
 				ExpectedRequeueDuration: time.Second * 0,
 				ConditionsExpected: clusterv1.Conditions{
 					clusterv1.Condition{
-						Type:   infrav1.AssociateBMHCondition,
-						Status: corev1.ConditionFalse,
-						Reason: infrav1.PauseAnnotationRemoveFailedReason,
+						Type:     infrav1.AssociateBMHCondition,
+						Status:   corev1.ConditionFalse,
+						Severity: clusterv1.ConditionSeverityWarning,
+						Reason:   infrav1.PauseAnnotationRemoveFailedReason,
 					},
 					clusterv1.Condition{
 						Type:   clusterv1.ReadyCondition,
@@ -881,6 +970,7 @@ var _ = Describe("Reconcile metal3machine", func() {
 				Objects: []client.Object{
 					newMetal3Machine(metal3machineName, m3mMetaWithIncorrectAnnotation(), nil, nil, false),
 					machineWithBootstrap(),
+					bootstrapDataSecret(),
 					newCluster(clusterName, clusterPauseSpec(), nil),
 					newMetal3Cluster(metal3ClusterName, nil, nil, nil, nil, false),
 				},
@@ -891,9 +981,10 @@ var _ = Describe("Reconcile metal3machine", func() {
 				ExpectedRequeueDuration: time.Second * 0,
 				ConditionsExpected: clusterv1.Conditions{
 					clusterv1.Condition{
-						Type:   infrav1.AssociateBMHCondition,
-						Status: corev1.ConditionFalse,
-						Reason: infrav1.PauseAnnotationSetFailedReason,
+						Type:     infrav1.AssociateBMHCondition,
+						Status:   corev1.ConditionFalse,
+						Severity: clusterv1.ConditionSeverityWarning,
+						Reason:   infrav1.PauseAnnotationSetFailedReason,
 					},
 					clusterv1.Condition{
 						Type:   clusterv1.ReadyCondition,
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
@@ -43,12 +44,15 @@ type reconcileNormalTestCase struct {
 	ExpectRequeue          bool
 	Provisioned            bool
 	BootstrapNotReady      bool
+	BootstrapDataMissing   bool
 	Annotated              bool
 	AssociateFails         bool
 	GetProviderIDFails     bool
 	GetBMHIDFails          bool
 	BMHIDSet               bool
 	SetNodeProviderIDFails bool
+	SetNodeRoleFails       bool
+	PowerScheduleRequeue   time.Duration
 }
 
 func setReconcileNormalExpectations(ctrl *gomock.Controller,
@@ -62,6 +66,7 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 	m.EXPECT().IsProvisioned().Return(tc.Provisioned)
 	if tc.Provisioned {
 		m.EXPECT().Update(context.TODO()).Return(nil)
+		m.EXPECT().PowerScheduleRequeueAfter().Return(tc.PowerScheduleRequeue)
 		m.EXPECT().IsBootstrapReady().MaxTimes(0)
 		m.EXPECT().AssociateM3Metadata(context.TODO()).MaxTimes(0)
 		m.EXPECT().HasAnnotation().MaxTimes(0)
@@ -83,6 +88,17 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 		return m
 	}
 
+	// Bootstrap data secret missing, we'll requeue, not call anything else
+	m.EXPECT().IsBootstrapDataAvailable(context.TODO()).Return(!tc.BootstrapDataMissing, nil)
+	if tc.BootstrapDataMissing {
+		m.EXPECT().SetConditionMetal3MachineToFalse(infrav1.AssociateBMHCondition,
+			infrav1.WaitingForBootstrapDataReason, clusterv1.ConditionSeverityInfo, "")
+		m.EXPECT().HasAnnotation().MaxTimes(0)
+		m.EXPECT().Associate(context.TODO()).MaxTimes(0)
+		m.EXPECT().Update(context.TODO()).MaxTimes(0)
+		return m
+	}
+
 	// Bootstrap data is ready and node is not annotated, i.e. not associated
 	m.EXPECT().HasAnnotation().Return(tc.Annotated)
 	if !tc.Annotated {
@@ -145,6 +161,17 @@ func setReconcileNormalExpectations(ctrl *gomock.Controller,
 		m.EXPECT().
 			SetNodeProviderID(context.TODO(), gomock.Eq(&provID), nil).
 			Return(nil)
+
+		// if we fail to set the node role, we do not go further
+		if tc.SetNodeRoleFails {
+			m.EXPECT().SetNodeRole(context.TODO(), provID, nil).Return(errors.New("Failed"))
+			m.EXPECT().SetProviderID(provID).MaxTimes(0)
+			m.EXPECT().SetConditionMetal3MachineToFalse(infrav1.KubernetesNodeReadyCondition,
+				infrav1.SettingNodeRoleFailedReason, clusterv1.ConditionSeverityWarning, gomock.Any())
+			return m
+		}
+
+		m.EXPECT().SetNodeRole(context.TODO(), provID, nil).Return(nil)
 		m.EXPECT().SetProviderID(provID)
 
 		// We did not get an id (got nil), so we'll requeue and not go further
@@ -232,17 +259,31 @@ var _ = Describe("Metal3Machine manager", func() {
 				} else {
 					Expect(res.Requeue).To(BeFalse())
 				}
+				if tc.Provisioned {
+					Expect(res.RequeueAfter).To(Equal(tc.PowerScheduleRequeue))
+				}
 			},
 			Entry("Provisioned", reconcileNormalTestCase{
 				ExpectError:   false,
 				ExpectRequeue: false,
 				Provisioned:   true,
 			}),
+			Entry("Provisioned with a power schedule", reconcileNormalTestCase{
+				ExpectError:          false,
+				ExpectRequeue:        false,
+				Provisioned:          true,
+				PowerScheduleRequeue: 2 * time.Hour,
+			}),
 			Entry("Bootstrap not ready", reconcileNormalTestCase{
 				ExpectError:       false,
 				ExpectRequeue:     false,
 				BootstrapNotReady: true,
 			}),
+			Entry("Bootstrap data secret missing", reconcileNormalTestCase{
+				ExpectError:          false,
+				ExpectRequeue:        true,
+				BootstrapDataMissing: true,
+			}),
 			Entry("Not Annotated", reconcileNormalTestCase{
 				ExpectError:   false,
 				ExpectRequeue: false,
@@ -281,6 +322,12 @@ var _ = Describe("Metal3Machine manager", func() {
 				BMHIDSet:               true,
 				SetNodeProviderIDFails: true,
 			}),
+			Entry("BMH ID set, SetNodeRole fails", reconcileNormalTestCase{
+				ExpectError:      true,
+				ExpectRequeue:    false,
+				BMHIDSet:         true,
+				SetNodeRoleFails: true,
+			}),
 		)
 	})
 
@@ -22,19 +22,26 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
 	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
 	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/clustercache"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 // Metal3RemediationReconciler reconciles a Metal3Remediation object.
@@ -44,6 +51,9 @@ type Metal3RemediationReconciler struct {
 	ManagerFactory             baremetal.ManagerFactoryInterface
 	Log                        logr.Logger
 	IsOutOfServiceTaintEnabled bool
+	// DryRun runs all the remediations as if they had the
+	// RemediationDryRunAnnotation.
+	DryRun bool
 }
 
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=list
@@ -93,9 +103,34 @@ func (r *Metal3RemediationReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		remediationLog.Error(err, "metal3Remediation's owner Machine could not be retrieved")
 		return ctrl.Result{}, errors.Wrapf(err, "metal3Remediation's owner Machine could not be retrieved")
 	}
+	if capiMachine == nil && metal3Remediation.Spec.MachineSelector != nil {
+		// Resolve the Machine selected by the remediation, which becomes its owner.
+		selectorMgr, err := r.ManagerFactory.NewRemediationManager(metal3Remediation, nil, nil, remediationLog)
+		if err != nil {
+			remediationLog.Error(err, "failed to create helper for managing the metal3remediation")
+			return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the metal3remediation")
+		}
+		capiMachine, err = selectorMgr.ResolveMachineSelector(ctx)
+		if err != nil {
+			remediationLog.Info("metal3Remediation's Machine selector could not be resolved", "error", err.Error())
+			selectorMgr.RecordEvent(corev1.EventTypeWarning, "MachineSelectorUnresolved", "%s", err.Error())
+			return ctrl.Result{}, errors.Wrap(err, "metal3Remediation's Machine selector could not be resolved")
+		}
+	}
 	if capiMachine == nil {
-		remediationLog.Info("metal3Remediation's owner Machine not set")
-		return ctrl.Result{}, errors.New("metal3Remediation's owner Machine not set")
+		if metal3Remediation.Spec.HostRef == nil {
+			remediationLog.Info("metal3Remediation's owner Machine not set")
+			return ctrl.Result{}, errors.New("metal3Remediation's owner Machine not set")
+		}
+		remediationLog = remediationLog.WithValues("unhealthy host detected", metal3Remediation.Spec.HostRef.Name)
+
+		// Create a helper for managing the remediation of a host without Machine.
+		remediationMgr, err := r.ManagerFactory.NewRemediationManager(metal3Remediation, nil, nil, remediationLog)
+		if err != nil {
+			remediationLog.Error(err, "failed to create helper for managing the metal3remediation")
+			return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the metal3remediation")
+		}
+		return r.reconcileHost(ctx, remediationMgr, r.isDryRun(metal3Remediation))
 	}
 	remediationLog = remediationLog.WithValues("unhealthy machine detected", capiMachine.Name)
 
@@ -121,18 +156,33 @@ func (r *Metal3RemediationReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 
 	// Handle both deleted and non-deleted remediations
-	return r.reconcileNormal(ctx, remediationMgr)
+	return r.reconcileNormal(ctx, remediationMgr, r.isDryRun(metal3Remediation))
+}
+
+// isDryRun returns true if the remediation must not act on the host, the node
+// or the Machine. A dry run goes through the same phases, logging the actions
+// it would take instead of taking them.
+func (r *Metal3RemediationReconciler) isDryRun(remediation *infrav1.Metal3Remediation) bool {
+	if r.DryRun {
+		return true
+	}
+	_, ok := remediation.Annotations[infrav1.RemediationDryRunAnnotation]
+	return ok
 }
 
 func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
-	remediationMgr baremetal.RemediationManagerInterface,
+	remediationMgr baremetal.RemediationManagerInterface, dryRun bool,
 ) (ctrl.Result, error) {
 	// If host is gone, exit early
 	host, _, err := remediationMgr.GetUnhealthyHost(ctx)
 	if err != nil {
+		if apierrors.IsNotFound(err) && baremetal.HostGoneTimeout > 0 {
+			return r.reconcileHostGone(ctx, remediationMgr, baremetal.HostGoneDeleteMachine, dryRun)
+		}
 		r.Log.Error(err, "unable to find a host for unhealthy machine")
 		return ctrl.Result{}, errors.Wrapf(err, "unable to find a host for unhealthy machine")
 	}
+	remediationMgr.ResetHostNotFoundTime()
 
 	// If user has set bmh.Spec.Online to false
 	// do not try to remediate the host
@@ -142,8 +192,23 @@ func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
 		return ctrl.Result{}, nil
 	}
 
+	// Never remediate a host known to be bad until the annotation is removed.
+	if _, ok := host.Annotations[infrav1.PermanentlyFailedAnnotation]; ok {
+		r.Log.Info("Unable to remediate, Host is permanently failed", "host", host.Name)
+		remediationMgr.SetRemediationPhase(infrav1.PhaseFailed)
+		return ctrl.Result{}, nil
+	}
+
 	remediationType := remediationMgr.GetRemediationType()
 
+	if remediationType == infrav1.QuarantineRemediationStrategy {
+		return r.remediateQuarantineStrategy(ctx, remediationMgr, dryRun)
+	}
+
+	if remediationType == infrav1.ReassociateRemediationStrategy {
+		return r.remediateReassociateStrategy(ctx, remediationMgr, host, dryRun)
+	}
+
 	if remediationType != infrav1.RebootRemediationStrategy {
 		r.Log.Info("unsupported remediation strategy")
 		return ctrl.Result{}, nil
@@ -178,10 +243,26 @@ func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
 			}
 		}
 
+		// Remove the unhealthy annotation once the machine has recovered and
+		// stayed healthy for the grace period.
+		unhealthyAnnotationKept := false
+		if _, ok := host.Annotations[infrav1.UnhealthyAnnotation]; ok {
+			if dryRun {
+				r.Log.Info("Dry run: would clear the unhealthy annotation once the machine is healthy", "host", host.Name)
+			} else {
+				cleared, err := remediationMgr.ClearUnhealthyAnnotation(ctx)
+				if err != nil {
+					r.Log.Error(err, "error clearing unhealthy annotation")
+					return ctrl.Result{}, errors.Wrapf(err, "error clearing unhealthy annotation")
+				}
+				unhealthyAnnotationKept = !cleared
+			}
+		}
+
 		switch remediationMgr.GetRemediationPhase() {
 		case infrav1.PhaseRunning:
 
-			return r.remediateRebootStrategy(ctx, remediationMgr, clusterClient, node)
+			return r.remediateRebootStrategy(ctx, remediationMgr, clusterClient, node, dryRun)
 
 		case infrav1.PhaseWaiting:
 
@@ -190,6 +271,8 @@ func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
 			if err != nil {
 				r.Log.Error(err, "error getting poweroff annotation status")
 				return ctrl.Result{}, errors.Wrap(err, "error getting poweroff annotation status")
+			} else if ok && dryRun {
+				r.Log.Info("Dry run: would power on the host")
 			} else if ok {
 				r.Log.Info("Powering on the host")
 				err := remediationMgr.RemovePowerOffAnnotation(ctx)
@@ -211,7 +294,9 @@ func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
 			// Restore node if available and not done yet
 			if remediationMgr.HasFinalizer() {
 				if node != nil {
-					if r.IsOutOfServiceTaintEnabled {
+					if dryRun {
+						r.Log.Info("Dry run: would restore the node", "node", node.Name)
+					} else if r.IsOutOfServiceTaintEnabled {
 						if remediationMgr.HasOutOfServiceTaint(node) {
 							if err := remediationMgr.RemoveOutOfServiceTaint(ctx, clusterClient, node); err != nil {
 								return ctrl.Result{}, errors.Wrapf(err, "error removing out-of-service taint from node %s", node.Name)
@@ -225,16 +310,31 @@ func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
 						}
 					}
 
-					// clean up
-					r.Log.Info("Remediation done, cleaning up remediation CR")
-					if !r.IsOutOfServiceTaintEnabled {
-						remediationMgr.RemoveNodeBackupAnnotations()
+					healthy, err := remediationMgr.NodeIsHealthy(ctx, clusterClient, node)
+					if err != nil {
+						return ctrl.Result{}, errors.Wrapf(err, "error checking the health of node %s", node.Name)
+					}
+					if healthy {
+						if dryRun {
+							r.Log.Info("Dry run: would remove the remediation taint", "node", node.Name)
+						} else if err := remediationMgr.RemoveRemediationTaint(ctx, clusterClient, node); err != nil {
+							return ctrl.Result{}, err
+						}
+						// clean up
+						r.Log.Info("Remediation done, cleaning up remediation CR")
+						remediationMgr.RecordEvent(corev1.EventTypeNormal, "RemediationSucceeded",
+							"Node %s of host %s is healthy", node.Name, host.Name)
+						if !r.IsOutOfServiceTaintEnabled {
+							remediationMgr.RemoveNodeBackupAnnotations()
+						}
+						remediationMgr.UnsetFinalizer()
+						return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 					}
-					remediationMgr.UnsetFinalizer()
-					return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 				} else if isNodeForbidden {
 					// we don't have a node, just remove finalizer
 					remediationMgr.UnsetFinalizer()
+					remediationMgr.RecordEvent(corev1.EventTypeNormal, "RemediationSucceeded",
+						"Host %s powered on again", host.Name)
 
 					r.Log.Info("Skipping node restore, remediation done, CR should be deleted soon")
 					return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
@@ -249,6 +349,14 @@ func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
 				return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 			}
 
+			// Escalate a host which keeps being unhealthy after its reboots,
+			// even if the retry limit is not reached
+			if remediationMgr.HasReachRebootsBeforeEscalation() {
+				r.Log.Info("Remediation timed out and reboots before escalation reached, escalating")
+				remediationMgr.Escalate()
+				return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+			}
+
 			// Try again if limit not reached
 			if remediationMgr.RetryLimitIsSet() && !remediationMgr.HasReachRetryLimit() {
 				r.Log.Info("Remediation timed out, will retry")
@@ -261,6 +369,13 @@ func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
 
 			r.Log.Info("Remediation timed out and retry limit reached")
 
+			if dryRun {
+				r.Log.Info("Dry run: would hand the Machine over to Cluster API for deletion and set the unhealthy annotation on the host",
+					"host", host.Name)
+				remediationMgr.SetRemediationPhase(infrav1.PhaseDeleting)
+				return ctrl.Result{}, nil
+			}
+
 			// When machine is still unhealthy after remediation, setting of OwnerRemediatedCondition
 			// moves control to CAPI machine controller. The owning controller will do
 			// preflight checks and handles the Machine deletion
@@ -283,12 +398,15 @@ func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
 			return ctrl.Result{}, nil
 
 		case infrav1.PhaseDeleting:
-			// nothing to do anymore
-			break
+			r.removeRemediationTaint(ctx, remediationMgr, clusterClient, node, dryRun)
+			// Check again for the end of the grace period.
+			if unhealthyAnnotationKept {
+				return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+			}
 
-		case infrav1.PhaseFailed:
-			// nothing to do anymore
-			break
+		case infrav1.PhaseFailed, infrav1.PhaseHostGone:
+			// nothing to do anymore, besides releasing the node
+			r.removeRemediationTaint(ctx, remediationMgr, clusterClient, node, dryRun)
 
 		default:
 			r.Log.Error(nil, "unknown phase!", "phase", remediationMgr.GetRemediationPhase())
@@ -297,18 +415,347 @@ func (r *Metal3RemediationReconciler) reconcileNormal(ctx context.Context,
 	return ctrl.Result{}, nil
 }
 
+// reconcileHost remediates a host which is not associated with a Machine. Without
+// a Machine there is no Node to back up or to wait for, so the host is only
+// rebooted and the remediation succeeds once the host is powered on again. It
+// is retried when the host is not powered on again before the timeout, and
+// fails once the retry limit is reached.
+func (r *Metal3RemediationReconciler) reconcileHost(ctx context.Context,
+	remediationMgr baremetal.RemediationManagerInterface, dryRun bool,
+) (ctrl.Result, error) {
+	host, _, err := remediationMgr.GetUnhealthyHost(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) && baremetal.HostGoneTimeout > 0 {
+			return r.reconcileHostGone(ctx, remediationMgr, false, dryRun)
+		}
+		r.Log.Error(err, "unable to find the host to remediate")
+		return ctrl.Result{}, errors.Wrapf(err, "unable to find the host to remediate")
+	}
+	remediationMgr.ResetHostNotFoundTime()
+
+	// If user has set bmh.Spec.Online to false
+	// do not try to remediate the host
+	if !remediationMgr.OnlineStatus(host) {
+		r.Log.Info("Unable to remediate, Host is powered off (spec.Online is false)")
+		remediationMgr.SetRemediationPhase(infrav1.PhaseFailed)
+		return ctrl.Result{}, nil
+	}
+
+	// Never remediate a host known to be bad until the annotation is removed.
+	if _, ok := host.Annotations[infrav1.PermanentlyFailedAnnotation]; ok {
+		r.Log.Info("Unable to remediate, Host is permanently failed", "host", host.Name)
+		remediationMgr.SetRemediationPhase(infrav1.PhaseFailed)
+		return ctrl.Result{}, nil
+	}
+
+	// Without a Machine, the host can only be rebooted.
+	if remediationType := remediationMgr.GetRemediationType(); remediationType != infrav1.RebootRemediationStrategy {
+		if remediationMgr.GetRemediationPhase() == infrav1.PhaseFailed {
+			return ctrl.Result{}, nil
+		}
+		r.Log.Info("Unable to remediate, unsupported remediation strategy for a host without Machine",
+			"strategy", remediationType)
+		remediationMgr.RecordEvent(corev1.EventTypeWarning, "UnsupportedRemediationStrategy",
+			"Remediation strategy %s is not supported for host %s without Machine", remediationType, host.Name)
+		remediationMgr.SetRemediationPhase(infrav1.PhaseFailed)
+		return ctrl.Result{}, nil
+	}
+
+	switch remediationMgr.GetRemediationPhase() {
+	case "":
+		remediationMgr.SetRemediationPhase(infrav1.PhaseRunning)
+		now := metav1.Now()
+		remediationMgr.SetLastRemediationTime(&now)
+		return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+
+	case infrav1.PhaseRunning:
+		return r.remediateRebootStrategy(ctx, remediationMgr, nil, nil, dryRun)
+
+	case infrav1.PhaseWaiting:
+		ok, err := remediationMgr.IsPowerOffRequested(ctx)
+		if err != nil {
+			r.Log.Error(err, "error getting poweroff annotation status")
+			return ctrl.Result{}, errors.Wrap(err, "error getting poweroff annotation status")
+		} else if ok && dryRun {
+			r.Log.Info("Dry run: would power on the host")
+		} else if ok {
+			r.Log.Info("Powering on the host")
+			err := remediationMgr.RemovePowerOffAnnotation(ctx)
+			if err != nil {
+				r.Log.Error(err, "error removing poweroff annotation")
+				return ctrl.Result{}, errors.Wrap(err, "error removing poweroff annotation")
+			}
+		}
+
+		// Wait until powered on
+		if on, err := remediationMgr.IsPoweredOn(ctx); err != nil {
+			r.Log.Error(err, "error getting power status")
+			return ctrl.Result{}, errors.Wrap(err, "error getting power status")
+		} else if !on {
+			// wait a bit before checking again if we are powered on, until
+			// the remediation times out
+			timedOut, _ := remediationMgr.TimeToRemediate(remediationMgr.GetTimeout().Duration)
+			if !timedOut {
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+			}
+			return r.hostRemediationTimedOut(ctx, remediationMgr, host, dryRun)
+		}
+
+		r.Log.Info("Host remediation done")
+		remediationMgr.UnsetFinalizer()
+		remediationMgr.SetRemediationPhase(infrav1.PhaseSucceeded)
+		return ctrl.Result{}, nil
+	}
+
+	// nothing to do anymore
+	return ctrl.Result{}, nil
+}
+
+// hostRemediationTimedOut retries the remediation of a host without Machine
+// which did not power on again in time, until the retry limit is reached. The
+// remediation then fails and the host is marked unhealthy.
+func (r *Metal3RemediationReconciler) hostRemediationTimedOut(ctx context.Context,
+	remediationMgr baremetal.RemediationManagerInterface, host *bmov1alpha1.BareMetalHost, dryRun bool,
+) (ctrl.Result, error) {
+	if remediationMgr.RetryLimitIsSet() && !remediationMgr.HasReachRetryLimit() {
+		r.Log.Info("Remediation timed out, will retry")
+		remediationMgr.SetRemediationPhase(infrav1.PhaseRunning)
+		now := metav1.Now()
+		remediationMgr.SetLastRemediationTime(&now)
+		remediationMgr.IncreaseRetryCount()
+		return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+	}
+
+	r.Log.Info("Remediation timed out and retry limit reached")
+	if dryRun {
+		r.Log.Info("Dry run: would set the unhealthy annotation on the host", "host", host.Name)
+	} else if err := remediationMgr.SetUnhealthyAnnotation(ctx); err != nil {
+		r.Log.Error(err, "error setting unhealthy annotation")
+		return ctrl.Result{}, errors.Wrapf(err, "error setting unhealthy annotation")
+	}
+	remediationMgr.RecordEvent(corev1.EventTypeWarning, "RemediationFailed",
+		"Host %s did not power on again before the remediation timed out", host.Name)
+	remediationMgr.UnsetFinalizer()
+	remediationMgr.SetRemediationPhase(infrav1.PhaseFailed)
+	return ctrl.Result{}, nil
+}
+
+// reconcileHostGone handles a remediation whose unhealthy host cannot be found. Once the
+// host has been missing for longer than HostGoneTimeout, the remediation is moved to the
+// terminal HostGone phase and, if deleteMachine is set, the Machine is handed over to
+// Cluster API for deletion.
+func (r *Metal3RemediationReconciler) reconcileHostGone(ctx context.Context,
+	remediationMgr baremetal.RemediationManagerInterface, deleteMachine bool, dryRun bool,
+) (ctrl.Result, error) {
+	if remediationMgr.GetRemediationPhase() == infrav1.PhaseHostGone {
+		// nothing to do anymore
+		return ctrl.Result{}, nil
+	}
+
+	timedOut, requeueAfter := remediationMgr.HostGoneTimedOut(baremetal.HostGoneTimeout)
+	if !timedOut {
+		r.Log.Info("Unhealthy host not found, waiting for it to reappear")
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	r.Log.Info("Unhealthy host is gone, stopping remediation")
+	if deleteMachine && dryRun {
+		r.Log.Info("Dry run: would hand the Machine over to Cluster API for deletion")
+	} else if deleteMachine {
+		// Setting the OwnerRemediatedCondition moves control to the CAPI machine
+		// controller, which handles the Machine deletion
+		if err := remediationMgr.SetOwnerRemediatedConditionNew(ctx); err != nil {
+			r.Log.Error(err, "error setting cluster api conditions")
+			return ctrl.Result{}, errors.Wrapf(err, "error setting cluster api conditions")
+		}
+	}
+	remediationMgr.UnsetFinalizer()
+	remediationMgr.SetRemediationPhase(infrav1.PhaseHostGone)
+	return ctrl.Result{}, nil
+}
+
+// remediateQuarantineStrategy executes the remediation using the quarantine
+// strategy. The node is cordoned and the host marked unhealthy so that it is
+// kept for investigation, no power action is taken. The remediation then stays
+// in the terminal Quarantined phase.
+func (r *Metal3RemediationReconciler) remediateQuarantineStrategy(ctx context.Context,
+	remediationMgr baremetal.RemediationManagerInterface, dryRun bool,
+) (ctrl.Result, error) {
+	if remediationMgr.GetRemediationPhase() == infrav1.PhaseQuarantined {
+		// nothing to do anymore
+		return ctrl.Result{}, nil
+	}
+
+	if dryRun {
+		r.Log.Info("Dry run: would cordon the node and set the unhealthy annotation on the host")
+	} else if err := r.quarantine(ctx, remediationMgr); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	now := metav1.Now()
+	remediationMgr.SetLastRemediationTime(&now)
+	remediationMgr.SetRemediationPhase(infrav1.PhaseQuarantined)
+	return ctrl.Result{}, nil
+}
+
+// quarantine cordons the node and sets the unhealthy annotation on the host.
+func (r *Metal3RemediationReconciler) quarantine(ctx context.Context,
+	remediationMgr baremetal.RemediationManagerInterface,
+) error {
+	r.Log.Info("Quarantining the node")
+	if err := remediationMgr.CordonNode(ctx); err != nil {
+		r.Log.Error(err, "error cordoning node")
+		return errors.Wrap(err, "error cordoning node")
+	}
+
+	// Set the unhealthy annotation on the BMH, this prevents it from being
+	// selected as a host once released.
+	if err := remediationMgr.SetUnhealthyAnnotation(ctx); err != nil {
+		r.Log.Error(err, "error setting unhealthy annotation")
+		return errors.Wrapf(err, "error setting unhealthy annotation")
+	}
+	return nil
+}
+
+// remediateReassociateStrategy executes the remediation using the reassociate
+// strategy. The host is fenced, i.e. marked unhealthy and powered off, then
+// the node is deleted and the Metal3Machine associated with another host,
+// preserving the Machine. The Machine is handed over to Cluster API for
+// deletion if the host is not powered off or no other host takes over before
+// the remediation timeout.
+func (r *Metal3RemediationReconciler) remediateReassociateStrategy(ctx context.Context,
+	remediationMgr baremetal.RemediationManagerInterface, host *bmov1alpha1.BareMetalHost, dryRun bool,
+) (ctrl.Result, error) {
+	switch remediationMgr.GetRemediationPhase() {
+	case "":
+		if dryRun {
+			r.Log.Info("Dry run: would fence the host", "host", host.Name)
+		} else if err := r.fenceHost(ctx, remediationMgr, host); err != nil {
+			return ctrl.Result{}, err
+		}
+		now := metav1.Now()
+		remediationMgr.SetLastRemediationTime(&now)
+		remediationMgr.SetRemediationPhase(infrav1.PhaseRunning)
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+
+	case infrav1.PhaseRunning:
+		// A host fenced in a dry run is never powered off.
+		if dryRun {
+			r.Log.Info("Dry run: would delete the node and associate the Metal3Machine with another host",
+				"host", host.Name)
+			remediationMgr.SetRemediationPhase(infrav1.PhaseReassociating)
+			return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+		}
+		if on, err := remediationMgr.IsPoweredOn(ctx); err != nil {
+			r.Log.Error(err, "error getting power status")
+			return ctrl.Result{}, errors.Wrap(err, "error getting power status")
+		} else if on {
+			return r.reassociationTimedOut(ctx, remediationMgr, dryRun)
+		}
+
+		// As with the reboot strategy, the node is only deleted once the host
+		// is powered off. The deletion is best effort, the new host joins the
+		// cluster anyway.
+		if clusterClient, err := remediationMgr.GetClusterClient(ctx); err != nil {
+			r.Log.Info("Unable to reach the workload cluster, not deleting the node", "error", err.Error())
+		} else if node, err := remediationMgr.GetNode(ctx, clusterClient); err != nil {
+			r.Log.Info("Unable to get the node, not deleting it", "error", err.Error())
+		} else if node != nil {
+			r.Log.Info("Deleting node")
+			if err := remediationMgr.DeleteNode(ctx, clusterClient, node); err != nil {
+				r.Log.Error(err, "error deleting node")
+				return ctrl.Result{}, errors.Wrap(err, "error deleting node")
+			}
+		}
+
+		if err := remediationMgr.RequestReassociation(ctx, host); err != nil {
+			r.Log.Error(err, "error requesting the reassociation")
+			return ctrl.Result{}, errors.Wrap(err, "error requesting the reassociation")
+		}
+		remediationMgr.SetRemediationPhase(infrav1.PhaseReassociating)
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+
+	case infrav1.PhaseReassociating:
+		// The remediation is deleted once the Machine is healthy on its new host.
+		return r.reassociationTimedOut(ctx, remediationMgr, dryRun)
+	}
+
+	// nothing to do anymore
+	return ctrl.Result{}, nil
+}
+
+// fenceHost marks the host unhealthy and powers it off.
+func (r *Metal3RemediationReconciler) fenceHost(ctx context.Context,
+	remediationMgr baremetal.RemediationManagerInterface, host *bmov1alpha1.BareMetalHost,
+) error {
+	// The unhealthy annotation prevents the host from being chosen again.
+	r.Log.Info("Fencing the host", "host", host.Name)
+	if err := remediationMgr.SetUnhealthyAnnotation(ctx); err != nil {
+		r.Log.Error(err, "error setting unhealthy annotation")
+		return errors.Wrapf(err, "error setting unhealthy annotation")
+	}
+	if ok, err := remediationMgr.IsPowerOffRequested(ctx); err != nil {
+		r.Log.Error(err, "error getting poweroff annotation status")
+		return errors.Wrap(err, "error getting poweroff annotation status")
+	} else if !ok {
+		if err := remediationMgr.SetPowerOffAnnotation(ctx); err != nil {
+			r.Log.Error(err, "error setting poweroff annotation")
+			return errors.Wrap(err, "error setting poweroff annotation")
+		}
+	}
+	return nil
+}
+
+// reassociationTimedOut hands the Machine over to Cluster API for deletion
+// once the remediation timed out, and requeues otherwise.
+func (r *Metal3RemediationReconciler) reassociationTimedOut(ctx context.Context,
+	remediationMgr baremetal.RemediationManagerInterface, dryRun bool,
+) (ctrl.Result, error) {
+	timedOut, _ := remediationMgr.TimeToRemediate(remediationMgr.GetTimeout().Duration)
+	if !timedOut {
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	r.Log.Info("Reassociation timed out")
+	if dryRun {
+		r.Log.Info("Dry run: would hand the Machine over to Cluster API for deletion")
+	} else if err := remediationMgr.SetOwnerRemediatedConditionNew(ctx); err != nil {
+		r.Log.Error(err, "error setting cluster api conditions")
+		return ctrl.Result{}, errors.Wrapf(err, "error setting cluster api conditions")
+	}
+	remediationMgr.SetRemediationPhase(infrav1.PhaseDeleting)
+	return ctrl.Result{}, nil
+}
+
 // remediateRebootStrategy executes the remediation using the reboot strategy.
 // Returns nil, nil when reconcile can continue.
 // Return a Result and optionally an error when reconcile should return.
 func (r *Metal3RemediationReconciler) remediateRebootStrategy(ctx context.Context,
 	remediationMgr baremetal.RemediationManagerInterface, clusterClient v1.CoreV1Interface,
-	node *corev1.Node) (ctrl.Result, error) {
+	node *corev1.Node, dryRun bool) (ctrl.Result, error) {
 	// add finalizer
 	if !remediationMgr.HasFinalizer() {
 		remediationMgr.SetFinalizer()
 		return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
 	}
 
+	// A dry run never powers the host off, so it moves on as if the host had
+	// been rebooted and the node fenced.
+	if dryRun {
+		r.Log.Info("Dry run: would set the poweroff annotation on the host, fence the node and power the host on again")
+		remediationMgr.IncreaseRebootCount()
+		remediationMgr.SetRemediationPhase(infrav1.PhaseWaiting)
+		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+	}
+
+	// Keep new workload away from the node while it is remediated. The taint
+	// is best effort, the node may well be unreachable.
+	if node != nil {
+		if err := remediationMgr.AddRemediationTaint(ctx, clusterClient, node); err != nil {
+			r.Log.Info("Unable to add the remediation taint, continuing", "node", node.Name, "error", err.Error())
+		}
+	}
+
 	// power off if needed
 	if ok, err := remediationMgr.IsPowerOffRequested(ctx); err != nil {
 		r.Log.Error(err, "error getting poweroff annotation status")
@@ -320,6 +767,7 @@ func (r *Metal3RemediationReconciler) remediateRebootStrategy(ctx context.Contex
 			r.Log.Error(err, "error setting poweroff annotation")
 			return ctrl.Result{}, errors.Wrap(err, "error setting poweroff annotation")
 		}
+		remediationMgr.IncreaseRebootCount()
 
 		// done for now, wait a bit before checking if we are powered off already
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
@@ -330,6 +778,20 @@ func (r *Metal3RemediationReconciler) remediateRebootStrategy(ctx context.Contex
 		r.Log.Error(err, "error getting power status")
 		return ctrl.Result{}, errors.Wrap(err, "error getting power status")
 	} else if on {
+		// Clear a stale poweroff annotation instead of waiting forever, and let
+		// the waiting phase handle the remediation timeout and retries.
+		if baremetal.RebootAnnotationTimeout > 0 {
+			remediationMgr.RecordRebootRequestedTime()
+			if remediationMgr.RebootAnnotationExpired(baremetal.RebootAnnotationTimeout) {
+				r.Log.Info("Host not powered off in time, removing the poweroff annotation")
+				if err := remediationMgr.RemovePowerOffAnnotation(ctx); err != nil {
+					r.Log.Error(err, "error removing poweroff annotation")
+					return ctrl.Result{}, errors.Wrap(err, "error removing poweroff annotation")
+				}
+				remediationMgr.SetRemediationPhase(infrav1.PhaseWaiting)
+				return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
+			}
+		}
 		// wait a bit before checking again if we are powered off already
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 	}
@@ -379,6 +841,20 @@ func (r *Metal3RemediationReconciler) remediateRebootStrategy(ctx context.Contex
 	return ctrl.Result{RequeueAfter: 5 * time.Second}, nil
 }
 
+// removeRemediationTaint removes the remediation taint from the node of a
+// remediation that is over. Failures are only logged, an unreachable node
+// must not block the terminal phases.
+func (r *Metal3RemediationReconciler) removeRemediationTaint(ctx context.Context,
+	remediationMgr baremetal.RemediationManagerInterface, clusterClient v1.CoreV1Interface,
+	node *corev1.Node, dryRun bool) {
+	if node == nil || dryRun {
+		return
+	}
+	if err := remediationMgr.RemoveRemediationTaint(ctx, clusterClient, node); err != nil {
+		r.Log.Info("Unable to remove the remediation taint", "node", node.Name, "error", err.Error())
+	}
+}
+
 // Returns whether annotations or labels were set / updated.
 func (r *Metal3RemediationReconciler) backupNode(remediationMgr baremetal.RemediationManagerInterface,
 	node *corev1.Node) bool {
@@ -488,5 +964,111 @@ func (r *Metal3RemediationReconciler) SetupWithManager(_ context.Context, mgr ct
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&infrav1.Metal3Remediation{}).
 		WithOptions(options).
+		// The timed requeues of the remediation remain as a backstop if a
+		// host event is missed.
+		Watches(
+			&bmov1alpha1.BareMetalHost{},
+			handler.EnqueueRequestsFromMapFunc(r.BareMetalHostToMetal3Remediations),
+			builder.WithPredicates(hostPowerChangedPredicate()),
+		).
 		Complete(r)
 }
+
+// hostPowerChangedPredicate only passes the BareMetalHost updates changing the
+// power state of the host.
+func hostPowerChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldHost, ok := e.ObjectOld.(*bmov1alpha1.BareMetalHost)
+			if !ok {
+				return false
+			}
+			newHost, ok := e.ObjectNew.(*bmov1alpha1.BareMetalHost)
+			if !ok {
+				return false
+			}
+			return oldHost.Status.PoweredOn != newHost.Status.PoweredOn
+		},
+	}
+}
+
+// BareMetalHostToMetal3Remediations will return reconcile requests for the
+// Metal3Remediations of a BareMetalHost, either referencing the host directly
+// or owned by the Machine of the Metal3Machine consuming the host.
+func (r *Metal3RemediationReconciler) BareMetalHostToMetal3Remediations(ctx context.Context, obj client.Object) []ctrl.Request {
+	host, ok := obj.(*bmov1alpha1.BareMetalHost)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a BareMetalHost but got a %T", obj),
+			"failed to get Metal3Remediations for BareMetalHost",
+		)
+		return []ctrl.Request{}
+	}
+
+	// Find the Machine owning the Metal3Machine consuming the host
+	machine := types.NamespacedName{}
+	if host.Spec.ConsumerRef != nil &&
+		host.Spec.ConsumerRef.Kind == Metal3Machine &&
+		host.Spec.ConsumerRef.GroupVersionKind().Group == infrav1.GroupVersion.Group {
+		metal3Machine := &infrav1.Metal3Machine{}
+		key := client.ObjectKey{
+			Name:      host.Spec.ConsumerRef.Name,
+			Namespace: host.Spec.ConsumerRef.Namespace,
+		}
+		if err := r.Client.Get(ctx, key, metal3Machine); err == nil {
+			for _, ownerRef := range metal3Machine.OwnerReferences {
+				if ownerRef.Kind == "Machine" && ownerRef.APIVersion == clusterv1.GroupVersion.String() {
+					machine = types.NamespacedName{
+						Name:      ownerRef.Name,
+						Namespace: metal3Machine.Namespace,
+					}
+					break
+				}
+			}
+		}
+	}
+
+	remediations := infrav1.Metal3RemediationList{}
+	if err := r.Client.List(ctx, &remediations); err != nil {
+		r.Log.Error(err, "failed to list Metal3Remediations")
+		return []ctrl.Request{}
+	}
+
+	requests := []ctrl.Request{}
+	for _, remediation := range remediations.Items {
+		if remediationTargetsHost(&remediation, host, machine) {
+			requests = append(requests, ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      remediation.Name,
+					Namespace: remediation.Namespace,
+				},
+			})
+		}
+	}
+	return requests
+}
+
+// remediationTargetsHost returns true if the Metal3Remediation references the
+// host, or is owned by the Machine of the host.
+func remediationTargetsHost(remediation *infrav1.Metal3Remediation,
+	host *bmov1alpha1.BareMetalHost, machine types.NamespacedName,
+) bool {
+	if hostRef := remediation.Spec.HostRef; hostRef != nil {
+		hostNamespace := hostRef.Namespace
+		if hostNamespace == "" {
+			hostNamespace = remediation.Namespace
+		}
+		return hostRef.Name == host.Name && hostNamespace == host.Namespace
+	}
+	if machine.Name == "" || remediation.Namespace != machine.Namespace {
+		return false
+	}
+	for _, ownerRef := range remediation.OwnerReferences {
+		if ownerRef.Kind == "Machine" && ownerRef.Name == machine.Name {
+			return true
+		}
+	}
+	return false
+}
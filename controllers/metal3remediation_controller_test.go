@@ -37,12 +37,15 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 var (
 	ownerMachineErrorMsg     = "metal3Remediation's owner Machine could not be retrieved"
 	ownerMachineNotSetMsg    = "metal3Remediation's owner Machine not set"
 	metal3MachineNotFoundMsg = "metal3machine not found"
+	hostNotFoundMsg          = "unable to find the host to remediate"
+	machineSelectorErrorMsg  = "metal3Remediation's Machine selector could not be resolved"
 )
 
 type reconcileNormalRemediationTestCase struct {
@@ -51,18 +54,49 @@ type reconcileNormalRemediationTestCase struct {
 	GetUnhealthyHostFails        bool
 	GetRemediationTypeFails      bool
 	HostStatusOffline            bool
+	HostPermanentlyFailed        bool
 	RemediationPhase             string
 	IsFinalizerSet               bool
 	IsPowerOffRequested          bool
 	IsPoweredOn                  bool
 	IsNodeForbidden              bool
+	IsNodeUnhealthy              bool
 	IsNodeBackedUp               bool
 	IsNodeDeleted                bool
 	IsTimedOut                   bool
 	IsRetryLimitReached          bool
+	IsEscalationReached          bool
 	IsOutOfServiceTaintSupported bool
 	IsOutOfServiceTaintAdded     bool
 	IsNodeDrained                bool
+	IsHostUnhealthy              bool
+	IsUnhealthyAnnotationKept    bool
+	IsRebootAnnotationExpired    bool
+	RebootAnnotationTimeoutZero  bool
+	IsHostGone                   bool
+	IsHostGoneTimedOut           bool
+	HostGoneDeleteMachine        bool
+	IsQuarantine                 bool
+	IsReassociate                bool
+	CordonNodeFails              bool
+	RemediationTaintFails        bool
+}
+
+type reconcileDryRunRemediationTestCase struct {
+	ExpectRequeue         bool
+	HasMachine            bool
+	HostStatusOffline     bool
+	HostPermanentlyFailed bool
+	IsQuarantine          bool
+	IsReassociate         bool
+	RemediationPhase      string
+	IsTimedOut            bool
+	IsRetryLimitReached   bool
+	IsEscalationReached   bool
+	IsHostGone            bool
+	IsHostGoneTimedOut    bool
+	IsPowerOffRequested   bool
+	IsNodeHealthy         bool
 }
 
 type reconcileRemediationTestCase struct {
@@ -80,17 +114,56 @@ type unMarshallRemediationTestCase struct {
 	Marshaled   string
 	ExpectError bool
 }
+type hostToRemediationsTestCase struct {
+	Host                 *bmov1alpha1.BareMetalHost
+	Metal3Machine        *infrav1.Metal3Machine
+	Remediations         []*infrav1.Metal3Remediation
+	ExpectedRemediations []string
+}
+
+func setHostGoneExpectations(m *baremetal_mocks.MockRemediationManagerInterface,
+	tc reconcileNormalRemediationTestCase) {
+	notFound := apierrors.NewNotFound(bmov1alpha1.GroupVersion.WithResource("baremetalhosts").GroupResource(), "foo_bmh")
+	m.EXPECT().GetUnhealthyHost(context.TODO()).Return(nil, nil, notFound)
+	m.EXPECT().GetRemediationPhase().Return(tc.RemediationPhase)
+	if tc.RemediationPhase == infrav1.PhaseHostGone {
+		return
+	}
+
+	m.EXPECT().HostGoneTimedOut(baremetal.HostGoneTimeout).Return(tc.IsHostGoneTimedOut, time.Minute)
+	if !tc.IsHostGoneTimedOut {
+		return
+	}
+	if tc.HostGoneDeleteMachine {
+		m.EXPECT().SetOwnerRemediatedConditionNew(context.TODO())
+	} else {
+		m.EXPECT().SetOwnerRemediatedConditionNew(gomock.Any()).MaxTimes(0)
+	}
+	m.EXPECT().UnsetFinalizer()
+	m.EXPECT().SetRemediationPhase(infrav1.PhaseHostGone)
+}
 
 func setReconcileNormalRemediationExpectations(ctrl *gomock.Controller,
 	tc reconcileNormalRemediationTestCase) *baremetal_mocks.MockRemediationManagerInterface {
 	m := baremetal_mocks.NewMockRemediationManagerInterface(ctrl)
 
 	bmh := &bmov1alpha1.BareMetalHost{}
+	if tc.HostPermanentlyFailed {
+		bmh.Annotations = map[string]string{infrav1.PermanentlyFailedAnnotation: ""}
+	}
+	if tc.IsHostUnhealthy {
+		bmh.Annotations = map[string]string{infrav1.UnhealthyAnnotation: "capm3/UnhealthyNode"}
+	}
 	if tc.GetUnhealthyHostFails {
 		m.EXPECT().GetUnhealthyHost(context.TODO()).Return(nil, nil, fmt.Errorf("can't find foo_bmh"))
 		return m
 	}
+	if tc.IsHostGone {
+		setHostGoneExpectations(m, tc)
+		return m
+	}
 	m.EXPECT().GetUnhealthyHost(context.TODO()).Return(bmh, nil, nil)
+	m.EXPECT().ResetHostNotFoundTime()
 
 	// If user has set bmh.Spec.Online to false, do not try to remediate the host and set remediation phase to failed
 	if tc.HostStatusOffline {
@@ -99,6 +172,10 @@ func setReconcileNormalRemediationExpectations(ctrl *gomock.Controller,
 		return m
 	}
 	m.EXPECT().OnlineStatus(bmh).Return(true)
+	if tc.HostPermanentlyFailed {
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseFailed)
+		return m
+	}
 
 	node := &corev1.Node{
 		TypeMeta: metav1.TypeMeta{},
@@ -121,15 +198,88 @@ func setReconcileNormalRemediationExpectations(ctrl *gomock.Controller,
 		}
 	}
 
+	expectRemoveRemediationTaint := func() {
+		if tc.IsNodeForbidden || tc.IsNodeDeleted {
+			return
+		}
+		if tc.RemediationTaintFails {
+			m.EXPECT().RemoveRemediationTaint(context.TODO(), gomock.Any(), node).Return(fmt.Errorf("node unreachable"))
+		} else {
+			m.EXPECT().RemoveRemediationTaint(context.TODO(), gomock.Any(), node).Return(nil)
+		}
+	}
+
 	if tc.GetRemediationTypeFails {
 		const wrongRemediationStrategy infrav1.RemediationType = "wrongRemediationStrategy"
 		m.EXPECT().GetRemediationType().Return(wrongRemediationStrategy)
 		return m
 	}
 
+	// The quarantine strategy takes no power action, any call to the power
+	// related methods of the mock fails the test.
+	if tc.IsQuarantine {
+		m.EXPECT().GetRemediationType().Return(infrav1.QuarantineRemediationStrategy)
+		m.EXPECT().GetRemediationPhase().Return(tc.RemediationPhase)
+		if tc.RemediationPhase == infrav1.PhaseQuarantined {
+			return m
+		}
+		if tc.CordonNodeFails {
+			m.EXPECT().CordonNode(context.TODO()).Return(fmt.Errorf("can't cordon mynode"))
+			return m
+		}
+		m.EXPECT().CordonNode(context.TODO()).Return(nil)
+		m.EXPECT().SetUnhealthyAnnotation(context.TODO()).Return(nil)
+		m.EXPECT().SetLastRemediationTime(gomock.Any())
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseQuarantined)
+		return m
+	}
+
+	// The reassociate strategy fences the host and hands it over to the
+	// Metal3Machine controller, without restoring the node.
+	if tc.IsReassociate {
+		m.EXPECT().GetRemediationType().Return(infrav1.ReassociateRemediationStrategy)
+		m.EXPECT().GetRemediationPhase().Return(tc.RemediationPhase)
+		m.EXPECT().HasFinalizer().MaxTimes(0)
+		switch tc.RemediationPhase {
+		case "":
+			m.EXPECT().SetUnhealthyAnnotation(context.TODO()).Return(nil)
+			m.EXPECT().IsPowerOffRequested(context.TODO()).Return(tc.IsPowerOffRequested, nil)
+			if !tc.IsPowerOffRequested {
+				m.EXPECT().SetPowerOffAnnotation(context.TODO()).Return(nil)
+			}
+			m.EXPECT().SetLastRemediationTime(gomock.Any())
+			m.EXPECT().SetRemediationPhase(infrav1.PhaseRunning)
+			return m
+		case infrav1.PhaseRunning:
+			m.EXPECT().IsPoweredOn(context.TODO()).Return(tc.IsPoweredOn, nil)
+			if !tc.IsPoweredOn {
+				expectGetNode()
+				if !tc.IsNodeForbidden && !tc.IsNodeDeleted {
+					m.EXPECT().DeleteNode(context.TODO(), gomock.Any(), node).Return(nil)
+				}
+				m.EXPECT().RequestReassociation(context.TODO(), bmh).Return(nil)
+				m.EXPECT().SetRemediationPhase(infrav1.PhaseReassociating)
+				return m
+			}
+		}
+		m.EXPECT().GetTimeout().Return(&metav1.Duration{Duration: 600 * time.Second})
+		m.EXPECT().TimeToRemediate(600*time.Second).Return(tc.IsTimedOut, time.Duration(0))
+		if tc.IsTimedOut {
+			m.EXPECT().SetOwnerRemediatedConditionNew(context.TODO()).Return(nil)
+			m.EXPECT().SetRemediationPhase(infrav1.PhaseDeleting)
+		}
+		return m
+	}
+
 	m.EXPECT().GetRemediationType().Return(infrav1.RebootRemediationStrategy)
 	m.EXPECT().GetRemediationPhase().Return(tc.RemediationPhase).MinTimes(1)
 
+	// The unhealthy annotation is cleared in any phase once the machine stayed
+	// healthy for the grace period.
+	if tc.IsHostUnhealthy && tc.RemediationPhase != "" {
+		m.EXPECT().ClearUnhealthyAnnotation(context.TODO()).Return(!tc.IsUnhealthyAnnotationKept, nil)
+	}
+
 	switch tc.RemediationPhase {
 	case "":
 		m.EXPECT().SetRemediationPhase(infrav1.PhaseRunning)
@@ -145,14 +295,34 @@ func setReconcileNormalRemediationExpectations(ctrl *gomock.Controller,
 			return m
 		}
 
+		if !tc.IsNodeForbidden && !tc.IsNodeDeleted {
+			if tc.RemediationTaintFails {
+				m.EXPECT().AddRemediationTaint(context.TODO(), gomock.Any(), node).Return(fmt.Errorf("node unreachable"))
+			} else {
+				m.EXPECT().AddRemediationTaint(context.TODO(), gomock.Any(), node).Return(nil)
+			}
+		}
+
 		m.EXPECT().IsPowerOffRequested(context.TODO()).Return(tc.IsPowerOffRequested, nil)
 		if !tc.IsPowerOffRequested {
 			m.EXPECT().SetPowerOffAnnotation(context.TODO())
+			m.EXPECT().IncreaseRebootCount()
 			return m
 		}
 
 		m.EXPECT().IsPoweredOn(context.TODO()).Return(tc.IsPoweredOn, nil)
 		if tc.IsPoweredOn {
+			if tc.RebootAnnotationTimeoutZero {
+				m.EXPECT().RebootAnnotationExpired(gomock.Any()).MaxTimes(0)
+				m.EXPECT().RemovePowerOffAnnotation(gomock.Any()).MaxTimes(0)
+				return m
+			}
+			m.EXPECT().RecordRebootRequestedTime()
+			m.EXPECT().RebootAnnotationExpired(baremetal.RebootAnnotationTimeout).Return(tc.IsRebootAnnotationExpired)
+			if tc.IsRebootAnnotationExpired {
+				m.EXPECT().RemovePowerOffAnnotation(context.TODO())
+				m.EXPECT().SetRemediationPhase(infrav1.PhaseWaiting)
+			}
 			return m
 		}
 		if tc.IsOutOfServiceTaintSupported {
@@ -199,6 +369,9 @@ func setReconcileNormalRemediationExpectations(ctrl *gomock.Controller,
 				if tc.IsOutOfServiceTaintAdded {
 					m.EXPECT().HasOutOfServiceTaint(gomock.Any()).Return(true)
 					m.EXPECT().RemoveOutOfServiceTaint(context.TODO(), gomock.Any(), gomock.Any()).Return(nil)
+					m.EXPECT().NodeIsHealthy(context.TODO(), gomock.Any(), gomock.Any()).Return(true, nil)
+					m.EXPECT().RemoveRemediationTaint(context.TODO(), gomock.Any(), node).Return(nil)
+					m.EXPECT().RecordEvent(corev1.EventTypeNormal, "RemediationSucceeded", gomock.Any(), gomock.Any(), gomock.Any())
 					m.EXPECT().UnsetFinalizer()
 					return m
 				}
@@ -206,12 +379,18 @@ func setReconcileNormalRemediationExpectations(ctrl *gomock.Controller,
 				if !tc.IsNodeDeleted {
 					m.EXPECT().GetNodeBackupAnnotations().Return("{\"foo\":\"bar\"}", "{\"answer\":\"42\"}")
 					m.EXPECT().UpdateNode(context.TODO(), gomock.Any(), gomock.Any())
-					m.EXPECT().RemoveNodeBackupAnnotations()
-					m.EXPECT().UnsetFinalizer()
-					return m
+					m.EXPECT().NodeIsHealthy(context.TODO(), gomock.Any(), gomock.Any()).Return(!tc.IsNodeUnhealthy, nil)
+					if !tc.IsNodeUnhealthy {
+						m.EXPECT().RemoveRemediationTaint(context.TODO(), gomock.Any(), node).Return(nil)
+						m.EXPECT().RecordEvent(corev1.EventTypeNormal, "RemediationSucceeded", gomock.Any(), gomock.Any(), gomock.Any())
+						m.EXPECT().RemoveNodeBackupAnnotations()
+						m.EXPECT().UnsetFinalizer()
+						return m
+					}
 				}
 				if tc.IsNodeForbidden {
 					m.EXPECT().UnsetFinalizer()
+					m.EXPECT().RecordEvent(corev1.EventTypeNormal, "RemediationSucceeded", gomock.Any(), gomock.Any())
 					return m
 				}
 			}
@@ -220,6 +399,11 @@ func setReconcileNormalRemediationExpectations(ctrl *gomock.Controller,
 		m.EXPECT().GetTimeout().Return(&metav1.Duration{Duration: time.Second})
 		m.EXPECT().TimeToRemediate(gomock.Any()).Return(tc.IsTimedOut, time.Second)
 		if tc.IsTimedOut {
+			m.EXPECT().HasReachRebootsBeforeEscalation().Return(tc.IsEscalationReached)
+			if tc.IsEscalationReached {
+				m.EXPECT().Escalate()
+				return m
+			}
 			m.EXPECT().RetryLimitIsSet().Return(true)
 			m.EXPECT().HasReachRetryLimit().Return(tc.IsRetryLimitReached)
 			if !tc.IsRetryLimitReached {
@@ -235,9 +419,257 @@ func setReconcileNormalRemediationExpectations(ctrl *gomock.Controller,
 
 	case infrav1.PhaseDeleting:
 		expectGetNode()
+		expectRemoveRemediationTaint()
 
 	case infrav1.PhaseFailed:
 		expectGetNode()
+		expectRemoveRemediationTaint()
+	}
+	return m
+}
+
+func setReconcileHostRemediationExpectations(ctrl *gomock.Controller,
+	tc reconcileNormalRemediationTestCase) *baremetal_mocks.MockRemediationManagerInterface {
+	m := baremetal_mocks.NewMockRemediationManagerInterface(ctrl)
+
+	bmh := &bmov1alpha1.BareMetalHost{}
+	if tc.HostPermanentlyFailed {
+		bmh.Annotations = map[string]string{infrav1.PermanentlyFailedAnnotation: ""}
+	}
+	if tc.GetUnhealthyHostFails {
+		m.EXPECT().GetUnhealthyHost(context.TODO()).Return(nil, nil, fmt.Errorf("can't find foo_bmh"))
+		return m
+	}
+	if tc.IsHostGone {
+		setHostGoneExpectations(m, tc)
+		return m
+	}
+	m.EXPECT().GetUnhealthyHost(context.TODO()).Return(bmh, nil, nil)
+	m.EXPECT().ResetHostNotFoundTime()
+
+	if tc.HostStatusOffline {
+		m.EXPECT().OnlineStatus(bmh).Return(false)
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseFailed)
+		return m
+	}
+	m.EXPECT().OnlineStatus(bmh).Return(true)
+	if tc.HostPermanentlyFailed {
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseFailed)
+		return m
+	}
+
+	if tc.IsQuarantine {
+		m.EXPECT().GetRemediationType().Return(infrav1.QuarantineRemediationStrategy)
+		m.EXPECT().GetRemediationPhase().Return(tc.RemediationPhase)
+		if tc.RemediationPhase != infrav1.PhaseFailed {
+			m.EXPECT().RecordEvent(corev1.EventTypeWarning, "UnsupportedRemediationStrategy",
+				gomock.Any(), gomock.Any(), gomock.Any())
+			m.EXPECT().SetRemediationPhase(infrav1.PhaseFailed)
+		}
+		return m
+	}
+	m.EXPECT().GetRemediationType().Return(infrav1.RebootRemediationStrategy)
+	m.EXPECT().GetRemediationPhase().Return(tc.RemediationPhase)
+
+	// There is no Machine, so the cluster and node must never be looked up
+	m.EXPECT().GetClusterClient(gomock.Any()).MaxTimes(0)
+	m.EXPECT().GetNode(gomock.Any(), gomock.Any()).MaxTimes(0)
+	m.EXPECT().SetOwnerRemediatedConditionNew(gomock.Any()).MaxTimes(0)
+
+	switch tc.RemediationPhase {
+	case "":
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseRunning)
+		m.EXPECT().SetLastRemediationTime(gomock.Any())
+
+	case infrav1.PhaseRunning:
+		m.EXPECT().HasFinalizer().Return(tc.IsFinalizerSet)
+		if !tc.IsFinalizerSet {
+			m.EXPECT().SetFinalizer().Return()
+			return m
+		}
+
+		m.EXPECT().IsPowerOffRequested(context.TODO()).Return(tc.IsPowerOffRequested, nil)
+		if !tc.IsPowerOffRequested {
+			m.EXPECT().SetPowerOffAnnotation(context.TODO())
+			m.EXPECT().IncreaseRebootCount()
+			return m
+		}
+
+		m.EXPECT().IsPoweredOn(context.TODO()).Return(tc.IsPoweredOn, nil)
+		if tc.IsPoweredOn {
+			if tc.RebootAnnotationTimeoutZero {
+				m.EXPECT().RebootAnnotationExpired(gomock.Any()).MaxTimes(0)
+				m.EXPECT().RemovePowerOffAnnotation(gomock.Any()).MaxTimes(0)
+				return m
+			}
+			m.EXPECT().RecordRebootRequestedTime()
+			m.EXPECT().RebootAnnotationExpired(baremetal.RebootAnnotationTimeout).Return(tc.IsRebootAnnotationExpired)
+			if tc.IsRebootAnnotationExpired {
+				m.EXPECT().RemovePowerOffAnnotation(context.TODO())
+				m.EXPECT().SetRemediationPhase(infrav1.PhaseWaiting)
+			}
+			return m
+		}
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseWaiting)
+
+	case infrav1.PhaseWaiting:
+		m.EXPECT().IsPowerOffRequested(context.TODO()).Return(tc.IsPowerOffRequested, nil)
+		if tc.IsPowerOffRequested {
+			m.EXPECT().RemovePowerOffAnnotation(context.TODO())
+		}
+
+		m.EXPECT().IsPoweredOn(context.TODO()).Return(tc.IsPoweredOn, nil)
+		if !tc.IsPoweredOn {
+			m.EXPECT().GetTimeout().Return(&metav1.Duration{Duration: time.Second})
+			m.EXPECT().TimeToRemediate(gomock.Any()).Return(tc.IsTimedOut, time.Second)
+			if !tc.IsTimedOut {
+				return m
+			}
+			m.EXPECT().RetryLimitIsSet().Return(true)
+			m.EXPECT().HasReachRetryLimit().Return(tc.IsRetryLimitReached)
+			if !tc.IsRetryLimitReached {
+				m.EXPECT().SetRemediationPhase(infrav1.PhaseRunning)
+				m.EXPECT().SetLastRemediationTime(gomock.Any())
+				m.EXPECT().IncreaseRetryCount()
+				return m
+			}
+			m.EXPECT().SetUnhealthyAnnotation(context.TODO())
+			m.EXPECT().RecordEvent(corev1.EventTypeWarning, "RemediationFailed", gomock.Any(), gomock.Any())
+			m.EXPECT().UnsetFinalizer()
+			m.EXPECT().SetRemediationPhase(infrav1.PhaseFailed)
+			return m
+		}
+		m.EXPECT().UnsetFinalizer()
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseSucceeded)
+	}
+	return m
+}
+
+func setReconcileDryRunRemediationExpectations(ctrl *gomock.Controller,
+	tc reconcileDryRunRemediationTestCase) *baremetal_mocks.MockRemediationManagerInterface {
+	m := baremetal_mocks.NewMockRemediationManagerInterface(ctrl)
+
+	// A dry run only updates the remediation, any call acting on the host,
+	// the node or the Machine fails the test.
+	m.EXPECT().SetPowerOffAnnotation(gomock.Any()).MaxTimes(0)
+	m.EXPECT().RemovePowerOffAnnotation(gomock.Any()).MaxTimes(0)
+	m.EXPECT().SetUnhealthyAnnotation(gomock.Any()).MaxTimes(0)
+	m.EXPECT().ClearUnhealthyAnnotation(gomock.Any()).MaxTimes(0)
+	m.EXPECT().SetOwnerRemediatedConditionNew(gomock.Any()).MaxTimes(0)
+	m.EXPECT().CordonNode(gomock.Any()).MaxTimes(0)
+	m.EXPECT().UpdateNode(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(0)
+	m.EXPECT().DeleteNode(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(0)
+	m.EXPECT().AddRemediationTaint(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(0)
+	m.EXPECT().RemoveRemediationTaint(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(0)
+	m.EXPECT().AddOutOfServiceTaint(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(0)
+	m.EXPECT().RemoveOutOfServiceTaint(gomock.Any(), gomock.Any(), gomock.Any()).MaxTimes(0)
+	m.EXPECT().RequestReassociation(gomock.Any(), gomock.Any()).MaxTimes(0)
+
+	if tc.IsHostGone {
+		notFound := apierrors.NewNotFound(bmov1alpha1.GroupVersion.WithResource("baremetalhosts").GroupResource(), "foo_bmh")
+		m.EXPECT().GetUnhealthyHost(context.TODO()).Return(nil, nil, notFound)
+		m.EXPECT().GetRemediationPhase().Return(tc.RemediationPhase)
+		m.EXPECT().HostGoneTimedOut(baremetal.HostGoneTimeout).Return(tc.IsHostGoneTimedOut, time.Minute)
+		if tc.IsHostGoneTimedOut {
+			m.EXPECT().UnsetFinalizer()
+			m.EXPECT().SetRemediationPhase(infrav1.PhaseHostGone)
+		}
+		return m
+	}
+
+	bmh := &bmov1alpha1.BareMetalHost{}
+	if tc.HostPermanentlyFailed {
+		bmh.Annotations = map[string]string{infrav1.PermanentlyFailedAnnotation: ""}
+	}
+	m.EXPECT().GetUnhealthyHost(context.TODO()).Return(bmh, nil, nil)
+	m.EXPECT().ResetHostNotFoundTime()
+	m.EXPECT().OnlineStatus(bmh).Return(!tc.HostStatusOffline)
+	if tc.HostStatusOffline || tc.HostPermanentlyFailed {
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseFailed)
+		return m
+	}
+
+	if tc.IsQuarantine {
+		m.EXPECT().GetRemediationType().Return(infrav1.QuarantineRemediationStrategy)
+		m.EXPECT().GetRemediationPhase().Return(tc.RemediationPhase)
+		m.EXPECT().SetLastRemediationTime(gomock.Any())
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseQuarantined)
+		return m
+	}
+	if tc.IsReassociate {
+		m.EXPECT().GetRemediationType().Return(infrav1.ReassociateRemediationStrategy)
+		m.EXPECT().GetRemediationPhase().Return(tc.RemediationPhase)
+		switch tc.RemediationPhase {
+		case "":
+			m.EXPECT().SetLastRemediationTime(gomock.Any())
+			m.EXPECT().SetRemediationPhase(infrav1.PhaseRunning)
+		case infrav1.PhaseRunning:
+			m.EXPECT().SetRemediationPhase(infrav1.PhaseReassociating)
+		case infrav1.PhaseReassociating:
+			m.EXPECT().GetTimeout().Return(&metav1.Duration{Duration: time.Second})
+			m.EXPECT().TimeToRemediate(gomock.Any()).Return(tc.IsTimedOut, time.Second)
+			if tc.IsTimedOut {
+				m.EXPECT().SetRemediationPhase(infrav1.PhaseDeleting)
+			}
+		}
+		return m
+	}
+	m.EXPECT().GetRemediationType().Return(infrav1.RebootRemediationStrategy)
+	m.EXPECT().GetRemediationPhase().Return(tc.RemediationPhase).MinTimes(1)
+
+	if tc.RemediationPhase == "" {
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseRunning)
+		m.EXPECT().SetLastRemediationTime(gomock.Any())
+		return m
+	}
+
+	// The workload cluster is only read, to follow the health of the node.
+	if tc.HasMachine {
+		m.EXPECT().GetClusterClient(context.TODO())
+		m.EXPECT().GetNode(context.TODO(), gomock.Any()).Return(&corev1.Node{}, nil)
+	}
+
+	switch tc.RemediationPhase {
+	case infrav1.PhaseRunning:
+		m.EXPECT().HasFinalizer().Return(true)
+		m.EXPECT().IncreaseRebootCount()
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseWaiting)
+
+	case infrav1.PhaseWaiting:
+		m.EXPECT().IsPowerOffRequested(context.TODO()).Return(tc.IsPowerOffRequested, nil)
+		m.EXPECT().IsPoweredOn(context.TODO()).Return(true, nil)
+		if !tc.HasMachine {
+			m.EXPECT().UnsetFinalizer()
+			m.EXPECT().SetRemediationPhase(infrav1.PhaseSucceeded)
+			return m
+		}
+		m.EXPECT().HasFinalizer().Return(true)
+		m.EXPECT().NodeIsHealthy(context.TODO(), gomock.Any(), gomock.Any()).Return(tc.IsNodeHealthy, nil)
+		if tc.IsNodeHealthy {
+			m.EXPECT().RecordEvent(corev1.EventTypeNormal, "RemediationSucceeded", gomock.Any(), gomock.Any(), gomock.Any())
+			m.EXPECT().RemoveNodeBackupAnnotations()
+			m.EXPECT().UnsetFinalizer()
+			return m
+		}
+		m.EXPECT().GetTimeout().Return(&metav1.Duration{Duration: time.Second})
+		m.EXPECT().TimeToRemediate(gomock.Any()).Return(tc.IsTimedOut, time.Second)
+		if !tc.IsTimedOut {
+			return m
+		}
+		m.EXPECT().HasReachRebootsBeforeEscalation().Return(tc.IsEscalationReached)
+		if tc.IsEscalationReached {
+			m.EXPECT().Escalate()
+			return m
+		}
+		m.EXPECT().RetryLimitIsSet().Return(true)
+		m.EXPECT().HasReachRetryLimit().Return(tc.IsRetryLimitReached)
+		if !tc.IsRetryLimitReached {
+			m.EXPECT().SetRemediationPhase(infrav1.PhaseRunning)
+			m.EXPECT().SetLastRemediationTime(gomock.Any())
+			m.EXPECT().IncreaseRetryCount()
+			return m
+		}
+		m.EXPECT().SetRemediationPhase(infrav1.PhaseDeleting)
 	}
 	return m
 }
@@ -348,9 +780,71 @@ var _ = Describe("Metal3Remediation controller", func() {
 				}},
 				Machine: newMachine(clusterName, machineName, "", "mynode"),
 			}),
+		Entry("Failed to retrieve the referenced host without owner machine",
+			reconcileRemediationTestCase{
+				TestRequest:   defaultTestRequest,
+				ExpectedError: &hostNotFoundMsg,
+				Metal3Remediation: &infrav1.Metal3Remediation{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      metal3RemediationName,
+						Namespace: namespaceName,
+					},
+					Spec: infrav1.Metal3RemediationSpec{
+						HostRef: &corev1.ObjectReference{
+							Name: baremetalhostName,
+						},
+					},
+				},
+			}),
+		Entry("Machine selector matching no Machine",
+			reconcileRemediationTestCase{
+				TestRequest:   defaultTestRequest,
+				ExpectedError: &machineSelectorErrorMsg,
+				Metal3Remediation: &infrav1.Metal3Remediation{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      metal3RemediationName,
+						Namespace: namespaceName,
+					},
+					Spec: infrav1.Metal3RemediationSpec{
+						MachineSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{clusterv1.ClusterNameLabel: "othercluster"},
+						},
+					},
+				},
+				Machine: newMachine(clusterName, machineName, "", "mynode"),
+			}),
+		Entry("Machine selector resolved, failed to retrieve metal3machine",
+			reconcileRemediationTestCase{
+				TestRequest:   defaultTestRequest,
+				ExpectedError: &metal3MachineNotFoundMsg,
+				Metal3Remediation: &infrav1.Metal3Remediation{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      metal3RemediationName,
+						Namespace: namespaceName,
+					},
+					Spec: infrav1.Metal3RemediationSpec{
+						MachineSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{clusterv1.ClusterNameLabel: clusterName},
+						},
+					},
+				},
+				Machine: newMachine(clusterName, machineName, "", "mynode"),
+			}),
 	)
 
 	DescribeTable("ReconcileNormal tests", func(tc reconcileNormalRemediationTestCase) {
+		defer func(timeout time.Duration, deleteMachine bool, rebootTimeout time.Duration) {
+			baremetal.HostGoneTimeout = timeout
+			baremetal.HostGoneDeleteMachine = deleteMachine
+			baremetal.RebootAnnotationTimeout = rebootTimeout
+		}(baremetal.HostGoneTimeout, baremetal.HostGoneDeleteMachine, baremetal.RebootAnnotationTimeout)
+		baremetal.HostGoneTimeout = 10 * time.Minute
+		baremetal.HostGoneDeleteMachine = tc.HostGoneDeleteMachine
+		baremetal.RebootAnnotationTimeout = 10 * time.Minute
+		if tc.RebootAnnotationTimeoutZero {
+			baremetal.RebootAnnotationTimeout = 0
+		}
+
 		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).Build()
 		testReconciler = &Metal3RemediationReconciler{
 			Client:                     fakeClient,
@@ -359,7 +853,7 @@ var _ = Describe("Metal3Remediation controller", func() {
 			IsOutOfServiceTaintEnabled: tc.IsOutOfServiceTaintSupported,
 		}
 		m := setReconcileNormalRemediationExpectations(goMockCtrl, tc)
-		res, err := testReconciler.reconcileNormal(context.TODO(), m)
+		res, err := testReconciler.reconcileNormal(context.TODO(), m, false)
 
 		if tc.ExpectError {
 			Expect(err).To(HaveOccurred())
@@ -382,11 +876,74 @@ var _ = Describe("Metal3Remediation controller", func() {
 			ExpectRequeue:     false,
 			HostStatusOffline: true,
 		}),
+		Entry("Should stop without remediating and set remediation phase to failed if bmh is permanently failed", reconcileNormalRemediationTestCase{
+			ExpectError:           false,
+			ExpectRequeue:         false,
+			HostPermanentlyFailed: true,
+		}),
 		Entry("Should stop without remediating if remediation type is not RebootRemediationStrategy", reconcileNormalRemediationTestCase{
 			ExpectError:             false,
 			ExpectRequeue:           false,
 			GetRemediationTypeFails: true,
 		}),
+		Entry("Should cordon the node and mark the host unhealthy with the quarantine strategy", reconcileNormalRemediationTestCase{
+			ExpectError:   false,
+			ExpectRequeue: false,
+			IsQuarantine:  true,
+		}),
+		Entry("Should return an error if the node cannot be cordoned", reconcileNormalRemediationTestCase{
+			ExpectError:     true,
+			ExpectRequeue:   false,
+			IsQuarantine:    true,
+			CordonNodeFails: true,
+		}),
+		Entry("Should do nothing once quarantined", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    false,
+			IsQuarantine:     true,
+			RemediationPhase: infrav1.PhaseQuarantined,
+		}),
+		Entry("Should fence the host with the reassociate strategy", reconcileNormalRemediationTestCase{
+			ExpectError:   false,
+			ExpectRequeue: true,
+			IsReassociate: true,
+		}),
+		Entry("Should wait for the fenced host to be powered off", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       true,
+			IsReassociate:       true,
+			RemediationPhase:    infrav1.PhaseRunning,
+			IsPowerOffRequested: true,
+			IsPoweredOn:         true,
+		}),
+		Entry("Should delete the node and request the reassociation once the host is fenced", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       true,
+			IsReassociate:       true,
+			RemediationPhase:    infrav1.PhaseRunning,
+			IsPowerOffRequested: true,
+		}),
+		Entry("Should request the reassociation when the node is already deleted", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       true,
+			IsReassociate:       true,
+			RemediationPhase:    infrav1.PhaseRunning,
+			IsPowerOffRequested: true,
+			IsNodeDeleted:       true,
+		}),
+		Entry("Should wait for the Metal3Machine to be associated with another host", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    true,
+			IsReassociate:    true,
+			RemediationPhase: infrav1.PhaseReassociating,
+		}),
+		Entry("Should hand the Machine over to Cluster API when the reassociation times out", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    false,
+			IsReassociate:    true,
+			RemediationPhase: infrav1.PhaseReassociating,
+			IsTimedOut:       true,
+		}),
 		Entry("Should set last remediation time, and then requeue", reconcileNormalRemediationTestCase{
 			ExpectError:         false,
 			ExpectRequeue:       true,
@@ -420,6 +977,15 @@ var _ = Describe("Metal3Remediation controller", func() {
 			IsNodeDeleted:       false,
 			IsTimedOut:          false,
 		}),
+		Entry("Should request power off even if the remediation taint cannot be added", reconcileNormalRemediationTestCase{
+			ExpectError:           false,
+			ExpectRequeue:         true,
+			RemediationPhase:      infrav1.PhaseRunning,
+			IsFinalizerSet:        true,
+			IsPowerOffRequested:   false,
+			IsPoweredOn:           true,
+			RemediationTaintFails: true,
+		}),
 		Entry("Should requeue while still powered on", reconcileNormalRemediationTestCase{
 			ExpectError:         false,
 			ExpectRequeue:       true,
@@ -431,6 +997,24 @@ var _ = Describe("Metal3Remediation controller", func() {
 			IsNodeDeleted:       false,
 			IsTimedOut:          false,
 		}),
+		Entry("Should remove a stale poweroff annotation and switch to waiting phase", reconcileNormalRemediationTestCase{
+			ExpectError:               false,
+			ExpectRequeue:             true,
+			RemediationPhase:          infrav1.PhaseRunning,
+			IsFinalizerSet:            true,
+			IsPowerOffRequested:       true,
+			IsPoweredOn:               true,
+			IsRebootAnnotationExpired: true,
+		}),
+		Entry("Should keep the poweroff annotation when the reboot annotation timeout is zero", reconcileNormalRemediationTestCase{
+			ExpectError:                 false,
+			ExpectRequeue:               true,
+			RemediationPhase:            infrav1.PhaseRunning,
+			IsFinalizerSet:              true,
+			IsPowerOffRequested:         true,
+			IsPoweredOn:                 true,
+			RebootAnnotationTimeoutZero: true,
+		}),
 		Entry("Should backup node when powered off, and then requeue", reconcileNormalRemediationTestCase{
 			ExpectError:         false,
 			ExpectRequeue:       true,
@@ -562,6 +1146,18 @@ var _ = Describe("Metal3Remediation controller", func() {
 			IsNodeDeleted:       false,
 			IsTimedOut:          false,
 		}),
+		Entry("Should restore node and requeue until the node is healthy", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       true,
+			RemediationPhase:    infrav1.PhaseWaiting,
+			IsFinalizerSet:      true,
+			IsPowerOffRequested: false,
+			IsPoweredOn:         true,
+			IsNodeBackedUp:      true,
+			IsNodeDeleted:       false,
+			IsNodeUnhealthy:     true,
+			IsTimedOut:          false,
+		}),
 		Entry("Should skip restore node if forbidden and clean up and requeue", reconcileNormalRemediationTestCase{
 			ExpectError:         false,
 			ExpectRequeue:       true,
@@ -598,18 +1194,406 @@ var _ = Describe("Metal3Remediation controller", func() {
 			IsTimedOut:          true,
 			IsRetryLimitReached: true,
 		}),
+		Entry("Should escalate once the reboots before escalation are reached, and then requeue", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       true,
+			RemediationPhase:    infrav1.PhaseWaiting,
+			IsFinalizerSet:      true,
+			IsPowerOffRequested: false,
+			IsPoweredOn:         true,
+			IsNodeBackedUp:      true,
+			IsNodeDeleted:       true,
+			IsTimedOut:          true,
+			IsEscalationReached: true,
+		}),
 		Entry("Should not requeue for Phase Deleting", reconcileNormalRemediationTestCase{
 			ExpectError:      false,
 			ExpectRequeue:    false,
 			RemediationPhase: infrav1.PhaseDeleting,
 		}),
+		Entry("Should requeue for Phase Deleting while the unhealthy annotation is kept", reconcileNormalRemediationTestCase{
+			ExpectError:               false,
+			ExpectRequeue:             true,
+			RemediationPhase:          infrav1.PhaseDeleting,
+			IsHostUnhealthy:           true,
+			IsUnhealthyAnnotationKept: true,
+		}),
+		Entry("Should not requeue for Phase Deleting once the unhealthy annotation is cleared", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    false,
+			RemediationPhase: infrav1.PhaseDeleting,
+			IsHostUnhealthy:  true,
+		}),
 		Entry("Should not requeue for Phase Failed", reconcileNormalRemediationTestCase{
 			ExpectError:      false,
 			ExpectRequeue:    false,
 			RemediationPhase: infrav1.PhaseFailed,
 		}),
+		Entry("Should clear the unhealthy annotation in Phase Failed", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    false,
+			RemediationPhase: infrav1.PhaseFailed,
+			IsHostUnhealthy:  true,
+		}),
+		Entry("Should not requeue for Phase Failed even if the remediation taint cannot be removed", reconcileNormalRemediationTestCase{
+			ExpectError:           false,
+			ExpectRequeue:         false,
+			RemediationPhase:      infrav1.PhaseFailed,
+			RemediationTaintFails: true,
+		}),
+		Entry("Should requeue while the host is missing and not timed out", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    true,
+			RemediationPhase: infrav1.PhaseWaiting,
+			IsHostGone:       true,
+		}),
+		Entry("Should trigger machine deletion and switch to phase HostGone when the host is gone", reconcileNormalRemediationTestCase{
+			ExpectError:           false,
+			ExpectRequeue:         false,
+			RemediationPhase:      infrav1.PhaseWaiting,
+			IsHostGone:            true,
+			IsHostGoneTimedOut:    true,
+			HostGoneDeleteMachine: true,
+		}),
+		Entry("Should switch to phase HostGone without touching the machine when deletion is disabled", reconcileNormalRemediationTestCase{
+			ExpectError:        false,
+			ExpectRequeue:      false,
+			RemediationPhase:   infrav1.PhaseRunning,
+			IsHostGone:         true,
+			IsHostGoneTimedOut: true,
+		}),
+		Entry("Should not requeue for Phase HostGone", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    false,
+			RemediationPhase: infrav1.PhaseHostGone,
+			IsHostGone:       true,
+		}),
 	)
 
+	DescribeTable("ReconcileHost tests", func(tc reconcileNormalRemediationTestCase) {
+		defer func(timeout time.Duration, rebootTimeout time.Duration) {
+			baremetal.HostGoneTimeout = timeout
+			baremetal.RebootAnnotationTimeout = rebootTimeout
+		}(baremetal.HostGoneTimeout, baremetal.RebootAnnotationTimeout)
+		baremetal.HostGoneTimeout = 10 * time.Minute
+		baremetal.RebootAnnotationTimeout = 10 * time.Minute
+		if tc.RebootAnnotationTimeoutZero {
+			baremetal.RebootAnnotationTimeout = 0
+		}
+
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).Build()
+		testReconciler = &Metal3RemediationReconciler{
+			Client:         fakeClient,
+			ManagerFactory: baremetal.NewManagerFactory(fakeClient),
+			Log:            logr.Discard(),
+		}
+		m := setReconcileHostRemediationExpectations(goMockCtrl, tc)
+		res, err := testReconciler.reconcileHost(context.TODO(), m, false)
+
+		if tc.ExpectError {
+			Expect(err).To(HaveOccurred())
+		} else {
+			Expect(err).NotTo(HaveOccurred())
+		}
+		if tc.ExpectRequeue {
+			Expect(res.Requeue || res.RequeueAfter > 0).To(BeTrue())
+		} else {
+			Expect(res.Requeue || res.RequeueAfter > 0).To(BeFalse())
+		}
+	},
+		Entry("Should error if referenced host not found", reconcileNormalRemediationTestCase{
+			ExpectError:           true,
+			ExpectRequeue:         false,
+			GetUnhealthyHostFails: true,
+		}),
+		Entry("Should set remediation phase to failed if bmh is set offline", reconcileNormalRemediationTestCase{
+			ExpectError:       false,
+			ExpectRequeue:     false,
+			HostStatusOffline: true,
+		}),
+		Entry("Should set remediation phase to failed if bmh is permanently failed", reconcileNormalRemediationTestCase{
+			ExpectError:           false,
+			ExpectRequeue:         false,
+			HostPermanentlyFailed: true,
+		}),
+		Entry("Should set last remediation time, and then requeue", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    true,
+			RemediationPhase: "",
+		}),
+		Entry("Should set finalizer, and then requeue", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    true,
+			RemediationPhase: infrav1.PhaseRunning,
+			IsFinalizerSet:   false,
+		}),
+		Entry("Should set power off annotation, and then requeue", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       true,
+			RemediationPhase:    infrav1.PhaseRunning,
+			IsFinalizerSet:      true,
+			IsPowerOffRequested: false,
+		}),
+		Entry("Should wait for host to power off", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       true,
+			RemediationPhase:    infrav1.PhaseRunning,
+			IsFinalizerSet:      true,
+			IsPowerOffRequested: true,
+			IsPoweredOn:         true,
+		}),
+		Entry("Should remove a stale poweroff annotation", reconcileNormalRemediationTestCase{
+			ExpectError:               false,
+			ExpectRequeue:             true,
+			RemediationPhase:          infrav1.PhaseRunning,
+			IsFinalizerSet:            true,
+			IsPowerOffRequested:       true,
+			IsPoweredOn:               true,
+			IsRebootAnnotationExpired: true,
+		}),
+		Entry("Should keep the poweroff annotation when the reboot annotation timeout is zero", reconcileNormalRemediationTestCase{
+			ExpectError:                 false,
+			ExpectRequeue:               true,
+			RemediationPhase:            infrav1.PhaseRunning,
+			IsFinalizerSet:              true,
+			IsPowerOffRequested:         true,
+			IsPoweredOn:                 true,
+			RebootAnnotationTimeoutZero: true,
+		}),
+		Entry("Should switch to waiting phase once powered off", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       true,
+			RemediationPhase:    infrav1.PhaseRunning,
+			IsFinalizerSet:      true,
+			IsPowerOffRequested: true,
+			IsPoweredOn:         false,
+		}),
+		Entry("Should remove power off annotation and wait for power on", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       true,
+			RemediationPhase:    infrav1.PhaseWaiting,
+			IsPowerOffRequested: true,
+			IsPoweredOn:         false,
+		}),
+		Entry("Should succeed once powered on", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       false,
+			RemediationPhase:    infrav1.PhaseWaiting,
+			IsPowerOffRequested: false,
+			IsPoweredOn:         true,
+		}),
+		Entry("Should retry when not powered on before the timeout", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    true,
+			RemediationPhase: infrav1.PhaseWaiting,
+			IsPoweredOn:      false,
+			IsTimedOut:       true,
+		}),
+		Entry("Should fail when not powered on before the timeout and retry limit reached", reconcileNormalRemediationTestCase{
+			ExpectError:         false,
+			ExpectRequeue:       false,
+			RemediationPhase:    infrav1.PhaseWaiting,
+			IsPoweredOn:         false,
+			IsTimedOut:          true,
+			IsRetryLimitReached: true,
+		}),
+		Entry("Should fail with an unsupported remediation strategy", reconcileNormalRemediationTestCase{
+			ExpectError:   false,
+			ExpectRequeue: false,
+			IsQuarantine:  true,
+		}),
+		Entry("Should not fail again with an unsupported remediation strategy", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    false,
+			RemediationPhase: infrav1.PhaseFailed,
+			IsQuarantine:     true,
+		}),
+		Entry("Should not requeue for Phase Succeeded", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    false,
+			RemediationPhase: infrav1.PhaseSucceeded,
+		}),
+		Entry("Should requeue while the referenced host is missing and not timed out", reconcileNormalRemediationTestCase{
+			ExpectError:      false,
+			ExpectRequeue:    true,
+			RemediationPhase: infrav1.PhaseWaiting,
+			IsHostGone:       true,
+		}),
+		Entry("Should switch to phase HostGone when the referenced host is gone", reconcileNormalRemediationTestCase{
+			ExpectError:        false,
+			ExpectRequeue:      false,
+			RemediationPhase:   infrav1.PhaseWaiting,
+			IsHostGone:         true,
+			IsHostGoneTimedOut: true,
+		}),
+	)
+
+	DescribeTable("ReconcileDryRun tests", func(tc reconcileDryRunRemediationTestCase) {
+		defer func(timeout time.Duration) {
+			baremetal.HostGoneTimeout = timeout
+		}(baremetal.HostGoneTimeout)
+		baremetal.HostGoneTimeout = 10 * time.Minute
+
+		testReconciler = &Metal3RemediationReconciler{
+			Log: logr.Discard(),
+		}
+		m := setReconcileDryRunRemediationExpectations(goMockCtrl, tc)
+		var res ctrl.Result
+		var err error
+		if tc.HasMachine {
+			res, err = testReconciler.reconcileNormal(context.TODO(), m, true)
+		} else {
+			res, err = testReconciler.reconcileHost(context.TODO(), m, true)
+		}
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Requeue || res.RequeueAfter > 0).To(Equal(tc.ExpectRequeue))
+	},
+		Entry("Should set the phase to running", reconcileDryRunRemediationTestCase{
+			ExpectRequeue: true,
+			HasMachine:    true,
+		}),
+		Entry("Should switch to waiting phase without powering off the host", reconcileDryRunRemediationTestCase{
+			ExpectRequeue:    true,
+			HasMachine:       true,
+			RemediationPhase: infrav1.PhaseRunning,
+		}),
+		Entry("Should wait for the timeout", reconcileDryRunRemediationTestCase{
+			ExpectRequeue:    true,
+			HasMachine:       true,
+			RemediationPhase: infrav1.PhaseWaiting,
+		}),
+		Entry("Should retry once timed out", reconcileDryRunRemediationTestCase{
+			ExpectRequeue:    true,
+			HasMachine:       true,
+			RemediationPhase: infrav1.PhaseWaiting,
+			IsTimedOut:       true,
+		}),
+		Entry("Should switch to deleting phase without touching the Machine", reconcileDryRunRemediationTestCase{
+			HasMachine:          true,
+			RemediationPhase:    infrav1.PhaseWaiting,
+			IsTimedOut:          true,
+			IsRetryLimitReached: true,
+		}),
+		Entry("Should escalate once the reboots before escalation are reached", reconcileDryRunRemediationTestCase{
+			ExpectRequeue:       true,
+			HasMachine:          true,
+			RemediationPhase:    infrav1.PhaseWaiting,
+			IsTimedOut:          true,
+			IsEscalationReached: true,
+		}),
+		Entry("Should not power on the host", reconcileDryRunRemediationTestCase{
+			ExpectRequeue:       true,
+			HasMachine:          true,
+			RemediationPhase:    infrav1.PhaseWaiting,
+			IsPowerOffRequested: true,
+		}),
+		Entry("Should finish without restoring the healthy node", reconcileDryRunRemediationTestCase{
+			ExpectRequeue:    true,
+			HasMachine:       true,
+			RemediationPhase: infrav1.PhaseWaiting,
+			IsNodeHealthy:    true,
+		}),
+		Entry("Should not requeue for phase deleting", reconcileDryRunRemediationTestCase{
+			HasMachine:       true,
+			RemediationPhase: infrav1.PhaseDeleting,
+		}),
+		Entry("Should switch to waiting phase without a Machine", reconcileDryRunRemediationTestCase{
+			ExpectRequeue:    true,
+			RemediationPhase: infrav1.PhaseRunning,
+		}),
+		Entry("Should succeed without a Machine", reconcileDryRunRemediationTestCase{
+			RemediationPhase: infrav1.PhaseWaiting,
+		}),
+		Entry("Should quarantine without cordoning the node", reconcileDryRunRemediationTestCase{
+			HasMachine:   true,
+			IsQuarantine: true,
+		}),
+		Entry("Should start reassociating without fencing the host", reconcileDryRunRemediationTestCase{
+			ExpectRequeue: true,
+			HasMachine:    true,
+			IsReassociate: true,
+		}),
+		Entry("Should reassociate without deleting the node", reconcileDryRunRemediationTestCase{
+			ExpectRequeue:    true,
+			HasMachine:       true,
+			IsReassociate:    true,
+			RemediationPhase: infrav1.PhaseRunning,
+		}),
+		Entry("Should switch to deleting phase once the reassociation timed out", reconcileDryRunRemediationTestCase{
+			HasMachine:       true,
+			IsReassociate:    true,
+			RemediationPhase: infrav1.PhaseReassociating,
+			IsTimedOut:       true,
+		}),
+		Entry("Should set remediation phase to failed if bmh is set offline", reconcileDryRunRemediationTestCase{
+			HasMachine:        true,
+			HostStatusOffline: true,
+		}),
+		Entry("Should set remediation phase to failed if bmh is permanently failed", reconcileDryRunRemediationTestCase{
+			HasMachine:            true,
+			HostPermanentlyFailed: true,
+		}),
+		Entry("Should requeue while the host is missing", reconcileDryRunRemediationTestCase{
+			ExpectRequeue:    true,
+			HasMachine:       true,
+			RemediationPhase: infrav1.PhaseWaiting,
+			IsHostGone:       true,
+		}),
+		Entry("Should switch to phase HostGone without touching the Machine", reconcileDryRunRemediationTestCase{
+			HasMachine:         true,
+			RemediationPhase:   infrav1.PhaseWaiting,
+			IsHostGone:         true,
+			IsHostGoneTimedOut: true,
+		}),
+	)
+
+	It("Should not touch the host of a dry-run remediation", func() {
+		host := &bmov1alpha1.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      baremetalhostName,
+				Namespace: namespaceName,
+			},
+			Spec: bmov1alpha1.BareMetalHostSpec{Online: true},
+		}
+		remediation := &infrav1.Metal3Remediation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        metal3RemediationName,
+				Namespace:   namespaceName,
+				Annotations: map[string]string{infrav1.RemediationDryRunAnnotation: ""},
+				Finalizers:  []string{infrav1.RemediationFinalizer},
+			},
+			Spec: infrav1.Metal3RemediationSpec{
+				Strategy: &infrav1.RemediationStrategy{
+					Type:    infrav1.RebootRemediationStrategy,
+					Timeout: &metav1.Duration{Duration: 600 * time.Second},
+				},
+				HostRef: &corev1.ObjectReference{Name: baremetalhostName},
+			},
+			Status: infrav1.Metal3RemediationStatus{Phase: infrav1.PhaseRunning},
+		}
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).
+			WithObjects(host, remediation).WithStatusSubresource(remediation).Build()
+		testReconciler = &Metal3RemediationReconciler{
+			Client:         fakeClient,
+			ManagerFactory: baremetal.NewManagerFactory(fakeClient),
+			Log:            logr.Discard(),
+		}
+
+		_, err := testReconciler.Reconcile(context.TODO(), defaultTestRequest)
+		Expect(err).NotTo(HaveOccurred())
+
+		savedHost := &bmov1alpha1.BareMetalHost{}
+		Expect(fakeClient.Get(context.TODO(), client.ObjectKeyFromObject(host), savedHost)).To(Succeed())
+		Expect(savedHost.Annotations).To(BeEmpty())
+		Expect(savedHost.ResourceVersion).To(Equal(host.ResourceVersion))
+
+		savedRemediation := &infrav1.Metal3Remediation{}
+		Expect(fakeClient.Get(context.TODO(), defaultTestRequest.NamespacedName, savedRemediation)).To(Succeed())
+		Expect(savedRemediation.Status.Phase).To(Equal(infrav1.PhaseWaiting))
+		Expect(savedRemediation.Status.RebootCount).To(Equal(1))
+	})
+
 	DescribeTable("Metal3Remediation marshal test",
 		func(tc marshallRemediationTestCase) {
 			nodeAnnotations, err := marshal(tc.Map)
@@ -652,4 +1636,141 @@ var _ = Describe("Metal3Remediation controller", func() {
 			}),
 	)
 
+	remediationHost := func(consumerRef *corev1.ObjectReference) *bmov1alpha1.BareMetalHost {
+		return &bmov1alpha1.BareMetalHost{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      baremetalhostName,
+				Namespace: namespaceName,
+			},
+			Spec: bmov1alpha1.BareMetalHostSpec{
+				ConsumerRef: consumerRef,
+			},
+		}
+	}
+	hostConsumerRef := &corev1.ObjectReference{
+		Name:       metal3machineName,
+		Namespace:  namespaceName,
+		Kind:       "Metal3Machine",
+		APIVersion: infrav1.GroupVersion.String(),
+	}
+	ownedMetal3Machine := &infrav1.Metal3Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      metal3machineName,
+			Namespace: namespaceName,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       machineName,
+				},
+			},
+		},
+	}
+	machineRemediation := func(name string, machine string) *infrav1.Metal3Remediation {
+		return &infrav1.Metal3Remediation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+				OwnerReferences: []metav1.OwnerReference{
+					{
+						APIVersion: clusterv1.GroupVersion.String(),
+						Kind:       "Machine",
+						Name:       machine,
+					},
+				},
+			},
+		}
+	}
+	hostRemediation := func(name string, host string) *infrav1.Metal3Remediation {
+		return &infrav1.Metal3Remediation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+			},
+			Spec: infrav1.Metal3RemediationSpec{
+				HostRef: &corev1.ObjectReference{
+					Name: host,
+				},
+			},
+		}
+	}
+
+	DescribeTable("BareMetalHost To Metal3Remediations tests",
+		func(tc hostToRemediationsTestCase) {
+			objects := []client.Object{}
+			if tc.Metal3Machine != nil {
+				objects = append(objects, tc.Metal3Machine)
+			}
+			for _, remediation := range tc.Remediations {
+				objects = append(objects, remediation)
+			}
+			r := Metal3RemediationReconciler{
+				Client: fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(objects...).Build(),
+				Log:    logr.Discard(),
+			}
+
+			reqs := r.BareMetalHostToMetal3Remediations(context.Background(), tc.Host)
+
+			names := []string{}
+			for _, req := range reqs {
+				Expect(req.Namespace).To(Equal(namespaceName))
+				names = append(names, req.Name)
+			}
+			Expect(names).To(ConsistOf(tc.ExpectedRemediations))
+		},
+		Entry("Remediation owned by the Machine of the host",
+			hostToRemediationsTestCase{
+				Host:          remediationHost(hostConsumerRef),
+				Metal3Machine: ownedMetal3Machine,
+				Remediations: []*infrav1.Metal3Remediation{
+					machineRemediation(metal3RemediationName, machineName),
+					machineRemediation("other-remediation", "other-machine"),
+				},
+				ExpectedRemediations: []string{metal3RemediationName},
+			},
+		),
+		Entry("Remediation referencing the host",
+			hostToRemediationsTestCase{
+				Host: remediationHost(nil),
+				Remediations: []*infrav1.Metal3Remediation{
+					hostRemediation(metal3RemediationName, baremetalhostName),
+					hostRemediation("other-remediation", "other-host"),
+				},
+				ExpectedRemediations: []string{metal3RemediationName},
+			},
+		),
+		Entry("Metal3Machine not found",
+			hostToRemediationsTestCase{
+				Host: remediationHost(hostConsumerRef),
+				Remediations: []*infrav1.Metal3Remediation{
+					machineRemediation(metal3RemediationName, machineName),
+				},
+				ExpectedRemediations: []string{},
+			},
+		),
+		Entry("No remediation",
+			hostToRemediationsTestCase{
+				Host:                 remediationHost(hostConsumerRef),
+				Metal3Machine:        ownedMetal3Machine,
+				ExpectedRemediations: []string{},
+			},
+		),
+	)
+
+	DescribeTable("BareMetalHost power change predicate tests",
+		func(oldPoweredOn bool, newPoweredOn bool, expectEnqueue bool) {
+			oldHost := remediationHost(nil)
+			oldHost.Status.PoweredOn = oldPoweredOn
+			newHost := remediationHost(nil)
+			newHost.Status.PoweredOn = newPoweredOn
+
+			updateEvent := event.UpdateEvent{ObjectOld: oldHost, ObjectNew: newHost}
+			Expect(hostPowerChangedPredicate().Update(updateEvent)).To(Equal(expectEnqueue))
+			Expect(hostPowerChangedPredicate().Create(event.CreateEvent{Object: newHost})).To(BeFalse())
+		},
+		Entry("Host powered on", false, true, true),
+		Entry("Host powered off", true, false, true),
+		Entry("Power state unchanged", true, true, false),
+	)
+
 })
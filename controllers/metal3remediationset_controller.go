@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+const (
+	remediationSetControllerName = "Metal3RemediationSet-controller"
+)
+
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3remediationsets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3remediationsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=metal3remediations,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch
+
+// Metal3RemediationSetReconciler reconciles a Metal3RemediationSet object.
+type Metal3RemediationSetReconciler struct {
+	Client         client.Client
+	ManagerFactory baremetal.ManagerFactoryInterface
+	Log            logr.Logger
+}
+
+// Reconcile handles Metal3RemediationSet events.
+func (r *Metal3RemediationSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	remediationSetLog := r.Log.WithName(remediationSetControllerName).WithValues("metal3-remediation-set", req.NamespacedName)
+
+	// Fetch the Metal3RemediationSet instance.
+	remediationSet := &infrav1.Metal3RemediationSet{}
+	if err := r.Client.Get(ctx, req.NamespacedName, remediationSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, errors.Wrap(err, "unable to fetch Metal3RemediationSet")
+	}
+
+	// The Metal3Remediations are owned by the set and garbage collected with it.
+	if !remediationSet.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	helper, err := patch.NewHelper(remediationSet, r.Client)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to init patch helper")
+	}
+
+	// Always patch the Metal3RemediationSet exiting this function so we can persist any status changes.
+	defer func() {
+		err := helper.Patch(ctx, remediationSet)
+		if err != nil {
+			remediationSetLog.Info("failed to patch Metal3RemediationSet")
+			rerr = err
+		}
+	}()
+
+	// Return early if the Metal3RemediationSet is paused.
+	if annotations.HasPaused(remediationSet) {
+		remediationSetLog.Info("Metal3RemediationSet is currently paused. Remove pause annotation to continue reconciliation.")
+		return ctrl.Result{}, nil
+	}
+
+	// Create a helper for managing the Metal3RemediationSet.
+	remediationSetMgr, err := r.ManagerFactory.NewRemediationSetManager(remediationSet, remediationSetLog)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to create helper for managing the remediationSetMgr")
+	}
+
+	return r.reconcileNormal(ctx, remediationSetMgr)
+}
+
+func (r *Metal3RemediationSetReconciler) reconcileNormal(ctx context.Context,
+	remediationSetMgr baremetal.RemediationSetManagerInterface,
+) (ctrl.Result, error) {
+	// Progress is driven by the events of the owned Metal3Remediations and of
+	// the Machines, no requeue is needed.
+	if err := remediationSetMgr.UpdateRemediations(ctx); err != nil {
+		r.Log.Error(err, "failed to update the remediations of the set")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager will add watches for Metal3RemediationSet controller.
+func (r *Metal3RemediationSetReconciler) SetupWithManager(_ context.Context, mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.Metal3RemediationSet{}).
+		WithOptions(options).
+		Owns(&infrav1.Metal3Remediation{}).
+		Watches(
+			&clusterv1.Machine{},
+			handler.EnqueueRequestsFromMapFunc(r.MachineToMetal3RemediationSets),
+		).
+		Complete(r)
+}
+
+// MachineToMetal3RemediationSets will return reconcile requests for the
+// Metal3RemediationSets whose selector matches the Machine.
+func (r *Metal3RemediationSetReconciler) MachineToMetal3RemediationSets(ctx context.Context, obj client.Object) []ctrl.Request {
+	machine, ok := obj.(*clusterv1.Machine)
+	if !ok {
+		r.Log.Error(errors.Errorf("expected a Machine but got a %T", obj),
+			"failed to get Metal3RemediationSets for Machine",
+		)
+		return nil
+	}
+
+	remediationSets := &infrav1.Metal3RemediationSetList{}
+	if err := r.Client.List(ctx, remediationSets, client.InNamespace(machine.Namespace)); err != nil {
+		r.Log.Error(err, "failed to list Metal3RemediationSets")
+		return nil
+	}
+
+	requests := []ctrl.Request{}
+	for _, remediationSet := range remediationSets.Items {
+		selector, err := metav1.LabelSelectorAsSelector(&remediationSet.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		if selector.Matches(labels.Set(machine.Labels)) {
+			requests = append(requests, ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      remediationSet.Name,
+					Namespace: remediationSet.Namespace,
+				},
+			})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	baremetal_mocks "github.com/metal3-io/cluster-api-provider-metal3/baremetal/mocks"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Metal3RemediationSet controller", func() {
+	remediationSetName := "maintenance"
+
+	newRemediationSet := func(name string, matchLabels map[string]string) *infrav1.Metal3RemediationSet {
+		return &infrav1.Metal3RemediationSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespaceName,
+			},
+			Spec: infrav1.Metal3RemediationSetSpec{
+				Selector: metav1.LabelSelector{
+					MatchLabels: matchLabels,
+				},
+				Strategy: &infrav1.RemediationStrategy{
+					Type:    infrav1.RebootRemediationStrategy,
+					Timeout: &metav1.Duration{Duration: 600 * time.Second},
+				},
+			},
+		}
+	}
+
+	type reconcileRemediationSetTestCase struct {
+		RemediationSet      *infrav1.Metal3RemediationSet
+		Paused              bool
+		UpdateError         error
+		ExpectUpdate        bool
+		ExpectError         bool
+		ExpectManagerCreate bool
+	}
+
+	DescribeTable("Test Metal3RemediationSet Reconcile",
+		func(tc reconcileRemediationSetTestCase) {
+			mockController := gomock.NewController(GinkgoT())
+			mf := baremetal_mocks.NewMockManagerFactoryInterface(mockController)
+			m := baremetal_mocks.NewMockRemediationSetManagerInterface(mockController)
+
+			objects := []client.Object{}
+			if tc.RemediationSet != nil {
+				if tc.Paused {
+					tc.RemediationSet.Annotations = map[string]string{clusterv1.PausedAnnotation: "true"}
+				}
+				objects = append(objects, tc.RemediationSet)
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).
+				WithObjects(objects...).WithStatusSubresource(objects...).Build()
+
+			if tc.ExpectManagerCreate {
+				mf.EXPECT().NewRemediationSetManager(gomock.Any(), gomock.Any()).Return(m, nil)
+			}
+			if tc.ExpectUpdate {
+				m.EXPECT().UpdateRemediations(gomock.Any()).Return(tc.UpdateError)
+			}
+
+			r := &Metal3RemediationSetReconciler{
+				Client:         fakeClient,
+				ManagerFactory: mf,
+				Log:            logr.Discard(),
+			}
+			result, err := r.Reconcile(context.TODO(), ctrl.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      remediationSetName,
+					Namespace: namespaceName,
+				},
+			})
+			if tc.ExpectError {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(result).To(Equal(ctrl.Result{}))
+			mockController.Finish()
+		},
+		Entry("Metal3RemediationSet not found", reconcileRemediationSetTestCase{}),
+		Entry("Metal3RemediationSet paused", reconcileRemediationSetTestCase{
+			RemediationSet: newRemediationSet(remediationSetName, map[string]string{"group": "maintenance"}),
+			Paused:         true,
+		}),
+		Entry("Remediations updated", reconcileRemediationSetTestCase{
+			RemediationSet:      newRemediationSet(remediationSetName, map[string]string{"group": "maintenance"}),
+			ExpectManagerCreate: true,
+			ExpectUpdate:        true,
+		}),
+		Entry("Remediations update failed", reconcileRemediationSetTestCase{
+			RemediationSet:      newRemediationSet(remediationSetName, map[string]string{"group": "maintenance"}),
+			ExpectManagerCreate: true,
+			ExpectUpdate:        true,
+			UpdateError:         errors.New("failed"),
+			ExpectError:         true,
+		}),
+	)
+
+	type machineToRemediationSetsTestCase struct {
+		MachineLabels    map[string]string
+		ExpectedRequests []string
+	}
+
+	DescribeTable("Test MachineToMetal3RemediationSets",
+		func(tc machineToRemediationSetsTestCase) {
+			fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(
+				newRemediationSet("maintenance", map[string]string{"group": "maintenance"}),
+				newRemediationSet("rack-1", map[string]string{"rack": "1"}),
+				newRemediationSet("everything", nil),
+			).Build()
+			r := &Metal3RemediationSetReconciler{
+				Client: fakeClient,
+				Log:    logr.Discard(),
+			}
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "machine-a",
+					Namespace: namespaceName,
+					Labels:    tc.MachineLabels,
+				},
+			}
+
+			requests := r.MachineToMetal3RemediationSets(context.TODO(), machine)
+			names := []string{}
+			for _, request := range requests {
+				Expect(request.Namespace).To(Equal(namespaceName))
+				names = append(names, request.Name)
+			}
+			Expect(names).To(ConsistOf(tc.ExpectedRequests))
+		},
+		Entry("Machine not selected", machineToRemediationSetsTestCase{
+			MachineLabels:    map[string]string{"group": "other"},
+			ExpectedRequests: []string{},
+		}),
+		Entry("Machine selected by one set", machineToRemediationSetsTestCase{
+			MachineLabels:    map[string]string{"group": "maintenance"},
+			ExpectedRequests: []string{"maintenance"},
+		}),
+		Entry("Machine selected by several sets", machineToRemediationSetsTestCase{
+			MachineLabels:    map[string]string{"group": "maintenance", "rack": "1"},
+			ExpectedRequests: []string{"maintenance", "rack-1"},
+		}),
+	)
+})
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const referenceHostWebhookPath = "/validate-infrastructure-cluster-x-k8s-io-v1beta1-referencehost"
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infrastructure-cluster-x-k8s-io-v1beta1-referencehost,mutating=false,failurePolicy=ignore,groups=infrastructure.cluster.x-k8s.io,resources=metal3datatemplates,versions=v1beta1,name=referencehost.metal3datatemplate.infrastructure.cluster.x-k8s.io,matchPolicy=Equivalent,sideEffects=None,admissionReviewVersions=v1;v1beta1
+
+// ReferenceHostWebhook rejects the Metal3DataTemplates whose networkData
+// links fetch their MAC address from host interfaces that the BareMetalHost
+// named in their reference host annotation does not have. On fleets of
+// uniform hardware, this reports at admission the NIC names which would only
+// fail the rendering otherwise. Templates without the annotation are not
+// checked, and the updates are only checked when they change the reference
+// host, so that a template can still be updated, e.g. to remove its
+// finalizers, once its reference host is gone.
+type ReferenceHostWebhook struct {
+	Client  client.Reader
+	decoder admission.Decoder
+}
+
+// SetupWebhookWithManager registers the webhook on the webhook server of the
+// manager.
+func (w *ReferenceHostWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	w.decoder = admission.NewDecoder(mgr.GetScheme())
+	mgr.GetWebhookServer().Register(referenceHostWebhookPath, &webhook.Admission{Handler: w})
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (w *ReferenceHostWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Kind.Kind != "Metal3DataTemplate" {
+		return admission.Allowed("")
+	}
+	m3dt := &infrav1.Metal3DataTemplate{}
+	if err := w.decoder.Decode(req, m3dt); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	hostName := m3dt.Annotations[baremetal.ReferenceHostAnnotation]
+	if hostName == "" || m3dt.Spec.NetworkData == nil || !m3dt.DeletionTimestamp.IsZero() {
+		return admission.Allowed("")
+	}
+	if req.Operation == admissionv1.Update {
+		oldM3dt := &infrav1.Metal3DataTemplate{}
+		if err := w.decoder.DecodeRaw(req.OldObject, oldM3dt); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if oldM3dt.Annotations[baremetal.ReferenceHostAnnotation] == hostName {
+			return admission.Allowed("")
+		}
+	}
+
+	host := &bmov1alpha1.BareMetalHost{}
+	err := w.Client.Get(ctx, client.ObjectKey{Name: hostName, Namespace: req.Namespace}, host)
+	if apierrors.IsNotFound(err) {
+		message := fmt.Sprintf("reference BareMetalHost %s/%s not found", req.Namespace, hostName)
+		if req.Operation == admissionv1.Update {
+			return admission.Allowed("").WithWarnings(message)
+		}
+		return admission.Denied(message)
+	}
+	if err != nil {
+		return admission.Allowed("").WithWarnings(
+			fmt.Sprintf("unable to check the host interfaces against the reference host: %v", err),
+		)
+	}
+	if host.Status.HardwareDetails == nil || host.Status.HardwareDetails.NIC == nil {
+		return admission.Allowed("").WithWarnings(fmt.Sprintf(
+			"reference BareMetalHost %s/%s is not inspected, the host interfaces are not checked",
+			req.Namespace, hostName,
+		))
+	}
+
+	missing := baremetal.MissingHostInterfaces(m3dt.Spec.NetworkData, host)
+	if len(missing) == 0 {
+		return admission.Allowed("")
+	}
+	return admission.Denied(fmt.Sprintf("reference BareMetalHost %s/%s has no interface %s",
+		req.Namespace, hostName, strings.Join(missing, ", "),
+	))
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	bmov1alpha1 "github.com/metal3-io/baremetal-operator/apis/metal3.io/v1alpha1"
+	infrav1 "github.com/metal3-io/cluster-api-provider-metal3/api/v1beta1"
+	"github.com/metal3-io/cluster-api-provider-metal3/baremetal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("ReferenceHost webhook", func() {
+	referenceHost := &bmov1alpha1.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "reference-host",
+			Namespace: namespaceName,
+			Annotations: map[string]string{
+				baremetal.InterfaceNamesAnnotation: "00:00:00:00:00:02=data0",
+			},
+		},
+		Status: bmov1alpha1.BareMetalHostStatus{
+			HardwareDetails: &bmov1alpha1.HardwareDetails{
+				NIC: []bmov1alpha1.NIC{
+					{Name: "eth0", MAC: "00:00:00:00:00:01"},
+					{Name: "eth1", MAC: "00:00:00:00:00:02"},
+				},
+			},
+		},
+	}
+	uninspectedHost := &bmov1alpha1.BareMetalHost{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "uninspected-host",
+			Namespace: namespaceName,
+		},
+	}
+
+	fromHostInterface := func(name string) *infrav1.NetworkLinkEthernetMac {
+		return &infrav1.NetworkLinkEthernetMac{FromHostInterface: ptr.To(name)}
+	}
+	newNetworkData := func(ethernets ...infrav1.NetworkDataLinkEthernet) *infrav1.NetworkData {
+		return &infrav1.NetworkData{
+			Links: infrav1.NetworkDataLink{Ethernets: ethernets},
+		}
+	}
+
+	newWebhook := func() *ReferenceHostWebhook {
+		fakeClient := fake.NewClientBuilder().WithScheme(setupScheme()).WithObjects(
+			referenceHost.DeepCopy(), uninspectedHost.DeepCopy(),
+		).Build()
+		return &ReferenceHostWebhook{
+			Client:  fakeClient,
+			decoder: admission.NewDecoder(setupScheme()),
+		}
+	}
+	newTemplate := func(referenceHost string, networkData *infrav1.NetworkData) *infrav1.Metal3DataTemplate {
+		m3dt := &infrav1.Metal3DataTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "m3dt", Namespace: namespaceName},
+			Spec: infrav1.Metal3DataTemplateSpec{
+				ClusterName: clusterName,
+				NetworkData: networkData,
+			},
+		}
+		if referenceHost != "" {
+			m3dt.Annotations = map[string]string{baremetal.ReferenceHostAnnotation: referenceHost}
+		}
+		return m3dt
+	}
+	// newRequest returns the admission request of the template, of an update
+	// from oldM3dt if it is not nil.
+	newRequest := func(m3dt, oldM3dt *infrav1.Metal3DataTemplate) admission.Request {
+		raw, err := json.Marshal(m3dt)
+		Expect(err).NotTo(HaveOccurred())
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Kind: metav1.GroupVersionKind{
+					Group:   infrav1.GroupVersion.Group,
+					Version: infrav1.GroupVersion.Version,
+					Kind:    "Metal3DataTemplate",
+				},
+				Namespace: namespaceName,
+				Object:    runtime.RawExtension{Raw: raw},
+			},
+		}
+		if oldM3dt != nil {
+			oldRaw, err := json.Marshal(oldM3dt)
+			Expect(err).NotTo(HaveOccurred())
+			req.Operation = admissionv1.Update
+			req.OldObject = runtime.RawExtension{Raw: oldRaw}
+		}
+		return req
+	}
+
+	type testCaseReferenceHostWebhook struct {
+		ReferenceHost    string
+		NetworkData      *infrav1.NetworkData
+		Update           bool
+		OldReferenceHost string
+		ExpectedDenied   string
+		ExpectedWarning  string
+	}
+
+	DescribeTable("Test ReferenceHost webhook Handle",
+		func(tc testCaseReferenceHostWebhook) {
+			m3dt := newTemplate(tc.ReferenceHost, tc.NetworkData)
+			var oldM3dt *infrav1.Metal3DataTemplate
+			if tc.Update {
+				oldM3dt = newTemplate(tc.OldReferenceHost, tc.NetworkData)
+			}
+
+			response := newWebhook().Handle(context.TODO(), newRequest(m3dt, oldM3dt))
+
+			if tc.ExpectedDenied != "" {
+				Expect(response.Allowed).To(BeFalse())
+				Expect(response.Result.Message).To(ContainSubstring(tc.ExpectedDenied))
+				return
+			}
+			Expect(response.Allowed).To(BeTrue())
+			if tc.ExpectedWarning != "" {
+				Expect(response.Warnings).To(ConsistOf(ContainSubstring(tc.ExpectedWarning)))
+			} else {
+				Expect(response.Warnings).To(BeEmpty())
+			}
+		},
+		Entry("No reference host", testCaseReferenceHostWebhook{
+			NetworkData: newNetworkData(infrav1.NetworkDataLinkEthernet{
+				Type: "phy", Id: "eth9", MACAddress: fromHostInterface("eth9"),
+			}),
+		}),
+		Entry("Matching NIC references", testCaseReferenceHostWebhook{
+			ReferenceHost: "reference-host",
+			NetworkData: &infrav1.NetworkData{
+				Links: infrav1.NetworkDataLink{
+					Ethernets: []infrav1.NetworkDataLinkEthernet{
+						{Type: "phy", Id: "eth0", MACAddress: fromHostInterface("eth0")},
+						{Type: "phy", Id: "data0", MACAddress: fromHostInterface("data0")},
+					},
+					Vlans: []infrav1.NetworkDataLinkVlan{
+						{Id: "vlan1", VlanID: 1, VlanLink: "eth0", MACAddress: fromHostInterface("eth0")},
+					},
+				},
+			},
+		}),
+		Entry("Missing NIC references", testCaseReferenceHostWebhook{
+			ReferenceHost: "reference-host",
+			NetworkData: &infrav1.NetworkData{
+				Links: infrav1.NetworkDataLink{
+					Ethernets: []infrav1.NetworkDataLinkEthernet{
+						{Type: "phy", Id: "eth0", MACAddress: fromHostInterface("eth0")},
+						{Type: "phy", Id: "eth2", MACAddress: fromHostInterface("eth2")},
+					},
+					Bonds: []infrav1.NetworkDataLinkBond{
+						{Id: "bond0", BondMode: "802.3ad", MACAddress: fromHostInterface("eth3")},
+					},
+				},
+			},
+			ExpectedDenied: "has no interface eth2, eth3",
+		}),
+		Entry("Missing NIC reference of an optional link", testCaseReferenceHostWebhook{
+			ReferenceHost: "reference-host",
+			NetworkData: newNetworkData(infrav1.NetworkDataLinkEthernet{
+				Type: "phy", Id: "eth2", MACAddress: fromHostInterface("eth2"), Optional: true,
+			}),
+		}),
+		Entry("Reference host not found", testCaseReferenceHostWebhook{
+			ReferenceHost: "missing-host",
+			NetworkData: newNetworkData(infrav1.NetworkDataLinkEthernet{
+				Type: "phy", Id: "eth0", MACAddress: fromHostInterface("eth0"),
+			}),
+			ExpectedDenied: "not found",
+		}),
+		Entry("Reference host not inspected", testCaseReferenceHostWebhook{
+			ReferenceHost: "uninspected-host",
+			NetworkData: newNetworkData(infrav1.NetworkDataLinkEthernet{
+				Type: "phy", Id: "eth0", MACAddress: fromHostInterface("eth0"),
+			}),
+			ExpectedWarning: "not inspected",
+		}),
+		Entry("Update keeping a reference host which is gone", testCaseReferenceHostWebhook{
+			ReferenceHost:    "missing-host",
+			OldReferenceHost: "missing-host",
+			Update:           true,
+			NetworkData: newNetworkData(infrav1.NetworkDataLinkEthernet{
+				Type: "phy", Id: "eth2", MACAddress: fromHostInterface("eth2"),
+			}),
+		}),
+		Entry("Update to a reference host not found", testCaseReferenceHostWebhook{
+			ReferenceHost:    "missing-host",
+			OldReferenceHost: "reference-host",
+			Update:           true,
+			NetworkData: newNetworkData(infrav1.NetworkDataLinkEthernet{
+				Type: "phy", Id: "eth0", MACAddress: fromHostInterface("eth0"),
+			}),
+			ExpectedWarning: "not found",
+		}),
+		Entry("Update to a reference host with missing NIC references", testCaseReferenceHostWebhook{
+			ReferenceHost: "reference-host",
+			Update:        true,
+			NetworkData: newNetworkData(infrav1.NetworkDataLinkEthernet{
+				Type: "phy", Id: "eth2", MACAddress: fromHostInterface("eth2"),
+			}),
+			ExpectedDenied: "has no interface eth2",
+		}),
+	)
+
+	It("Allows removing the finalizer of a template whose reference host is gone", func() {
+		networkData := newNetworkData(infrav1.NetworkDataLinkEthernet{
+			Type: "phy", Id: "eth0", MACAddress: fromHostInterface("eth0"),
+		})
+		oldM3dt := newTemplate("missing-host", networkData)
+		oldM3dt.Finalizers = []string{infrav1.DataTemplateFinalizer}
+		oldM3dt.DeletionTimestamp = ptr.To(metav1.Now())
+		m3dt := oldM3dt.DeepCopy()
+		m3dt.Finalizers = nil
+
+		response := newWebhook().Handle(context.TODO(), newRequest(m3dt, oldM3dt))
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Warnings).To(BeEmpty())
+	})
+})
@@ -381,6 +381,7 @@ func newBareMetalHost(bmhName string, spec *bmov1alpha1.BareMetalHostSpec,
 			Provisioning: bmov1alpha1.ProvisionStatus{
 				State: bmov1alpha1.StateProvisioned,
 			},
+			PoweredOn: true,
 		}
 	}
 	bmh := &bmov1alpha1.BareMetalHost{
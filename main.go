@@ -51,6 +51,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/controllers/clustercache"
 	"sigs.k8s.io/cluster-api/controllers/remote"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	caipamv1 "sigs.k8s.io/cluster-api/exp/ipam/api/v1alpha1"
 	"sigs.k8s.io/cluster-api/util/flags"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -85,8 +86,10 @@ var (
 	metal3DataTemplateConcurrency    int
 	metal3DataConcurrency            int
 	metal3LabelSyncConcurrency       int
+	metal3HostInventoryConcurrency   int
 	metal3MachineTemplateConcurrency int
 	metal3RemediationConcurrency     int
+	metal3RemediationSetConcurrency  int
 	restConfigQPS                    float32
 	restConfigBurst                  int
 	webhookPort                      int
@@ -96,6 +99,45 @@ var (
 	watchFilterValue                 string
 	logOptions                       = logs.NewOptions()
 	enableBMHNameBasedPreallocation  bool
+	rerenderNetworkDataOnNICChange   bool
+	rerenderMetaDataOnSourceChange   bool
+	ipClaimCreationRate              float64
+	ipClaimCreationBurst             int
+	machineFinalizer                 string
+	remediationFinalizer             string
+	powerOnGracePeriod               time.Duration
+	unhealthyAnnotationGracePeriod   time.Duration
+	rebootAnnotationTimeout          time.Duration
+	minRemediationTimeout            time.Duration
+	preDeprovisionHookURL            string
+	preDeprovisionHookTimeout        time.Duration
+	preDeprovisionHookFailOpen       bool
+	deprovisionTimeout               time.Duration
+	forceDeprovisionOnTimeout        bool
+	postDeprovisionPower             string
+	userDataSizeThreshold            int
+	patchConflictRetries             int
+	hostReservationTTL               time.Duration
+	hostReferenceLostPolicy          string
+	hostErrorGracePeriod             time.Duration
+	maxProvisioningAttempts          int
+	maxConcurrentDeprovisions        int
+	hostRemediationCooldown          time.Duration
+	hostAnnotationLabels             []string
+	disqualifyingHostAnnotations     []string
+	machineTopologyLabels            map[string]string
+	failureDomainHostLabel           string
+	detectImageDiskFormat            bool
+	hostGoneTimeout                  time.Duration
+	hostGoneDeleteMachine            bool
+	workloadClusterTimeout           time.Duration
+	enableHostMappingEndpoint        bool
+	clearStaleUnhealthyAnnotations   bool
+	verifyRBAC                       bool
+	remediationDryRun                bool
+	auditLogLevel                    int
+	readOnly                         bool
+	nodeIPFallback                   bool
 	managerOptions                   = flags.ManagerOptions{}
 )
 
@@ -106,6 +148,7 @@ func init() {
 	_ = infrav1.AddToScheme(myscheme)
 	_ = clusterv1.AddToScheme(myscheme)
 	_ = bmov1alpha1.AddToScheme(myscheme)
+	_ = controlplanev1.AddToScheme(myscheme)
 }
 
 // Add RBAC for the authorized diagnostics endpoint.
@@ -133,6 +176,14 @@ func main() {
 		setupLog.Error(err, "Unable to start manager: invalid flags")
 		os.Exit(1)
 	}
+	if postDeprovisionPower != "" && postDeprovisionPower != infrav1.PowerOn && postDeprovisionPower != infrav1.PowerOff {
+		setupLog.Error(fmt.Errorf("invalid --post-deprovision-power %q, must be on or off", postDeprovisionPower), "Unable to start manager: invalid flags")
+		os.Exit(1)
+	}
+	if hostReferenceLostPolicy != baremetal.HostReferenceLostReclaim && hostReferenceLostPolicy != baremetal.HostReferenceLostFail {
+		setupLog.Error(fmt.Errorf("invalid --host-reference-lost-policy %q, must be Reclaim or Fail", hostReferenceLostPolicy), "Unable to start manager: invalid flags")
+		os.Exit(1)
+	}
 
 	var watchNamespaces map[string]cache.Config
 	if watchNamespace != "" {
@@ -200,6 +251,54 @@ func main() {
 	ctx := ctrl.SetupSignalHandler()
 
 	baremetal.EnableBMHNameBasedPreallocation = enableBMHNameBasedPreallocation
+	baremetal.RerenderNetworkDataOnNICChange = rerenderNetworkDataOnNICChange
+	baremetal.RerenderMetaDataOnSourceChange = rerenderMetaDataOnSourceChange
+	baremetal.IPClaimCreationRate = ipClaimCreationRate
+	baremetal.IPClaimCreationBurst = ipClaimCreationBurst
+	baremetal.MachineFinalizer = machineFinalizer
+	baremetal.RemediationFinalizer = remediationFinalizer
+	baremetal.PowerOnGracePeriod = powerOnGracePeriod
+	baremetal.UnhealthyAnnotationGracePeriod = unhealthyAnnotationGracePeriod
+	baremetal.RebootAnnotationTimeout = rebootAnnotationTimeout
+	baremetal.PreDeprovisionHookURL = preDeprovisionHookURL
+	baremetal.PreDeprovisionHookTimeout = preDeprovisionHookTimeout
+	baremetal.PreDeprovisionHookFailOpen = preDeprovisionHookFailOpen
+	baremetal.DeprovisionTimeout = deprovisionTimeout
+	baremetal.ForceDeprovisionOnTimeout = forceDeprovisionOnTimeout
+	baremetal.MaxConcurrentDeprovisions = maxConcurrentDeprovisions
+	baremetal.PostDeprovisionPower = postDeprovisionPower
+	baremetal.UserDataSizeThreshold = userDataSizeThreshold
+	baremetal.PatchConflictRetries = patchConflictRetries
+	baremetal.HostReservationTTL = hostReservationTTL
+	baremetal.HostReferenceLostPolicy = hostReferenceLostPolicy
+	baremetal.HostErrorGracePeriod = hostErrorGracePeriod
+	baremetal.MaxProvisioningAttempts = maxProvisioningAttempts
+	baremetal.HostRemediationCooldown = hostRemediationCooldown
+	baremetal.AuditLogLevel = auditLogLevel
+	baremetal.ReadOnly = readOnly
+	baremetal.NodeIPFallback = nodeIPFallback
+	baremetal.HostAnnotationLabels = hostAnnotationLabels
+	baremetal.DisqualifyingHostAnnotations = disqualifyingHostAnnotations
+	baremetal.MachineTopologyLabels = machineTopologyLabels
+	baremetal.FailureDomainHostLabel = failureDomainHostLabel
+	baremetal.DetectImageDiskFormat = detectImageDiskFormat
+	baremetal.HostGoneTimeout = hostGoneTimeout
+	baremetal.HostGoneDeleteMachine = hostGoneDeleteMachine
+	baremetal.WorkloadClusterTimeout = workloadClusterTimeout
+	baremetal.EventRecorder = mgr.GetEventRecorderFor(controllerName)
+
+	if enableHostMappingEndpoint {
+		if err := mgr.AddMetricsServerExtraHandler(baremetal.HostMappingPath,
+			baremetal.HostMappingHandler(mgr.GetClient()),
+		); err != nil {
+			setupLog.Error(err, "unable to add host mapping endpoint")
+			os.Exit(1)
+		}
+	}
+
+	if verifyRBAC {
+		verifyPermissions(ctx, restConfig)
+	}
 
 	setupChecks(mgr)
 	setupReconcilers(ctx, mgr)
@@ -231,6 +330,279 @@ func initFlags(fs *pflag.FlagSet) {
 		"If set to true, it enables PreAllocation field to use Metal3IPClaim name structured with BaremetalHost and M3IPPool names",
 	)
 
+	fs.BoolVar(
+		&rerenderNetworkDataOnNICChange,
+		"rerender-network-data-on-nic-change",
+		false,
+		"If set to true, the networkData secret of a Metal3Data is re-rendered when the NICs of its BareMetalHost change",
+	)
+
+	fs.BoolVar(
+		&rerenderMetaDataOnSourceChange,
+		"rerender-metadata-on-source-change",
+		false,
+		"If set to true, the Secrets and ConfigMaps referenced in the metadata of Metal3DataTemplates are watched and the metaData secret of a Metal3Data is re-rendered when they change",
+	)
+
+	fs.Float64Var(
+		&ipClaimCreationRate,
+		"ipclaim-creation-rate",
+		0,
+		"Maximum number of IP claims created per second from a single IP pool. Zero disables the limit.",
+	)
+
+	fs.IntVar(
+		&ipClaimCreationBurst,
+		"ipclaim-creation-burst",
+		1,
+		"Number of IP claims created at once from a single IP pool before --ipclaim-creation-rate applies.",
+	)
+
+	fs.StringVar(
+		&machineFinalizer,
+		"machine-finalizer",
+		infrav1.MachineFinalizer,
+		"Finalizer set on the Metal3Machines and their Metal3DataClaims. Use distinct values when running several instances on the same resources.",
+	)
+
+	fs.StringVar(
+		&remediationFinalizer,
+		"remediation-finalizer",
+		infrav1.RemediationFinalizer,
+		"Finalizer set on the Metal3Remediations. Use distinct values when running several instances on the same resources.",
+	)
+
+	fs.DurationVar(
+		&powerOnGracePeriod,
+		"power-on-grace-period",
+		0,
+		"Duration a provisioned BareMetalHost must be reported as powered on before its Metal3Machine is marked ready (e.g. 30s). Zero disables the grace period.",
+	)
+
+	fs.DurationVar(
+		&unhealthyAnnotationGracePeriod,
+		"unhealthy-annotation-grace-period",
+		0,
+		"Duration a remediated Machine must stay healthy before the unhealthy annotation is removed from its BareMetalHost (e.g. 5m). Zero removes it as soon as the Machine is healthy.",
+	)
+
+	fs.BoolVar(
+		&clearStaleUnhealthyAnnotations,
+		"clear-stale-unhealthy-annotations",
+		false,
+		"Remove the unhealthy annotation from the BareMetalHosts whose node has been Ready for the unhealthy annotation grace period and whose Machine is not being remediated.",
+	)
+
+	fs.BoolVar(
+		&verifyRBAC,
+		"verify-rbac",
+		false,
+		"If set to true, the key RBAC permissions of the controllers are verified with SelfSubjectAccessReviews at startup and an error is logged for each missing one.",
+	)
+
+	fs.DurationVar(
+		&rebootAnnotationTimeout,
+		"reboot-annotation-timeout",
+		10*time.Minute,
+		"Duration after which a remediation poweroff annotation not acted upon by the BareMetal Operator is removed from its BareMetalHost (e.g. 10m). Zero disables the timeout and keeps the annotation until the host is powered off.",
+	)
+
+	fs.DurationVar(
+		&minRemediationTimeout,
+		"min-remediation-timeout",
+		infrav1.DefaultMinRemediationTimeout,
+		"Minimum timeout of the remediation strategies accepted by the webhooks, to avoid rebooting hosts again before their node can recover (e.g. 1m).",
+	)
+
+	fs.StringVar(
+		&preDeprovisionHookURL,
+		"pre-deprovision-hook-url",
+		"",
+		"URL called with a POST request before a BareMetalHost is deprovisioned. Deprovisioning waits for a 2xx answer. If unspecified, no hook is called.",
+	)
+
+	fs.DurationVar(
+		&preDeprovisionHookTimeout,
+		"pre-deprovision-hook-timeout",
+		30*time.Second,
+		"Timeout of the pre-deprovision hook call (e.g. 30s).",
+	)
+
+	fs.BoolVar(
+		&preDeprovisionHookFailOpen,
+		"pre-deprovision-hook-fail-open",
+		false,
+		"If set to true, deprovisioning proceeds when the pre-deprovision hook fails or times out, instead of retrying the hook.",
+	)
+
+	fs.DurationVar(
+		&deprovisionTimeout,
+		"deprovision-timeout",
+		0,
+		"Duration after which a BareMetalHost still deprovisioning sets the DeprovisionStuck condition on its Metal3Machine (e.g. 1h). Zero disables the timeout.",
+	)
+
+	fs.BoolVar(
+		&forceDeprovisionOnTimeout,
+		"force-deprovision-on-timeout",
+		false,
+		"If set to true, automated cleaning is disabled on a BareMetalHost hitting the deprovision timeout, so that it becomes available without being cleaned.",
+	)
+
+	fs.IntVar(
+		&maxConcurrentDeprovisions,
+		"max-concurrent-deprovisions",
+		0,
+		"Number of BareMetalHosts of a cluster deprovisioned at once by the Metal3Machine controller, so that tearing down a large cluster does not overload the provisioning network. Zero disables the limit.",
+	)
+
+	fs.StringVar(
+		&postDeprovisionPower,
+		"post-deprovision-power",
+		"",
+		"Power state (on or off) the BareMetalHosts are left in once deprovisioned, overridden by metal3Machine.spec.postDeprovisionPower. When empty, hosts are powered off unless CAPM3_FAST_TRACK is true and automated cleaning is not disabled.",
+	)
+
+	fs.IntVar(
+		&userDataSizeThreshold,
+		"userdata-size-threshold",
+		0,
+		"Size in bytes above which the userdata rendered for a Metal3Data sets its UserDataSize condition to false, as some BMCs or firmware fail on large userdata. Zero disables the check.",
+	)
+
+	fs.IntVar(
+		&patchConflictRetries,
+		"patch-conflict-retries",
+		3,
+		"Number of times a patch of a BareMetalHost by the remediation controller failing with a conflict is retried on the refetched host.",
+	)
+
+	fs.DurationVar(
+		&hostReservationTTL,
+		"host-reservation-ttl",
+		0,
+		"Duration after which a BareMetalHost associated with a Metal3Machine but not being provisioned is released and the Metal3Machine associated again (e.g. 1h). Zero disables the release.",
+	)
+
+	fs.StringVar(
+		&hostReferenceLostPolicy,
+		"host-reference-lost-policy",
+		baremetal.HostReferenceLostReclaim,
+		"Behavior when the consumerRef of the BareMetalHost of a Metal3Machine no longer references it, e.g. after being cleared manually: Reclaim sets it again if the host is not consumed by anything else, Fail marks the Metal3Machine failed.",
+	)
+
+	fs.DurationVar(
+		&hostErrorGracePeriod,
+		"host-error-grace-period",
+		0,
+		"Duration a BareMetalHost must have been in an error state continuously before its Metal3Machine is marked failed (e.g. 10m). Zero disables marking the Metal3Machine failed.",
+	)
+
+	fs.IntVar(
+		&maxProvisioningAttempts,
+		"max-provisioning-attempts",
+		0,
+		"Number of provisioning attempts of a BareMetalHost after which a failed provisioning marks its Metal3Machine failed, instead of the host being cleaned and provisioned again. Zero disables the limit.",
+	)
+
+	fs.IntVar(
+		&auditLogLevel,
+		"audit-log-level",
+		-1,
+		"Log verbosity of the audit lines recording each change made to a BareMetalHost before it is patched. A negative level, the default, disables the audit log.",
+	)
+
+	fs.BoolVar(
+		&nodeIPFallback,
+		"node-ip-fallback",
+		false,
+		"Resolve the node of a Metal3Machine by its internal IP addresses when no node has the label of its BareMetalHost, e.g. when the kubelet does not set the label.",
+	)
+
+	fs.BoolVar(
+		&readOnly,
+		"read-only",
+		false,
+		"Run the controllers without writing to the BareMetalHosts, the other objects they manage and the nodes of the workload clusters, nor calling the pre-deprovision hook. The writes are logged instead, only the status and conditions of the reconciled objects are updated.",
+	)
+
+	fs.DurationVar(
+		&hostRemediationCooldown,
+		"host-remediation-cooldown",
+		0,
+		"Duration after a remediation of a BareMetalHost during which it is not chosen for a new Metal3Machine (e.g. 30m). Zero disables the cooldown.",
+	)
+
+	fs.StringSliceVar(
+		&hostAnnotationLabels,
+		"host-annotation-labels",
+		[]string{},
+		"Comma-separated list of Machine labels copied as annotations onto the associated BareMetalHost and kept in sync (e.g. example.com/cost-center,example.com/project).",
+	)
+
+	fs.StringSliceVar(
+		&disqualifyingHostAnnotations,
+		"disqualifying-host-annotations",
+		[]string{},
+		"Comma-separated list of annotation keys excluding the BareMetalHosts carrying any of them from being chosen for a Metal3Machine (e.g. metal3.io/problem).",
+	)
+
+	fs.StringToStringVar(
+		&machineTopologyLabels,
+		"machine-topology-labels",
+		map[string]string{},
+		"Comma-separated list of Machine label keys mapped to BareMetalHost label keys, only the hosts with the value of the Machine label being chosen for its Metal3Machine (e.g. topology.example.com/zone=topology.kubernetes.io/zone).",
+	)
+
+	fs.StringVar(
+		&failureDomainHostLabel,
+		"failure-domain-host-label",
+		"",
+		"Key of the BareMetalHost label holding the failure domain of the host, only the hosts in the failure domain of the Machine being chosen for its Metal3Machine (e.g. topology.kubernetes.io/zone).",
+	)
+
+	fs.BoolVar(
+		&detectImageDiskFormat,
+		"detect-image-disk-format",
+		true,
+		"Infer the disk format of the images that do not set it from the extension of their URL (qcow2, raw, vdi or vmdk).",
+	)
+
+	fs.DurationVar(
+		&hostGoneTimeout,
+		"remediation-host-gone-timeout",
+		0,
+		"Duration after which a remediation whose BareMetalHost cannot be found anymore is moved to the HostGone phase (e.g. 10m). Defaults to 0, which disables the timeout and keeps retrying forever.",
+	)
+
+	fs.BoolVar(
+		&hostGoneDeleteMachine,
+		"remediation-host-gone-delete-machine",
+		false,
+		"Hand the Machine of a remediation whose BareMetalHost is gone over to Cluster API for deletion, once --remediation-host-gone-timeout is over. Defaults to false, which leaves the Machine untouched.",
+	)
+
+	fs.DurationVar(
+		&workloadClusterTimeout,
+		"workload-cluster-timeout",
+		30*time.Second,
+		"Timeout of each call to the API server of a workload cluster during remediation (node get, update and delete, pod listing). Zero disables the timeout.",
+	)
+
+	fs.BoolVar(
+		&remediationDryRun,
+		"remediation-dry-run",
+		false,
+		"Run all the remediations in dry-run mode: they go through their phases but only log the actions they would take on the hosts, nodes and Machines.",
+	)
+
+	fs.BoolVar(
+		&enableHostMappingEndpoint,
+		"enable-host-mapping-endpoint",
+		false,
+		"Serve the read-only mapping of BareMetalHosts to Metal3Machines and nodes as JSON on the "+baremetal.HostMappingPath+" path of the metrics server.",
+	)
+
 	fs.DurationVar(
 		&leaderElectionLeaseDuration,
 		"leader-elect-lease-duration",
@@ -323,12 +695,18 @@ func initFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&metal3LabelSyncConcurrency, "metal3labelsync-concurrency", 10,
 		"Number of metal3labelsyncs to process simultaneously")
 
+	fs.IntVar(&metal3HostInventoryConcurrency, "metal3hostinventory-concurrency", 10,
+		"Number of metal3hostinventories to process simultaneously")
+
 	fs.IntVar(&metal3MachineTemplateConcurrency, "metal3machinetemplate-concurrency", 10,
 		"Number of metal3machinetemplates to process simultaneously")
 
 	fs.IntVar(&metal3RemediationConcurrency, "metal3remediation-concurrency", 10,
 		"Number of metal3remediations to process simultaneously")
 
+	fs.IntVar(&metal3RemediationSetConcurrency, "metal3remediationset-concurrency", 10,
+		"Number of metal3remediationsets to process simultaneously")
+
 	fs.Float32Var(&restConfigQPS, "kube-api-qps", 20,
 		"Maximum queries per second from the controller client to the Kubernetes API server. Default 20")
 
@@ -363,6 +741,31 @@ func waitForAPIs(cfg *rest.Config) error {
 	return nil
 }
 
+// verifyPermissions logs an error for each of the key permissions the
+// controllers are not granted, to report insufficient RBAC before reconciling.
+func verifyPermissions(ctx context.Context, cfg *rest.Config) {
+	clientSet, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		setupLog.Error(err, "unable to create the client to verify the RBAC permissions")
+		return
+	}
+	missing, err := baremetal.MissingPermissions(ctx, clientSet.AuthorizationV1().SelfSubjectAccessReviews(),
+		watchNamespace, baremetal.RequiredPermissions,
+	)
+	if err != nil {
+		setupLog.Error(err, "unable to verify the RBAC permissions")
+		return
+	}
+	for _, permission := range missing {
+		setupLog.Error(nil, "Missing RBAC permission, check the ClusterRole and RoleBindings of the manager",
+			"permission", permission.String(), "namespace", watchNamespace,
+		)
+	}
+	if len(missing) == 0 {
+		setupLog.Info("RBAC permissions verified")
+	}
+}
+
 func setupChecks(mgr ctrl.Manager) {
 	if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
 		setupLog.Error(err, "unable to create ready check")
@@ -474,6 +877,18 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		os.Exit(1)
 	}
 
+	if clearStaleUnhealthyAnnotations {
+		if err := (&controllers.Metal3HostInventoryReconciler{
+			Client:           mgr.GetClient(),
+			Log:              ctrl.Log.WithName("controllers").WithName("Metal3HostInventory"),
+			CapiClientGetter: infraremote.NewClusterClient,
+			WatchFilterValue: watchFilterValue,
+		}).SetupWithManager(ctx, mgr, concurrency(metal3HostInventoryConcurrency)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Metal3HostInventoryReconciler")
+			os.Exit(1)
+		}
+	}
+
 	if err := (&controllers.Metal3MachineTemplateReconciler{
 		Client:         mgr.GetClient(),
 		ClusterCache:   clusterCache,
@@ -494,10 +909,20 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		ManagerFactory:             baremetal.NewManagerFactory(mgr.GetClient()),
 		Log:                        ctrl.Log.WithName("controllers").WithName("Metal3Remediation"),
 		IsOutOfServiceTaintEnabled: isOOSTSupported,
+		DryRun:                     remediationDryRun,
 	}).SetupWithManager(ctx, mgr, concurrency(metal3RemediationConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Metal3Remediation")
 		os.Exit(1)
 	}
+
+	if err := (&controllers.Metal3RemediationSetReconciler{
+		Client:         mgr.GetClient(),
+		ManagerFactory: baremetal.NewManagerFactory(mgr.GetClient()),
+		Log:            ctrl.Log.WithName("controllers").WithName("Metal3RemediationSet"),
+	}).SetupWithManager(ctx, mgr, concurrency(metal3RemediationSetConcurrency)); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Metal3RemediationSet")
+		os.Exit(1)
+	}
 }
 
 func setupWebhooks(mgr ctrl.Manager) {
@@ -516,11 +941,27 @@ func setupWebhooks(mgr ctrl.Manager) {
 		os.Exit(1)
 	}
 
-	if err := (&infrav1.Metal3DataTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+	if err := (&controllers.HostSelectorWebhook{
+		Client: mgr.GetClient(),
+	}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "HostSelector")
+		os.Exit(1)
+	}
+
+	if err := (&infrav1.Metal3DataTemplateWebhook{
+		Client: mgr.GetClient(),
+	}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Metal3DataTemplate")
 		os.Exit(1)
 	}
 
+	if err := (&controllers.ReferenceHostWebhook{
+		Client: mgr.GetClient(),
+	}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ReferenceHost")
+		os.Exit(1)
+	}
+
 	if err := (&infrav1.Metal3Data{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Metal3Data")
 		os.Exit(1)
@@ -531,12 +972,16 @@ func setupWebhooks(mgr ctrl.Manager) {
 		os.Exit(1)
 	}
 
-	if err := (&infrav1.Metal3Remediation{}).SetupWebhookWithManager(mgr); err != nil {
+	if err := (&infrav1.Metal3RemediationWebhook{
+		MinRemediationTimeout: minRemediationTimeout,
+	}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Metal3Remediation")
 		os.Exit(1)
 	}
 
-	if err := (&infrav1.Metal3RemediationTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+	if err := (&infrav1.Metal3RemediationTemplateWebhook{
+		MinRemediationTimeout: minRemediationTimeout,
+	}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Metal3RemediationTemplate")
 		os.Exit(1)
 	}